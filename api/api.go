@@ -0,0 +1,10 @@
+// Package api implements the HTTP endpoints used by external systems, such as
+// home automation servers, to query and control the device.
+//
+// All endpoints are registered with an http.Server under the path prefix
+// "/api/v1/". Endpoints that change the state of the device require the secret
+// of the Settings, if one is set (see guard).
+package api
+
+// Prefix defines the path prefix of all endpoints.
+const Prefix = "/api/v1/"
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/ardnew/weatherhub/asset"
+	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Assets registers the endpoint "PUT /api/v1/assets", which writes an archive
+// of images (see package asset) to the given Region of flash, and shows them
+// once the archive is complete.
+//
+// Since request bodies are limited in size, the archive is written in parts,
+// each given the offset of its first byte by the query parameter "offset", e.g.:
+//
+//	split -b 2048 -d assets.bin part.
+//	for p in part.*; do
+//	  curl -T $p "http://weatherhub/api/v1/assets?offset=$((10#${p#part.} * 2048))"
+//	done
+//
+// The part at offset 0 erases the previous archive. Each part is answered with
+// 204 once the archive written so far is complete and valid, or with 202 if it
+// is still incomplete.
+func Assets(server *http.Server, region *asset.Region, disp *display.Display) {
+
+	server.Handle("PUT", Prefix+"assets", guard(func(w *http.ResponseWriter, r *http.Request) {
+
+		off, err := strconv.ParseInt(r.Param("offset"), 10, 64)
+		if nil != err || r.ContentLength <= 0 {
+			w.Error(400)
+			return
+		}
+		part := make([]byte, r.ContentLength)
+		if _, err := io.ReadFull(r.Body, part); nil != err {
+			w.Error(400)
+			return
+		}
+		if err := region.Write(off, part); nil != err {
+			if asset.ErrSize == err {
+				w.Error(413)
+			} else {
+				w.Error(500)
+			}
+			return
+		}
+
+		images, err := region.Load()
+		if nil != err {
+			w.WriteHeader(202, "text/plain") // incomplete
+			return
+		}
+		disp.SetImages(images)
+		w.WriteHeader(204, "text/plain")
+	}))
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// realm identifies the protection space of HTTP basic authentication, shown by
+// browsers when prompting for credentials.
+const realm = "weatherhub"
+
+// guard returns a Handler that calls handle only if the request is authorized
+// by the Secret of the Settings (see config.Authorized), and otherwise replies
+// with 401, prompting browsers for credentials.
+//
+// Clients authenticate with either a bearer token or HTTP basic authentication
+// with any user name, e.g.:
+//
+//	curl -H "Authorization: Bearer <secret>" ...
+//	curl -u ":<secret>" ...
+func guard(handle http.Handler) http.Handler {
+	return func(w *http.ResponseWriter, r *http.Request) {
+		if !config.Authorized(r.Secret()) {
+			w.Header("WWW-Authenticate", `Basic realm="`+realm+`"`)
+			w.Error(401)
+			return
+		}
+		handle(w, r)
+	}
+}
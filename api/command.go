@@ -0,0 +1,48 @@
+package api
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/ardnew/weatherhub/command"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// MaxCommandLine defines the length of the longest command line accepted.
+const MaxCommandLine = 80
+
+// Command registers the endpoint "POST /api/v1/command", which performs the
+// command line given by the request body (see package command), e.g.:
+//
+//	curl -d 'set brightness 40' http://weatherhub/api/v1/command
+//
+// The output of the command is returned as plain text. These are the same
+// commands accepted by the serial console.
+func Command(server *http.Server) {
+
+	server.Handle("POST", Prefix+"command", guard(func(w *http.ResponseWriter, r *http.Request) {
+
+		var line [MaxCommandLine + 1]byte
+		n, err := io.ReadFull(r.Body, line[:])
+		if nil != err && io.ErrUnexpectedEOF != err && io.EOF != err {
+			w.Error(400)
+			return
+		}
+		if nil == err {
+			w.Error(413) // line too long
+			return
+		}
+
+		var out bytes.Buffer
+		switch command.Exec(&out, string(line[:n])) {
+		case nil:
+			w.WriteHeader(200, "text/plain")
+		case command.ErrUnknown:
+			w.Error(404)
+			return
+		default:
+			w.WriteHeader(400, "text/plain")
+		}
+		w.Write(out.Bytes())
+	}))
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"github.com/ardnew/weatherhub/buzzer"
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/feed/webhook"
+	"github.com/ardnew/weatherhub/theme"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Config registers the endpoints "GET /api/v1/config", which exports the
+// Settings adjustable at runtime, "POST /api/v1/config", which changes some of
+// them, and "PUT /api/v1/config", which imports all of them, each encoded as a
+// JSON object (see config.Settings Append).
+//
+// POST changes only the fields present in the request. PUT replaces all of the
+// Settings, so that those exported by GET may be restored after reflashing or
+// cloned to another device. Fields absent from the request are reset, except
+// for the secrets, which are kept unless given.
+//
+// The field "secret" sets the secret required by control endpoints (see guard),
+// or disables authentication if empty. The secrets, i.e., "secret", the WiFi
+// passphrase, and the API keys, are only exported if the query parameter
+// "secrets" is "1".
+//
+// The reply of GET also includes the names of every page and built-in theme,
+// for use by the configuration UI, which are ignored when imported.
+func Config(server *http.Server, disp *display.Display) {
+
+	server.Handle("GET", Prefix+"config", guard(func(w *http.ResponseWriter, r *http.Request) {
+
+		b := config.Get().Append(nil, "1" == r.Param("secrets"))
+		b = b[:len(b)-1] // reopen the object to append the names
+		b = appendNames(append(b, `,"pages":`...), disp.Pages())
+		b = appendNames(append(b, `,"themes":`...), theme.Names())
+		b = append(b, "}\n"...)
+
+		w.WriteHeader(200, "application/json")
+		w.Write(b)
+	}))
+
+	update := func(w *http.ResponseWriter, r *http.Request) {
+
+		s := config.Get()
+		if "PUT" == r.Method {
+			s = s.Secrets()
+		}
+		err := s.Decode(r.Body)
+		if nil == err {
+			err = validate(s, disp.Pages())
+		}
+		if nil != err {
+			w.Error(400)
+			return
+		}
+		config.Set(func(c *config.Settings) {
+			*c = s
+		})
+		w.WriteHeader(204, "text/plain")
+	}
+	server.Handle("POST", Prefix+"config", guard(update))
+	server.Handle("PUT", Prefix+"config", guard(update))
+}
+
+// validate returns config.ErrInvalidSetting if any of the Settings cannot be
+// applied.
+func validate(s config.Settings, pages []string) error {
+	loc := s.Location
+	if loc.Latitude < -90 || loc.Latitude > 90 ||
+		loc.Longitude < -180 || loc.Longitude > 180 {
+		return config.ErrInvalidSetting
+	}
+	if _, ok := theme.Lookup(s.Theme); !ok && "" != s.Theme {
+		return config.ErrInvalidSetting
+	}
+	if nil != display.ValidClocks(s.Clocks) {
+		return config.ErrInvalidSetting
+	}
+	for _, m := range [...]string{s.Melody.Alarm, s.Melody.Alert, s.Melody.Chime} {
+		if _, err := buzzer.Parse(m); "" != m && nil != err {
+			return config.ErrInvalidSetting
+		}
+	}
+	for _, h := range s.Webhooks {
+		if _, ok := webhook.ParseEvent(h.Event); !ok {
+			return config.ErrInvalidSetting
+		}
+	}
+	for _, h := range s.Hidden {
+		known := false
+		for _, p := range pages {
+			known = known || p == h
+		}
+		if !known {
+			return config.ErrInvalidSetting
+		}
+	}
+	return nil
+}
+
+// appendNames appends the given names to b as a JSON array of strings.
+func appendNames(b []byte, name []string) []byte {
+	b = append(b, '[')
+	for i, n := range name {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = json.AppendString(b, n)
+	}
+	return append(b, ']')
+}
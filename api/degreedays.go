@@ -0,0 +1,55 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// DegreeDays registers the endpoint "GET /api/v1/degreedays", which reports the
+// base temperatures and the degree days accumulated over the current and
+// previous month (see model.DegreeDays), in °C and °C-days, e.g.:
+//
+//	{"base": {"heating": 18.0, "cooling": 18.0, "growing": 10.0, "cap": 30.0},
+//	 "month": {"start": "2024-05-01", "days": 14,
+//	  "heating": 31.5, "cooling": 4.0, "growing": 98.5},
+//	 "previous": null}
+func DegreeDays(server *http.Server) {
+
+	server.Handle("GET", Prefix+"degreedays", func(w *http.ResponseWriter, r *http.Request) {
+
+		dd := model.Peek().DegreeDays
+		b := append([]byte(`{"base":{"heating":`), formatDegrees(dd.Base.Heating)...)
+		b = append(append(b, `,"cooling":`...), formatDegrees(dd.Base.Cooling)...)
+		b = append(append(b, `,"growing":`...), formatDegrees(dd.Base.Growing)...)
+		b = append(append(b, `,"cap":`...), formatDegrees(dd.Base.Cap)...)
+		b = appendMonth(append(b, `},"month":`...), dd.Month)
+		b = appendMonth(append(b, `,"previous":`...), dd.Previous)
+		b = append(b, "}\n"...)
+
+		w.WriteHeader(200, "application/json")
+		w.Write(b)
+	})
+}
+
+// appendMonth appends the degree days of the given month to b as a JSON object,
+// or null if none were accumulated.
+func appendMonth(b []byte, m model.DegreeMonth) []byte {
+	if !m.Valid() {
+		return append(b, "null"...)
+	}
+	b = append(b, `{"start":"`...)
+	b = append(m.Start.AppendFormat(b, "2006-01-02"), `","days":`...)
+	b = strconv.AppendInt(b, int64(m.Days), 10)
+	b = append(append(b, `,"heating":`...), formatDegrees(m.Heating)...)
+	b = append(append(b, `,"cooling":`...), formatDegrees(m.Cooling)...)
+	b = append(append(b, `,"growing":`...), formatDegrees(m.Growing)...)
+	return append(b, '}')
+}
+
+// formatDegrees returns the given temperature or degree days with one digit
+// following the decimal point.
+func formatDegrees(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', 1, 32)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Display registers the endpoint "POST /api/v1/display", which adjusts the
+// settings of the display at runtime, e.g., while tuning the timing of a panel
+// that ghosts or flickers.
+//
+// The timing preset is given either by the query parameter "timing" or by the
+// field of the same name in a JSON request body:
+//
+//	{"timing": "smooth"}
+//
+// The preset in effect is shown on the diagnostics page.
+func Display(server *http.Server, disp *display.Display) {
+
+	server.Handle("POST", Prefix+"display", guard(func(w *http.ResponseWriter, r *http.Request) {
+
+		name := r.Param("timing")
+		if r.ContentLength > 0 {
+			err := json.Extract(r.Body, func(path []byte, kind json.Kind, value []byte) error {
+				if "timing" == string(path) {
+					name = string(value)
+				}
+				return nil
+			})
+			if nil != err {
+				w.Error(400)
+				return
+			}
+		}
+
+		timing, ok := display.ParseTiming(name)
+		if !ok {
+			w.Error(400)
+			return
+		}
+		if err := disp.SetTiming(timing); nil != err {
+			w.Error(500) // previous preset restored
+			return
+		}
+		w.WriteHeader(204, "text/plain")
+	}))
+}
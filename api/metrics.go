@@ -0,0 +1,89 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Metrics registers the endpoint "GET /api/v1/metrics", which reports the
+// diagnostics of the device in the text format scraped by Prometheus, e.g.:
+//
+//	weatherhub_uptime_seconds 86400
+//	weatherhub_boots_total 12
+//	weatherhub_connects_total 3
+//	weatherhub_ping_up{target="gateway"} 1
+//	weatherhub_ping_rtt_seconds{target="gateway"} 0.003
+//	weatherhub_ping_up{target="internet"} 0
+//	weatherhub_ntp_offset_seconds -0.012
+//	weatherhub_ntp_delay_seconds 0.038
+//	weatherhub_ntp_age_seconds 5400
+//	weatherhub_quota_used{host="api.n2yo.com"} 41
+//	weatherhub_quota_limit{host="api.n2yo.com"} 1000
+//
+// Unlike the status endpoint, which describes the device, every value is a
+// number, so that it may be graphed over time, e.g., to tell problems with the
+// WiFi (the gateway) from those beyond the router (the internet). Samples of
+// values not known are omitted, e.g., the round-trip time of a ping without a
+// reply, or the time synchronization before the first.
+func Metrics(server *http.Server) {
+
+	server.Handle("GET", Prefix+"metrics", func(w *http.ResponseWriter, r *http.Request) {
+
+		data := model.Peek()
+		d := data.Diagnostics
+		b := appendSample(nil, "uptime_seconds", "", "", float64(d.Uptime()/time.Second))
+		if 0 != d.Boots {
+			b = appendSample(b, "boots_total", "", "", float64(d.Boots))
+		}
+		b = appendSample(b, "connects_total", "", "", float64(d.Connects))
+		b = appendPing(b, "gateway", d.Gateway)
+		b = appendPing(b, "internet", d.Internet)
+		if s := d.TimeSync; !s.Synced.IsZero() {
+			b = appendSample(b, "ntp_offset_seconds", "", "", s.Offset.Seconds())
+			b = appendSample(b, "ntp_delay_seconds", "", "", s.Delay.Seconds())
+			b = appendSample(b, "ntp_age_seconds", "", "", float64(data.Time.Sub(s.Synced)/time.Second))
+		}
+		for _, q := range d.Quota {
+			if "" != q.Host {
+				b = appendSample(b, "quota_used", "host", q.Host, float64(q.Used))
+				b = appendSample(b, "quota_limit", "host", q.Host, float64(q.Limit))
+			}
+		}
+
+		w.WriteHeader(200, "text/plain; version=0.0.4")
+		w.Write(b)
+	})
+}
+
+// appendPing appends the samples of the given ping of target to b: whether a
+// reply was received, and if so, its round-trip time.
+func appendPing(b []byte, target string, p model.Ping) []byte {
+	if "" == p.Host {
+		return b // not yet pinged
+	}
+	up := 0.0
+	if p.OK {
+		up = 1
+	}
+	b = appendSample(b, "ping_up", "target", target, up)
+	if p.OK {
+		b = appendSample(b, "ping_rtt_seconds", "target", target, p.RTT.Seconds())
+	}
+	return b
+}
+
+// appendSample appends a line of the metric with the given name, prefixed by
+// "weatherhub_", and value to b, with the given label unless empty. Values of
+// labels are host names, which need no escaping.
+func appendSample(b []byte, name, label, value string, v float64) []byte {
+	b = append(append(b, "weatherhub_"...), name...)
+	if "" != label {
+		b = append(append(append(append(b, '{'), label...), `="`...), value...)
+		b = append(b, `"}`...)
+	}
+	b = strconv.AppendFloat(append(b, ' '), v, 'g', -1, 64)
+	return append(b, '\n')
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for notify endpoint configuration.
+const (
+	DefaultEventDuration = 5 * time.Second
+)
+
+// Event defines the appearance of the notice posted for a named event.
+type Event struct {
+	Text     string
+	Icon     string
+	Flash    model.Flash
+	Color    color.RGBA
+	Duration time.Duration
+	Priority model.Priority
+}
+
+// DefaultEvents defines the events recognized by default.
+var DefaultEvents = map[string]Event{
+	"doorbell": {
+		Text:     "Doorbell",
+		Icon:     "bell",
+		Flash:    model.FlashBlink,
+		Color:    color.RGBA{R: 0xFF, G: 0xC0, B: 0x00, A: 0xFF},
+		Duration: 10 * time.Second,
+		Priority: model.PriorityHigh,
+	},
+	"motion": {
+		Text:     "Motion",
+		Icon:     "motion",
+		Flash:    model.FlashBorder,
+		Color:    color.RGBA{R: 0xFF, G: 0x40, B: 0x00, A: 0xFF},
+		Duration: 5 * time.Second,
+		Priority: model.PriorityNormal,
+	},
+	"package": {
+		Text:     "Delivery",
+		Icon:     "package",
+		Flash:    model.FlashNone,
+		Color:    color.RGBA{R: 0x80, G: 0xFF, B: 0x00, A: 0xFF},
+		Duration: 10 * time.Second,
+		Priority: model.PriorityNormal,
+	},
+}
+
+// NotifyConfig defines the events recognized by the notify endpoint.
+type NotifyConfig struct {
+	Events map[string]Event // keyed by event name
+}
+
+// Notify registers the endpoint "POST /api/v1/notify", which posts a notice
+// for an event, e.g., a doorbell press or motion detected by a camera.
+//
+// The event name is given either by the query parameter "event" or by the
+// field of the same name in a JSON request body. The appearance configured for
+// the event may be overridden with the optional body fields:
+//
+//	{"event": "doorbell", "text": "Front door", "icon": "bell",
+//	 "flash": "strobe", "color": "#FF0000", "duration": 5}
+//
+// where duration is given in seconds. Names of events not configured are still
+// accepted, using the body fields alone, so that the request is rejected with
+// 400 Bad Request unless it gives the text. A notice that cannot be queued, since
+// the queue is full of notices of greater priority, is rejected with 503 Service
+// Unavailable, so that the client may try again later.
+func Notify(server *http.Server, config NotifyConfig) {
+
+	if nil == config.Events {
+		config.Events = DefaultEvents
+	}
+
+	server.Handle("POST", Prefix+"notify", guard(func(w *http.ResponseWriter, r *http.Request) {
+
+		// collect the request fields before looking up the event's appearance.
+		var text, icon, flash, tint, seconds string
+		name := r.Param("event")
+		if r.ContentLength > 0 {
+			err := json.Extract(r.Body, func(path []byte, kind json.Kind, value []byte) error {
+				switch string(path) {
+				case "event":
+					name = string(value)
+				case "text":
+					text = string(value)
+				case "icon":
+					icon = string(value)
+				case "flash":
+					flash = string(value)
+				case "color":
+					tint = string(value)
+				case "duration":
+					seconds = string(value)
+				}
+				return nil
+			})
+			if nil != err {
+				w.Error(400)
+				return
+			}
+		}
+
+		ev, ok := config.Events[name]
+		if !ok {
+			ev = Event{Color: notify.ErrorColor}
+		}
+		if "" != text {
+			ev.Text = text
+		}
+		if "" != icon {
+			ev.Icon = icon
+		}
+		if f, ok := notify.ParseFlash(flash); ok {
+			ev.Flash = f
+		}
+		if c, ok := notify.ParseColor(tint); ok {
+			ev.Color = c
+		}
+		if sec, err := strconv.ParseFloat(seconds, 64); nil == err && sec > 0 {
+			ev.Duration = time.Duration(sec * float64(time.Second))
+		}
+		if 0 == ev.Duration {
+			ev.Duration = DefaultEventDuration
+		}
+		if "" == ev.Text {
+			w.Error(400)
+			return
+		}
+
+		if !notify.Post(model.Notice{
+			ID:       "notify/" + name,
+			Text:     ev.Text,
+			Icon:     ev.Icon,
+			Color:    ev.Color,
+			Flash:    ev.Flash,
+			Priority: ev.Priority,
+			Dismiss:  model.DismissShown,
+			Duration: ev.Duration,
+		}) {
+			w.Error(503) // queue is full of notices with greater priority
+			return
+		}
+		w.WriteHeader(204, "text/plain")
+	}))
+}
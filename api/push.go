@@ -0,0 +1,118 @@
+package api
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for push endpoint configuration.
+const (
+	DefaultPushInterval   = 10 * time.Second
+	DefaultPushMaxClients = 2
+)
+
+// PushConfig defines how often the push endpoint sends the status object, and
+// to how many clients at once.
+type PushConfig struct {
+	Status     StatusConfig
+	Interval   time.Duration
+	MaxClients int // each client occupies one socket of the WiFi coprocessor
+}
+
+// Pusher is a Feed that sends the status object to the clients of the push
+// endpoint periodically.
+type Pusher struct {
+	config   PushConfig
+	client   []pushClient
+	lastSync time.Time
+}
+
+type pushClient struct {
+	ws   *http.WebSocket
+	cbor bool
+}
+
+// Push registers the endpoint "GET /api/v1/push", which upgrades the request to
+// a WebSocket over which the status object of the status endpoint is pushed
+// once on connecting, and then periodically.
+//
+// Each message is a JSON text message, or, with the query parameter
+// "format=cbor", a binary message encoded as CBOR instead (see package cbor).
+// The request is rejected with 503 Service Unavailable while the maximum number
+// of clients are connected.
+//
+// The returned Pusher must be synchronized by the run loop.
+func Push(server *http.Server, config PushConfig) *Pusher {
+
+	if config.Interval == 0 {
+		config.Interval = DefaultPushInterval
+	}
+	if config.MaxClients == 0 {
+		config.MaxClients = DefaultPushMaxClients
+	}
+	p := &Pusher{config: config}
+
+	server.Handle("GET", Prefix+"push", func(w *http.ResponseWriter, r *http.Request) {
+
+		if len(p.client) >= p.config.MaxClients {
+			w.Error(503)
+			return
+		}
+		ws, err := w.Upgrade(r)
+		if nil != err {
+			return
+		}
+		c := pushClient{ws: ws, cbor: "cbor" == r.Param("format")}
+		if nil != p.send(c, model.Peek()) {
+			ws.Close()
+			return
+		}
+		p.client = append(p.client, c)
+	})
+
+	return p
+}
+
+// Sync answers the control frames of each client, and sends the status object
+// to each if the interval has elapsed. Clients that closed the WebSocket, or
+// that could not be sent the status object, are disconnected.
+func (p *Pusher) Sync() error {
+
+	if 0 == len(p.client) {
+		return nil
+	}
+	data := model.Peek()
+	due := feed.Expired(data.Time, p.lastSync, p.config.Interval)
+	if due {
+		p.lastSync = data.Time
+	}
+
+	keep := p.client[:0]
+	for _, c := range p.client {
+		err := c.ws.Poll()
+		if nil == err && due {
+			err = p.send(c, data)
+		}
+		if nil != err {
+			c.ws.Close()
+			continue
+		}
+		keep = append(keep, c)
+	}
+	for i := len(keep); i < len(p.client); i++ {
+		p.client[i] = pushClient{}
+	}
+	p.client = keep
+	return nil
+}
+
+// send sends the status object to the given client in its negotiated encoding.
+func (p *Pusher) send(c pushClient, data model.Model) error {
+	if c.cbor {
+		return c.ws.WriteBinary(appendStatusCBOR(nil, p.config.Status, data))
+	}
+	return c.ws.WriteText(appendStatus(nil, p.config.Status, data))
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/cbor"
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// StatusConfig defines the identity reported by the status endpoint.
+type StatusConfig struct {
+	Name     func() string // returns the name of the device on the network
+	Features []string      // names of the optional features compiled in
+}
+
+// Status registers the endpoint "GET /api/v1/status", which reports the
+// identity and state of the device, e.g.:
+//
+//	{"name": "weatherhub", "status": "synchronized", "ip": "192.168.1.20",
+//	 "uptime": 86400, "boots": 12, "reset": "watchdog",
+//	 "features": ["mqtt", "server", "sensors"],
+//	 "ping": {"gateway": 3, "internet": 41},
+//	 "ntp": {"server": "us.pool.ntp.org", "stratum": 2, "offset": -12,
+//	  "delay": 38, "age": 5400}}
+//
+// The name distinguishes devices when several are used on one network. The
+// uptime is given in seconds, and boots counts the times the device has started,
+// or is null if unknown, i.e., without the "store" feature. The reset is what
+// last reset the microcontroller, e.g., "power-on", "brownout", or "watchdog".
+// The round-trip times of the most recent pings (see package ping) are given in
+// milliseconds, or null if no reply was received. The offset applied to the
+// system time by the most recent NTP synchronization and the delay of its
+// request are given in milliseconds, and the time since in seconds, or ntp is
+// null if the time was never synchronized.
+//
+// With the query parameter "format=cbor", the same object is encoded as CBOR
+// instead, which is about half the size (see package cbor).
+func Status(server *http.Server, config StatusConfig) {
+
+	server.Handle("GET", Prefix+"status", func(w *http.ResponseWriter, r *http.Request) {
+
+		data := model.Peek()
+		if "cbor" == r.Param("format") {
+			w.WriteHeader(200, cbor.ContentType)
+			w.Write(appendStatusCBOR(nil, config, data))
+			return
+		}
+		w.WriteHeader(200, "application/json")
+		w.Write(append(appendStatus(nil, config, data), '\n'))
+	})
+}
+
+// appendStatus appends the status object encoded as JSON to b.
+func appendStatus(b []byte, config StatusConfig, data model.Model) []byte {
+	b = json.AppendString(append(b, `{"name":`...), config.Name())
+	b = json.AppendString(append(b, `,"status":`...), data.Status.String())
+	b = json.AppendString(append(b, `,"ip":`...), data.IP.String())
+	b = strconv.AppendInt(append(b, `,"uptime":`...), int64(data.Diagnostics.Uptime()/time.Second), 10)
+	if b = append(b, `,"boots":`...); 0 == data.Diagnostics.Boots {
+		b = append(b, "null"...)
+	} else {
+		b = strconv.AppendUint(b, uint64(data.Diagnostics.Boots), 10)
+	}
+	b = json.AppendString(append(b, `,"reset":`...), data.Diagnostics.Reset.String())
+	b = append(b, `,"features":[`...)
+	for i, f := range config.Features {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = json.AppendString(b, f)
+	}
+	b = append(b, `],"ping":{"gateway":`...)
+	b = appendRTT(b, data.Diagnostics.Gateway)
+	b = appendRTT(append(b, `,"internet":`...), data.Diagnostics.Internet)
+	b = appendTimeSync(append(b, `},"ntp":`...), data.Diagnostics.TimeSync, data.Time)
+	return append(b, '}')
+}
+
+// appendStatusCBOR appends the status object encoded as CBOR to b.
+func appendStatusCBOR(b []byte, config StatusConfig, data model.Model) []byte {
+	b = cbor.AppendMap(b, 9)
+	b = cbor.AppendString(cbor.AppendString(b, "name"), config.Name())
+	b = cbor.AppendString(cbor.AppendString(b, "status"), data.Status.String())
+	b = cbor.AppendString(cbor.AppendString(b, "ip"), data.IP.String())
+	b = cbor.AppendInt(cbor.AppendString(b, "uptime"), int64(data.Diagnostics.Uptime()/time.Second))
+	if b = cbor.AppendString(b, "boots"); 0 == data.Diagnostics.Boots {
+		b = cbor.AppendNull(b)
+	} else {
+		b = cbor.AppendUint(b, uint64(data.Diagnostics.Boots))
+	}
+	b = cbor.AppendString(cbor.AppendString(b, "reset"), data.Diagnostics.Reset.String())
+	b = cbor.AppendArray(cbor.AppendString(b, "features"), len(config.Features))
+	for _, f := range config.Features {
+		b = cbor.AppendString(b, f)
+	}
+	b = cbor.AppendMap(cbor.AppendString(b, "ping"), 2)
+	b = appendRTTCBOR(cbor.AppendString(b, "gateway"), data.Diagnostics.Gateway)
+	b = appendRTTCBOR(cbor.AppendString(b, "internet"), data.Diagnostics.Internet)
+	b = cbor.AppendString(b, "ntp")
+	s := data.Diagnostics.TimeSync
+	if s.Synced.IsZero() {
+		return cbor.AppendNull(b)
+	}
+	b = cbor.AppendMap(b, 5)
+	b = cbor.AppendString(cbor.AppendString(b, "server"), s.Server)
+	b = cbor.AppendUint(cbor.AppendString(b, "stratum"), uint64(s.Stratum))
+	b = cbor.AppendInt(cbor.AppendString(b, "offset"), int64(s.Offset/time.Millisecond))
+	b = cbor.AppendInt(cbor.AppendString(b, "delay"), int64(s.Delay/time.Millisecond))
+	return cbor.AppendInt(cbor.AppendString(b, "age"), int64(data.Time.Sub(s.Synced)/time.Second))
+}
+
+// appendRTTCBOR appends the round-trip time of the given ping to b as for
+// appendRTT, encoded as CBOR.
+func appendRTTCBOR(b []byte, p model.Ping) []byte {
+	if !p.OK {
+		return cbor.AppendNull(b)
+	}
+	return cbor.AppendInt(b, int64(p.RTT/time.Millisecond))
+}
+
+// appendTimeSync appends the object describing the given synchronization to b,
+// or null if never synchronized.
+func appendTimeSync(b []byte, s model.TimeSync, now time.Time) []byte {
+	if s.Synced.IsZero() {
+		return append(b, "null"...)
+	}
+	b = json.AppendString(append(b, `{"server":`...), s.Server)
+	b = strconv.AppendUint(append(b, `,"stratum":`...), uint64(s.Stratum), 10)
+	b = strconv.AppendInt(append(b, `,"offset":`...), int64(s.Offset/time.Millisecond), 10)
+	b = strconv.AppendInt(append(b, `,"delay":`...), int64(s.Delay/time.Millisecond), 10)
+	b = strconv.AppendInt(append(b, `,"age":`...), int64(now.Sub(s.Synced)/time.Second), 10)
+	return append(b, '}')
+}
+
+// appendRTT appends the round-trip time of the given ping to b, in
+// milliseconds, or null if no reply was received.
+func appendRTT(b []byte, p model.Ping) []byte {
+	if !p.OK {
+		return append(b, "null"...)
+	}
+	return strconv.AppendInt(b, int64(p.RTT/time.Millisecond), 10)
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+//go:generate go run ui_gen.go
+
+// UI registers the endpoint "GET /", which serves a single-page configuration
+// UI for editing the Settings of the device from a phone browser, using the
+// endpoints registered by Config and Status. Browsers prompt for the secret, if
+// one is set, with any user name.
+//
+// The page is stored in flash compressed with gzip, which every browser
+// accepts, so it is sent as-is without decompressing it on the device. The
+// source of the page is ui/index.html.
+func UI(server *http.Server) {
+
+	server.Handle("GET", "/", guard(func(w *http.ResponseWriter, r *http.Request) {
+		w.Header("Content-Encoding", "gzip")
+		w.WriteHeader(200, "text/html; charset=utf-8")
+		// copy the page from flash in small pieces, rather than converting it to
+		// a byte slice, which would copy all of it to the heap.
+		var buf [256]byte
+		for rest := page; len(rest) > 0; {
+			n := copy(buf[:], rest)
+			if _, err := w.Write(buf[:n]); nil != err {
+				return
+			}
+			rest = rest[n:]
+		}
+	}))
+}
@@ -0,0 +1,48 @@
+//go:build ignore
+// +build ignore
+
+// This program compresses the configuration UI in ui/index.html into the byte
+// slice served by UI, in ui_page.go. Run it with go generate whenever the page
+// is changed.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	page, err := ioutil.ReadFile("ui/index.html")
+	if nil != err {
+		halt(err)
+	}
+	var gz bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	zw.Write(page)
+	zw.Close()
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by ui_gen.go from ui/index.html; DO NOT EDIT.\n\n")
+	out.WriteString("package api\n\n")
+	out.WriteString("// page is the configuration UI, compressed with gzip. It is a constant,\n")
+	out.WriteString("// rather than a byte slice, so that it remains in flash.\n")
+	out.WriteString("const page = \"")
+	for i, b := range gz.Bytes() {
+		if i > 0 && 0 == i%32 {
+			out.WriteString("\" +\n\t\"")
+		}
+		fmt.Fprintf(&out, "\\x%02x", b)
+	}
+	out.WriteString("\"\n")
+	if err := ioutil.WriteFile("ui_page.go", out.Bytes(), 0644); nil != err {
+		halt(err)
+	}
+}
+
+func halt(err error) {
+	fmt.Fprintln(os.Stderr, "error: "+err.Error())
+	os.Exit(1)
+}
@@ -0,0 +1,197 @@
+// Package asset loads images supplied at runtime, e.g., custom notice icons or
+// a boot splash, from an archive written to a region of flash, so that they may
+// be changed without recompiling the firmware.
+//
+// The archive is a header followed by a sequence of images (all integers are
+// little-endian):
+//
+//	magic    "WHA2"
+//	length   uint32, of the images following the header
+//	checksum uint32, CRC-32 (IEEE) of the images following the header
+//
+// and each image:
+//
+//	name     uint8 length, followed by that many bytes, e.g., "splash"
+//	width    uint8, in pixels
+//	height   uint8, in pixels
+//	frames   uint8, number of frames, 1 unless animated
+//	delay    uint16, milliseconds each frame is shown
+//	colors   uint8, number of palette entries, 1-15
+//	palette  colors × 3 bytes, red, green, and blue of indices 1-colors
+//	pixels   frames × (width × height + 1) / 2 bytes, 4-bit palette indices of
+//	         each frame, row-major, high nibble first; index 0 is transparent
+//
+// Archives of the previous format, "WHA1", are also accepted, whose images
+// omit frames and delay and are never animated.
+//
+// An image with the name of an icon is shown in its place with notices, and an
+// image named "splash" is shown while the device is connecting. Animated images
+// loop continuously in either place.
+package asset
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image/color"
+	"time"
+
+	"github.com/ardnew/weatherhub/config"
+)
+
+// MaxSize defines the size of the region of flash holding the archive, which is
+// also the most memory the images loaded from it may occupy.
+const MaxSize = 16 * 1024 // bytes
+
+// magic identifies an archive, and changes with its format.
+const (
+	magic  = "WHA2"
+	magic1 = "WHA1" // without animation
+)
+
+// header precedes the images: magic, length, and checksum.
+const header = len(magic) + 4 + 4
+
+var (
+	ErrFormat = errors.New("malformed asset archive")
+	ErrSize   = errors.New("asset archive exceeds the size of its region")
+)
+
+// Image is an indexed bitmap of at most 15 colors and transparency, with one or
+// more frames.
+type Image struct {
+	Name          string
+	Width, Height int16
+	Frames        int
+	Delay         time.Duration // each frame is shown
+	Palette       []color.RGBA  // colors of indices 1-15
+	Pixels        []byte        // 4-bit indices, row-major, high nibble first
+}
+
+// FrameAt returns the frame shown the given time after the animation began,
+// which loops continuously.
+func (im *Image) FrameAt(elapsed time.Duration) int {
+	if im.Frames < 2 || im.Delay <= 0 {
+		return 0
+	}
+	return int(elapsed/im.Delay) % im.Frames
+}
+
+// At returns the color of the pixel at x, y of the given frame, and false if it
+// is transparent or outside of the Image.
+func (im *Image) At(frame int, x, y int16) (color.RGBA, bool) {
+	if frame < 0 || frame >= im.Frames ||
+		x < 0 || y < 0 || x >= im.Width || y >= im.Height {
+		return color.RGBA{}, false
+	}
+	size := (int(im.Width)*int(im.Height) + 1) / 2
+	i := int(y)*int(im.Width) + int(x)
+	index := im.Pixels[frame*size+i/2]
+	if 0 == i%2 {
+		index >>= 4
+	}
+	index &= 0xF
+	if 0 == index || int(index) > len(im.Palette) {
+		return color.RGBA{}, false
+	}
+	return im.Palette[index-1], true
+}
+
+// Region is the part of a Device holding the archive, whose erase blocks are
+// not otherwise used.
+type Region struct {
+	dev   config.Device
+	start int64 // index of its first erase block
+}
+
+// NewRegion returns the Region of MaxSize bytes, rounded up to whole erase
+// blocks, that ends at the erase block with the given index.
+func NewRegion(dev config.Device, end int64) (*Region, error) {
+	block := dev.EraseBlockSize()
+	start := end - (MaxSize+block-1)/block
+	if start < 0 {
+		return nil, ErrSize
+	}
+	return &Region{dev: dev, start: start}, nil
+}
+
+// Load returns the images of the archive in the Region, or none if the Region
+// holds no archive, e.g., if never written. Returns ErrFormat if the archive is
+// corrupt or incomplete.
+func (r *Region) Load() ([]Image, error) {
+	base := r.start * r.dev.EraseBlockSize()
+
+	var head [header]byte
+	if _, err := r.dev.ReadAt(head[:], base); nil != err {
+		return nil, err
+	}
+	animated := magic == string(head[:len(magic)])
+	if !animated && magic1 != string(head[:len(magic1)]) {
+		return nil, nil
+	}
+	size := binary.LittleEndian.Uint32(head[len(magic):])
+	if size > MaxSize-uint32(header) {
+		return nil, ErrFormat
+	}
+	b := make([]byte, size)
+	if _, err := r.dev.ReadAt(b, base+int64(header)); nil != err {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(b) != binary.LittleEndian.Uint32(head[len(magic)+4:]) {
+		return nil, ErrFormat
+	}
+	return decode(b, animated)
+}
+
+// Write writes p to the Region at the given offset from its start. The entire
+// Region is erased first if the offset is 0, so that an archive larger than a
+// single request may be written in consecutive parts.
+func (r *Region) Write(off int64, p []byte) error {
+	if off < 0 || off+int64(len(p)) > MaxSize {
+		return ErrSize
+	}
+	block := r.dev.EraseBlockSize()
+	if 0 == off {
+		if err := r.dev.EraseBlocks(r.start, (MaxSize+block-1)/block); nil != err {
+			return err
+		}
+	}
+	_, err := r.dev.WriteAt(p, r.start*block+off)
+	return err
+}
+
+// decode returns the images encoded in b, which are animated if given the frames
+// and delay of each. The images refer to b rather than copying their palettes
+// and pixels.
+func decode(b []byte, animated bool) ([]Image, error) {
+	var images []Image
+	for len(b) > 0 {
+		n := int(b[0])
+		if len(b) < 1+n+6 {
+			return nil, ErrFormat
+		}
+		im := Image{Name: string(b[1 : 1+n]), Frames: 1}
+		b = b[1+n:]
+		im.Width, im.Height = int16(b[0]), int16(b[1])
+		b = b[2:]
+		if animated {
+			im.Frames = int(b[0])
+			im.Delay = time.Duration(binary.LittleEndian.Uint16(b[1:])) * time.Millisecond
+			b = b[3:]
+		}
+		colors := int(b[0])
+		pixels := im.Frames * ((int(im.Width)*int(im.Height) + 1) / 2)
+		if im.Frames < 1 || colors < 1 || colors > 15 || len(b) < 1+3*colors+pixels {
+			return nil, ErrFormat
+		}
+		b = b[1:]
+		im.Palette = make([]color.RGBA, colors)
+		for i := range im.Palette {
+			im.Palette[i] = color.RGBA{R: b[3*i], G: b[3*i+1], B: b[3*i+2], A: 0xFF}
+		}
+		b = b[3*colors:]
+		im.Pixels, b = b[:pixels], b[pixels:]
+		images = append(images, im)
+	}
+	return images, nil
+}
@@ -0,0 +1,43 @@
+// Package astro implements astronomical computations, such as the phases of the
+// moon, using the algorithms of Jean Meeus, "Astronomical Algorithms" (1998).
+//
+// Only the principal periodic terms are included, which is accurate to within
+// a few minutes, more than sufficient for display purposes.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// unixEpoch defines the Julian date of the Unix epoch, 1970-01-01 00:00 UTC.
+const unixEpoch = 2440587.5
+
+// j2000 defines the Julian date of the standard epoch J2000.0.
+const j2000 = 2451545.0
+
+// Julian returns the Julian date of time t.
+func Julian(t time.Time) float64 {
+	return unixEpoch + float64(t.Unix())/86400 + float64(t.Nanosecond())/86400e9
+}
+
+// Time returns the time of Julian date jd, in the location of time loc.
+func Time(jd float64, loc *time.Location) time.Time {
+	sec := (jd - unixEpoch) * 86400
+	return time.Unix(int64(math.Floor(sec)), 0).In(loc)
+}
+
+// rad converts degrees to radians.
+func rad(deg float64) float64 { return deg * math.Pi / 180 }
+
+// deg converts radians to degrees.
+func deg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// norm returns angle a (degrees) reduced to the range [0, 360).
+func norm(a float64) float64 {
+	a = math.Mod(a, 360)
+	if a < 0 {
+		a += 360
+	}
+	return a
+}
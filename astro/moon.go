@@ -0,0 +1,139 @@
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// deltaT approximates the difference between ephemeris time and UTC, in
+// seconds, which changes by less than a second per year.
+const deltaT = 69
+
+// SynodicMonth defines the mean length of a lunation, in days.
+const SynodicMonth = 29.530588861
+
+// Lunation identifies a principal phase of the moon.
+type Lunation uint8
+
+// Constants defining each principal phase of the moon.
+const (
+	NewMoon Lunation = iota
+	FullMoon
+)
+
+// Phase describes a principal phase of the moon and when it occurs.
+type Phase struct {
+	Lunation Lunation
+	Time     time.Time
+}
+
+// Phases returns all principal phases of the moon occurring in the month of
+// time t, in order, in the location of t.
+func Phases(t time.Time) []Phase {
+	y, m, _ := t.Date()
+	start := time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	end := start.AddDate(0, 1, 0)
+	// begin with the lunation preceding the start of the month
+	k := math.Floor(lunations(start)) - 1
+	var phases []Phase
+	for {
+		for _, l := range []Lunation{NewMoon, FullMoon} {
+			at := Time(phase(k, l), t.Location())
+			if !at.Before(end) {
+				return phases
+			}
+			if !at.Before(start) {
+				phases = append(phases, Phase{Lunation: l, Time: at})
+			}
+		}
+		k++
+	}
+}
+
+// MoonAge returns the time elapsed since the most recent new moon at time t,
+// in days, ranging from 0 up to the length of the current lunation.
+func MoonAge(t time.Time) float64 {
+	jd := Julian(t)
+	k := math.Floor(lunations(t)) + 1
+	for {
+		if nm := phase(k, NewMoon); nm <= jd {
+			return jd - nm
+		}
+		k--
+	}
+}
+
+// MoonIllumination returns the illuminated fraction of the moon's disk at time
+// t, ranging from 0 (new) to 1 (full).
+func MoonIllumination(t time.Time) float64 {
+	return (1 - math.Cos(2*math.Pi*MoonAge(t)/SynodicMonth)) / 2
+}
+
+// MoonLongitude returns the geocentric ecliptic longitude of the moon at time t,
+// in degrees, including only its largest periodic term (accurate to about one
+// degree).
+func MoonLongitude(t time.Time) float64 {
+	d := Julian(t) - j2000
+	l := 218.316 + 13.176396*d // mean longitude
+	m := 134.963 + 13.064993*d // mean anomaly
+	return norm(l + 6.289*math.Sin(rad(m)))
+}
+
+// zodiac defines the name of each sign of the tropical zodiac, beginning at the
+// March equinox.
+var zodiac = [12]string{
+	"Aries", "Taurus", "Gemini", "Cancer", "Leo", "Virgo", "Libra",
+	"Scorpio", "Sagittarius", "Capricorn", "Aquarius", "Pisces",
+}
+
+// Zodiac returns the name of the sign of the tropical zodiac containing the
+// given ecliptic longitude, in degrees.
+func Zodiac(longitude float64) string {
+	return zodiac[int(norm(longitude)/30)%12]
+}
+
+// lunations returns the (fractional) number of lunations since the new moon of
+// 2000-01-06, which has number 0.
+func lunations(t time.Time) float64 {
+	return (Julian(t) - 2451550.09766) / SynodicMonth
+}
+
+// phase returns the Julian ephemeris date of the principal phase l of lunation
+// number k (Meeus, ch. 49).
+func phase(k float64, l Lunation) float64 {
+	if FullMoon == l {
+		k += 0.5
+	}
+	T := k / 1236.85
+	jde := 2451550.09766 + SynodicMonth*k +
+		0.00015437*T*T - 0.000000150*T*T*T + 0.00000000073*T*T*T*T
+
+	e := 1 - 0.002516*T - 0.0000074*T*T
+	M := rad(norm(2.5534 + 29.10535670*k - 0.0000014*T*T))     // sun's anomaly
+	Mp := rad(norm(201.5643 + 385.81693528*k + 0.0107582*T*T)) // moon's anomaly
+	F := rad(norm(160.7108 + 390.67050284*k - 0.0016118*T*T))  // argument of latitude
+	O := rad(norm(124.7746 - 1.56375588*k + 0.0020672*T*T))    // ascending node
+
+	var c float64
+	switch l {
+	case NewMoon:
+		c = -0.40720*math.Sin(Mp) + 0.17241*e*math.Sin(M) +
+			0.01608*math.Sin(2*Mp) + 0.01039*math.Sin(2*F) +
+			0.00739*e*math.Sin(Mp-M) - 0.00514*e*math.Sin(Mp+M) +
+			0.00208*e*e*math.Sin(2*M) - 0.00111*math.Sin(Mp-2*F) -
+			0.00057*math.Sin(Mp+2*F) + 0.00056*e*math.Sin(2*Mp+M) -
+			0.00042*math.Sin(3*Mp) + 0.00042*e*math.Sin(M+2*F) +
+			0.00038*e*math.Sin(M-2*F) - 0.00024*e*math.Sin(2*Mp-M) -
+			0.00017*math.Sin(O)
+	case FullMoon:
+		c = -0.40614*math.Sin(Mp) + 0.17302*e*math.Sin(M) +
+			0.01614*math.Sin(2*Mp) + 0.01043*math.Sin(2*F) +
+			0.00734*e*math.Sin(Mp-M) - 0.00515*e*math.Sin(Mp+M) +
+			0.00209*e*e*math.Sin(2*M) - 0.00111*math.Sin(Mp-2*F) -
+			0.00057*math.Sin(Mp+2*F) + 0.00056*e*math.Sin(2*Mp+M) -
+			0.00042*math.Sin(3*Mp) + 0.00042*e*math.Sin(M+2*F) +
+			0.00038*e*math.Sin(M-2*F) - 0.00024*e*math.Sin(2*Mp-M) -
+			0.00017*math.Sin(O)
+	}
+	return jde + c - deltaT/86400.0
+}
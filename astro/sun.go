@@ -0,0 +1,102 @@
+package astro
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/fixed"
+)
+
+// Elevations of the sun, in degrees, bounding periods of twilight and daylight
+// of interest to photographers.
+const (
+	GoldenHigh = 6.0  // golden hour begins/ends with the sun this high
+	GoldenLow  = -4.0 // golden hour ends/begins, and blue hour begins/ends
+	BlueLow    = -6.0 // blue hour begins/ends (civil twilight)
+	Horizon    = -0.833
+)
+
+// unixJ2000 defines the Unix time of the standard epoch J2000.0.
+const unixJ2000 = 946728000
+
+// Rates of change of the solar angles, in degrees per day, with 32 fraction
+// bits for precision over the thousands of days since J2000.0.
+const (
+	rateLongitude = 4233323348 // 0.9856474
+	rateAnomaly   = 4233121055 // 0.9856003
+	rateObliquity = -1718      // -0.0000004
+)
+
+// sun returns the declination of the sun and the equation of time at n days
+// since J2000.0, in degrees and minutes, respectively (low-precision formulas
+// of the Astronomical Almanac, accurate to about one minute of time).
+//
+// The computation uses the fixed-point arithmetic of package fixed, which
+// limits n to dates before the year 2089.
+func sun(n fixed.Q16) (dec, eot fixed.Q16) {
+	l := advance(fixed.From(280.460), rateLongitude, n) // mean longitude
+	g := advance(fixed.From(357.528), rateAnomaly, n)   // mean anomaly
+	lambda := l + fixed.From(1.915).Mul(fixed.Sin(g)) +
+		fixed.From(0.020).Mul(fixed.Sin(2*g))
+	eps := advance(fixed.From(23.439), rateObliquity, n) // obliquity of the ecliptic
+	dec = fixed.Asin(fixed.Sin(eps).Mul(fixed.Sin(lambda)))
+	ra := fixed.Norm(fixed.Atan2(fixed.Cos(eps).Mul(fixed.Sin(lambda)),
+		fixed.Cos(lambda)))
+	diff := l - ra
+	if diff > fixed.Int(180) {
+		diff -= fixed.Int(360)
+	} else if diff < fixed.Int(-180) {
+		diff += fixed.Int(360)
+	}
+	return dec, 4 * diff
+}
+
+// advance returns angle a, in degrees, advanced by n days at the given rate, in
+// degrees per day with 32 fraction bits, reduced to the range [0, 360).
+func advance(a fixed.Q16, rate int64, n fixed.Q16) fixed.Q16 {
+	const full = 360 << 16
+	v := int64(a) + (int64(n)*rate+1<<31)>>32
+	return fixed.Norm(fixed.Q16(v % full))
+}
+
+// SunTime returns the time on the day of date t (in the location of t) when the
+// sun crosses the given elevation, in degrees, either rising or setting, at the
+// position with given latitude and longitude (degrees north and east).
+// Returns false if the sun does not cross that elevation on that day, e.g., in
+// polar summer or winter.
+func SunTime(t time.Time, latitude, longitude, elevation float64, rising bool) (time.Time, bool) {
+
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	// local midnight in UTC minutes, relative to UTC midnight of the same date
+	_, offset := midnight.Zone()
+	utc := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	n := fixed.Q16(((utc.Unix() - unixJ2000) << 16) / 86400)
+
+	phi := fixed.From(float32(latitude))
+	lon := fixed.From(float32(longitude))
+	sinElev := fixed.Sin(fixed.From(float32(elevation)))
+	noon := fixed.Int(720) - 4*lon
+	at := noon // UTC minutes, first estimate at solar noon
+	for i := 0; i < 2; i++ {
+		dec, eot := sun(n + at/1440)
+		cosH := (sinElev - fixed.Sin(phi).Mul(fixed.Sin(dec))).
+			Div(fixed.Cos(phi).Mul(fixed.Cos(dec)))
+		if cosH < -fixed.One || cosH > fixed.One {
+			return time.Time{}, false
+		}
+		h := fixed.Acos(cosH) // hour angle of the crossing
+		if rising {
+			h = -h
+		}
+		at = noon - eot + 4*h
+	}
+
+	// the crossing computed for the UTC date may fall on an adjacent local date.
+	at += fixed.Q16((int64(offset) << 16) / 60)
+	if at < 0 {
+		at += fixed.Int(1440)
+	} else if at >= fixed.Int(1440) {
+		at -= fixed.Int(1440)
+	}
+	return midnight.Add(time.Duration(int64(at) * int64(time.Minute) >> 16)), true
+}
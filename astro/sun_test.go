@@ -0,0 +1,103 @@
+package astro
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Error limits of SunTime compared with the same formulas evaluated in double
+// precision, and with published times of sunrise and sunset, which the
+// low-precision formulas approximate to about one minute.
+const (
+	sunLimit       = 10 * time.Second
+	publishedLimit = 2 * time.Minute
+)
+
+// sunTime is SunTime evaluated in double precision.
+func sunTime(t time.Time, latitude, longitude, elevation float64, rising bool) (time.Time, bool) {
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	_, offset := midnight.Zone()
+	utc := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	n := float64(utc.Unix()-unixJ2000) / 86400
+	noon := 720 - 4*longitude
+	at := noon
+	for i := 0; i < 2; i++ {
+		day := n + at/1440
+		l := norm(280.460 + 0.9856474*day)
+		g := rad(norm(357.528 + 0.9856003*day))
+		lambda := rad(l + 1.915*math.Sin(g) + 0.020*math.Sin(2*g))
+		eps := rad(23.439 - 0.0000004*day)
+		dec := math.Asin(math.Sin(eps) * math.Sin(lambda))
+		ra := norm(deg(math.Atan2(math.Cos(eps)*math.Sin(lambda), math.Cos(lambda))))
+		diff := l - ra
+		if diff > 180 {
+			diff -= 360
+		} else if diff < -180 {
+			diff += 360
+		}
+		cosH := (math.Sin(rad(elevation)) - math.Sin(rad(latitude))*math.Sin(dec)) /
+			(math.Cos(rad(latitude)) * math.Cos(dec))
+		if cosH < -1 || cosH > 1 {
+			return time.Time{}, false
+		}
+		h := deg(math.Acos(cosH))
+		if rising {
+			h = -h
+		}
+		at = noon - 4*diff + 4*h
+	}
+	at += float64(offset) / 60
+	if at < 0 {
+		at += 1440
+	} else if at >= 1440 {
+		at -= 1440
+	}
+	return midnight.Add(time.Duration(at * float64(time.Minute))), true
+}
+
+func TestSunTime(t *testing.T) {
+	for day := 0; day < 3*365; day += 3 {
+		for lat := -60.0; lat <= 60; lat += 15 {
+			for lon := -180.0; lon < 180; lon += 45 {
+				zone := time.FixedZone("", int(lon/15)*3600)
+				date := time.Date(2021, time.January, 1, 12, 0, 0, 0, zone).AddDate(0, 0, day)
+				for _, rising := range []bool{true, false} {
+					got, ok := SunTime(date, lat, lon, Horizon, rising)
+					want, wok := sunTime(date, lat, lon, Horizon, rising)
+					if ok != wok {
+						t.Fatalf("SunTime(%v, %v, %v) ok = %v, want %v", date, lat, lon, ok, wok)
+					}
+					if d := got.Sub(want); ok && (d > sunLimit || d < -sunLimit) {
+						t.Fatalf("SunTime(%v, %v, %v, %v) = %v, want %v", date, lat, lon, rising, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSunTimePublished(t *testing.T) {
+	bst := time.FixedZone("BST", 1*3600)
+	est := time.FixedZone("EST", -5*3600)
+	for _, x := range []struct {
+		lat, lon float64
+		rising   bool
+		want     time.Time
+	}{
+		{51.5072, -0.1276, true, time.Date(2021, time.June, 21, 4, 43, 0, 0, bst)},        // London
+		{51.5072, -0.1276, false, time.Date(2021, time.June, 21, 21, 21, 0, 0, bst)},      // London
+		{40.7128, -74.0060, true, time.Date(2021, time.December, 21, 7, 16, 0, 0, est)},   // New York
+		{40.7128, -74.0060, false, time.Date(2021, time.December, 21, 16, 32, 0, 0, est)}, // New York
+	} {
+		got, ok := SunTime(x.want, x.lat, x.lon, Horizon, x.rising)
+		if d := got.Sub(x.want); !ok || d > publishedLimit || d < -publishedLimit {
+			t.Errorf("SunTime(%v, %v, %v) = %v, want %v", x.lat, x.lon, x.rising, got, x.want)
+		}
+	}
+	// the sun neither rises nor sets at the pole in summer
+	if _, ok := SunTime(time.Date(2021, time.June, 21, 0, 0, 0, 0, time.UTC), 89, 0, Horizon, true); ok {
+		t.Error("sun rises at the pole in June")
+	}
+}
@@ -0,0 +1,250 @@
+// Package ble implements a passive scanner of Bluetooth Low Energy (BLE)
+// advertisements, using a controller speaking the host controller interface
+// (HCI) over UART, e.g., the NINA-W102 coprocessor in its BLE mode.
+//
+// Scanning is passive: no scan requests are sent, and no connections are made,
+// so that advertisements of beacons are received without pairing.
+package ble
+
+import (
+	"errors"
+	"machine"
+	"time"
+)
+
+// Default constants for Device configuration.
+const (
+	DefaultBaudRate = 115200
+	DefaultInterval = 100 * time.Millisecond
+	DefaultWindow   = 100 * time.Millisecond
+)
+
+// CommandTimeout defines how long to wait for the controller to complete each
+// command.
+const CommandTimeout = time.Second
+
+var (
+	ErrNoController = errors.New("no reply from BLE controller")
+	ErrCommand      = errors.New("BLE controller command failed")
+)
+
+// HCI packet types, events, and command opcodes.
+const (
+	hciCommand = 0x01
+	hciEvent   = 0x04
+
+	evtCommandComplete = 0x0E
+	evtLEMeta          = 0x3E
+	subAdvReport       = 0x02
+
+	opReset          = 0x0C03
+	opSetEventMask   = 0x0C01
+	opLESetScanParam = 0x200B
+	opLESetScanOn    = 0x200C
+)
+
+// Config defines the UART pins and baud rate connected to the controller, and
+// how often and how long it listens for advertisements.
+//
+// If Reset is not machine.NoPin, the controller is reset before it is
+// configured, holding Select low, which is how the NINA-W102 is told to start
+// in BLE mode instead of WiFi. Since the NINA requires flow control, RTS is
+// also held low while the controller may send.
+type Config struct {
+	TX, RX, RTS   machine.Pin
+	Reset, Select machine.Pin
+	BaudRate      uint32
+	Interval      time.Duration
+	Window        time.Duration // of each Interval, at most Interval
+}
+
+// Address is the address of a BLE device, most significant byte first.
+type Address [6]byte
+
+// String returns the address in its usual form, e.g., "A4:C1:38:0B:12:EF".
+func (a Address) String() string {
+	const hex = "0123456789ABCDEF"
+	var b [17]byte
+	for i, c := range a {
+		if i > 0 {
+			b[3*i-1] = ':'
+		}
+		b[3*i], b[3*i+1] = hex[c>>4], hex[c&0xF]
+	}
+	return string(b[:])
+}
+
+// Advertisement is an advertising report received while scanning. Its Data is
+// only valid until the next call to Receive.
+type Advertisement struct {
+	Address Address
+	RSSI    int8   // dBm
+	Data    []byte // advertising data structures
+}
+
+// ServiceData returns the data of the service with the given 16-bit UUID, or
+// nil if the Advertisement has none.
+func (a Advertisement) ServiceData(uuid uint16) []byte {
+	for d := a.Data; len(d) > 1; {
+		n := int(d[0]) // of type and data
+		if 0 == n || n >= len(d) {
+			return nil
+		}
+		// type 0x16: service data, 16-bit UUID little-endian, then data
+		if 0x16 == d[1] && n >= 3 && uuid == uint16(d[2])|uint16(d[3])<<8 {
+			return d[4 : 1+n]
+		}
+		d = d[1+n:]
+	}
+	return nil
+}
+
+// Device wraps the UART connected to the BLE controller.
+type Device struct {
+	uart   *machine.UART
+	config Config
+	packet [260]byte // HCI packet received, at most 255 bytes of parameters
+	n      int       // length of packet
+}
+
+// New returns a new Device using the given UART and configuration.
+// The UART is also initialized and configured for use.
+func New(uart *machine.UART, config Config) *Device {
+
+	if config.BaudRate == 0 {
+		config.BaudRate = DefaultBaudRate
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Window == 0 {
+		config.Window = DefaultWindow
+	}
+	if config.Window > config.Interval {
+		config.Window = config.Interval
+	}
+
+	if machine.NoPin != config.Reset {
+		config.Select.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		config.Reset.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		config.Select.Low()
+		config.Reset.Low()
+		time.Sleep(10 * time.Millisecond)
+		config.Reset.High()
+		time.Sleep(750 * time.Millisecond) // boot
+	}
+	if machine.NoPin != config.RTS {
+		config.RTS.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		config.RTS.Low()
+	}
+
+	uart.Configure(machine.UARTConfig{
+		BaudRate: config.BaudRate,
+		TX:       config.TX,
+		RX:       config.RX,
+	})
+
+	return &Device{uart: uart, config: config}
+}
+
+// Configure resets the controller and begins scanning passively.
+func (d *Device) Configure() error {
+
+	if err := d.command(opReset); nil != err {
+		return err
+	}
+	// enable all events, including LE meta events (bit 61)
+	if err := d.command(opSetEventMask,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x3F); nil != err {
+		return err
+	}
+	// in units of 0.625 ms
+	interval := uint16(d.config.Interval / (625 * time.Microsecond))
+	window := uint16(d.config.Window / (625 * time.Microsecond))
+	if err := d.command(opLESetScanParam,
+		0x00, // passive
+		uint8(interval), uint8(interval>>8),
+		uint8(window), uint8(window>>8),
+		0x00, // own address public
+		0x00, // accept all advertisements
+	); nil != err {
+		return err
+	}
+	// do not filter duplicates, which would hide updated measurements
+	return d.command(opLESetScanOn, 0x01, 0x00)
+}
+
+// Receive returns the next advertisement received, and ok true, or ok false if
+// none has been received. Receive never blocks.
+func (d *Device) Receive() (adv Advertisement, ok bool) {
+	for {
+		p := d.next()
+		if nil == p {
+			return adv, false
+		}
+		// LE meta event, advertising report: subevent, count, then for each
+		// report: type, address type, address (LSB first), length, data, RSSI
+		if evtLEMeta != p[1] || len(p) < 5 || subAdvReport != p[3] || 1 != p[4] {
+			continue
+		}
+		r := p[5:]
+		if len(r) < 9 || len(r) < 10+int(r[8]) {
+			continue
+		}
+		for i := 0; i < 6; i++ {
+			adv.Address[i] = r[7-i]
+		}
+		adv.Data = r[9 : 9+int(r[8])]
+		adv.RSSI = int8(r[9+int(r[8])])
+		return adv, true
+	}
+}
+
+// command sends the given command with the given parameters, and waits for it
+// to complete. Any advertisements received meanwhile are dropped.
+func (d *Device) command(op uint16, param ...byte) error {
+	d.uart.Write([]byte{hciCommand, uint8(op), uint8(op >> 8), uint8(len(param))})
+	d.uart.Write(param)
+	start := time.Now()
+	for time.Since(start) < CommandTimeout {
+		p := d.next()
+		if nil == p {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		// command complete: packets allowed, opcode, status
+		if evtCommandComplete == p[1] && len(p) >= 7 &&
+			op == uint16(p[4])|uint16(p[5])<<8 {
+			if 0 != p[6] {
+				return ErrCommand
+			}
+			return nil
+		}
+	}
+	return ErrNoController
+}
+
+// next returns the next complete event packet received, beginning with its
+// packet type, or nil if none has been received. The packet returned is only
+// valid until the next call.
+func (d *Device) next() []byte {
+	// discard the packet returned previously
+	if d.n >= 3 && d.n >= 3+int(d.packet[2]) {
+		d.n = 0
+	}
+	for d.uart.Buffered() > 0 {
+		c, err := d.uart.ReadByte()
+		if nil != err {
+			return nil
+		}
+		if 0 == d.n && hciEvent != c {
+			continue // not an event, or out of sync
+		}
+		d.packet[d.n] = c
+		d.n++
+		if d.n >= 3 && d.n == 3+int(d.packet[2]) {
+			return d.packet[:d.n]
+		}
+	}
+	return nil
+}
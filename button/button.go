@@ -0,0 +1,96 @@
+// Package button implements a Feed polling a push button, which calls a
+// function each time the button is pressed, and optionally another each time it
+// is held.
+package button
+
+import (
+	"machine"
+	"time"
+)
+
+// Default constants for Button configuration.
+const (
+	DefaultDebounce = 30 * time.Millisecond
+	DefaultHold     = 2 * time.Second
+)
+
+// Config defines the level of the pin while the button is pressed, how long
+// the level must be stable before a change is accepted, and how long the button
+// must be held to call the hold function rather than the press function.
+type Config struct {
+	ActiveHigh bool // the pin is high while pressed; otherwise, low with pull-up
+	Debounce   time.Duration
+	Hold       time.Duration
+}
+
+// Button is a Feed that polls the pin of a push button.
+type Button struct {
+	pin     machine.Pin
+	config  Config
+	press   func()
+	hold    func()    // nil if holding the button is not distinguished
+	pressed bool      // debounced state
+	held    bool      // hold function has been called since pressed
+	level   bool      // state most recently read
+	since   time.Time // when level last changed
+}
+
+// New returns a new Button reading the given pin, which calls press each time
+// the button is pressed.
+func New(pin machine.Pin, press func(), config Config) *Button {
+	return NewHold(pin, press, nil, config)
+}
+
+// NewHold returns a new Button reading the given pin, which calls hold each
+// time the button is held for the configured duration, and otherwise calls
+// press each time the button is released. If hold is nil, it is equivalent to
+// New.
+func NewHold(pin machine.Pin, press, hold func(), config Config) *Button {
+
+	if config.Debounce == 0 {
+		config.Debounce = DefaultDebounce
+	}
+	if config.Hold == 0 {
+		config.Hold = DefaultHold
+	}
+
+	mode := machine.PinInputPullup
+	if config.ActiveHigh {
+		mode = machine.PinInput
+	}
+	pin.Configure(machine.PinConfig{Mode: mode})
+
+	return &Button{pin: pin, config: config, press: press, hold: hold}
+}
+
+// Offline identifies the Button as a feed.Offline, which needs no network.
+func (b *Button) Offline() {}
+
+// Sync reads the pin, and calls the press or hold function if the button has
+// been pressed or held since the previous call.
+func (b *Button) Sync() error {
+	level := b.pin.Get() == b.config.ActiveHigh
+	now := time.Now()
+	if level != b.level {
+		b.level, b.since = level, now
+		return nil
+	}
+	if level != b.pressed && now.Sub(b.since) >= b.config.Debounce {
+		b.pressed = level
+		switch {
+		case nil == b.hold:
+			if level {
+				b.press()
+			}
+		case level:
+			b.held = false
+		case !b.held:
+			b.press() // released before held long enough
+		}
+	}
+	if nil != b.hold && b.pressed && !b.held && now.Sub(b.since) >= b.config.Hold {
+		b.held = true
+		b.hold()
+	}
+	return nil
+}
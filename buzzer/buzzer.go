@@ -0,0 +1,109 @@
+// Package buzzer plays melodies on a piezo buzzer driven by a PWM output.
+//
+// Tones are sequenced by polling, like every other Feed, rather than by
+// sleeping, so that the display and network are serviced while a melody plays.
+// The duration of each tone is therefore only as precise as the interval at
+// which the run loop polls its feeds.
+//
+// Melodies may be given in the RTTTL ringtone format (see Parse), so that each
+// use of the buzzer, e.g., the hourly chime or a weather alert, has a distinct
+// and configurable sound.
+package buzzer
+
+import (
+	"machine"
+	"time"
+)
+
+// Tone is a note of the buzzer followed by silence.
+type Tone struct {
+	Freq uint16 // Hz, or 0 for a rest
+	On   time.Duration
+	Off  time.Duration
+}
+
+// Beep defines a single short tone, played if no melody is configured.
+var Beep = []Tone{{Freq: 2000, On: 150 * time.Millisecond, Off: 350 * time.Millisecond}}
+
+// PWM is a PWM peripheral of the microcontroller, e.g., machine.TCC0.
+type PWM interface {
+	Configure(config machine.PWMConfig) error
+	Channel(pin machine.Pin) (uint8, error)
+	SetPeriod(period uint64) error
+	Top() uint32
+	Set(channel uint8, value uint32)
+}
+
+// Buzzer is a Feed that plays melodies on a passive piezo buzzer connected to a
+// pin of a PWM peripheral.
+type Buzzer struct {
+	pwm     PWM
+	channel uint8
+	tone    []Tone    // melody playing
+	next    int       // index of the tone sounding or next to sound
+	on      bool      // the buzzer is sounding
+	until   time.Time // when the current tone or silence ends
+}
+
+// New returns a new Buzzer driving the given pin with the given PWM peripheral.
+func New(pwm PWM, pin machine.Pin) (*Buzzer, error) {
+	if err := pwm.Configure(machine.PWMConfig{}); nil != err {
+		return nil, err
+	}
+	channel, err := pwm.Channel(pin)
+	if nil != err {
+		return nil, err
+	}
+	b := &Buzzer{pwm: pwm, channel: channel}
+	b.set(0)
+	return b, nil
+}
+
+// Play begins playing the given melody, replacing any melody still playing.
+// The melody is not copied, so it must not be modified while playing.
+func (b *Buzzer) Play(tone []Tone) {
+	b.tone, b.next, b.until = tone, 0, time.Time{}
+	b.set(0)
+}
+
+// Playing returns true if a melody has not yet finished.
+func (b *Buzzer) Playing() bool {
+	return b.next < len(b.tone)
+}
+
+// Sync starts or stops the buzzer at the end of each tone and silence of the
+// melody playing.
+func (b *Buzzer) Sync() error {
+	if !b.Playing() {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(b.until) {
+		return nil
+	}
+	t := b.tone[b.next]
+	if b.on || 0 == t.Freq || 0 == t.On {
+		// the tone has ended, or is a rest, so remain silent until the next.
+		b.set(0)
+		b.until = now.Add(t.Off)
+		b.next++
+		return nil
+	}
+	b.set(t.Freq)
+	b.until = now.Add(t.On)
+	return nil
+}
+
+// set sounds the buzzer at the given frequency, in Hz, or silences it if zero.
+func (b *Buzzer) set(freq uint16) {
+	b.on = 0 != freq
+	if !b.on {
+		b.pwm.Set(b.channel, 0)
+		return
+	}
+	if nil != b.pwm.SetPeriod(uint64(time.Second)/uint64(freq)) {
+		b.pwm.Set(b.channel, 0)
+		return
+	}
+	b.pwm.Set(b.channel, b.pwm.Top()/2) // square wave is loudest
+}
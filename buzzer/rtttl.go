@@ -0,0 +1,124 @@
+package buzzer
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidMelody = errors.New("invalid RTTTL melody")
+)
+
+// Defaults of the RTTTL format, used if omitted from the defaults section.
+const (
+	defaultDuration = 4
+	defaultOctave   = 6
+	defaultBeat     = 63
+)
+
+// staccato defines the fraction of each note that is silent, so that repeated
+// notes are distinct.
+const staccato = 8 // 1/8
+
+// semitones maps each note name a-g to its semitones above C.
+var semitones = [7]int{9, 11, 0, 2, 4, 5, 7}
+
+// Parse returns the tones of the given melody in RTTTL (Ring Tone Text Transfer
+// Language) format, e.g.:
+//
+//	chime:d=4,o=5,b=120:8e6,8c6,8d6,4g,8p,8g,8d6,8e6,4c6
+//
+// The name and defaults sections may be omitted, e.g., "8e6,8c6,4g". Each note
+// is an optional duration (1, 2, 4, 8, 16, or 32 for whole through 32nd notes),
+// a name (a-g, or p for a rest), an optional sharp (#), an optional dot (.)
+// extending its duration by half, and an optional octave (4-7). Returns
+// ErrInvalidMelody if the melody cannot be parsed.
+func Parse(melody string) ([]Tone, error) {
+	duration, octave, beat := defaultDuration, defaultOctave, defaultBeat
+	notes := melody
+	if sec := strings.Split(melody, ":"); 3 == len(sec) {
+		for _, def := range strings.Split(sec[1], ",") {
+			kv := strings.SplitN(strings.TrimSpace(def), "=", 2)
+			if 2 != len(kv) {
+				continue
+			}
+			v, err := strconv.Atoi(kv[1])
+			if nil != err || v <= 0 {
+				return nil, ErrInvalidMelody
+			}
+			switch kv[0] {
+			case "d":
+				duration = v
+			case "o":
+				octave = v
+			case "b":
+				beat = v
+			}
+		}
+		notes = sec[2]
+	} else if 1 != len(sec) {
+		return nil, ErrInvalidMelody
+	}
+
+	whole := 4 * time.Minute / time.Duration(beat) // four beats of a quarter note
+	var tone []Tone
+	for _, note := range strings.Split(notes, ",") {
+		t, ok := parseNote(strings.ToLower(strings.TrimSpace(note)), duration, octave, whole)
+		if !ok {
+			return nil, ErrInvalidMelody
+		}
+		tone = append(tone, t)
+	}
+	return tone, nil
+}
+
+// parseNote returns the tone of a single note of an RTTTL melody, using the
+// given default duration and octave, and the length of a whole note.
+func parseNote(note string, duration, octave int, whole time.Duration) (Tone, bool) {
+	i := 0
+	for i < len(note) && note[i] >= '0' && note[i] <= '9' {
+		i++
+	}
+	if i > 0 {
+		duration, _ = strconv.Atoi(note[:i])
+	}
+	if duration <= 0 || i == len(note) {
+		return Tone{}, false
+	}
+	name := note[i]
+	i++
+	semitone := -1
+	if name >= 'a' && name <= 'g' {
+		semitone = semitones[name-'a']
+	} else if 'p' != name {
+		return Tone{}, false
+	}
+	if i < len(note) && '#' == note[i] {
+		if semitone >= 0 { // a sharp rest is still a rest
+			semitone++
+		}
+		i++
+	}
+	length := whole / time.Duration(duration)
+	// the dot may precede or follow the octave, both of which are common.
+	for ; i < len(note); i++ {
+		switch c := note[i]; {
+		case '.' == c:
+			length += length / 2
+		case c >= '0' && c <= '9':
+			octave = int(c - '0')
+		default:
+			return Tone{}, false
+		}
+	}
+	if semitone < 0 {
+		return Tone{Off: length}, true
+	}
+	// A4 is 440 Hz, and C4 is 9 semitones below it.
+	freq := 440 * math.Pow(2, float64((octave-4)*12+semitone-9)/12)
+	gap := length / staccato
+	return Tone{Freq: uint16(freq + 0.5), On: length - gap, Off: gap}, true
+}
@@ -0,0 +1,28 @@
+package buzzer
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tones, err := Parse("test:d=4,o=5,b=120:a,p,p#,8c#6,2p.")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 5 != len(tones) {
+		t.Fatalf("%d tones, want 5", len(tones))
+	}
+	if 880 != tones[0].Freq {
+		t.Errorf("a5 = %d Hz, want 880", tones[0].Freq)
+	}
+	if 1109 != tones[3].Freq {
+		t.Errorf("c#6 = %d Hz, want 1109", tones[3].Freq)
+	}
+	// rests are silent, sharp or not, and last as long as their notes
+	for _, i := range []int{1, 2, 4} {
+		if 0 != tones[i].Freq || 0 != tones[i].On {
+			t.Errorf("rest %d = %+v, want silence", i, tones[i])
+		}
+	}
+	if tones[1].Off != tones[2].Off || 3*tones[1].Off != tones[4].Off {
+		t.Errorf("rests last %v, %v, %v", tones[1].Off, tones[2].Off, tones[4].Off)
+	}
+}
@@ -0,0 +1,45 @@
+// Package calendar converts dates of the Gregorian calendar to those of other
+// calendars, e.g., lunar or religious calendars, shown beneath the Gregorian
+// date on the clock page.
+//
+// Calendars are registered by name, so that other packages may contribute them
+// from an init function without modifying this package.
+package calendar
+
+import "time"
+
+// Date is a date of a Calendar.
+type Date struct {
+	Year  int
+	Month string // name of the month, abbreviated to fit beside the day and year
+	Day   int
+}
+
+// Calendar converts Gregorian dates to dates of another calendar.
+type Calendar interface {
+	// Date returns the date of the calendar on the Gregorian date of t, in the
+	// location of t. Days of the calendar begin at midnight.
+	Date(t time.Time) Date
+}
+
+// calendars maps the name of each registered Calendar to the Calendar.
+var calendars = map[string]Calendar{}
+
+// Register registers the Calendar with the given name, e.g., "islamic".
+func Register(name string, c Calendar) {
+	calendars[name] = c
+}
+
+// Lookup returns the Calendar registered with the given name, and false if
+// none is registered.
+func Lookup(name string) (Calendar, bool) {
+	c, ok := calendars[name]
+	return c, ok
+}
+
+// days returns the number of days from 1970-01-01 to the date of t, in the
+// location of t.
+func days(t time.Time) int {
+	y, m, d := t.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
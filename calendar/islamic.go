@@ -0,0 +1,43 @@
+package calendar
+
+import "time"
+
+func init() { Register("islamic", Islamic{}) }
+
+// islamicEpoch defines the date of 1 Muharram 1 AH, 16 July 622 of the Julian
+// calendar, in days since 1970-01-01.
+const islamicEpoch = -492148
+
+// islamicMonths defines the abbreviated names of the months of the Islamic
+// calendar.
+var islamicMonths = [12]string{
+	"Muh", "Saf", "Rab1", "Rab2", "Jum1", "Jum2",
+	"Raj", "Sha", "Ram", "Shaw", "DhuQ", "DhuH",
+}
+
+// Islamic is the tabular (arithmetic) Islamic calendar, with 11 leap years in
+// each 30-year cycle. Since the religious calendar begins each month with the
+// sighting of the new crescent moon, its dates may differ by a day or two.
+type Islamic struct{}
+
+// Date returns the date of the Islamic calendar on the Gregorian date of t.
+func (Islamic) Date(t time.Time) Date {
+	n := days(t) - islamicEpoch // days since 1 Muharram 1 AH
+	year := (30*n + 10646) / 10631
+	for n < islamicStart(year, 1) {
+		year--
+	}
+	month := 12
+	for n < islamicStart(year, month) {
+		month--
+	}
+	return Date{Year: year, Month: islamicMonths[month-1],
+		Day: n - islamicStart(year, month) + 1}
+}
+
+// islamicStart returns the first day of the given month of the given year, in
+// days since 1 Muharram 1 AH. Months alternate between 30 and 29 days, and the
+// last month of leap years has 30 days.
+func islamicStart(year, month int) int {
+	return (year-1)*354 + (3+11*year)/30 + (59*(month-1)+1)/2
+}
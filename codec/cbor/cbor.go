@@ -0,0 +1,90 @@
+// Package cbor implements an encoder of the Concise Binary Object
+// Representation (CBOR, RFC 8949), a compact binary alternative to JSON.
+//
+// Like strconv.AppendInt, each function appends the encoding of a single item
+// to a byte slice, so that documents are built without reflection or any
+// allocation beyond growing the slice. Maps and arrays are given the number of
+// items they contain, followed by the items themselves (for maps, alternating
+// keys and values).
+package cbor
+
+import "math"
+
+// ContentType defines the media type of CBOR documents.
+const ContentType = "application/cbor"
+
+// Major types of the initial byte of each item.
+const (
+	majorUint   = 0 << 5
+	majorNeg    = 1 << 5
+	majorText   = 3 << 5
+	majorArray  = 4 << 5
+	majorMap    = 5 << 5
+	majorSimple = 7 << 5
+)
+
+// AppendUint appends the unsigned integer v to b.
+func AppendUint(b []byte, v uint64) []byte {
+	return appendHead(b, majorUint, v)
+}
+
+// AppendInt appends the integer v to b.
+func AppendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return appendHead(b, majorNeg, uint64(-1-v))
+	}
+	return appendHead(b, majorUint, uint64(v))
+}
+
+// AppendFloat32 appends the single-precision floating point number v to b.
+func AppendFloat32(b []byte, v float32) []byte {
+	u := math.Float32bits(v)
+	return append(b, majorSimple|26, byte(u>>24), byte(u>>16), byte(u>>8), byte(u))
+}
+
+// AppendBool appends the boolean v to b.
+func AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, majorSimple|21)
+	}
+	return append(b, majorSimple|20)
+}
+
+// AppendNull appends null to b.
+func AppendNull(b []byte) []byte {
+	return append(b, majorSimple|22)
+}
+
+// AppendString appends the text string s to b.
+func AppendString(b []byte, s string) []byte {
+	return append(appendHead(b, majorText, uint64(len(s))), s...)
+}
+
+// AppendArray appends the head of an array of n items to b, which must be
+// followed by the items.
+func AppendArray(b []byte, n int) []byte {
+	return appendHead(b, majorArray, uint64(n))
+}
+
+// AppendMap appends the head of a map of n pairs to b, which must be followed
+// by the key and value of each pair.
+func AppendMap(b []byte, n int) []byte {
+	return appendHead(b, majorMap, uint64(n))
+}
+
+// appendHead appends the initial byte of an item of the given major type, and
+// its argument v in the fewest bytes.
+func appendHead(b []byte, major byte, v uint64) []byte {
+	switch {
+	case v < 24:
+		return append(b, major|byte(v))
+	case v <= math.MaxUint8:
+		return append(b, major|24, byte(v))
+	case v <= math.MaxUint16:
+		return append(b, major|25, byte(v>>8), byte(v))
+	case v <= math.MaxUint32:
+		return append(b, major|26, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return append(b, major|27, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,420 @@
+// Package json implements a streaming extractor for the scalar values of a
+// JSON document.
+//
+// The document is never decoded into memory. Instead, a Handler is called with
+// the path and content of each scalar value (string, number, bool, or null) as
+// it is read from the stream, which lets the caller keep only the fields it is
+// interested in. This keeps heap usage small and constant regardless of the
+// size of the document, which matters on devices with only a few KB of RAM.
+//
+// Paths are formed by joining object keys and array indices with '.', e.g., the
+// value 3 in {"a":[{"b":1},{"b":3}]} has path "a.1.b".
+//
+// Documents are encoded by appending to a byte slice, for which AppendString
+// quotes strings as JSON requires (unlike strconv.Quote, whose escapes, e.g.,
+// "\x00", are not all valid JSON).
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Constants limiting the resources used by an extractor.
+const (
+	MaxDepth = 16  // maximum nesting of objects and arrays
+	MaxValue = 128 // values longer than this many bytes are truncated
+)
+
+var (
+	ErrSyntax = errors.New("invalid JSON syntax")
+	ErrDepth  = errors.New("JSON nesting exceeds maximum depth")
+)
+
+// Kind identifies the type of a scalar JSON value.
+type Kind uint8
+
+// Constants defining each possible Kind of scalar value.
+const (
+	String Kind = iota
+	Number
+	Bool
+	Null
+)
+
+// Handler is called for each scalar value in a JSON document. The path and
+// value slices are only valid until the Handler returns.
+// If the Handler returns a non-nil error, extraction stops and the error is
+// returned to the caller of Extract.
+type Handler func(path []byte, kind Kind, value []byte) error
+
+// Extract reads a single JSON value from r, calling handle for each of the
+// scalar values it contains.
+func Extract(r io.Reader, handle Handler) error {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, 64)
+	}
+	x := &extractor{
+		rd:     br,
+		handle: handle,
+		path:   make([]byte, 0, 64),
+		value:  make([]byte, 0, MaxValue),
+	}
+	return x.parse(0)
+}
+
+// Match returns true if the given path matches pattern, which is a path that
+// may contain wildcard segments "*" matching any single key or array index.
+// If the first wildcard matched an array index, that index is also returned;
+// otherwise, the returned index is -1.
+func Match(path []byte, pattern string) (ok bool, index int) {
+	index = -1
+	for wild := false; ; {
+		p, q := path, pattern
+		if i := bytes.IndexByte(path, '.'); i >= 0 {
+			p = path[:i]
+		}
+		if i := strings.IndexByte(pattern, '.'); i >= 0 {
+			q = pattern[:i]
+		}
+		if "*" == q {
+			if !wild {
+				wild = true
+				if n, err := strconv.Atoi(string(p)); nil == err {
+					index = n
+				}
+			}
+		} else if string(p) != q {
+			return false, -1
+		}
+		path, pattern = path[len(p):], pattern[len(q):]
+		if 0 == len(path) || 0 == len(pattern) {
+			if len(path) != len(pattern) {
+				return false, -1
+			}
+			return true, index
+		}
+		path, pattern = path[1:], pattern[1:] // skip '.'
+	}
+}
+
+type extractor struct {
+	rd     *bufio.Reader
+	handle Handler
+	path   []byte
+	value  []byte
+}
+
+func (x *extractor) parse(depth int) error {
+	if depth > MaxDepth {
+		return ErrDepth
+	}
+	c, err := x.next()
+	if nil != err {
+		return err
+	}
+	switch {
+	case '{' == c:
+		return x.object(depth + 1)
+	case '[' == c:
+		return x.array(depth + 1)
+	case '"' == c:
+		if x.value, err = x.string(x.value[:0]); nil != err {
+			return err
+		}
+		return x.handle(x.path, String, x.value)
+	case 't' == c:
+		return x.literal("rue", Bool, "true")
+	case 'f' == c:
+		return x.literal("alse", Bool, "false")
+	case 'n' == c:
+		return x.literal("ull", Null, "null")
+	case '-' == c || ('0' <= c && c <= '9'):
+		return x.number(c)
+	}
+	return ErrSyntax
+}
+
+func (x *extractor) object(depth int) error {
+	c, err := x.next()
+	if nil != err {
+		return err
+	}
+	if '}' == c {
+		return nil
+	}
+	mark := len(x.path)
+	for {
+		if '"' != c {
+			return ErrSyntax
+		}
+		if mark > 0 {
+			x.path = append(x.path, '.')
+		}
+		if x.path, err = x.string(x.path); nil != err {
+			return err
+		}
+		if c, err = x.next(); nil != err {
+			return err
+		}
+		if ':' != c {
+			return ErrSyntax
+		}
+		if err = x.parse(depth); nil != err {
+			return err
+		}
+		x.path = x.path[:mark]
+		if c, err = x.next(); nil != err {
+			return err
+		}
+		if '}' == c {
+			return nil
+		}
+		if ',' != c {
+			return ErrSyntax
+		}
+		if c, err = x.next(); nil != err {
+			return err
+		}
+	}
+}
+
+func (x *extractor) array(depth int) error {
+	c, err := x.next()
+	if nil != err {
+		return err
+	}
+	if ']' == c {
+		return nil
+	}
+	if err = x.rd.UnreadByte(); nil != err {
+		return err
+	}
+	mark := len(x.path)
+	for index := 0; ; index++ {
+		if mark > 0 {
+			x.path = append(x.path, '.')
+		}
+		x.path = strconv.AppendInt(x.path, int64(index), 10)
+		if err = x.parse(depth); nil != err {
+			return err
+		}
+		x.path = x.path[:mark]
+		if c, err = x.next(); nil != err {
+			return err
+		}
+		if ']' == c {
+			return nil
+		}
+		if ',' != c {
+			return ErrSyntax
+		}
+	}
+}
+
+// string appends the content of a string, whose opening quote has already been
+// read, to dst. The content appended is limited to MaxValue bytes, but the
+// remainder of the string is still consumed.
+func (x *extractor) string(dst []byte) ([]byte, error) {
+	limit := len(dst) + MaxValue
+	for {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return dst, unexpected(err)
+		}
+		switch {
+		case '"' == c:
+			return dst, nil
+		case '\\' == c:
+			if c, err = x.rd.ReadByte(); nil != err {
+				return dst, unexpected(err)
+			}
+			switch c {
+			case '"', '\\', '/':
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'u':
+				r, err := x.rune()
+				if nil != err {
+					return dst, err
+				}
+				if len(dst)+utf8.RuneLen(r) <= limit {
+					dst = appendRune(dst, r)
+				}
+				continue
+			default:
+				return dst, ErrSyntax
+			}
+		case c < 0x20:
+			return dst, ErrSyntax
+		}
+		if len(dst) < limit {
+			dst = append(dst, c)
+		}
+	}
+}
+
+// rune reads the hex digits of a \u escape sequence, including the trailing
+// low surrogate of a surrogate pair.
+func (x *extractor) rune() (rune, error) {
+	r, err := x.hex()
+	if nil != err {
+		return 0, err
+	}
+	if r >= 0xD800 && r < 0xDC00 {
+		// high surrogate, must be followed by "\u" and a low surrogate
+		var b [2]byte
+		if _, err := io.ReadFull(x.rd, b[:]); nil != err {
+			return 0, unexpected(err)
+		}
+		if '\\' != b[0] || 'u' != b[1] {
+			return utf8.RuneError, ErrSyntax
+		}
+		lo, err := x.hex()
+		if nil != err {
+			return 0, err
+		}
+		if lo < 0xDC00 || lo >= 0xE000 {
+			return utf8.RuneError, nil
+		}
+		r = 0x10000 + (r-0xD800)<<10 + (lo - 0xDC00)
+	}
+	return r, nil
+}
+
+func (x *extractor) hex() (r rune, err error) {
+	for i := 0; i < 4; i++ {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return 0, unexpected(err)
+		}
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c -= 'a' - 10
+		case 'A' <= c && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, ErrSyntax
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, nil
+}
+
+func (x *extractor) number(c byte) error {
+	x.value = append(x.value[:0], c)
+	for {
+		c, err := x.rd.ReadByte()
+		if err == io.EOF {
+			break // number at end of document
+		}
+		if nil != err {
+			return err
+		}
+		if !('0' <= c && c <= '9') &&
+			'-' != c && '+' != c && '.' != c && 'e' != c && 'E' != c {
+			if err = x.rd.UnreadByte(); nil != err {
+				return err
+			}
+			break
+		}
+		if len(x.value) < MaxValue {
+			x.value = append(x.value, c)
+		}
+	}
+	return x.handle(x.path, Number, x.value)
+}
+
+func (x *extractor) literal(rest string, kind Kind, value string) error {
+	for i := 0; i < len(rest); i++ {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return unexpected(err)
+		}
+		if rest[i] != c {
+			return ErrSyntax
+		}
+	}
+	x.value = append(x.value[:0], value...)
+	return x.handle(x.path, kind, x.value)
+}
+
+// next returns the next byte that is not whitespace.
+func (x *extractor) next() (byte, error) {
+	for {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return 0, unexpected(err)
+		}
+		if ' ' != c && '\t' != c && '\n' != c && '\r' != c {
+			return c, nil
+		}
+	}
+}
+
+// AppendString appends s to b as a quoted JSON string, escaping quotes,
+// backslashes, and control characters. Invalid UTF-8 is replaced by U+FFFD.
+func AppendString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, n := utf8.DecodeRuneInString(s[i:])
+			if utf8.RuneError == r && 1 == n {
+				b = append(b, `\ufffd`...)
+			} else {
+				b = append(b, s[i:i+n]...)
+			}
+			i += n
+			continue
+		}
+		switch {
+		case '"' == c || '\\' == c:
+			b = append(b, '\\', c)
+		case '\n' == c:
+			b = append(b, `\n`...)
+		case '\r' == c:
+			b = append(b, `\r`...)
+		case '\t' == c:
+			b = append(b, `\t`...)
+		case c < 0x20:
+			b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		default:
+			b = append(b, c)
+		}
+		i++
+	}
+	return append(b, '"')
+}
+
+func appendRune(dst []byte, r rune) []byte {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	return append(dst, b[:n]...)
+}
+
+// unexpected converts io.EOF to io.ErrUnexpectedEOF, since reaching the end of
+// the stream in the middle of a value means the document is truncated.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package json
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"testing"
+	"unicode/utf8"
+)
+
+// FuzzExtract checks that Extract never panics nor reports a value longer
+// than MaxValue, whatever the document.
+func FuzzExtract(f *testing.F) {
+	f.Add([]byte(`{"a":[{"b":1},{"b":3}],"c":"dé","e":true,"f":null}`))
+	f.Add([]byte(`[[[[[[[[[[[[[[[[[[1]]]]]]]]]]]]]]]]]]`))
+	f.Add([]byte(`"😀"`))
+	f.Fuzz(func(t *testing.T, doc []byte) {
+		Extract(bytes.NewReader(doc), func(path []byte, kind Kind, value []byte) error {
+			if len(value) > MaxValue {
+				t.Fatalf("value of %d bytes exceeds MaxValue", len(value))
+			}
+			if kind > Null {
+				t.Fatalf("unknown kind %d", kind)
+			}
+			return nil
+		})
+	})
+}
+
+// FuzzExtractString checks that a string encoded by encoding/json is extracted
+// unchanged, up to MaxValue bytes.
+func FuzzExtractString(f *testing.F) {
+	f.Add("plain")
+	f.Add("quote \" backslash \\ tab \t <html> &  ")
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) || len(s) > MaxValue {
+			return
+		}
+		doc, err := stdjson.Marshal(map[string]string{"k": s})
+		if nil != err {
+			t.Skip()
+		}
+		var got []byte
+		err = Extract(bytes.NewReader(doc), func(path []byte, kind Kind, value []byte) error {
+			if "k" != string(path) || String != kind {
+				t.Fatalf("path %q kind %d", path, kind)
+			}
+			got = append(got[:0], value...)
+			return nil
+		})
+		if nil != err {
+			t.Fatalf("%s: %v", doc, err)
+		}
+		if s != string(got) {
+			t.Fatalf("extracted %q from %s, want %q", got, doc, s)
+		}
+	})
+}
+
+// FuzzAppendString checks that a string appended by AppendString is valid JSON,
+// decoded by encoding/json unchanged if it is valid UTF-8.
+func FuzzAppendString(f *testing.F) {
+	f.Add("plain")
+	f.Add("nul \x00 bell \a quote \" backslash \\ dé 😀 \xff")
+	f.Fuzz(func(t *testing.T, s string) {
+		doc := AppendString(nil, s)
+		var got string
+		if err := stdjson.Unmarshal(doc, &got); nil != err {
+			t.Fatalf("%s: %v", doc, err)
+		}
+		if utf8.ValidString(s) && s != got {
+			t.Fatalf("decoded %q from %s, want %q", got, doc, s)
+		}
+	})
+}
+
+// FuzzMatch checks that Match never panics, and that a path matches itself
+// and a pattern with its first segment replaced by a wildcard.
+func FuzzMatch(f *testing.F) {
+	f.Add([]byte("a.1.b"), "a.*.b")
+	f.Add([]byte("hourly.time.0"), "hourly.*.0")
+	f.Add([]byte(""), "*")
+	f.Fuzz(func(t *testing.T, path []byte, pattern string) {
+		Match(path, pattern)
+		if bytes.Contains(path, []byte("*")) {
+			return
+		}
+		if ok, index := Match(path, string(path)); !ok || -1 != index {
+			t.Fatalf("%q does not match itself: %v %d", path, ok, index)
+		}
+		rest := ""
+		if i := bytes.IndexByte(path, '.'); i >= 0 {
+			rest = string(path[i:])
+		}
+		if ok, _ := Match(path, "*"+rest); !ok {
+			t.Fatalf("%q does not match %q", path, "*"+rest)
+		}
+	})
+}
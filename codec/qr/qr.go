@@ -0,0 +1,369 @@
+// Package qr implements an encoder of QR codes of versions 1-3, i.e., 21-29
+// modules square, which fit on a panel 32 pixels tall with one pixel per module.
+//
+// Data is encoded in byte mode with error correction level L, so that a code
+// holds up to 53 bytes, e.g., a URL or the credentials of a WiFi network.
+package qr
+
+import "errors"
+
+// MaxSize defines the number of modules on each side of the largest code.
+const MaxSize = 17 + 4*maxVersion
+
+// maxVersion defines the largest version of code encoded.
+const maxVersion = 3
+
+var (
+	ErrTooLong = errors.New("data exceeds the capacity of a QR code")
+)
+
+// Number of data and error correction codewords of each version, at error
+// correction level L, each of a single block.
+var (
+	dataCodewords = [maxVersion + 1]int{0, 19, 34, 55}
+	eccCodewords  = [maxVersion + 1]int{0, 7, 10, 15}
+)
+
+// Code is a QR code, a square of dark and light modules.
+type Code struct {
+	Size   int // number of modules on each side
+	module [MaxSize][MaxSize]bool
+}
+
+// Dark returns true if the module at column x, row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.module[y][x]
+}
+
+// Encode returns the smallest Code encoding the given data, or ErrTooLong if
+// the data does not fit in any version.
+func Encode(data string) (*Code, error) {
+
+	version := 1
+	for ; version <= maxVersion; version++ {
+		if 4+8+8*len(data) <= 8*dataCodewords[version] {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, ErrTooLong
+	}
+
+	// mode indicator (byte), character count, data, terminator, and padding
+	var w bitWriter
+	w.put(0x4, 4)
+	w.put(uint32(len(data)), 8)
+	for i := 0; i < len(data); i++ {
+		w.put(uint32(data[i]), 8)
+	}
+	capacity := 8 * dataCodewords[version]
+	if n := capacity - w.n; n < 4 {
+		w.put(0, n)
+	} else {
+		w.put(0, 4)
+	}
+	w.put(0, (8-w.n%8)%8)
+	for pad := uint32(0xEC); w.n < capacity; pad ^= 0xEC ^ 0x11 {
+		w.put(pad, 8)
+	}
+	codewords := append(w.b, reedSolomon(w.b, eccCodewords[version])...)
+
+	e := &encoder{code: &Code{Size: 17 + 4*version}}
+	e.drawFunctionPatterns(version)
+	e.drawCodewords(codewords)
+
+	// apply the mask of least penalty
+	best, penalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		e.applyMask(mask)
+		e.drawFormatBits(mask)
+		if p := e.penalty(); penalty < 0 || p < penalty {
+			best, penalty = mask, p
+		}
+		e.applyMask(mask) // undo, since masks are their own inverse
+	}
+	e.applyMask(best)
+	e.drawFormatBits(best)
+	return e.code, nil
+}
+
+// bitWriter appends bits to a byte slice, most significant first.
+type bitWriter struct {
+	b []byte
+	n int // number of bits written
+}
+
+// put appends the n least significant bits of v.
+func (w *bitWriter) put(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if 0 == w.n%8 {
+			w.b = append(w.b, 0)
+		}
+		w.b[w.n/8] |= byte(v>>uint(i)&1) << uint(7-w.n%8)
+		w.n++
+	}
+}
+
+// encoder draws the modules of a Code, distinguishing the function patterns
+// from the modules holding data, which alone are masked.
+type encoder struct {
+	code     *Code
+	function [MaxSize][MaxSize]bool
+}
+
+// set sets the module at column x, row y as a function module.
+func (e *encoder) set(x, y int, dark bool) {
+	e.code.module[y][x] = dark
+	e.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder, and alignment patterns, and
+// reserves the modules of the format bits.
+func (e *encoder) drawFunctionPatterns(version int) {
+	size := e.code.Size
+	for i := 0; i < size; i++ {
+		e.set(6, i, 0 == i%2)
+		e.set(i, 6, 0 == i%2)
+	}
+	e.drawFinder(3, 3)
+	e.drawFinder(size-4, 3)
+	e.drawFinder(3, size-4)
+	if version > 1 {
+		e.drawAlignment(size-7, size-7)
+	}
+	e.drawFormatBits(0)
+}
+
+// drawFinder draws a finder pattern and its separator centered at x, y.
+func (e *encoder) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= e.code.Size || yy >= e.code.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			e.set(xx, yy, 2 != dist && 4 != dist)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centered at x, y.
+func (e *encoder) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			e.set(x+dx, y+dy, 1 != max(abs(dx), abs(dy)))
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format bits of the given mask, and
+// the dark module.
+func (e *encoder) drawFormatBits(mask int) {
+	const levelL = 1
+	data := levelL<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return 0 != bits>>uint(i)&1 }
+
+	size := e.code.Size
+	for i := 0; i <= 5; i++ {
+		e.set(8, i, bit(i))
+	}
+	e.set(8, 7, bit(6))
+	e.set(8, 8, bit(7))
+	e.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		e.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		e.set(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		e.set(8, size-15+i, bit(i))
+	}
+	e.set(8, size-8, true)
+}
+
+// drawCodewords draws the given codewords in the modules not used by function
+// patterns, in the zigzag order of two-module columns from the bottom right.
+func (e *encoder) drawCodewords(codewords []byte) {
+	size := e.code.Size
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if 6 == right {
+			right = 5 // skip the vertical timing pattern
+		}
+		upward := 0 == (right+1)&2
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if upward {
+					y = size - 1 - vert
+				}
+				if !e.function[y][x] && i < 8*len(codewords) {
+					e.code.module[y][x] = 0 != codewords[i/8]>>uint(7-i%8)&1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by the given mask pattern.
+func (e *encoder) applyMask(mask int) {
+	size := e.code.Size
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = 0 == (x+y)%2
+			case 1:
+				invert = 0 == y%2
+			case 2:
+				invert = 0 == x%3
+			case 3:
+				invert = 0 == (x+y)%3
+			case 4:
+				invert = 0 == (x/3+y/2)%2
+			case 5:
+				invert = 0 == x*y%2+x*y%3
+			case 6:
+				invert = 0 == (x*y%2+x*y%3)%2
+			case 7:
+				invert = 0 == ((x+y)%2+x*y%3)%2
+			}
+			if invert && !e.function[y][x] {
+				e.code.module[y][x] = !e.code.module[y][x]
+			}
+		}
+	}
+}
+
+// penalty returns the penalty score of the Code, by which masks are compared:
+// runs of modules of the same color, blocks of the same color, patterns that
+// resemble finders, and imbalance of dark and light modules.
+func (e *encoder) penalty() int {
+	size := e.code.Size
+	m := &e.code.module
+	at := func(x, y int, transpose bool) bool {
+		if transpose {
+			return m[x][y]
+		}
+		return m[y][x]
+	}
+	// finder-like pattern 1:1:3:1:1 with four light modules on either side
+	finder := [...]bool{true, false, true, true, true, false, true}
+
+	score, dark := 0, 0
+	for _, transpose := range [...]bool{false, true} {
+		for y := 0; y < size; y++ {
+			run := 0
+			for x := 0; x < size; x++ {
+				if x > 0 && at(x, y, transpose) == at(x-1, y, transpose) {
+					run++
+					if 5 == run {
+						score += 3
+					} else if run > 5 {
+						score++
+					}
+				} else {
+					run = 1
+				}
+				if x+7 > size {
+					continue
+				}
+				match := true
+				for i, f := range finder {
+					if f != at(x+i, y, transpose) {
+						match = false
+						break
+					}
+				}
+				if match && (light(at, x-4, x, y, size, transpose) ||
+					light(at, x+7, x+11, y, size, transpose)) {
+					score += 40
+				}
+			}
+		}
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if m[y][x] {
+				dark++
+			}
+			if x+1 < size && y+1 < size && m[y][x] == m[y][x+1] &&
+				m[y][x] == m[y+1][x] && m[y][x] == m[y+1][x+1] {
+				score += 3
+			}
+		}
+	}
+	total := size * size
+	return score + 10*((abs(20*dark-10*total)+total-1)/total-1)
+}
+
+// light returns true if the modules from from to to (exclusive) of row y, or of
+// column y if transposed, are light. Modules beyond the edges are light.
+func light(at func(x, y int, transpose bool) bool, from, to, y, size int, transpose bool) bool {
+	for x := from; x < to; x++ {
+		if x >= 0 && x < size && at(x, y, transpose) {
+			return false
+		}
+	}
+	return true
+}
+
+// reedSolomon returns the given number of error correction codewords of data.
+func reedSolomon(data []byte, n int) []byte {
+	// generator polynomial, the product of (x - 2^i) for i in [0, n)
+	divisor := make([]byte, n)
+	divisor[n-1] = 1
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		for j := range divisor {
+			divisor[j] = gfMul(divisor[j], root)
+			if j+1 < n {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	rem := make([]byte, n)
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for i := range rem {
+			rem[i] ^= gfMul(divisor[i], factor)
+		}
+	}
+	return rem
+}
+
+// gfMul returns the product of x and y in GF(2^8) modulo x^8+x^4+x^3+x^2+1.
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>uint(i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
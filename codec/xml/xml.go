@@ -0,0 +1,392 @@
+// Package xml implements a streaming extractor for the text content and
+// attributes of the elements of an XML document.
+//
+// Like package codec/json, the document is never decoded into memory. Instead,
+// a Handler is called with the path and content of each element's text and each
+// attribute as they are read from the stream.
+//
+// Paths are formed by joining element names with '.', and attribute names are
+// prefixed with '@', e.g., in <a><b c="1">2</b></a>, the attribute value 1 has
+// path "a.b.@c" and the text 2 has path "a.b". Namespace prefixes are retained
+// as part of the name.
+//
+// Only the subset of XML commonly found in syndication feeds and web APIs is
+// understood: DTDs are skipped, and only the predefined and numeric character
+// entities are decoded.
+package xml
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Constants limiting the resources used by an extractor.
+const (
+	MaxDepth = 16  // maximum nesting of elements
+	MaxValue = 128 // values longer than this many bytes are truncated
+)
+
+var (
+	ErrSyntax = errors.New("invalid XML syntax")
+	ErrDepth  = errors.New("XML nesting exceeds maximum depth")
+)
+
+// Handler is called for the text content of each element containing text, and
+// for each attribute. The path and value slices are only valid until the
+// Handler returns. Leading and trailing whitespace is removed from text.
+// If the Handler returns a non-nil error, extraction stops and the error is
+// returned to the caller of Extract.
+type Handler func(path []byte, value []byte) error
+
+// Extract reads an XML document from r, calling handle for the text content and
+// attributes of each of its elements.
+func Extract(r io.Reader, handle Handler) error {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, 64)
+	}
+	x := &extractor{
+		rd:     br,
+		handle: handle,
+		path:   make([]byte, 0, 64),
+		text:   make([]byte, 0, MaxValue),
+	}
+	return x.parse()
+}
+
+type extractor struct {
+	rd     *bufio.Reader
+	handle Handler
+	path   []byte
+	mark   [MaxDepth]int // length of path before each open element
+	depth  int
+	text   []byte
+}
+
+func (x *extractor) parse() error {
+	for {
+		c, err := x.rd.ReadByte()
+		if err == io.EOF {
+			if x.depth > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+		if nil != err {
+			return err
+		}
+		if '<' != c {
+			if x.depth > 0 {
+				if err = x.char(c); nil != err {
+					return err
+				}
+			}
+			continue
+		}
+		if c, err = x.rd.ReadByte(); nil != err {
+			return unexpected(err)
+		}
+		switch c {
+		case '?':
+			err = x.skip("?>") // processing instruction or XML declaration
+		case '!':
+			err = x.special()
+		case '/':
+			err = x.close()
+		default:
+			if err = x.rd.UnreadByte(); nil == err {
+				err = x.open()
+			}
+		}
+		if nil != err {
+			return err
+		}
+	}
+}
+
+// open reads the name and attributes of a start tag.
+func (x *extractor) open() error {
+	if x.depth >= MaxDepth {
+		return ErrDepth
+	}
+	x.mark[x.depth] = len(x.path)
+	x.depth++
+	if len(x.path) > 0 {
+		x.path = append(x.path, '.')
+	}
+	c, err := x.name()
+	if nil != err {
+		return err
+	}
+	x.text = x.text[:0]
+	for {
+		switch {
+		case '>' == c:
+			return nil
+		case '/' == c:
+			// empty-element tag
+			if c, err = x.rd.ReadByte(); nil != err {
+				return unexpected(err)
+			}
+			if '>' != c {
+				return ErrSyntax
+			}
+			x.pop()
+			return nil
+		case isSpace(c):
+			if c, err = x.rd.ReadByte(); nil != err {
+				return unexpected(err)
+			}
+		default:
+			if err = x.rd.UnreadByte(); nil != err {
+				return err
+			}
+			if c, err = x.attr(); nil != err {
+				return err
+			}
+		}
+	}
+}
+
+// attr reads a single attribute of a start tag, returning the byte following
+// its value.
+func (x *extractor) attr() (byte, error) {
+	mark := len(x.path)
+	x.path = append(x.path, ".@"...)
+	c, err := x.name()
+	if nil != err {
+		return 0, err
+	}
+	for isSpace(c) {
+		if c, err = x.rd.ReadByte(); nil != err {
+			return 0, unexpected(err)
+		}
+	}
+	if '=' != c {
+		return 0, ErrSyntax
+	}
+	quote, err := x.rd.ReadByte()
+	for nil == err && isSpace(quote) {
+		quote, err = x.rd.ReadByte()
+	}
+	if nil != err {
+		return 0, unexpected(err)
+	}
+	if '"' != quote && '\'' != quote {
+		return 0, ErrSyntax
+	}
+	value := x.text[:0]
+	for {
+		if c, err = x.rd.ReadByte(); nil != err {
+			return 0, unexpected(err)
+		}
+		if quote == c {
+			break
+		}
+		if '&' == c {
+			if value, err = x.entity(value); nil != err {
+				return 0, err
+			}
+		} else if len(value) < MaxValue {
+			value = append(value, c)
+		}
+	}
+	if err = x.handle(x.path, value); nil != err {
+		return 0, err
+	}
+	x.path, x.text = x.path[:mark], value[:0]
+	c, err = x.rd.ReadByte()
+	return c, unexpected(err)
+}
+
+// close reads an end tag and emits the text content of its element.
+func (x *extractor) close() error {
+	for {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return unexpected(err)
+		}
+		if '>' == c {
+			break
+		}
+	}
+	if 0 == x.depth {
+		return ErrSyntax
+	}
+	if text := bytes.TrimSpace(x.text); len(text) > 0 {
+		if err := x.handle(x.path, text); nil != err {
+			return err
+		}
+	}
+	x.text = x.text[:0]
+	x.pop()
+	return nil
+}
+
+// special reads markup beginning with "<!": comments, CDATA sections, and DTDs.
+func (x *extractor) special() error {
+	c, err := x.rd.ReadByte()
+	if nil != err {
+		return unexpected(err)
+	}
+	switch c {
+	case '-':
+		return x.skip("-->")
+	case '[':
+		if err = x.expect("CDATA["); nil != err {
+			return err
+		}
+		// copy content verbatim until "]]>"
+		for {
+			if c, err = x.rd.ReadByte(); nil != err {
+				return unexpected(err)
+			}
+			if ']' == c {
+				if b, err := x.rd.Peek(2); nil == err && "]>" == string(b) {
+					_, err = x.rd.Discard(2)
+					return err
+				}
+			}
+			if x.depth > 0 && len(x.text) < MaxValue {
+				x.text = append(x.text, c)
+			}
+		}
+	default:
+		return x.skip(">") // DOCTYPE; internal subsets are not supported
+	}
+}
+
+// char appends a byte of element text, decoding entity references.
+func (x *extractor) char(c byte) (err error) {
+	if '&' == c {
+		x.text, err = x.entity(x.text)
+	} else if len(x.text) < MaxValue {
+		x.text = append(x.text, c)
+	}
+	return
+}
+
+// entity decodes the entity reference following '&' and appends it to dst.
+// Unrecognized entities are appended verbatim.
+func (x *extractor) entity(dst []byte) ([]byte, error) {
+	var ref [12]byte
+	n := 0
+	for {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return dst, unexpected(err)
+		}
+		if ';' == c {
+			break
+		}
+		if n == len(ref) || isSpace(c) || '<' == c {
+			return dst, ErrSyntax
+		}
+		ref[n] = c
+		n++
+	}
+	var r rune
+	switch name := string(ref[:n]); name {
+	case "amp":
+		r = '&'
+	case "lt":
+		r = '<'
+	case "gt":
+		r = '>'
+	case "quot":
+		r = '"'
+	case "apos":
+		r = '\''
+	default:
+		if n > 1 && '#' == ref[0] {
+			var v uint64
+			var err error
+			if 'x' == ref[1] || 'X' == ref[1] {
+				v, err = strconv.ParseUint(name[2:], 16, 32)
+			} else {
+				v, err = strconv.ParseUint(name[1:], 10, 32)
+			}
+			if nil == err && utf8.ValidRune(rune(v)) {
+				r = rune(v)
+				break
+			}
+		}
+		if len(dst)+n+2 <= MaxValue {
+			dst = append(append(append(dst, '&'), ref[:n]...), ';')
+		}
+		return dst, nil
+	}
+	if len(dst)+utf8.RuneLen(r) <= MaxValue {
+		var b [utf8.UTFMax]byte
+		dst = append(dst, b[:utf8.EncodeRune(b[:], r)]...)
+	}
+	return dst, nil
+}
+
+// name appends an element or attribute name to the path, returning the byte
+// following it.
+func (x *extractor) name() (byte, error) {
+	for {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return 0, unexpected(err)
+		}
+		if isSpace(c) || '>' == c || '/' == c || '=' == c {
+			return c, nil
+		}
+		x.path = append(x.path, c)
+	}
+}
+
+// skip discards input up to and including the given terminator, which must
+// not be longer than 4 bytes.
+func (x *extractor) skip(term string) error {
+	var last [4]byte
+	for n := 1; ; n++ {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return unexpected(err)
+		}
+		copy(last[:], last[1:])
+		last[len(last)-1] = c
+		if n >= len(term) && term == string(last[len(last)-len(term):]) {
+			return nil
+		}
+	}
+}
+
+// expect reads the given literal, returning ErrSyntax if it is not found.
+func (x *extractor) expect(lit string) error {
+	for i := 0; i < len(lit); i++ {
+		c, err := x.rd.ReadByte()
+		if nil != err {
+			return unexpected(err)
+		}
+		if lit[i] != c {
+			return ErrSyntax
+		}
+	}
+	return nil
+}
+
+func (x *extractor) pop() {
+	x.depth--
+	x.path = x.path[:x.mark[x.depth]]
+}
+
+func isSpace(c byte) bool {
+	return ' ' == c || '\t' == c || '\n' == c || '\r' == c
+}
+
+// unexpected converts io.EOF to io.ErrUnexpectedEOF, since reaching the end of
+// the stream in the middle of markup means the document is truncated.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
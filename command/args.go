@@ -0,0 +1,84 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of an Arg.
+type Kind uint8
+
+// Constants defining each possible Kind.
+const (
+	KindWord   Kind = iota // any single word
+	KindInt                // decimal integer from Min to Max
+	KindChoice             // one of Choices
+	KindText               // all remaining words, joined by single spaces
+)
+
+// Arg describes an argument of a Command. Arguments described are checked by
+// Exec before the Command is run, so that every interface dispatching commands
+// rejects invalid arguments the same way.
+type Arg struct {
+	Name     string
+	Kind     Kind
+	Min, Max int      // of KindInt
+	Choices  []string // of KindChoice
+	Optional bool     // may be omitted, as must every Arg following
+}
+
+// usage returns the usage of the Arg, e.g., "<percent>" or "[count]".
+func (a Arg) usage() string {
+	s := a.Name
+	switch a.Kind {
+	case KindChoice:
+		s = strings.Join(a.Choices, "|")
+	case KindText:
+		s += "..."
+	}
+	if a.Optional {
+		return "[" + s + "]"
+	}
+	return "<" + s + ">"
+}
+
+// valid returns true if the given word is a valid value of the Arg.
+func (a Arg) valid(word string) bool {
+	switch a.Kind {
+	case KindInt:
+		n, err := strconv.Atoi(word)
+		return nil == err && n >= a.Min && n <= a.Max
+	case KindChoice:
+		for _, c := range a.Choices {
+			if c == word {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// parse returns the arguments given by the words following the name of a
+// Command with the given Args, one per Arg given, or false if they are invalid.
+// A KindText Arg consumes every remaining word.
+func parse(args []Arg, word []string) ([]string, bool) {
+	var value []string
+	for _, a := range args {
+		if 0 == len(word) {
+			if !a.Optional {
+				return nil, false
+			}
+			break
+		}
+		if KindText == a.Kind {
+			value, word = append(value, strings.Join(word, " ")), nil
+			break
+		}
+		if !a.valid(word[0]) {
+			return nil, false
+		}
+		value, word = append(value, word[0]), word[1:]
+	}
+	return value, 0 == len(word)
+}
@@ -0,0 +1,120 @@
+// Package command implements the commands used to inspect and control the
+// device, shared by every interface accepting them, i.e., the serial console,
+// the HTTP API, MQTT (see feed/control), and the IR remote, so that each
+// command is defined only once.
+//
+// A command line is a sequence of words separated by spaces. The leading words
+// name the Command, e.g., "wifi scan", and the remaining words are its
+// arguments, e.g., "set brightness 40".
+package command
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrUnknown = errors.New("unknown command")
+	ErrUsage   = errors.New("invalid arguments")
+)
+
+// Command is a named operation of the device.
+//
+// If Args are given, the arguments are checked against them before Run is
+// called, which then receives one argument per Arg given, and Usage is derived
+// from them unless given.
+type Command struct {
+	Name  string // one or more words, e.g., "ntp sync"
+	Usage string // arguments, e.g., "<percent>"
+	Help  string // brief description
+	Args  []Arg
+	// Run performs the command with the given arguments, writing any output to
+	// w. Returns ErrUsage if the arguments are invalid.
+	Run func(w io.Writer, args []string) error
+}
+
+// commands contains every registered Command, in order of registration.
+var commands []Command
+
+// Register adds the given Command to those recognized by Exec.
+func Register(c Command) {
+	if "" == c.Usage && nil != c.Args {
+		usage := make([]string, len(c.Args))
+		for i, a := range c.Args {
+			usage[i] = a.usage()
+		}
+		c.Usage = strings.Join(usage, " ")
+	}
+	commands = append(commands, c)
+}
+
+// Exec performs the command given by line, writing any output to w. The
+// Command with the longest name matching the leading words of line is used.
+func Exec(w io.Writer, line string) error {
+	word := strings.Fields(line)
+	if 0 == len(word) {
+		return nil
+	}
+	var (
+		match *Command
+		size  int // number of words in the name of match
+	)
+	for i := range commands {
+		name := strings.Fields(commands[i].Name)
+		if len(name) > len(word) || len(name) <= size {
+			continue
+		}
+		if equal(name, word[:len(name)]) {
+			match, size = &commands[i], len(name)
+		}
+	}
+	if nil == match {
+		return ErrUnknown
+	}
+	args, err := word[size:], error(nil)
+	if nil != match.Args {
+		var ok bool
+		if args, ok = parse(match.Args, args); !ok {
+			err = ErrUsage
+		}
+	}
+	if nil == err {
+		err = match.Run(w, args)
+	}
+	if ErrUsage == err {
+		io.WriteString(w, "usage: "+match.Name+" "+match.Usage+"\n")
+	}
+	return err
+}
+
+// Help writes the name, usage, and description of every Command to w.
+func Help(w io.Writer) {
+	for _, c := range commands {
+		line := c.Name
+		if "" != c.Usage {
+			line += " " + c.Usage
+		}
+		io.WriteString(w, line+"\n    "+c.Help+"\n")
+	}
+}
+
+func equal(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	Register(Command{
+		Name: "help",
+		Help: "list all commands",
+		Run: func(w io.Writer, args []string) error {
+			Help(w)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,687 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"machine"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/buzzer"
+	"github.com/ardnew/weatherhub/codec/qr"
+	"github.com/ardnew/weatherhub/command"
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/demo"
+	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/feed/forecast"
+	"github.com/ardnew/weatherhub/history"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/lang"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/profile"
+	"github.com/ardnew/weatherhub/run"
+	"github.com/ardnew/weatherhub/stopwatch"
+	"github.com/ardnew/weatherhub/wifi/network"
+	"github.com/ardnew/weatherhub/wifi/ntp"
+)
+
+var (
+	ErrFollowing       = errors.New("weather is fetched by the leader device")
+	ErrNoNetwork       = errors.New("not joined to a wireless network")
+	ErrTooManyWebhooks = errors.New("too many webhooks")
+	ErrNoWebhook       = errors.New("no such webhook")
+)
+
+// qrDuration defines how long a QR code is shown by the command "qr".
+const qrDuration = 2 * time.Minute
+
+// registerCommands registers the commands controlling the subsystems created
+// by main, used by the serial console and HTTP API alike. The forecast is nil
+// if weather is received from a leader device.
+func registerCommands(env *run.Env, host *ntp.NTP, fc *forecast.Forecast) {
+
+	command.Register(command.Command{
+		Name: "status",
+		Help: "show the identity and state of the device",
+		Run: func(w io.Writer, args []string) error {
+			data := model.Peek()
+			io.WriteString(w, "name:       "+env.WiFi.Name()+"\n"+
+				"version:    "+version+"\n"+
+				"status:     "+data.Status.String()+"\n"+
+				"ssid:       "+data.AP.SSID+"\n"+
+				"ip:         "+data.IP.String()+"\n"+
+				"time:       "+data.Time.Format("2006-01-02 15:04:05 MST")+"\n"+
+				"ntp:        "+timeSync(data)+"\n"+
+				"uptime:     "+data.Diagnostics.Uptime().Round(time.Second).String()+"\n"+
+				"boots:      "+strconv.FormatUint(uint64(data.Diagnostics.Boots), 10)+"\n"+
+				"reset:      "+data.Diagnostics.Reset.String()+"\n"+
+				"brightness: "+strconv.Itoa(env.Display.Brightness())+"%\n"+
+				"timing:     "+env.Display.Timing().String()+"\n"+
+				"features:   "+strings.Join(run.Features(), " ")+"\n")
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "wifi scan",
+		Help: "list the access points in range",
+		Run: func(w io.Writer, args []string) error {
+			return env.WiFi.Scan(func(ssid string, rssi int32) {
+				io.WriteString(w, strconv.Itoa(int(rssi))+" dBm  "+ssid+"\n")
+			})
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set brightness",
+		Help: "change the brightness of the display (1-100)",
+		Args: []command.Arg{{Name: "percent", Kind: command.KindInt, Min: 1, Max: 100}},
+		Run: func(w io.Writer, args []string) error {
+			percent, _ := strconv.Atoi(args[0])
+			env.Display.SetBrightness(percent)
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set timing",
+		Help: "change the timing preset of the display panel",
+		Args: []command.Arg{{Kind: command.KindChoice,
+			Choices: []string{"default", "smooth", "color", "safe"}}},
+		Run: func(w io.Writer, args []string) error {
+			t, _ := display.ParseTiming(args[0])
+			return env.Display.SetTiming(t)
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "test pattern",
+		Help: "show a test pattern across the entire panel, or restore the pages",
+		Args: []command.Arg{{Kind: command.KindChoice,
+			Choices: []string{"off", "gradient", "checker", "red", "green", "blue",
+				"white", "sweep"}}},
+		Run: func(w io.Writer, args []string) error {
+			p, _ := display.ParsePattern(args[0])
+			env.Display.SetPattern(p)
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set secret",
+		Help: "change the secret required by control channels, or clear it",
+		Args: []command.Arg{{Name: "secret", Optional: true}},
+		Run: func(w io.Writer, args []string) error {
+			secret := ""
+			if 1 == len(args) {
+				secret = args[0]
+			}
+			config.Set(func(s *config.Settings) {
+				s.Secret = secret
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set name",
+		Help: "change the name of the device on the network, or restore the default",
+		Args: []command.Arg{{Name: "name", Optional: true}},
+		Run: func(w io.Writer, args []string) error {
+			name := ""
+			if 1 == len(args) {
+				if !config.ValidName(args[0]) {
+					return config.ErrInvalidSetting
+				}
+				name = args[0]
+			}
+			config.Set(func(s *config.Settings) {
+				s.Name = name
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set wifi",
+		Help: "change the access point tried first when connecting, open if no passphrase",
+		Args: []command.Arg{{Name: "ssid"}, {Name: "passphrase", Optional: true}},
+		Run: func(w io.Writer, args []string) error {
+			ap := network.AP{SSID: args[0]}
+			if 2 == len(args) {
+				ap.Pass = args[1]
+			}
+			config.Set(func(s *config.Settings) {
+				s.WiFi = ap
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set key",
+		Help: "change the API key of an online service, or clear it",
+		Args: []command.Arg{
+			{Kind: command.KindChoice, Choices: []string{"n2yo"}},
+			{Name: "key", Optional: true},
+		},
+		Run: func(w io.Writer, args []string) error {
+			key := ""
+			if 2 == len(args) {
+				key = args[1]
+			}
+			config.Set(func(s *config.Settings) {
+				s.Keys.N2YO = key
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name:  "set sleep",
+		Usage: "[<from> <until>]",
+		Help:  "change the hours of the day the display is off (0-23), or never sleep",
+		Args: []command.Arg{
+			{Name: "from", Kind: command.KindInt, Min: 0, Max: 23, Optional: true},
+			{Name: "until", Kind: command.KindInt, Min: 0, Max: 23, Optional: true},
+		},
+		Run: func(w io.Writer, args []string) error {
+			var sleep config.Sleep
+			switch len(args) {
+			case 1:
+				return command.ErrUsage
+			case 2:
+				sleep.From, _ = strconv.Atoi(args[0])
+				sleep.Until, _ = strconv.Atoi(args[1])
+			}
+			config.Set(func(s *config.Settings) {
+				s.Sleep = sleep
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set chime",
+		Help: "turn the hourly chime on or off, which is silent while the display sleeps",
+		Args: []command.Arg{{Kind: command.KindChoice, Choices: []string{"on", "off"}}},
+		Run: func(w io.Writer, args []string) error {
+			config.Set(func(s *config.Settings) {
+				s.Chime.On = "on" == args[0]
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set chime count",
+		Help: "beep once for each hour after the chime, or not",
+		Args: []command.Arg{{Kind: command.KindChoice, Choices: []string{"on", "off"}}},
+		Run: func(w io.Writer, args []string) error {
+			config.Set(func(s *config.Settings) {
+				s.Chime.Count = "on" == args[0]
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set chime days",
+		Help: "change the days the chime sounds, e.g., mon,tue, or every day",
+		Args: []command.Arg{{Name: "days", Optional: true}},
+		Run: func(w io.Writer, args []string) error {
+			var days config.Days
+			if 1 == len(args) {
+				var ok bool
+				if days, ok = config.ParseDays(args[0]); !ok {
+					return config.ErrInvalidSetting
+				}
+			}
+			config.Set(func(s *config.Settings) {
+				s.Chime.Days = days
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set language",
+		Help: "change the language of phrases describing the weather, e.g., the summary",
+		Args: []command.Arg{{Kind: command.KindChoice, Choices: lang.Codes()}},
+		Run: func(w io.Writer, args []string) error {
+			language, _ := lang.Parse(args[0])
+			config.Set(func(s *config.Settings) {
+				s.Language = language
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "set melody",
+		Help: "change the RTTTL melody of alarms, alerts, or the chime, or restore the default",
+		Args: []command.Arg{
+			{Kind: command.KindChoice, Choices: []string{"alarm", "alert", "chime"}},
+			{Name: "rtttl", Kind: command.KindText, Optional: true},
+		},
+		Run: func(w io.Writer, args []string) error {
+			melody := ""
+			if 2 == len(args) {
+				if _, err := buzzer.Parse(args[1]); nil != err {
+					return err
+				}
+				melody = args[1]
+			}
+			config.Set(func(s *config.Settings) {
+				switch args[0] {
+				case "alarm":
+					s.Melody.Alarm = melody
+				case "alert":
+					s.Melody.Alert = melody
+				case "chime":
+					s.Melody.Chime = melody
+				}
+			})
+			return nil
+		},
+	})
+
+	// webhooks replace the list of the settings, never modifying it.
+	addWebhook := func(h config.Webhook) error {
+		var err error
+		config.Set(func(s *config.Settings) {
+			if len(s.Webhooks) >= config.MaxWebhooks {
+				err = ErrTooManyWebhooks
+				return
+			}
+			s.Webhooks = append(append([]config.Webhook{}, s.Webhooks...), h)
+		})
+		return err
+	}
+
+	command.Register(command.Command{
+		Name: "webhook add",
+		Help: "request a URL when an alert is posted or the network is joined again",
+		Args: []command.Arg{
+			{Kind: command.KindChoice, Choices: []string{"alert", "reconnect"}},
+			{Name: "url"},
+			{Name: "body", Kind: command.KindText, Optional: true},
+		},
+		Run: func(w io.Writer, args []string) error {
+			h := config.Webhook{Event: args[0], URL: args[1]}
+			if 3 == len(args) {
+				h.Body = args[2]
+			}
+			return addWebhook(h)
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "webhook add temperature",
+		Help: "request a URL when the outdoor temperature crosses a threshold",
+		Args: []command.Arg{
+			{Name: "celsius", Kind: command.KindInt, Min: -60, Max: 60},
+			{Name: "url"},
+			{Name: "body", Kind: command.KindText, Optional: true},
+		},
+		Run: func(w io.Writer, args []string) error {
+			t, _ := strconv.Atoi(args[0])
+			h := config.Webhook{Event: "temperature", URL: args[1], Threshold: float32(t)}
+			if 3 == len(args) {
+				h.Body = args[2]
+			}
+			return addWebhook(h)
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "webhook remove",
+		Help: "stop requesting the webhook numbered by \"webhook list\"",
+		Args: []command.Arg{{Name: "number", Kind: command.KindInt, Min: 1, Max: config.MaxWebhooks}},
+		Run: func(w io.Writer, args []string) error {
+			n, _ := strconv.Atoi(args[0])
+			var err error
+			config.Set(func(s *config.Settings) {
+				if n > len(s.Webhooks) {
+					err = ErrNoWebhook
+					return
+				}
+				hooks := append([]config.Webhook{}, s.Webhooks[:n-1]...)
+				s.Webhooks = append(hooks, s.Webhooks[n:]...)
+			})
+			return err
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "webhook list",
+		Help: "show the webhooks requested when events occur",
+		Run: func(w io.Writer, args []string) error {
+			for i, h := range config.Get().Webhooks {
+				event := h.Event
+				if "temperature" == event {
+					event += " " + strconv.FormatFloat(float64(h.Threshold), 'f', -1, 32)
+				}
+				io.WriteString(w, strconv.Itoa(i+1)+": "+event+" "+h.URL+"\n")
+			}
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "scene",
+		Help: "apply a named bundle of display settings, or list them",
+		Args: []command.Arg{{Name: "name", Optional: true}},
+		Run: func(w io.Writer, args []string) error {
+			if 1 == len(args) {
+				return env.Display.SetScene(args[0])
+			}
+			for _, name := range env.Display.Scenes() {
+				if name == env.Display.Scene() {
+					name += " *"
+				}
+				io.WriteString(w, name+"\n")
+			}
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "config show",
+		Help: "show the settings, excluding secrets",
+		Run: func(w io.Writer, args []string) error {
+			w.Write(append(config.Get().Append(nil, false), '\n'))
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "profile",
+		Help: "show the time spent in each subsystem of the run loop",
+		Run: func(w io.Writer, args []string) error {
+			loops, elapsed := profile.Report(func(s profile.Stats) {
+				if 0 == s.Calls {
+					return
+				}
+				mean := s.Total / time.Duration(s.Calls)
+				io.WriteString(w, s.Section.String()+": "+
+					strconv.FormatUint(uint64(s.Calls), 10)+" calls, total "+
+					s.Total.Round(time.Millisecond).String()+", mean "+
+					mean.Round(time.Microsecond).String()+", max "+
+					s.Max.Round(time.Microsecond).String()+"\n")
+			})
+			io.WriteString(w, strconv.FormatUint(uint64(loops), 10)+" loops in "+
+				elapsed.Round(time.Second).String())
+			if 0 != loops {
+				io.WriteString(w, ", "+(elapsed/time.Duration(loops)).Round(time.Microsecond).String()+" each")
+			}
+			io.WriteString(w, "\n")
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "profile reset",
+		Help: "clear the times measured by the profile",
+		Run: func(w io.Writer, args []string) error {
+			profile.Reset()
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name:  "qr",
+		Usage: "<url|wifi|off|text...>",
+		Help:  "show the URL of the device, the network joined, or any text as a QR code",
+		Run: func(w io.Writer, args []string) error {
+			if 0 == len(args) {
+				return command.ErrUsage
+			}
+			data := model.Peek()
+			code := model.QR{Until: time.Now().Add(qrDuration)}
+			switch args[0] {
+			case "off":
+				code = model.QR{}
+			case "url":
+				code.Text, code.Caption = "http://"+data.IP.String()+"/", "Scan to open"
+			case "wifi":
+				if data.AP.Wired || data.AP.Cellular || "" == data.AP.SSID {
+					return ErrNoNetwork
+				}
+				code.Text, code.Caption = wifiURI(data.AP), "Scan to join "+data.AP.SSID
+			default:
+				code.Text, code.Caption = strings.Join(args, " "), "Scan"
+			}
+			if "" != code.Text {
+				if _, err := qr.Encode(code.Text); nil != err {
+					return err
+				}
+			}
+			model.Set(func(m *model.Model) { m.QR = code })
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "stopwatch",
+		Help: "show the elapsed time and laps of the stopwatch",
+		Run: func(w io.Writer, args []string) error {
+			s := model.Peek().Stopwatch
+			state := "stopped"
+			if s.Running {
+				state = "running"
+			}
+			io.WriteString(w, state+" "+s.At(time.Now()).Round(10*time.Millisecond).String()+"\n")
+			for n := 1; n <= s.Laps; n++ {
+				if lap, ok := s.Lap(n); ok {
+					io.WriteString(w, "lap "+strconv.Itoa(n)+": "+
+						lap.Round(10*time.Millisecond).String()+"\n")
+				}
+			}
+			return nil
+		},
+	})
+
+	for _, c := range []struct {
+		name, help string
+		run        func()
+	}{
+		{"start", "start the stopwatch", stopwatch.Start},
+		{"stop", "stop the stopwatch", stopwatch.Stop},
+		{"lap", "record a lap of the running stopwatch", stopwatch.Lap},
+		{"reset", "stop the stopwatch and clear its time and laps", stopwatch.Reset},
+	} {
+		run := c.run
+		command.Register(command.Command{
+			Name: "stopwatch " + c.name,
+			Help: c.help,
+			Run: func(w io.Writer, args []string) error {
+				run()
+				return nil
+			},
+		})
+	}
+
+	command.Register(command.Command{
+		Name: "ping",
+		Help: "show the round-trip time to a host name or IP address",
+		Args: []command.Arg{{Name: "host"}},
+		Run: func(w io.Writer, args []string) error {
+			rtt, err := env.WiFi.Ping(args[0], 0)
+			if nil != err {
+				return err
+			}
+			io.WriteString(w, rtt.String()+"\n")
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "hops",
+		Help: "show the number of hops to a host name or IP address",
+		Args: []command.Arg{{Name: "host"}},
+		Run: func(w io.Writer, args []string) error {
+			n, err := env.WiFi.Hops(args[0], 30)
+			if nil != err {
+				return err
+			}
+			io.WriteString(w, strconv.Itoa(int(n))+"\n")
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "ntp sync",
+		Help: "synchronize the time with the NTP server now",
+		Run: func(w io.Writer, args []string) error {
+			host.Expire()
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "weather fetch",
+		Help: "poll the forecast service now",
+		Run: func(w io.Writer, args []string) error {
+			if nil == fc {
+				return ErrFollowing
+			}
+			fc.Expire()
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "sync now",
+		Help: "synchronize the time and poll the forecast service now",
+		Args: []command.Arg{},
+		Run: func(w io.Writer, args []string) error {
+			host.Expire()
+			if nil != fc {
+				fc.Expire()
+			}
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "page next",
+		Help: "advance the carousel to the next page",
+		Args: []command.Arg{},
+		Run: func(w io.Writer, args []string) error {
+			env.Display.NextPage()
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "page pin",
+		Help: "keep the carousel on the current page, or release it",
+		Args: []command.Arg{{Kind: command.KindChoice, Choices: []string{"on", "off"}}},
+		Run: func(w io.Writer, args []string) error {
+			env.Display.SetPinned("on" == args[0])
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "demo",
+		Help: "show every page with synthetic data, without the network",
+		Args: []command.Arg{{Kind: command.KindChoice, Choices: []string{"on", "off"}}},
+		Run: func(w io.Writer, args []string) error {
+			if "on" == args[0] {
+				demo.Start(display.Icons())
+			} else {
+				demo.Stop()
+			}
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "reboot",
+		Help: "restart the device",
+		Args: []command.Arg{},
+		Run: func(w io.Writer, args []string) error {
+			journal.Println("reboot requested")
+			time.Sleep(100 * time.Millisecond) // let any reply be sent
+			machine.CPUReset()
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "history raw",
+		Help: "show the most recent raw values of smoothed quantities",
+		Args: []command.Arg{{Name: "name", Optional: true}},
+		Run: func(w io.Writer, args []string) error {
+			history.Each(func(r *history.Raw) {
+				if 1 == len(args) && args[0] != r.Name() {
+					return
+				}
+				io.WriteString(w, r.Name()+":\n")
+				r.Tail(history.MaxSamples, func(s history.Sample) {
+					io.WriteString(w, "  "+s.Time.Format("15:04:05")+" "+
+						strconv.FormatFloat(float64(s.Value), 'f', 2, 32)+"\n")
+				})
+			})
+			return nil
+		},
+	})
+
+	command.Register(command.Command{
+		Name: "log tail",
+		Help: "show the most recent messages logged",
+		Args: []command.Arg{{Name: "count", Kind: command.KindInt, Min: 1,
+			Max: journal.MaxEntries, Optional: true}},
+		Run: func(w io.Writer, args []string) error {
+			n := journal.MaxEntries
+			if 1 == len(args) {
+				n, _ = strconv.Atoi(args[0])
+			}
+			journal.Tail(n, func(e journal.Entry) {
+				io.WriteString(w, e.Time.Format("15:04:05")+" "+e.Text+"\n")
+			})
+			return nil
+		},
+	})
+}
+
+// timeSync describes the most recent synchronization of the system time, e.g.,
+// "us.pool.ntp.org stratum 2, offset -12ms, delay 38ms, 1h30m0s ago".
+func timeSync(data model.Model) string {
+	s := data.Diagnostics.TimeSync
+	if s.Synced.IsZero() {
+		return "never synchronized"
+	}
+	return s.Server + " stratum " + strconv.Itoa(int(s.Stratum)) +
+		", offset " + s.Offset.Round(time.Millisecond).String() +
+		", delay " + s.Delay.Round(time.Millisecond).String() +
+		", " + data.Time.Sub(s.Synced).Round(time.Second).String() + " ago"
+}
+
+// wifiURI returns the URI of the credentials of the given access point, which
+// phones join when scanned as a QR code, e.g.:
+//
+//	WIFI:T:WPA;S:home;P:secret;;
+func wifiURI(ap network.AP) string {
+	escape := strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+	uri := "WIFI:"
+	switch {
+	case "" == ap.Pass:
+		uri += "T:nopass;"
+	case ap.WEP:
+		uri += "T:WEP;"
+	default:
+		uri += "T:WPA;"
+	}
+	uri += "S:" + escape.Replace(ap.SSID) + ";"
+	if "" != ap.Pass {
+		uri += "P:" + escape.Replace(ap.Pass) + ";"
+	}
+	if ap.Hidden {
+		uri += "H:true;"
+	}
+	return uri + ";"
+}
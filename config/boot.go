@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/binary"
+)
+
+// CountBoot increments the number of times the device has started, which is
+// kept in the erase block preceding those of the saved Settings, and returns
+// the new count.
+//
+// The block is a log of counts, each appended to the erased space following the
+// previous one, so that the block is only erased once it is full, i.e., once
+// every five hundred or so boots rather than on every boot. Each record is the
+// count followed by its complement, so that a record partially written when
+// power was lost is skipped.
+func CountBoot(dev Device) (uint32, error) {
+
+	block := dev.EraseBlockSize()
+	start := dev.Size()/block - blockBoots
+	if start < 0 {
+		return 0, ErrStoreSize
+	}
+	base := start * block
+
+	// find the last count written, which precedes the first erased record
+	var (
+		buf   [64]byte
+		count uint32
+		next  = int64(-1) // offset of the first erased record
+	)
+	for off := int64(0); off < block && next < 0; off += int64(len(buf)) {
+		if _, err := dev.ReadAt(buf[:], base+off); nil != err {
+			return 0, err
+		}
+		for i := 0; i < len(buf); i += 8 {
+			v := binary.LittleEndian.Uint32(buf[i:])
+			w := binary.LittleEndian.Uint32(buf[i+4:])
+			if 0xFFFFFFFF == v && 0xFFFFFFFF == w {
+				next = off + int64(i)
+				break
+			}
+			if v == ^w {
+				count = v
+			}
+		}
+	}
+	count++
+
+	if next < 0 {
+		// the block is full, so begin again
+		if err := dev.EraseBlocks(start, 1); nil != err {
+			return 0, err
+		}
+		next = 0
+	}
+	binary.LittleEndian.PutUint32(buf[:4], count)
+	binary.LittleEndian.PutUint32(buf[4:8], ^count)
+	if _, err := dev.WriteAt(buf[:8], base+next); nil != err {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,322 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/lang"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Settings are encoded as a JSON object, both when exported by the HTTP API
+// and when saved to a Store, e.g.:
+//
+//	{"name": "kitchen",
+//	 "wifi": {"ssid": "home", "pass": "secret", "priority": 0, "hidden": false,
+//	  "wep": false, "bssid": ""},
+//	 "location": {"latitude": 40.71, "longitude": -74.01, "city": "New York"},
+//	 "units": {"system": "imperial", "pressure": "metric"}, "language": "en",
+//	 "locale": {"order": "day-month", "week": "monday", "number": "shown"},
+//	 "hidden": "ski,surf", "theme": "default",
+//	 "clocks": "Tokyo=Asia/Tokyo,London=Europe/London", "secret": "hunter2",
+//	 "keys": {"n2yo": "ABCD-1234"}, "sleep": {"from": 23, "until": 7},
+//	 "chime": {"on": true, "count": false, "days": "sat,sun"},
+//	 "melody": {"alarm": "", "alert": "", "chime": "bells:d=4,o=5,b=100:c,e,g"},
+//	 "webhooks": [{"event": "temperature", "url": "https://ntfy.sh/home",
+//	  "body": "", "threshold": 30}]}
+//
+// The secrets are the WiFi passphrase, "secret", and the API keys of "keys".
+//
+// The "webhooks" decoded replace all of those in the Settings, and hooks whose
+// "url" is empty are dropped, so that e.g. [{"url": ""}] removes every hook.
+
+// Append appends s to b as a JSON object, including the secrets only if secrets
+// is true.
+func (s Settings) Append(b []byte, secrets bool) []byte {
+	b = json.AppendString(append(b, `{"name":`...), s.Name)
+	b = json.AppendString(append(b, `,"wifi":{"ssid":`...), s.WiFi.SSID)
+	if secrets {
+		b = json.AppendString(append(b, `,"pass":`...), s.WiFi.Pass)
+	}
+	b = strconv.AppendInt(append(b, `,"priority":`...), int64(s.WiFi.Priority), 10)
+	b = strconv.AppendBool(append(b, `,"hidden":`...), s.WiFi.Hidden)
+	b = strconv.AppendBool(append(b, `,"wep":`...), s.WiFi.WEP)
+	b = json.AppendString(append(b, `,"bssid":`...), s.WiFi.BSSID)
+	b = append(b, `},"location":{"latitude":`...)
+	b = strconv.AppendFloat(b, s.Location.Latitude, 'f', -1, 64)
+	b = append(b, `,"longitude":`...)
+	b = strconv.AppendFloat(b, s.Location.Longitude, 'f', -1, 64)
+	b = json.AppendString(append(b, `,"city":`...), s.Location.City)
+	b = append(b, `},"units":{"system":"`...)
+	b = append(b, s.Units.System.String()...)
+	b = append(append(b, `","temperature":"`...), s.Units.Temperature.String()...)
+	b = append(append(b, `","speed":"`...), s.Units.Speed.String()...)
+	b = append(append(b, `","pressure":"`...), s.Units.Pressure.String()...)
+	b = append(append(b, `","precipitation":"`...), s.Units.Precipitation.String()...)
+	b = append(append(b, `","height":"`...), s.Units.Height.String()...)
+	b = append(append(b, `"},"language":"`...), s.Language.String()...)
+	b = append(append(b, `","locale":{"order":"`...), s.Locale.Order.String()...)
+	b = append(append(b, `","week":"`...), s.Locale.Start.String()...)
+	b = append(append(b, `","number":"`...), s.Locale.Number.String()...)
+	b = json.AppendString(append(b, `"},"hidden":`...), strings.Join(s.Hidden, ","))
+	b = json.AppendString(append(b, `,"theme":`...), s.Theme)
+	b = json.AppendString(append(b, `,"clocks":`...), joinClocks(s.Clocks))
+	if secrets {
+		b = json.AppendString(append(b, `,"secret":`...), s.Secret)
+		b = json.AppendString(append(b, `,"keys":{"n2yo":`...), s.Keys.N2YO)
+		b = append(b, '}')
+	}
+	b = strconv.AppendInt(append(b, `,"sleep":{"from":`...), int64(s.Sleep.From), 10)
+	b = strconv.AppendInt(append(b, `,"until":`...), int64(s.Sleep.Until), 10)
+	b = strconv.AppendBool(append(b, `},"chime":{"on":`...), s.Chime.On)
+	b = strconv.AppendBool(append(b, `,"count":`...), s.Chime.Count)
+	b = json.AppendString(append(b, `,"days":`...), s.Chime.Days.String())
+	b = json.AppendString(append(b, `},"melody":{"alarm":`...), s.Melody.Alarm)
+	b = json.AppendString(append(b, `,"alert":`...), s.Melody.Alert)
+	b = json.AppendString(append(b, `,"chime":`...), s.Melody.Chime)
+	b = append(b, `},"webhooks":[`...)
+	for i, h := range s.Webhooks {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = json.AppendString(append(b, `{"event":`...), h.Event)
+		b = json.AppendString(append(b, `,"url":`...), h.URL)
+		b = json.AppendString(append(b, `,"body":`...), h.Body)
+		b = append(b, `,"threshold":`...)
+		b = append(strconv.AppendFloat(b, float64(h.Threshold), 'f', -1, 32), '}')
+	}
+	return append(b, "]}"...)
+}
+
+// Decode changes the fields of s given by the JSON object read from r.
+// Unrecognized fields are ignored. Returns ErrInvalidSetting if any field has
+// an invalid value, in which case s may be partially changed.
+func (s *Settings) Decode(r io.Reader) error {
+	return s.extract(r, func(path string, err error) error {
+		return err
+	})
+}
+
+// decodeLenient is like Decode, except that fields with an invalid value are
+// also ignored, each calling skip with its path, e.g., a value only known to
+// newer firmware.
+func (s *Settings) decodeLenient(r io.Reader, skip func(path string)) error {
+	return s.extract(r, func(path string, err error) error {
+		if ErrInvalidSetting == err {
+			skip(path)
+			return nil
+		}
+		return err
+	})
+}
+
+// extract changes the fields of s given by the JSON object read from r. If a
+// field cannot be changed, extraction stops unless fail returns nil.
+func (s *Settings) extract(r io.Reader, fail func(path string, err error) error) error {
+	hooks := false // Webhooks replaced by those read
+	err := json.Extract(r, func(path []byte, kind json.Kind, value []byte) error {
+		if !hooks && bytes.HasPrefix(path, []byte("webhooks.")) {
+			s.Webhooks, hooks = nil, true
+		}
+		if err := s.set(string(path), string(value)); nil != err {
+			return fail(string(path), err)
+		}
+		return nil
+	})
+	if hooks {
+		kept := s.Webhooks[:0]
+		for _, h := range s.Webhooks {
+			if "" != h.URL {
+				kept = append(kept, h)
+			}
+		}
+		s.Webhooks = kept
+	}
+	return err
+}
+
+// set changes the field of s with the given JSON path to the given value.
+func (s *Settings) set(path, value string) error {
+	var err error
+	switch path {
+	case "name":
+		if s.Name = value; !ValidName(value) {
+			err = ErrInvalidSetting
+		}
+	case "wifi.ssid":
+		s.WiFi.SSID = value
+	case "wifi.pass":
+		s.WiFi.Pass = value
+	case "wifi.priority":
+		s.WiFi.Priority, err = strconv.Atoi(value)
+	case "wifi.hidden":
+		s.WiFi.Hidden, err = strconv.ParseBool(value)
+	case "wifi.wep":
+		s.WiFi.WEP, err = strconv.ParseBool(value)
+	case "wifi.bssid":
+		s.WiFi.BSSID = value
+	case "location.latitude":
+		s.Location.Latitude, err = strconv.ParseFloat(value, 64)
+	case "location.longitude":
+		s.Location.Longitude, err = strconv.ParseFloat(value, 64)
+	case "location.city":
+		s.Location.City = value
+	case "units.system":
+		err = system(&s.Units.System, value)
+	case "units.temperature":
+		err = system(&s.Units.Temperature, value)
+	case "units.speed":
+		err = system(&s.Units.Speed, value)
+	case "units.pressure":
+		err = system(&s.Units.Pressure, value)
+	case "units.precipitation":
+		err = system(&s.Units.Precipitation, value)
+	case "units.height":
+		err = system(&s.Units.Height, value)
+	case "language":
+		var ok bool
+		if s.Language, ok = lang.Parse(value); !ok {
+			err = ErrInvalidSetting
+		}
+	case "locale.order":
+		var ok bool
+		if s.Locale.Order, ok = model.ParseDateOrder(value); !ok {
+			err = ErrInvalidSetting
+		}
+	case "locale.week":
+		var ok bool
+		if s.Locale.Start, ok = model.ParseWeekStart(value); !ok {
+			err = ErrInvalidSetting
+		}
+	case "locale.number":
+		var ok bool
+		if s.Locale.Number, ok = model.ParseWeekNumber(value); !ok {
+			err = ErrInvalidSetting
+		}
+	case "hidden":
+		s.Hidden = nil // replaced, never modified (see Set)
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); "" != name {
+				s.Hidden = append(s.Hidden, name)
+			}
+		}
+	case "theme":
+		s.Theme = value
+	case "clocks":
+		s.Clocks, err = splitClocks(value)
+	case "secret":
+		s.Secret = value
+	case "keys.n2yo":
+		s.Keys.N2YO = value
+	case "sleep.from":
+		s.Sleep.From, err = hour(value)
+	case "sleep.until":
+		s.Sleep.Until, err = hour(value)
+	case "chime.on":
+		s.Chime.On, err = strconv.ParseBool(value)
+	case "chime.count":
+		s.Chime.Count, err = strconv.ParseBool(value)
+	case "chime.days":
+		var ok bool
+		if s.Chime.Days, ok = ParseDays(value); !ok {
+			err = ErrInvalidSetting
+		}
+	case "melody.alarm":
+		s.Melody.Alarm = value
+	case "melody.alert":
+		s.Melody.Alert = value
+	case "melody.chime":
+		s.Melody.Chime = value
+	default:
+		if strings.HasPrefix(path, "webhooks.") {
+			err = s.setWebhook(path[len("webhooks."):], value)
+		}
+	}
+	if nil != err {
+		return ErrInvalidSetting
+	}
+	return nil
+}
+
+// setWebhook changes the field of the Webhook with the given path, e.g., "0.url",
+// to the given value. The Webhook following the last is appended.
+func (s *Settings) setWebhook(path, value string) error {
+	i := strings.IndexByte(path, '.')
+	if i < 0 {
+		return ErrInvalidSetting
+	}
+	n, err := strconv.Atoi(path[:i])
+	if nil != err || n < 0 || n > len(s.Webhooks) || n >= MaxWebhooks {
+		return ErrInvalidSetting
+	}
+	if n == len(s.Webhooks) {
+		s.Webhooks = append(s.Webhooks, Webhook{})
+	}
+	h := &s.Webhooks[n]
+	switch path[i+1:] {
+	case "event":
+		h.Event = value
+	case "url":
+		h.URL = value
+	case "body":
+		h.Body = value
+	case "threshold":
+		var t float64
+		t, err = strconv.ParseFloat(value, 32)
+		h.Threshold = float32(t)
+	}
+	return err
+}
+
+// joinClocks returns the given world clocks as a list of "label=zone" separated
+// by commas.
+func joinClocks(clocks []model.WorldClock) string {
+	var b strings.Builder
+	for i, c := range clocks {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(c.Label + "=" + c.Zone)
+	}
+	return b.String()
+}
+
+// splitClocks returns a new slice of the world clocks of the given list, as
+// returned by joinClocks.
+func splitClocks(list string) ([]model.WorldClock, error) {
+	var clocks []model.WorldClock
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); "" == item {
+			continue
+		}
+		i := strings.IndexByte(item, '=')
+		if i < 0 {
+			return nil, ErrInvalidSetting
+		}
+		clocks = append(clocks, model.WorldClock{
+			Label: strings.TrimSpace(item[:i]),
+			Zone:  strings.TrimSpace(item[i+1:]),
+		})
+	}
+	return clocks, nil
+}
+
+// hour returns the hour of the day, 0-23, given in decimal.
+func hour(value string) (int, error) {
+	h, err := strconv.Atoi(value)
+	if nil == err && (h < 0 || h > 23) {
+		err = ErrInvalidSetting
+	}
+	return h, err
+}
+
+func system(s *model.System, name string) error {
+	var ok bool
+	if *s, ok = model.ParseSystem(name); !ok {
+		return ErrInvalidSetting
+	}
+	return nil
+}
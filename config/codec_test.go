@@ -0,0 +1,41 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecodeWebhooks(t *testing.T) {
+	s := Settings{Webhooks: []Webhook{{Event: "alert", URL: "http://a"}}}
+	want := []Webhook{
+		{Event: "temperature", URL: "http://t/{value}", Threshold: 30.5},
+		{Event: "reconnect", URL: "http://r", Body: `{"text":"{text}"}`},
+	}
+	doc := Settings{Webhooks: want}.Append(nil, false)
+	if err := s.Decode(bytes.NewReader(doc)); nil != err {
+		t.Fatalf("Decode(%s) = %v", doc, err)
+	}
+	if len(want) != len(s.Webhooks) {
+		t.Fatalf("Decode(%s) = %+v, want %+v", doc, s.Webhooks, want)
+	}
+	for i := range want {
+		if want[i] != s.Webhooks[i] {
+			t.Errorf("Webhooks[%d] = %+v, want %+v", i, s.Webhooks[i], want[i])
+		}
+	}
+
+	// hooks without a URL are dropped, and those omitted are kept.
+	if err := s.Decode(strings.NewReader(`{"webhooks":[{"url":""}]}`)); nil != err || 0 != len(s.Webhooks) {
+		t.Errorf("Decode(empty) = %+v, %v, want none", s.Webhooks, err)
+	}
+	s.Webhooks = want
+	if err := s.Decode(strings.NewReader(`{"theme":"night"}`)); nil != err || len(want) != len(s.Webhooks) {
+		t.Errorf("Decode(theme) = %+v, %v, want %+v", s.Webhooks, err, want)
+	}
+
+	// the index of each hook follows the last.
+	if err := s.Decode(strings.NewReader(`{"webhooks":{"1":{"url":"x"}}}`)); ErrInvalidSetting != err {
+		t.Errorf("Decode(gap) = %v, want %v", err, ErrInvalidSetting)
+	}
+}
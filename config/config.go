@@ -0,0 +1,228 @@
+// Package config holds the settings of the device that may be changed at
+// runtime, e.g., from the configuration UI served by the HTTP server, rather
+// than only by the configuration compiled into the program.
+//
+// The zero value of each setting selects the compiled configuration. Settings
+// are applied by the subsystems using them, which Watch for changes.
+package config
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardnew/weatherhub/lang"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/network"
+)
+
+var (
+	ErrInvalidSetting = errors.New("invalid setting")
+)
+
+// Settings are the options of the device adjustable at runtime.
+type Settings struct {
+	Name     string     // of the device on the network, e.g., its DHCP host name
+	WiFi     network.AP // tried first among access points of equal priority
+	Location Location
+	Units    model.Units
+	Language lang.Language // of phrases describing the weather, e.g., the summary
+	Locale   model.Locale
+	Hidden   []string           // names of pages omitted from the carousel
+	Theme    string             // name of the theme used on ordinary days
+	Clocks   []model.WorldClock // time zones of the world clock page
+	Secret   string             // required by control channels, unless empty
+	Keys     Keys
+	Sleep    Sleep // hours the display is off, e.g., overnight
+	Chime    Chime // of the hourly chime, if built with the buzzer
+	Melody   Melody
+	Webhooks []Webhook // requested when events occur (see package webhook)
+}
+
+// ValidName returns true if the given name of the device is empty, selecting
+// the compiled name, or is a valid host name label: up to 63 letters, digits,
+// and hyphens, neither beginning nor ending with a hyphen.
+func ValidName(name string) bool {
+	if len(name) > 63 || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || '-' == c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Keys are the credentials of online services used by feeds. Each key given
+// here is used in place of the key configured for its feed, if any.
+type Keys struct {
+	N2YO string // satellite passes (see package satellite)
+}
+
+// Secrets returns a copy of s containing only the secrets, i.e., the WiFi
+// passphrase, Secret, and Keys.
+func (s Settings) Secrets() Settings {
+	return Settings{WiFi: network.AP{Pass: s.WiFi.Pass}, Secret: s.Secret, Keys: s.Keys}
+}
+
+// Location defines the location of the device. The zero value selects the
+// configured location, or geolocation if none is configured.
+type Location struct {
+	Latitude  float64 // degrees north
+	Longitude float64 // degrees east
+	City      string
+}
+
+// Known returns true if the coordinates of the Location are given.
+func (l Location) Known() bool {
+	return 0 != l.Latitude || 0 != l.Longitude
+}
+
+// Sleep defines the hours of the day the display is off, from the beginning of
+// From until the beginning of Until, 0-23, which may span midnight. The display
+// never sleeps if both are equal, e.g., zero.
+type Sleep struct {
+	From  int
+	Until int
+}
+
+// Chime defines the hourly chime (see package chime), which is silent while the
+// display sleeps.
+type Chime struct {
+	On    bool // the chime sounds at all
+	Count bool // beep once for each hour after the melody
+	Days  Days // days the chime sounds, or every day if none
+}
+
+// Melody defines the RTTTL melodies played on the buzzer (see buzzer.Parse).
+// Each melody left empty is the compiled default.
+type Melody struct {
+	Alarm string // e.g., of wind gusts
+	Alert string // of weather advisories
+	Chime string // of the hourly chime
+}
+
+// MaxWebhooks defines the number of Webhooks in the Settings.
+const MaxWebhooks = 4
+
+// Webhook defines a request made when an event occurs (see webhook.Hook), whose
+// Event is the name of a webhook.Event, e.g., "alert".
+type Webhook struct {
+	Event     string
+	URL       string  // template, e.g., "https://ntfy.sh/home?title={event}"
+	Body      string  // template, POST as JSON if given, or GET otherwise
+	Threshold float32 // °C, of event "temperature"
+}
+
+// Days is a set of days of the week, each given by the bit 1<<time.Weekday.
+type Days uint8
+
+// Weekdays returns the days of the set in order beginning Sunday, or nil if the
+// set is empty.
+func (d Days) Weekdays() []time.Weekday {
+	var day []time.Weekday
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		if 0 != d&(1<<w) {
+			day = append(day, w)
+		}
+	}
+	return day
+}
+
+// String returns the days of the set as a list of abbreviated names separated
+// by commas, e.g., "mon,wed,fri".
+func (d Days) String() string {
+	var name []string
+	for _, w := range d.Weekdays() {
+		name = append(name, strings.ToLower(w.String()[:3]))
+	}
+	return strings.Join(name, ",")
+}
+
+// ParseDays returns the set of days of the given list, as returned by String.
+func ParseDays(list string) (Days, bool) {
+	var d Days
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); "" == name {
+			continue
+		}
+		w := time.Sunday
+		for w <= time.Saturday && name != strings.ToLower(w.String()[:3]) {
+			w++
+		}
+		if w > time.Saturday {
+			return 0, false
+		}
+		d |= 1 << w
+	}
+	return d, true
+}
+
+// Hides returns true if the page with the given name is omitted from the
+// carousel.
+func (s Settings) Hides(page string) bool {
+	for _, h := range s.Hidden {
+		if h == page {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorized returns true if the given secret matches the Secret of the
+// Settings, or if no Secret is set. It is used by every channel accepting
+// commands that change the state of the device, e.g., the HTTP API and MQTT
+// messages, so that anyone on the network cannot blank or rename the device.
+//
+// The serial console is not authenticated, since it requires physical access.
+func Authorized(secret string) bool {
+	want := Get().Secret
+	return "" == want ||
+		1 == subtle.ConstantTimeCompare([]byte(secret), []byte(want))
+}
+
+// state holds the instance variable of the Settings and the functions watching
+// them for changes.
+var state = struct {
+	lock  sync.Mutex
+	data  Settings
+	watch []func(prev, next Settings)
+}{}
+
+// Get safely returns a copy of the Settings.
+func Get() (data Settings) {
+	state.lock.Lock()
+	data = state.data
+	state.lock.Unlock()
+	return
+}
+
+// Set provides synchronized read+write access to the Settings via argument to
+// the given closure. Every function registered with Watch is then called with
+// the Settings before and after the closure was called.
+//
+// The closure must replace, not modify, the Hidden, Clocks, and Webhooks slices,
+// which are shared by every copy of the Settings.
+func Set(set func(*Settings)) {
+	state.lock.Lock()
+	prev := state.data
+	set(&state.data)
+	next := state.data
+	watch := state.watch
+	state.lock.Unlock()
+	for _, w := range watch {
+		w(prev, next)
+	}
+}
+
+// Watch registers a function called with the Settings before and after each
+// call to Set, so that any changes may be applied.
+func Watch(w func(prev, next Settings)) {
+	state.lock.Lock()
+	state.watch = append(state.watch, w)
+	state.lock.Unlock()
+}
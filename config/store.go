@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"strconv"
+
+	"github.com/ardnew/weatherhub/journal"
+)
+
+var (
+	ErrStoreSize = errors.New("settings exceed the size of the store")
+	errCorrupt   = errors.New("saved settings are corrupt")
+	errSchema    = errors.New("saved settings have an unknown schema")
+)
+
+// Device is nonvolatile memory holding the saved Settings, e.g., the flash chip
+// of the board (see tinygo.org/x/drivers/flash). Its methods are a subset of
+// those of every TinyGo block device.
+type Device interface {
+	ReadAt(p []byte, off int64) (int, error)
+	WriteAt(p []byte, off int64) (int, error)
+	Size() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+// Erase blocks used at the end of the Device, counted back from the last.
+const (
+	blockSettings = 1 // two copies of the Settings, this and the one before
+	blockBoots    = 3 // log of boot counts (see CountBoot)
+)
+
+// Reserved defines the number of erase blocks at the end of the Device used by
+// the store, which must not be used otherwise.
+const Reserved = blockBoots
+
+// magic identifies saved Settings, and changes with the format of the header.
+// Copies saved with the header of legacyMagic, which has no schema version, are
+// still restored, as version 1.
+const (
+	magic       = "WHS3"
+	legacyMagic = "WHS2"
+)
+
+// Schema is the version of the encoded Settings, saved with each copy. It must
+// be incremented whenever the meaning of a field or its values changes, so that
+// copies saved by other firmware can be told apart, and load must then convert
+// copies of each older version. Copies of a newer version, saved by newer
+// firmware, are not restored, since the meaning of their fields is unknown.
+// Fields merely added need no new version: a copy is decoded ignoring unknown
+// fields and invalid values, and fields missing from it keep their zero value,
+// i.e., the compiled default.
+const Schema = 1
+
+// header precedes the saved Settings: magic, schema version (uint8), sequence
+// number of the copy (little-endian uint32), length of the encoded Settings
+// (little-endian uint16), and CRC-32 of the version, sequence number, length,
+// and encoded Settings (little-endian).
+const header = len(magic) + 1 + 4 + 2 + 4
+
+// slot describes one of the two copies of the saved Settings.
+type slot struct {
+	index int64  // of its erase block
+	seq   uint32 // incremented with each save, 0 if invalid
+}
+
+// Open restores the Settings saved to the last erase blocks of the given Device,
+// if any, and then saves the Settings whenever they change, including the
+// secrets. Settings that are missing, corrupt, or of an unknown Schema are
+// ignored, so that the compiled configuration is used instead.
+//
+// The Settings are saved alternately to two erase blocks, each with a sequence
+// number and checksum, and the valid copy with the greater sequence number is
+// restored. Saving erases and rewrites only the older copy, and its header is
+// written last, so that a loss of power while saving, e.g., a brownout, leaves
+// at worst the previous Settings intact rather than none at all.
+func Open(dev Device) error {
+
+	last := dev.Size()/dev.EraseBlockSize() - blockSettings
+	if last < 1 {
+		return ErrStoreSize
+	}
+
+	var (
+		newest  = slot{index: last - 1}
+		restore Settings
+		corrupt bool
+	)
+	for _, index := range [...]int64{last - 1, last} {
+		s, seq, err := load(dev, index)
+		if errCorrupt == err {
+			corrupt = true
+			continue
+		}
+		if errSchema == err {
+			continue // reported by load
+		}
+		if nil != err {
+			return err
+		}
+		if seq > newest.seq {
+			newest = slot{index: index, seq: seq}
+			restore = s
+		}
+	}
+	if 0 != newest.seq {
+		Set(func(c *Settings) { *c = restore })
+	} else if corrupt {
+		journal.Println("error: " + errCorrupt.Error())
+	}
+
+	Watch(func(prev, next Settings) {
+		// the older copy, i.e., the block other than the newest
+		index := 2*last - 1 - newest.index
+		if err := save(dev, index, newest.seq+1, next); nil != err {
+			journal.Println("error: save settings: " + err.Error())
+			return
+		}
+		newest = slot{index: index, seq: newest.seq + 1}
+	})
+	return nil
+}
+
+// load returns the Settings saved to the erase block at index, and the sequence
+// number of the copy, which is 0 if there is no copy, or errCorrupt if the copy
+// is invalid, e.g., partially written, or errSchema if the copy has a Schema
+// unknown to this firmware.
+func load(dev Device, index int64) (Settings, uint32, error) {
+	block := dev.EraseBlockSize()
+
+	var head [header]byte
+	if _, err := dev.ReadAt(head[:], index*block); nil != err {
+		return Settings{}, 0, err
+	}
+	// off is the offset of the sequence number, following the version, if any.
+	off, version := len(magic), byte(1)
+	switch string(head[:len(magic)]) {
+	case magic:
+		version, off = head[off], off+1
+	case legacyMagic:
+	default:
+		return Settings{}, 0, nil
+	}
+	seq := binary.LittleEndian.Uint32(head[off:])
+	size := int64(binary.LittleEndian.Uint16(head[off+4:]))
+	if size > block-int64(off+10) {
+		return Settings{}, 0, errCorrupt
+	}
+	b := make([]byte, size)
+	if _, err := dev.ReadAt(b, index*block+int64(off+10)); nil != err {
+		return Settings{}, 0, err
+	}
+	sum := crc32.Update(crc32.ChecksumIEEE(head[len(magic):off+6]), crc32.IEEETable, b)
+	if sum != binary.LittleEndian.Uint32(head[off+6:]) {
+		return Settings{}, 0, errCorrupt
+	}
+	if version < 1 || version > Schema {
+		journal.Println("error: " + errSchema.Error() + ": " +
+			strconv.Itoa(int(version)))
+		return Settings{}, 0, errSchema
+	}
+	var s Settings
+	if nil != s.decodeLenient(bytes.NewReader(b), func(path string) {
+		journal.Println("settings: ignored invalid " + path)
+	}) {
+		return Settings{}, 0, errCorrupt
+	}
+	return s, seq, nil
+}
+
+// save writes the encoded Settings to the erase block at index, with the given
+// sequence number. The header is written after the Settings, so that the copy
+// is invalid until completely written.
+func save(dev Device, index int64, seq uint32, s Settings) error {
+	block := dev.EraseBlockSize()
+	b := make([]byte, header, 512)
+	b = s.Append(b, true)
+	size := len(b) - header
+	if int64(len(b)) > block || size > 0xFFFF {
+		return ErrStoreSize
+	}
+	off := len(magic) + 1 // of the sequence number
+	copy(b, magic)
+	b[len(magic)] = Schema
+	binary.LittleEndian.PutUint32(b[off:], seq)
+	binary.LittleEndian.PutUint16(b[off+4:], uint16(size))
+	sum := crc32.Update(crc32.ChecksumIEEE(b[len(magic):off+6]), crc32.IEEETable, b[header:])
+	binary.LittleEndian.PutUint32(b[off+6:], sum)
+	if err := dev.EraseBlocks(index, 1); nil != err {
+		return err
+	}
+	if _, err := dev.WriteAt(b[header:], index*block+int64(header)); nil != err {
+		return err
+	}
+	_, err := dev.WriteAt(b[:header], index*block)
+	return err
+}
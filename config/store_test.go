@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// memory is a Device of two erase blocks held in RAM.
+type memory [2 * 4096]byte
+
+func (m *memory) ReadAt(p []byte, off int64) (int, error)  { return copy(p, m[off:]), nil }
+func (m *memory) WriteAt(p []byte, off int64) (int, error) { return copy(m[off:], p), nil }
+func (m *memory) Size() int64                              { return int64(len(m)) }
+func (m *memory) EraseBlockSize() int64                    { return 4096 }
+
+func (m *memory) EraseBlocks(start, len int64) error {
+	for i := start * 4096; i < (start+len)*4096; i++ {
+		m[i] = 0xFF
+	}
+	return nil
+}
+
+// write stores a copy of the given encoded Settings to the erase block at index,
+// with the given header magic and, unless it is legacyMagic, schema version.
+func write(m *memory, index int64, mag string, version byte, seq uint32, body string) {
+	b := []byte(mag)
+	if legacyMagic != mag {
+		b = append(b, version)
+	}
+	off := len(b)
+	b = append(b, make([]byte, 10)...)
+	binary.LittleEndian.PutUint32(b[off:], seq)
+	binary.LittleEndian.PutUint16(b[off+4:], uint16(len(body)))
+	sum := crc32.Update(crc32.ChecksumIEEE(b[len(mag):off+6]), crc32.IEEETable, []byte(body))
+	binary.LittleEndian.PutUint32(b[off+6:], sum)
+	m.EraseBlocks(index, 1)
+	m.WriteAt(append(b, body...), index*4096)
+}
+
+func TestLoadSaved(t *testing.T) {
+	var m memory
+	if err := save(&m, 1, 7, Settings{Theme: "night"}); nil != err {
+		t.Fatalf("save() = %v", err)
+	}
+	s, seq, err := load(&m, 1)
+	if nil != err || 7 != seq || "night" != s.Theme {
+		t.Errorf("load() = %q, %d, %v, want %q, 7, nil", s.Theme, seq, err, "night")
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		magic   string
+		version byte
+		err     error
+	}{
+		{"legacy", legacyMagic, 0, nil},
+		{"current", magic, Schema, nil},
+		{"zero", magic, 0, errSchema},
+		{"newer", magic, Schema + 1, errSchema},
+	} {
+		var m memory
+		write(&m, 0, tc.magic, tc.version, 3, `{"theme":"night"}`)
+		s, seq, err := load(&m, 0)
+		if tc.err != err {
+			t.Errorf("%s: load() error = %v, want %v", tc.name, err, tc.err)
+			continue
+		}
+		if nil == err && (3 != seq || "night" != s.Theme) {
+			t.Errorf("%s: load() = %q, %d, want %q, 3", tc.name, s.Theme, seq, "night")
+		}
+	}
+}
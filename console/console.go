@@ -0,0 +1,104 @@
+// Package console implements an interactive shell on the serial port (USB CDC
+// on most boards), for inspecting and controlling the device without a network.
+//
+// Each line received is performed by package command, which is shared with the
+// HTTP API, so the same commands are available on both.
+package console
+
+import (
+	"machine"
+
+	"github.com/ardnew/weatherhub/command"
+)
+
+// MaxLine defines the length of the longest command line accepted.
+const MaxLine = 80
+
+// prompt is written before each command line is read.
+const prompt = "> "
+
+// Console is a Feed that reads command lines from the serial port.
+//
+// Bytes are read only as they arrive, so that Sync never blocks the run loop.
+type Console struct {
+	line  [MaxLine]byte
+	n     int  // length of line
+	over  bool // line exceeded MaxLine and is discarded
+	cr    bool // previous byte was a carriage return
+	shown bool // prompt has been written
+}
+
+// New returns a new Console using the serial port.
+func New() *Console {
+	return &Console{}
+}
+
+// Offline identifies the Console as a feed.Offline, which needs no network.
+func (c *Console) Offline() {}
+
+// Sync reads any bytes received by the serial port, echoing them, and performs
+// the command line once complete.
+func (c *Console) Sync() error {
+
+	if !c.shown {
+		machine.Serial.Write([]byte(prompt))
+		c.shown = true
+	}
+
+	for machine.Serial.Buffered() > 0 {
+		b, err := machine.Serial.ReadByte()
+		if nil != err {
+			return err
+		}
+		lf := '\n' == b && c.cr // second byte of CRLF, already handled
+		c.cr = '\r' == b
+		if lf {
+			continue
+		}
+		switch b {
+		case '\r', '\n':
+			machine.Serial.Write([]byte("\r\n"))
+			if c.over {
+				machine.Serial.Write([]byte("error: line too long\r\n"))
+			} else if err := command.Exec(writer{}, string(c.line[:c.n])); nil != err {
+				machine.Serial.Write([]byte("error: " + err.Error() + "\r\n"))
+			}
+			c.n, c.over = 0, false
+			machine.Serial.Write([]byte(prompt))
+		case '\b', 0x7F: // backspace, delete
+			if c.n > 0 {
+				c.n--
+				machine.Serial.Write([]byte("\b \b"))
+			}
+		default:
+			if b < ' ' {
+				continue // ignore other control characters
+			}
+			if c.n == len(c.line) {
+				c.over = true
+				continue
+			}
+			c.line[c.n] = b
+			c.n++
+			machine.Serial.WriteByte(b)
+		}
+	}
+	return nil
+}
+
+// writer writes command output to the serial port, translating each newline to
+// the carriage return and newline expected by terminals.
+type writer struct{}
+
+func (writer) Write(b []byte) (int, error) {
+	start := 0
+	for i, ch := range b {
+		if '\n' == ch {
+			machine.Serial.Write(b[start:i])
+			machine.Serial.Write([]byte("\r\n"))
+			start = i + 1
+		}
+	}
+	machine.Serial.Write(b[start:])
+	return len(b), nil
+}
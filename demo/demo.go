@@ -0,0 +1,268 @@
+// Package demo shows every page of the carousel, one after another, with
+// synthetic data, e.g., for a demonstration away from any known network, or to
+// verify a new panel before provisioning credentials.
+//
+// While active, the Status of the Model is StatusDemo, in which the run loop
+// neither connects to a network nor polls feeds that require one, and the
+// Model holds synthetic weather and data of every page. Every few pages, a
+// notice is shown with the next icon compiled in. The data replaced is restored
+// once demo mode is stopped, and the device connects again.
+package demo
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+)
+
+// Default constants of demo mode.
+const (
+	DefaultDwell  = 4 * time.Second // each page is shown
+	DefaultNotice = 3               // pages shown between notices
+)
+
+// noticeID identifies the notices posted by demo mode.
+const noticeID = "demo"
+
+// state holds the data replaced by synthetic data, and the progress through
+// the pages and icons.
+var state struct {
+	active bool
+	saved  saved
+	icons  []string
+	icon   int       // index of the next icon shown
+	pages  int       // pages shown since the previous notice
+	page   time.Time // when the current page was first shown
+	second time.Time // time of the Model, truncated to seconds
+}
+
+// Active returns true if demo mode is active.
+func Active() bool {
+	return state.active
+}
+
+// Start activates demo mode, showing each of the given icons in turn, e.g.,
+// those returned by display.Icons. It has no effect if already active.
+func Start(icons []string) {
+	if state.active {
+		return
+	}
+	now := time.Now()
+	state.active, state.icons, state.icon, state.pages = true, icons, 0, 0
+	state.page, state.second = now, now.Truncate(time.Second)
+	model.Set(func(m *model.Model) {
+		state.saved.save(m)
+		fill(m, now)
+		m.Status = model.StatusDemo
+	})
+}
+
+// Stop deactivates demo mode, restoring the data of every page as it was when
+// started, and disconnects, so that the run loop connects again. Everything else
+// changed meanwhile, e.g., the diagnostics, is kept. It has no effect if not
+// active.
+func Stop() {
+	if !state.active {
+		return
+	}
+	state.active = false
+	model.Set(func(m *model.Model) {
+		state.saved.restore(m)
+		m.Status = model.StatusDisconnected
+	})
+}
+
+// Toggle stops demo mode if active, and otherwise starts it with the given
+// icons.
+func Toggle(icons []string) {
+	if state.active {
+		Stop()
+	} else {
+		Start(icons)
+	}
+}
+
+// Step advances the time of the synthetic Model to now once per second, and
+// posts a notice with the next icon every DefaultNotice pages. Returns true if
+// the current page has been shown for DefaultDwell, and the carousel should
+// advance to the next page.
+func Step(now time.Time) bool {
+	if !state.active {
+		return false
+	}
+	if sec := now.Truncate(time.Second); !sec.Equal(state.second) {
+		state.second = sec
+		model.Set(func(m *model.Model) { m.Time = now })
+		notify.Update(now)
+	}
+	if now.Sub(state.page) < DefaultDwell {
+		return false
+	}
+	state.page = now
+	if state.pages++; state.pages >= DefaultNotice && 0 != len(state.icons) {
+		state.pages = 0
+		name := state.icons[state.icon%len(state.icons)]
+		state.icon++
+		notify.Post(model.Notice{
+			ID:       noticeID,
+			Text:     name,
+			Icon:     name,
+			Color:    color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+			Priority: model.PriorityNormal,
+			Dismiss:  model.DismissShown,
+			Duration: DefaultDwell / 2,
+			Posted:   now,
+		})
+	}
+	return true
+}
+
+// saved holds the fields of the Model replaced by fill.
+type saved struct {
+	location   model.Location
+	weather    model.Weather
+	accuracy   model.Accuracy
+	degreeDays model.DegreeMonth
+	garden     model.Garden
+	indoor     model.Indoor
+	remote     model.Remotes
+	snowDay    model.SnowDay
+	aurora     model.Aurora
+	health     model.Health
+	ski        model.Ski
+	surf       model.Surf
+	bands      model.Bands
+	schedule   model.Schedule
+	stopwatch  model.Stopwatch
+	transit    model.Transit
+	scores     model.Scores
+	news       model.News
+	qr         model.QR
+	notice     model.Notices
+}
+
+// save copies the fields of m replaced by fill into s.
+func (s *saved) save(m *model.Model) {
+	s.location, s.weather, s.accuracy = m.Location, m.Weather, m.Accuracy
+	s.degreeDays, s.garden, s.indoor = m.DegreeDays.Month, m.Garden, m.Indoor
+	s.remote, s.snowDay, s.aurora, s.health = m.Remote, m.SnowDay, m.Aurora, m.Health
+	s.ski, s.surf, s.bands, s.schedule = m.Ski, m.Surf, m.Bands, m.Schedule
+	s.stopwatch, s.transit, s.scores, s.news = m.Stopwatch, m.Transit, m.Scores, m.News
+	s.qr, s.notice = m.QR, m.Notice
+}
+
+// restore copies the fields saved in s back into m. The time of m is not
+// restored, since Step keeps it current.
+func (s *saved) restore(m *model.Model) {
+	m.Location, m.Weather, m.Accuracy = s.location, s.weather, s.accuracy
+	m.DegreeDays.Month, m.Garden, m.Indoor = s.degreeDays, s.garden, s.indoor
+	m.Remote, m.SnowDay, m.Aurora, m.Health = s.remote, s.snowDay, s.aurora, s.health
+	m.Ski, m.Surf, m.Bands, m.Schedule = s.ski, s.surf, s.bands, s.schedule
+	m.Stopwatch, m.Transit, m.Scores, m.News = s.stopwatch, s.transit, s.scores, s.news
+	m.QR, m.Notice = s.qr, s.notice
+}
+
+// fill replaces the data of every page of m with synthetic data as of now,
+// leaving its configuration and diagnostics.
+func fill(m *model.Model, now time.Time) {
+
+	m.Time = now
+	m.Location = model.Location{Latitude: 44.98, Longitude: -93.27,
+		City: "Demo", Zone: "America/Chicago"}
+
+	// a mild, partly cloudy day, with rain arriving this afternoon
+	m.Weather = model.Weather{Updated: now, Current: model.Conditions{
+		Temperature: 21.5, Humidity: 55, WindSpeed: 4.2, WindGust: 9.1,
+		Code: model.CodePartlyCloudy,
+	}}
+	hour := now.Truncate(time.Hour)
+	for i := range m.Weather.Hour {
+		code, chance, rain := model.CodePartlyCloudy, uint8(10), float32(0)
+		if i >= 4 && i < 8 {
+			code, chance, rain = model.CodeShowers, 70, 1.5
+		}
+		m.Weather.Hour[i] = model.Hour{
+			Time:        hour.Add(time.Duration(i) * time.Hour),
+			Temperature: 21.5 - float32(i%12)/2,
+			Humidity:    55,
+			Chance:      chance,
+			Rain:        rain,
+			WindGust:    9.1,
+			Code:        code,
+		}
+	}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	codes := [model.MaxDays]model.Code{model.CodeShowers, model.CodeClear, model.CodeSnow}
+	for i := range m.Weather.Day {
+		m.Weather.Day[i] = model.Day{
+			Date:   day.AddDate(0, 0, i),
+			High:   24 - float32(6*i),
+			Low:    12 - float32(7*i),
+			Chance: uint8(60 - 20*i),
+			Rain:   float32(4 - 2*i),
+			Code:   codes[i],
+		}
+	}
+	m.Weather.Nowcast.Start = now.Truncate(time.Minute)
+	for i := range m.Weather.Nowcast.Rain {
+		if i >= 20 && i < 50 {
+			m.Weather.Nowcast.Rain[i] = float32(i-20) / 10
+		}
+	}
+
+	yesterday := day.AddDate(0, 0, -1)
+	m.Accuracy = model.Accuracy{Date: yesterday, ForecastHigh: 23, ForecastLow: 11,
+		ObservedHigh: 24.2, ObservedLow: 10.4, HighBias: 0.8, LowBias: -0.3, Days: 14}
+	m.DegreeDays.Month = model.DegreeMonth{Start: day.AddDate(0, 0, 1-now.Day()),
+		Days: 12, Heating: 86, Cooling: 4, Growing: 120}
+	m.Garden = model.Garden{Known: true, Water: true, Deficit: 12.5}
+	m.Indoor = model.Indoor{Updated: now, Temperature: 22.1, Humidity: 41,
+		DewPoint: 8.4, Comfort: model.ComfortGood}
+	m.Remote = model.Remotes{
+		{ID: "1", Name: "Pool", Updated: now, Temperature: 26.3,
+			Humidity: model.NoHumidity, Battery: 3000, RSSI: -71},
+		{ID: "2", Name: "Shed", Updated: now, Temperature: 18.9,
+			Humidity: 62, Battery: 2400, LowBattery: true, RSSI: -95},
+	}
+	m.SnowDay = model.SnowDay{Chance: 65, Snow: 12, Low: -8,
+		Morning: day.AddDate(0, 0, 1).Add(8 * time.Hour)}
+	m.Aurora = model.Aurora{Updated: now, Kp: 5.3, Required: 5,
+		Visibility: model.VisibilityPossible, Show: true}
+	m.Health = model.Health{Updated: now, AQI: 42, Pollen: 18, HasPollen: true}
+	m.Ski = model.Ski{Active: true, Name: "Demo Peak", Depth: 85, Snowfall: 10,
+		Temperature: -6}
+	m.Surf = model.Surf{Active: true, Name: "Demo Beach", Height: 1.6, Period: 11,
+		Direction: 290}
+	m.Bands = model.Bands{Active: true, Updated: now, Flux: 142, A: 8, K: 2,
+		Band: [model.MaxBands]model.Band{
+			{Name: "80m-40m", Day: model.PropagationFair, Night: model.PropagationGood},
+			{Name: "30m-20m", Day: model.PropagationGood, Night: model.PropagationGood},
+			{Name: "17m-15m", Day: model.PropagationGood, Night: model.PropagationFair},
+			{Name: "12m-10m", Day: model.PropagationFair, Night: model.PropagationPoor},
+		}}
+	m.Schedule = model.Schedule{Active: true, Event: [model.MaxEvents]model.Event{
+		{Name: "Fajr", Time: day.Add(5*time.Hour + 12*time.Minute)},
+		{Name: "Sunrise", Time: day.Add(6*time.Hour + 48*time.Minute)},
+		{Name: "Dhuhr", Time: day.Add(13*time.Hour + 4*time.Minute)},
+		{Name: "Maghrib", Time: day.Add(19*time.Hour + 21*time.Minute)},
+		{Name: "Isha", Time: day.Add(20*time.Hour + 55*time.Minute)},
+	}}
+	m.Stopwatch = model.Stopwatch{Elapsed: 83*time.Second + 450*time.Millisecond,
+		Laps: 2, Split: [model.MaxLaps]time.Duration{41 * time.Second, 83 * time.Second}}
+	m.Transit = model.Transit{Active: true, Departure: [model.MaxDepartures]model.Departure{
+		{Route: "21", Time: now.Add(4 * time.Minute)},
+		{Route: "Blue", Time: now.Add(11 * time.Minute)},
+	}}
+	m.Scores = model.Scores{Game: [model.MaxGames]model.Game{
+		{Home: "MIN", Away: "CHI", HomeScore: "3", AwayScore: "1", Status: "Final"},
+		{Home: "DAL", Away: "DEN", HomeScore: "88", AwayScore: "91", Status: "Q4"},
+	}}
+	m.News = model.News{Active: true, Headline: [model.MaxHeadlines]string{
+		"Demo mode shows every page with synthetic data",
+		"Send \"demo off\" or hold the up button to exit",
+	}}
+	m.QR = model.QR{}
+	m.Notice = model.Notices{}
+}
@@ -0,0 +1,51 @@
+package display
+
+import (
+	"time"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// accuracyPage shows how far yesterday's forecast high and low were off from
+// those observed, and the bias of the forecast over the past several days.
+type accuracyPage struct{}
+
+func (p *accuracyPage) Active(data model.Model) bool {
+	acc := data.Accuracy
+	return acc.Valid() && data.Time.Sub(acc.Date) < 48*time.Hour
+}
+
+func (p *accuracyPage) Draw(d *Display, data model.Model, clear bool) {
+	acc := data.Accuracy
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, "Forecast off",
+			d.theme.Title)
+	}
+	d.valueRow(2, "High", d.appendDelta(d.text(), acc.ForecastHigh, acc.ObservedHigh, 0), clear)
+	d.valueRow(3, "Low", d.appendDelta(d.text(), acc.ForecastLow, acc.ObservedLow, 0), clear)
+	if acc.Days > 1 {
+		label := "Bias"
+		if acc.Corrected {
+			label = "Fixed" // bias is subtracted from the forecast
+		}
+		bias := d.appendDelta(d.text(), acc.HighBias, 0, 1)
+		bias = append(bias, '/')
+		bias = d.appendDelta(bias, acc.LowBias, 0, 1)
+		d.valueRow(4, label, bias, clear)
+	}
+}
+
+// appendDelta appends the signed difference between temperatures a and b, in
+// degrees Celsius, to text, converted to the units shown with prec digits
+// following the decimal point.
+func (d *Display) appendDelta(text []byte, a, b float32, prec int) []byte {
+	ta, sym := d.units.Temp(a)
+	tb, _ := d.units.Temp(b)
+	delta := ta - tb
+	if delta >= 0 {
+		text = append(text, '+')
+	}
+	return append(appendFixed(text, delta, prec), sym[:len(sym)-1]...)
+}
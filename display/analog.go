@@ -0,0 +1,96 @@
+package display
+
+import (
+	"image/color"
+	"math"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// dialRadius defines the radius of the dial of the analog clock page, which
+// spans the height of the display.
+const dialRadius = 15
+
+// Lengths of the hands of the analog clock, in pixels from the center of the
+// dial, which must be shorter than the radius of the hour marks.
+const (
+	hourHand   = 8
+	minuteHand = 12
+	secondHand = 12
+	markRadius = dialRadius - 2
+)
+
+// hand is a line drawn from the center of the dial to its tip.
+type hand struct {
+	x, y int16
+}
+
+// analogPage shows the time of day on a dial with hour, minute, and second
+// hands, with the weekday and date to its right.
+type analogPage struct {
+	hand [3]hand // tips of the hands drawn, hour, minute, and second
+	day  int     // day of year drawn, or 0 if not drawn
+}
+
+func (p *analogPage) Active(data model.Model) bool { return true }
+
+func (p *analogPage) Draw(d *Display, data model.Model, clear bool) {
+
+	cx, cy := int16(dialRadius+1), int16(dialRadius+1)
+
+	if clear {
+		p.hand, p.day = [3]hand{}, 0
+		d.drawCircle(cx, cy, dialRadius, d.theme.Text)
+	}
+
+	t := data.Time
+	second := float64(t.Second()) / 60
+	minute := (float64(t.Minute()) + second) / 60
+	hour := (float64(t.Hour()%12) + minute) / 12
+
+	var next [3]hand
+	next[0].x, next[0].y = tip(cx, cy, hourHand, hour)
+	next[1].x, next[1].y = tip(cx, cy, minuteHand, minute)
+	next[2].x, next[2].y = tip(cx, cy, secondHand, second)
+
+	// erase every hand before drawing any, since they may overlap, and restore
+	// any hour mark a rounded pixel of a hand may have covered.
+	black := color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+	for _, h := range p.hand {
+		if h != (hand{}) {
+			d.drawLine(cx, cy, h.x, h.y, black)
+		}
+	}
+	for h := 0; h < 12; h++ {
+		x, y := tip(cx, cy, markRadius, float64(h)/12)
+		d.plot(x, y, d.theme.Text)
+	}
+	d.drawLine(cx, cy, next[0].x, next[0].y, d.theme.Time)
+	d.drawLine(cx, cy, next[1].x, next[1].y, d.theme.Time)
+	d.drawLine(cx, cy, next[2].x, next[2].y, d.theme.Value)
+	p.hand = next
+
+	if p.day != t.YearDay() {
+		p.day = t.YearDay()
+		x := int16(2*dialRadius + 4)
+		width, _ := d.hub.Size()
+		d.fillRect(x, 0, width-x, 4*rowHeight, black)
+		tinyfont.WriteLine(d.hub, &font, x, 2+2*rowHeight,
+			t.Weekday().String()[:3], d.theme.Weekday)
+		locale := d.locale
+		locale.Number = model.NumberHidden // no room beside the dial
+		drawText(d.hub, x, 2+3*rowHeight, appendDate(d.text(), t, locale, true), d.theme.Date)
+	}
+}
+
+// tip returns the position of the tip of a hand of the given length, pointing
+// the given fraction of a turn clockwise from 12 o'clock on a dial centered at
+// cx, cy.
+func tip(cx, cy, length int16, turn float64) (int16, int16) {
+	a := 2 * math.Pi * turn
+	x := float64(length) * math.Sin(a)
+	y := -float64(length) * math.Cos(a)
+	return cx + int16(math.Round(x)), cy + int16(math.Round(y))
+}
@@ -0,0 +1,66 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// visibilityColors maps each aurora Visibility to the color of its indicator.
+var visibilityColors = [...]color.RGBA{
+	model.VisibilityNone:     {R: 0x80, G: 0x80, B: 0x80, A: 0xFF},
+	model.VisibilityLow:      {R: 0x20, G: 0x80, B: 0x40, A: 0xFF},
+	model.VisibilityPossible: {R: 0x40, G: 0xFF, B: 0x80, A: 0xFF},
+	model.VisibilityLikely:   {R: 0xFF, G: 0x40, B: 0xC0, A: 0xFF},
+}
+
+// auroraPage shows the planetary K-index as a gauge, marked with the K-index
+// at which the aurora reaches the device Location, and the resulting chance of
+// seeing it.
+type auroraPage struct{}
+
+func (p *auroraPage) Active(data model.Model) bool { return data.Aurora.Show }
+
+// Urgency preempts the carousel while the aurora is likely to be seen.
+func (p *auroraPage) Urgency(data model.Model) Urgency {
+	if model.VisibilityLikely == data.Aurora.Visibility {
+		return UrgencyElevated
+	}
+	return UrgencyNone
+}
+
+func (p *auroraPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+	aur := data.Aurora
+
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, "Aurora",
+			d.theme.Title)
+	}
+
+	kp := appendFixed(append(d.text(), "Kp "...), aur.Kp, 1)
+	d.fillRect(width/2, 2, width/2, rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	drawText(d.hub, width-textWidth(kp), 2+rowHeight, kp, d.theme.Value)
+
+	// gauge of Kp 0-9, marked at the required K-index
+	var (
+		gx, gy, gw, gh int16 = 0, 2 + rowHeight + 1, width, rowHeight - 2
+		fill                 = int16(float32(gw) * aur.Kp / 9)
+		mark                 = int16(float32(gw) * aur.Required / 9)
+		c                    = visibilityColors[aur.Visibility]
+	)
+	d.fillRect(gx, gy, gw, gh, color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xFF})
+	d.fillRect(gx, gy, fill, gh, c)
+	if mark < gw {
+		d.fillRect(gx+mark, gy-1, 1, gh+2, d.theme.Text)
+	}
+
+	d.fillRect(0, 2+2*rowHeight, width, 2*rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	tinyfont.WriteLine(d.hub, &font, 0, 2+3*rowHeight,
+		aur.Visibility.String(), c)
+	drawText(d.hub, 0, 2+4*rowHeight,
+		appendFixed(append(d.text(), "Needs Kp "...), aur.Required, 1), d.theme.Text)
+}
@@ -0,0 +1,55 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// propagationColors maps each band Propagation to the color of its condition.
+var propagationColors = [...]color.RGBA{
+	model.PropagationUnknown: {R: 0x80, G: 0x80, B: 0x80, A: 0xFF},
+	model.PropagationPoor:    {R: 0xFF, G: 0x30, B: 0x30, A: 0xFF},
+	model.PropagationFair:    {R: 0xFF, G: 0xC0, B: 0x20, A: 0xFF},
+	model.PropagationGood:    {R: 0x40, G: 0xFF, B: 0x40, A: 0xFF},
+}
+
+// bandsPage shows the solar flux and geomagnetic indices, and a table of the
+// propagation on each group of HF bands by day (left) and by night (right).
+type bandsPage struct{}
+
+func (p *bandsPage) Active(data model.Model) bool { return data.Bands.Active }
+
+func (p *bandsPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+	bands := data.Bands
+
+	idx := append(d.text(), "SFI"...)
+	idx = appendInt(append(appendInt(append(appendInt(idx, bands.Flux),
+		" A"...), bands.A), " K"...), bands.K)
+	d.valueRow(1, "HF", idx, clear)
+
+	for i, band := range bands.Band {
+		ty := 2 + int16(i+2)*rowHeight
+		d.fillRect(0, ty-rowHeight, width, rowHeight,
+			color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+		if "" == band.Name {
+			continue
+		}
+		// the band names are shortened to fit, e.g., "80m-40m" as "80-40".
+		name := d.text()
+		for j := 0; j < len(band.Name); j++ {
+			if 'm' != band.Name[j] {
+				name = append(name, band.Name[j])
+			}
+		}
+		drawText(d.hub, 0, ty, name, d.theme.Text)
+		tinyfont.WriteLine(d.hub, &font, width-36, ty,
+			band.Day.String(), propagationColors[band.Day])
+		tinyfont.WriteLine(d.hub, &font, width-16, ty,
+			band.Night.String(), propagationColors[band.Night])
+	}
+}
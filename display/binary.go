@@ -0,0 +1,65 @@
+package display
+
+import (
+	"image/color"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Geometry of the dots of the binary clock page, in pixels.
+const (
+	dotSize  = 3
+	dotPitch = 6 // distance between the rows and columns of dots
+	pairGap  = 4 // additional space between hours, minutes, and seconds
+)
+
+// binaryBits defines the number of bits of each decimal digit of the time of
+// day "15:04:05" shown by the binary clock page, which are the fewest needed by
+// the greatest value of each digit.
+var binaryBits = [6]uint{2, 4, 3, 4, 3, 4}
+
+// binaryDark defines the color of unset bits, so that the shape of the clock
+// remains visible.
+var binaryDark = color.RGBA{R: 0x10, G: 0x18, B: 0x10, A: 0xFF}
+
+// binaryPage shows the time of day as binary-coded decimal, with a column of
+// dots for each digit of the hours, minutes, and seconds, and the least
+// significant bit at the bottom.
+type binaryPage struct {
+	digit [6]int // digits drawn, or -1 if not drawn
+}
+
+func (p *binaryPage) Active(data model.Model) bool { return true }
+
+func (p *binaryPage) Draw(d *Display, data model.Model, clear bool) {
+
+	if clear {
+		p.digit = [6]int{-1, -1, -1, -1, -1, -1}
+	}
+
+	width, height := d.hub.Size()
+	span := int16(5*dotPitch + 2*pairGap + dotSize)
+	x0 := (width - span) / 2
+	y0 := (height + 3*dotPitch - dotSize) / 2 // top of the least significant bit
+
+	t := data.Time
+	digit := [6]int{
+		t.Hour() / 10, t.Hour() % 10,
+		t.Minute() / 10, t.Minute() % 10,
+		t.Second() / 10, t.Second() % 10,
+	}
+	for i, v := range digit {
+		if v == p.digit[i] {
+			continue
+		}
+		p.digit[i] = v
+		x := x0 + int16(i)*dotPitch + int16(i/2)*pairGap
+		for b := uint(0); b < binaryBits[i]; b++ {
+			c := binaryDark
+			if 0 != v&(1<<b) {
+				c = d.theme.Time
+			}
+			d.fillRect(x, y0-int16(b)*dotPitch, dotSize, dotSize, c)
+		}
+	}
+}
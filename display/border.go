@@ -0,0 +1,109 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Constants defining the weather shown by the forecast border.
+const (
+	borderHours  = 12 // hours of forecast around the border, like a clock dial
+	borderChance = 20 // least probability of precipitation shown, percent
+	borderWarm   = 27 // least temperature shown as heat, degrees Celsius
+	borderHot    = 32 // least temperature shown as intense heat, degrees Celsius
+)
+
+// border colors the ring of pixels surrounding the active area of the panel by
+// the forecast of the next 12 hours, for weather at a glance without reading
+// any text. Like the dial of a clock, the ring begins at the top center with
+// the current hour and proceeds clockwise, each twelfth colored by the weather
+// of one hour: white for snow, blue for rain (brighter if more probable), and
+// orange or red for heat. Hours of fair weather are dark.
+//
+// The active area is reduced by one pixel on each side to make room for the
+// ring (see Config.Border).
+type border struct {
+	ring  Area                    // outermost pixels, in panel coordinates
+	shown bool                    // colors are drawn
+	color [borderHours]color.RGBA // of each hour drawn
+}
+
+// inset returns the Area inside of the ring a, reduced by one pixel on each
+// side, and false if a is too small.
+func (a Area) inset() (Area, bool) {
+	if a.Width < 3 || a.Height < 3 {
+		return a, false
+	}
+	return Area{X: a.X + 1, Y: a.Y + 1, Width: a.Width - 2, Height: a.Height - 2}, true
+}
+
+// draw draws the ring, if enabled, unless the colors of each hour are unchanged
+// since last drawn.
+func (b *border) draw(d *Display, data model.Model) {
+	if 0 == b.ring.Width {
+		return // disabled
+	}
+	var c [borderHours]color.RGBA
+	if data.Weather.Valid() {
+		now := data.Time.Truncate(time.Hour)
+		for i, j := 0, 0; i < model.MaxHours && j < borderHours; i++ {
+			h := data.Weather.Hour[i]
+			if h.Time.IsZero() || h.Time.Before(now) {
+				continue
+			}
+			c[j], j = hourColor(h), j+1
+		}
+	}
+	if b.shown && c == b.color {
+		return
+	}
+	b.shown, b.color = true, c
+
+	w, h := int(b.ring.Width), int(b.ring.Height)
+	n := 2*(w-1) + 2*(h-1) // pixels of the ring
+	for i := 0; i < n; i++ {
+		x, y := b.point((i+w/2)%n, w, h) // beginning at the top center
+		d.hub.setPanelPixel(b.ring.X+x, b.ring.Y+y, c[i*borderHours/n])
+	}
+}
+
+// point returns the position of the kth pixel of a ring of the given width and
+// height, clockwise from its top-left corner.
+func (b *border) point(k, w, h int) (int16, int16) {
+	switch {
+	case k < w-1:
+		return int16(k), 0
+	case k < (w-1)+(h-1):
+		return int16(w - 1), int16(k - (w - 1))
+	case k < 2*(w-1)+(h-1):
+		return int16(w - 1 - (k - (w - 1) - (h - 1))), int16(h - 1)
+	}
+	return 0, int16(h - 1 - (k - 2*(w-1) - (h - 1)))
+}
+
+// hourColor returns the color of the forecast weather of the given hour, or
+// black if fair.
+func hourColor(h model.Hour) color.RGBA {
+	switch {
+	case h.Snow > 0 || (h.Code.Snowing() && h.Chance >= borderChance):
+		return color.RGBA{R: 0xC0, G: 0xC0, B: 0xC0, A: 0xFF}
+	case h.Chance >= borderChance:
+		// brighter blue as precipitation becomes more probable.
+		b := 0x40 + uint16(h.Chance)*0xBF/100
+		return color.RGBA{R: 0x00, G: uint8(b / 4), B: uint8(b), A: 0xFF}
+	case h.Temperature >= borderHot:
+		return color.RGBA{R: 0xFF, G: 0x20, B: 0x00, A: 0xFF}
+	case h.Temperature >= borderWarm:
+		return color.RGBA{R: 0xFF, G: 0x80, B: 0x00, A: 0xFF}
+	}
+	return color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+}
+
+// clearPage erases the entire panel, including the border, which is redrawn
+// once the page is drawn.
+func (d *Display) clearPage() {
+	d.hub.ClearDisplay()
+	d.border.shown = false
+}
@@ -0,0 +1,173 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// nowcastWidth defines the length of the precipitation nowcast strip, in pixels
+// (one per minute).
+const nowcastWidth = 60
+
+// clockPage shows the current time of day, weekday, and date, and optionally the
+// day of the year (see Config.DayOfYear) and the date of an alternative calendar
+// (see Config.Calendar). If precipitation is expected within
+// the hour, a strip across the top shows its intensity each minute, beginning
+// now at the left.
+type clockPage struct {
+	now    timeStamp
+	tim    [8]byte   // time of day, formatted as "15:04:05"
+	date   [16]byte  // date, formatted by appendDate
+	strip  time.Time // minute the nowcast strip was drawn, zero if not shown
+	health model.SyncHealth
+}
+
+type timeStamp time.Time
+
+func (p *clockPage) Active(data model.Model) bool { return true }
+
+func (p *clockPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, height := d.hub.Size()
+
+	if clear {
+		p.now = timeStamp{} // forget what was drawn, redraw everything
+		p.strip = time.Time{}
+		d.decorate()
+		if data.AP.Unsecured() {
+			d.drawUnsecured()
+		}
+	}
+	p.drawNowcast(d, data)
+
+	p.drawTime(d, data.Time)
+
+	// the weekday and date are lifted a row to show the date of the alternative
+	// calendar beneath them, if any.
+	var lift int16
+	if nil != d.cal {
+		lift = rowHeight
+	}
+
+	_, dow, day := p.now.set(data.Time)
+	if "" != dow {
+		var (
+			tx, ty         int16 = 0, height - 1*rowHeight - 2 - lift
+			px, py, pw, ph int16 = 0, height - 2*rowHeight - 2 - lift, 64, rowHeight
+		)
+		d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+		tinyfont.WriteLine(d.hub, &font, tx, ty, dow, d.theme.Weekday)
+		if d.config.DayOfYear {
+			// right-aligned, e.g., "D032", beside even the longest weekday.
+			n := data.Time.YearDay()
+			yday := appendTwo(append(d.text(), 'D', byte('0'+n/100)), n%100)
+			drawText(d.hub, width-textWidth(yday), ty, yday, d.theme.Date)
+		}
+	}
+	if day {
+		var (
+			tx, ty         int16 = 0, height - 0*rowHeight - 2 - lift
+			px, py, pw, ph int16 = 0, height - 1*rowHeight - 2 - lift, 64, rowHeight
+		)
+		d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+		// the month is abbreviated to leave room for the week number, if shown.
+		date := appendDate(p.date[:0], data.Time, d.locale, d.locale.ShowWeek())
+		drawText(d.hub, tx, ty, date, d.theme.Date)
+		if nil != d.cal {
+			d.fillRect(px, py+lift, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+			alt := appendCalendarDate(p.date[:0], d.cal.Date(data.Time), d.locale)
+			drawText(d.hub, tx, ty+lift, alt, d.theme.Date)
+		}
+	}
+	// the glyph shares the row of the date, which erases it when redrawn.
+	if h := data.Diagnostics.TimeSync.Health(data.Time); day || h != p.health {
+		p.health = h
+		d.drawSyncHealth(h)
+	}
+}
+
+// drawTime draws the time of day, which changes on every update.
+func (p *clockPage) drawTime(d *Display, t time.Time) {
+	width, _ := d.hub.Size()
+	var (
+		timeWidth      int16 = 4*6 + 3*2
+		tx, ty         int16 = width - timeWidth, 2 + rowHeight
+		px, py, pw, ph int16 = width - timeWidth, 2, timeWidth, rowHeight
+	)
+	d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	drawText(d.hub, tx, ty, appendClock(p.tim[:0], t, true), d.theme.Time)
+}
+
+// drawNowcast draws the nowcast strip, once per minute, if precipitation is
+// expected within the hour. Otherwise, a previously drawn strip is erased.
+func (p *clockPage) drawNowcast(d *Display, data model.Model) {
+
+	minute := data.Time.Truncate(time.Minute)
+	if minute.Equal(p.strip) {
+		return
+	}
+	width, _ := d.hub.Size()
+	x := (width - nowcastWidth) / 2
+
+	wet := false
+	for m := 0; m < nowcastWidth && !wet; m++ {
+		v, _ := data.Weather.Nowcast.At(minute.Add(time.Duration(m) * time.Minute))
+		wet = v >= rainTrace
+	}
+	if !wet {
+		if !p.strip.IsZero() {
+			d.fillRect(x, 0, nowcastWidth, 2, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+			p.strip = time.Time{}
+		}
+		return
+	}
+
+	p.strip = minute
+	for m := int16(0); m < nowcastWidth; m++ {
+		v, ok := data.Weather.Nowcast.At(minute.Add(time.Duration(m) * time.Minute))
+		c := color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+		if ok {
+			c = rainColor(v)
+		}
+		d.fillRect(x+m, 0, 1, 2, c)
+	}
+}
+
+// rainTrace defines the least precipitation intensity, in millimeters per hour,
+// considered rain.
+const rainTrace = 0.1
+
+// rainColor returns the color of the given precipitation intensity, in
+// millimeters per hour, similar to weather radar palettes.
+func rainColor(v float32) color.RGBA {
+	switch {
+	case v < rainTrace:
+		return color.RGBA{R: 0x10, G: 0x10, B: 0x18, A: 0xFF} // dry
+	case v < 1:
+		return color.RGBA{R: 0x40, G: 0x80, B: 0xFF, A: 0xFF} // light
+	case v < 4:
+		return color.RGBA{R: 0x00, G: 0xC0, B: 0x40, A: 0xFF} // moderate
+	case v < 10:
+		return color.RGBA{R: 0xFF, G: 0xD0, B: 0x00, A: 0xFF} // heavy
+	case v < 30:
+		return color.RGBA{R: 0xFF, G: 0x40, B: 0x00, A: 0xFF} // very heavy
+	}
+	return color.RGBA{R: 0xFF, G: 0x00, B: 0xC0, A: 0xFF} // extreme
+}
+
+// set saves time t, returning the weekday of t if it differs from the time
+// previously saved, as a constant string, and whether the date differs.
+func (s *timeStamp) set(t time.Time) (new bool, dow string, day bool) {
+	p := time.Time(*s)
+	new = p.IsZero()
+	if new || p.Weekday() != t.Weekday() {
+		dow = t.Weekday().String()
+	}
+	day = new || p.YearDay() != t.YearDay()
+	*s = timeStamp(t) // update the saved timestamp
+	return
+}
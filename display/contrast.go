@@ -0,0 +1,95 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+)
+
+// Contrast selects how text is kept legible when drawn over graphics, e.g., the
+// imagery of a clock face beneath its text.
+type Contrast uint8
+
+// Constants defining each Contrast.
+const (
+	ContrastOutline Contrast = iota // text of too little contrast is outlined in black or white
+	ContrastSwap                    // text of too little contrast is drawn in black or white instead
+	ContrastOff                     // text is always drawn in its own color
+)
+
+// Constants defining when text is considered illegible.
+const (
+	minContrast = 0x60 // least difference in luminance between text and background
+	minLit      = 0x18 // least luminance of a background of graphics, not a dark page
+)
+
+// luminance returns the relative luminance of c, 0-255, weighted as in ITU-R
+// BT.709.
+func luminance(c color.RGBA) int {
+	return (54*int(c.R) + 183*int(c.G) + 19*int(c.B)) >> 8
+}
+
+// drawLegible draws text with its baseline at y, as tinyfont.WriteLine, in
+// color c unless it would be illegible against the background surrounding it,
+// in which case it is outlined in, or drawn in, the contrasting black or white
+// as configured (see Config.Contrast). Text on a dark background is always
+// drawn in color c, as chosen by the theme.
+func (d *Display) drawLegible(x, y int16, text string, c color.RGBA) {
+	if "" == text {
+		return // tinyfont.LineWidth panics on empty text
+	}
+	edge, style := edgeColor(c), StylePlain
+	if ContrastOff != d.config.Contrast {
+		_, w := tinyfont.LineWidth(&font, text)
+		bg := d.surrounding(x-1, y-rowHeight, int16(w)+2, rowHeight+2)
+		c, edge, style = d.legible(c, bg, style)
+	}
+	writeStyled(d.hub, x, y, text, c, edge, style)
+}
+
+// legible returns the color, edge color, and style of text in color c drawn in
+// the given style over a background of mean luminance bg, which are changed as
+// configured (see Config.Contrast) if the text would be illegible.
+func (d *Display) legible(c color.RGBA, bg int, style TextStyle) (color.RGBA, color.RGBA, TextStyle) {
+	if ContrastOff == d.config.Contrast {
+		return c, edgeColor(c), style
+	}
+	diff := luminance(c) - bg
+	if diff < 0 {
+		diff = -diff
+	}
+	if bg < minLit || diff >= minContrast {
+		return c, edgeColor(c), style
+	}
+	edge := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	if bg >= 0x80 {
+		edge = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+	}
+	if ContrastSwap == d.config.Contrast {
+		return edge, edgeColor(edge), style
+	}
+	return c, edge, StyleOutline
+}
+
+// surrounding returns the mean luminance of the pixels along the edges of the
+// given rectangle, which surround the text drawn within it, so that any text
+// previously drawn in the same place does not count as its background.
+func (d *Display) surrounding(x, y, w, h int16) int {
+	sum, n := 0, 0
+	add := func(px, py int16) {
+		sum += luminance(d.hub.getPixel(px, py))
+		n++
+	}
+	for i := int16(0); i < w; i++ {
+		add(x+i, y)
+		add(x+i, y+h-1)
+	}
+	for j := int16(1); j < h-1; j++ {
+		add(x, y+j)
+		add(x+w-1, y+j)
+	}
+	if 0 == n {
+		return 0
+	}
+	return sum / n
+}
@@ -0,0 +1,81 @@
+package display
+
+import (
+	"image/color"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/theme"
+)
+
+// spriteSize defines the width and height of each decoration sprite, in pixels.
+const spriteSize = 5
+
+// sprite is a monochrome bitmap. Each element is a row of pixels, with the
+// most significant of the low spriteSize bits leftmost.
+type sprite [spriteSize]uint8
+
+// sprites maps each Decoration to its sprite.
+var sprites = map[theme.Decoration]*sprite{
+	theme.DecorationHearts:   {0b01010, 0b11111, 0b11111, 0b01110, 0b00100},
+	theme.DecorationSnow:     {0b10101, 0b01110, 0b11111, 0b01110, 0b10101},
+	theme.DecorationStars:    {0b00100, 0b01110, 0b11111, 0b01110, 0b01010},
+	theme.DecorationPumpkins: {0b00100, 0b01110, 0b11111, 0b11111, 0b01110},
+}
+
+// unsecured is the sprite of an open padlock, shown on the clock page while the
+// access point joined is unsecured (see network.AP.Unsecured).
+var unsecured = sprite{0b00011, 0b00010, 0b11111, 0b11011, 0b11111}
+
+// drawUnsecured draws the unsecured sprite in the empty space at the top of the
+// clock page, to the right of any decoration.
+func (d *Display) drawUnsecured() {
+	d.drawSprite(&unsecured, 4*(spriteSize+2), 2, color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF})
+}
+
+// syncGlyph is the sprite of a clock face, shown at the bottom-right corner of
+// the clock page in the color of the health of the time synchronization.
+var syncGlyph = sprite{0b01110, 0b10101, 0b10111, 0b10001, 0b01110}
+
+// syncColor maps each SyncHealth to the color of its glyph.
+var syncColor = [...]color.RGBA{
+	model.SyncUnknown: {R: 0x40, G: 0x40, B: 0x40, A: 0xFF},
+	model.SyncGood:    {R: 0x00, G: 0xC0, B: 0x00, A: 0xFF},
+	model.SyncFair:    {R: 0xFF, G: 0xC0, B: 0x00, A: 0xFF},
+	model.SyncPoor:    {R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+}
+
+// drawSyncHealth draws the glyph of the given SyncHealth in the bottom-right
+// corner, to the right of the date.
+func (d *Display) drawSyncHealth(h model.SyncHealth) {
+	width, height := d.hub.Size()
+	x, y := width-spriteSize-1, height-spriteSize-2
+	d.fillRect(x, y, spriteSize, spriteSize, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	d.drawSprite(&syncGlyph, x, y, syncColor[h])
+}
+
+// decorate draws the current theme's decoration in the empty space at the
+// top-left corner of the clock page, alternating between two theme colors.
+func (d *Display) decorate() {
+	spr, ok := sprites[d.theme.Decoration]
+	if !ok {
+		return
+	}
+	for i := int16(0); i < 4; i++ {
+		c := d.theme.Title
+		if 0 != i%2 {
+			c = d.theme.Value
+		}
+		d.drawSprite(spr, 1+i*(spriteSize+2), 2, c)
+	}
+}
+
+// drawSprite draws the given sprite with its top-left corner at x, y.
+func (d *Display) drawSprite(spr *sprite, x, y int16, c color.RGBA) {
+	for row, bits := range spr {
+		for col := int16(0); col < spriteSize; col++ {
+			if 0 != bits&(1<<(spriteSize-1-col)) {
+				d.hub.SetPixel(x+col, y+int16(row), c)
+			}
+		}
+	}
+}
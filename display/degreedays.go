@@ -0,0 +1,33 @@
+package display
+
+import (
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// degreeDaysPage shows the heating, cooling, and growing degree days
+// accumulated this month.
+type degreeDaysPage struct{}
+
+func (p *degreeDaysPage) Active(data model.Model) bool {
+	return data.DegreeDays.Month.Valid()
+}
+
+func (p *degreeDaysPage) Draw(d *Display, data model.Model, clear bool) {
+	month := data.DegreeDays.Month
+	if clear {
+		title := month.Start.Month().String()[:3] + " degree days"
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, title, d.theme.Title)
+	}
+	d.valueRow(2, "Heat", d.appendDegreeDays(d.text(), month.Heating), clear)
+	d.valueRow(3, "Cool", d.appendDegreeDays(d.text(), month.Cooling), clear)
+	d.valueRow(4, "Grow", d.appendDegreeDays(d.text(), month.Growing), clear)
+}
+
+// appendDegreeDays appends the given degree days, in °C-days, to text,
+// converted to the units shown.
+func (d *Display) appendDegreeDays(text []byte, dd float32) []byte {
+	v, sym := d.units.TempDelta(dd)
+	return append(appendInt(text, int(v+0.5)), sym...)
+}
@@ -0,0 +1,56 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// diagnosticsPage shows information about the operation of the device itself.
+// It is only included in the carousel if enabled by the display Config.
+type diagnosticsPage struct{}
+
+func (p *diagnosticsPage) Active(data model.Model) bool { return true }
+
+func (p *diagnosticsPage) Draw(d *Display, data model.Model, clear bool) {
+	width, _ := d.hub.Size()
+	// timing preset of the panel, adjusted at runtime via the API, shown at the
+	// right of the title row.
+	timing := append(d.text(), data.Diagnostics.Timing...)
+	tw := textWidth(timing)
+	if clear {
+		drawText(d.hub, width-tw, 2+rowHeight, timing, d.theme.Value)
+	}
+	// uptime and number of boots, if known, e.g., "Up 3d04h #12", at the left of
+	// the title row, so that a device restarting silently is obvious.
+	up := appendUptime(append(d.text(), "Up "...), data.Diagnostics.Uptime())
+	if 0 != data.Diagnostics.Boots {
+		up = appendInt(append(up, " #"...), int(data.Diagnostics.Boots))
+	}
+	d.fillRect(0, 2, width-tw-1, rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, ellipsis(string(up), width-tw-2),
+		d.theme.Title)
+	// usage of each API quota, as a percentage of the daily limit
+	for i, q := range data.Diagnostics.Quota {
+		if "" == q.Host || q.Limit <= 0 {
+			continue
+		}
+		d.valueRow(int16(2+i), ellipsis(q.Host, 40), append(appendInt(d.text(), 100*q.Used/q.Limit), '%'), clear)
+	}
+	// round-trip time to the gateway and internet, "-" if no reply.
+	ping := appendRTT(d.text(), data.Diagnostics.Gateway)
+	ping = appendRTT(append(ping, '/'), data.Diagnostics.Internet)
+	d.valueRow(5, "Ping", append(ping, "ms"...), clear)
+}
+
+// appendRTT appends the round-trip time of the given ping to b, in
+// milliseconds, or "-" if no reply was received.
+func appendRTT(b []byte, p model.Ping) []byte {
+	if !p.OK {
+		return append(b, '-')
+	}
+	return appendInt(b, int(p.RTT/time.Millisecond))
+}
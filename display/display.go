@@ -5,12 +5,16 @@ import (
 	"image/color"
 	"machine"
 	"strconv"
+	"strings"
 	"time"
 
 	"tinygo.org/x/drivers/rgb75"
 	"tinygo.org/x/tinyfont"
 
+	"github.com/ardnew/weatherhub/asset"
+	"github.com/ardnew/weatherhub/calendar"
 	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/theme"
 )
 
 // Default constants for Display configuration.
@@ -22,18 +26,65 @@ const (
 	DefaultColorDepth = 4  // bits
 )
 
+// Config defines the configuration of the HUB75 device driver and the
+// appearance of the pages drawn on it.
+type Config struct {
+	rgb75.Config
+	Area        Area // active area of the panel drawn on, the entire panel if zero
+	Theme       theme.Config
+	Units       model.Units              // units of quantities shown, with per-quantity overrides
+	Locale      model.Locale             // conventions of dates shown
+	DayOfYear   bool                     // show the ordinal day of the year on the clock page
+	Calendar    string                   // name of a registered calendar.Calendar shown beneath the date
+	Brightness  int                      // percent, 1-100
+	Timing      Timing                   // preset overriding ColorDepth and DoubleBuffer
+	Dither      bool                     // smooth gradients with ordered dithering
+	Border      bool                     // color the ring of pixels around the active area by the forecast
+	Contrast    Contrast                 // keeping text legible over graphics beneath it
+	TextStyle   TextStyle                // of the news ticker scrolled across the bottom row
+	ScrollRate  time.Duration            // time per pixel scrolled by marquees, e.g., the news ticker
+	Diagnostics bool                     // include the diagnostics page in the carousel
+	Faces       []Face                   // clock pages in the carousel, each a page of its own
+	Plugins     []string                 // names of registered ClockFaces, following Faces
+	Clocks      []model.WorldClock       // time zones of the world clock page
+	Dwell       map[string]time.Duration // of named pages in the carousel, overriding DefaultDwell
+	FrameRate   int                      // animation frames per second
+	Budget      time.Duration            // of each animation per frame (see frames)
+	Scenes      []Scene                  // switchable bundles of settings, DefaultScenes if empty
+}
+
 // Display wraps the HUB75 device driver.
 type Display struct {
-	hub *rgb75.Device
-	now *timeStamp
+	hub    *panel
+	config Config // configuration of the panel, before applying timing
+	timing Timing // preset in effect
+	step   uint16 // difference between color levels dithered (see setPixel)
+	page   *carousel
+	world  *worldPage              // world clock page, whose time zones may be changed
+	border border                  // forecast colored around the active area (see Config.Border)
+	ticker marquee                 // news headlines scrolling across the bottom row
+	news   bool                    // ticker is shown
+	test   testPattern             // shown in place of every page, for testing the panel
+	notice overlay                 // notice shown in place of the current page
+	frames frames                  // timing of animations
+	fault  time.Time               // when the panel last failed to configure, zero if none
+	sleep  sleep                   // hours the panel is off (see SetSleep)
+	images map[string]*asset.Image // supplied at runtime, by name (see SetImages)
+	splash animation               // shown while connecting, stopped once synchronized
+	themes *theme.Selector
+	theme  *theme.Theme      // theme in effect on the current date
+	scene  string            // name of the scene most recently applied (see SetScene)
+	shown  model.News        // news headlines shown by the ticker
+	units  model.Units       // units of quantities shown by pages
+	locale model.Locale      // conventions of dates shown by pages
+	cal    calendar.Calendar // alternative calendar of dates shown, nil if none
+	buf    [32]byte          // text formatted by pages (see text)
 }
 
-type timeStamp time.Time
-
 // New returns a new Display initialized with given configuration.
 // This method will always return a nil Display or a nil error. It will never
 // return nil or non-nil for both Display and error.
-func New(config rgb75.Config) (*Display, error) {
+func New(config Config) (*Display, error) {
 
 	// initialize the HUB75 device driver
 	hub := rgb75.New(
@@ -46,6 +97,12 @@ func New(config rgb75.Config) (*Display, error) {
 			machine.HUB75_ADDR_A, machine.HUB75_ADDR_B, machine.HUB75_ADDR_C,
 			machine.HUB75_ADDR_D, machine.HUB75_ADDR_E,
 		})
+	return newDisplay(hub, config)
+}
+
+// newDisplay returns a new Display drawing on the given device driver, which is
+// configured with the given configuration.
+func newDisplay(hub driver, config Config) (*Display, error) {
 
 	// configure the display
 	if 0 == config.Width {
@@ -57,17 +114,121 @@ func New(config rgb75.Config) (*Display, error) {
 	if 0 == config.ColorDepth {
 		config.ColorDepth = DefaultColorDepth
 	}
-	if err := hub.Configure(config); nil != err {
+	if config.Brightness <= 0 || config.Brightness > 100 {
+		config.Brightness = DefaultBrightness
+	}
+	if config.ScrollRate <= 0 {
+		config.ScrollRate = DefaultScrollRate
+	}
+	if 0 == len(config.Scenes) {
+		config.Scenes = DefaultScenes
+	}
+	if err := ValidClocks(config.Clocks); nil != err {
+		return nil, err
+	}
+	area, err := config.Area.active(config.Width, config.Height)
+	if nil != err {
+		return nil, err
+	}
+	var ring Area
+	if config.Border {
+		var ok bool
+		ring = area
+		if area, ok = ring.inset(); !ok {
+			return nil, ErrArea
+		}
+	}
+	scan := config.Timing.apply(config)
+	if err := hub.Configure(scan.Config); nil != err {
 		return nil, err
 	}
+	model.Mod(func(m *model.Model) {
+		m.Diagnostics.Timing = config.Timing.String()
+	})
 
 	// initialize and begin updating screen
 	hub.ClearDisplay()
 	hub.Resume()
 
-	return &Display{hub: hub, now: &timeStamp{}}, nil
+	if 0 == len(config.Faces) {
+		config.Faces = DefaultFaces
+	}
+
+	// the QR code is shown in place of any other page, including a running
+	// stopwatch, so it must be the first page held.
+	page := []namedPage{{"qr", &qrPage{}}}
+	for _, f := range config.Faces {
+		page = append(page, namedPage{f.String(), f.page()})
+	}
+	for _, name := range config.Plugins {
+		newFace, ok := faces[name]
+		if !ok {
+			return nil, ErrUnknownFace
+		}
+		page = append(page, namedPage{name, &facePage{face: newFace()}})
+	}
+	var cal calendar.Calendar
+	if "" != config.Calendar {
+		var ok bool
+		if cal, ok = calendar.Lookup(config.Calendar); !ok {
+			return nil, ErrUnknownCalendar
+		}
+	}
+	world := &worldPage{clocks: config.Clocks}
+	page = append(page, namedPage{"world", world})
+	page = append(page, namedPage{"stopwatch", &stopwatchPage{}})
+	page = append(page, []namedPage{
+		{"transit", &transitPage{}},
+		{"scores", &scoresPage{}},
+		{"garden", &gardenPage{}},
+		{"indoor", &indoorPage{}},
+		{"remote", &remotePage{}},
+		{"snowday", &snowdayPage{}},
+		{"moon", &moonPage{}},
+		{"golden", &goldenPage{}},
+		{"aurora", &auroraPage{}},
+		{"health", &healthPage{}},
+		{"accuracy", &accuracyPage{}},
+		{"degreedays", &degreeDaysPage{}},
+		{"ski", &skiPage{}},
+		{"surf", &surfPage{}},
+		{"bands", &bandsPage{}},
+		{"schedule", &schedulePage{}},
+		{"wash", &washPage{}},
+	}...)
+	if config.Diagnostics {
+		page = append(page, namedPage{"diagnostics", &diagnosticsPage{}})
+	}
+
+	c := newCarousel(DefaultDwell, page...)
+	for name, dwell := range config.Dwell {
+		k := 0
+		for k < len(c.name) && name != c.name[k] {
+			k++
+		}
+		if k == len(c.name) {
+			return nil, ErrUnknownPage
+		}
+		c.dwells[k] = dwell
+	}
+
+	return &Display{
+		hub:    &panel{driver: hub, area: area, level: uint16(config.Brightness)},
+		config: config,
+		timing: config.Timing,
+		step:   ditherStep(config.Dither, scan.ColorDepth),
+		units:  config.Units,
+		locale: config.Locale,
+		cal:    cal,
+		themes: theme.New(config.Theme),
+		page:   c,
+		world:  world,
+		border: border{ring: ring},
+		frames: newFrames(config.FrameRate, config.Budget),
+	}, nil
 }
 
+// Update redraws the display using the given Model data.
 func (d *Display) Update(data model.Model) {
 	// Update is only called if the Model data has changed. When the model data
 	// changes, we redraw the entire display so that we don't leave stale pixels
@@ -75,66 +236,125 @@ func (d *Display) Update(data model.Model) {
 	// This could be improved to only redraw the regions that need updating, but
 	// the redrawing occurs quite quickly with this much-simpler technique.
 
+	if PatternNone != d.test.pattern {
+		// the pattern is drawn again if the panel was cleared, e.g., on changing
+		// its brightness.
+		if d.page.stale {
+			d.page.stale, d.test.step = false, -1
+			d.drawPattern(data.Time)
+		}
+		return
+	}
+
 	width, height := d.hub.Size()
 
+	d.splash.stop() // unless shown again by the status screen
+
 	switch data.Status {
 	case model.StatusIdle, model.StatusDisconnected:
 		d.hub.ClearDisplay()
-		tinyfont.WriteLine(d.hub, &tinyfont.TomThumb, 0, height-2, "Disconnected",
+		d.drawSplash()
+		tinyfont.WriteLine(d.hub, &font, 0, height-2, "Disconnected",
 			color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF})
 
 	case model.StatusConnecting:
 		d.hub.ClearDisplay()
-		tinyfont.WriteLine(d.hub, &tinyfont.TomThumb, 0, height-2, "Connecting...",
+		d.drawSplash()
+		tinyfont.WriteLine(d.hub, &font, 0, height-2, "Connecting...",
 			color.RGBA{R: 0x00, G: 0x00, B: 0xFF, A: 0xFF})
 
+	case model.StatusCaptive:
+		d.hub.ClearDisplay()
+		tinyfont.WriteLine(d.hub, &font, 0, height-8, data.AP.SSID,
+			color.RGBA{R: 0xFF, G: 0xA5, B: 0x00, A: 0xFF})
+		tinyfont.WriteLine(d.hub, &font, 0, height-2, "requires sign-in",
+			color.RGBA{R: 0xFF, G: 0xA5, B: 0x00, A: 0xFF})
+
 	case model.StatusUnsynchronized:
 		d.hub.ClearDisplay()
+		d.drawSplash()
 		str := "Synchronizing"
 		if data.Retry > 0 {
 			str += "(" + strconv.FormatUint(uint64(data.Retry), 10) + ")"
 		}
 		str += "..."
-		tinyfont.WriteLine(d.hub, &tinyfont.TomThumb, 0, height-2, str,
+		tinyfont.WriteLine(d.hub, &font, 0, height-2, str,
 			color.RGBA{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF})
 
-	case model.StatusSynchronized:
-
-		const rowHeight = 6
-
-		new, dow, doy, tim := d.now.set(data.Time)
-		if new {
-			d.hub.ClearDisplay()
+	case model.StatusSynchronized, model.StatusDemo:
+		d.doze(data.Time)
+		if i := data.Notice.Top(data.Time); i >= 0 {
+			d.notice.draw(d, data.Notice[i])
+			return
 		}
-
-		if "" != tim {
-			var (
-				timeWidth      int16 = 4*6 + 3*2
-				tx, ty         int16 = width - timeWidth, 2 + rowHeight
-				px, py, pw, ph int16 = width - timeWidth, 2, timeWidth, rowHeight
-			)
-			d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
-			tinyfont.WriteLine(d.hub, &tinyfont.TomThumb, tx, ty, tim,
-				color.RGBA{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF})
+		if d.notice.shown {
+			// the notice covered the entire page, so it must be redrawn.
+			d.notice.shown = false
+			d.page.redraw()
+		}
+		if th := d.themes.For(data.Time); th != d.theme {
+			// colors have changed, redraw the entire page using the new theme.
+			d.theme = th
+			d.page.redraw()
 		}
-		if "" != dow {
-			var (
-				tx, ty         int16 = 0, height - 1*rowHeight - 2
-				px, py, pw, ph int16 = 0, height - 2*rowHeight - 2, 64, rowHeight
-			)
-			d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
-			tinyfont.WriteLine(d.hub, &tinyfont.TomThumb, tx, ty, dow,
-				color.RGBA{R: 0x00, G: 0xFF, B: 0xFF, A: 0xFF})
+		if data.News.Active != d.news {
+			// the ticker covers the bottom row of the page, so the page must be
+			// redrawn entirely when the ticker is shown or hidden.
+			d.news = data.News.Active
+			d.page.redraw()
 		}
-		if "" != doy {
-			var (
-				tx, ty         int16 = 0, height - 0*rowHeight - 2
-				px, py, pw, ph int16 = 0, height - 1*rowHeight - 2, 64, rowHeight
-			)
-			d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
-			tinyfont.WriteLine(d.hub, &tinyfont.TomThumb, tx, ty, doy,
-				color.RGBA{R: 0x00, G: 0x00, B: 0xFF, A: 0xFF})
+		if page, clear := d.page.next(data); nil != page {
+			if clear {
+				d.clearPage()
+			}
+			page.Draw(d, data, clear)
 		}
+		d.border.draw(d, data)
+		if d.news {
+			if data.News != d.shown {
+				// joining the headlines allocates, so only join them when changed.
+				d.shown = data.News
+				d.ticker.set(headlines(data.News), d.theme.Ticker)
+			}
+			d.ticker.x, d.ticker.y, d.ticker.w = 0, height-2, width
+			d.ticker.color, d.ticker.style = d.theme.Ticker, d.config.TextStyle
+			d.ticker.tint = tintSeparators(d.ticker.tint[:0], d.ticker.text,
+				d.theme.Ticker, d.theme.Value)
+			d.ticker.draw(d)
+		}
+	}
+
+	if model.StatusSynchronized != data.Status && model.StatusDemo != data.Status {
+		// the status screens overwrite everything, so restart the carousel to
+		// redraw the entire page once synchronized.
+		d.page.reset()
+	}
+}
+
+// Animate advances any animated content of the current page. It should be
+// called frequently, independent of changes to the Model data, and draws a
+// frame only as often as the configured frame rate.
+func (d *Display) Animate(now time.Time) {
+	if d.retry(now) || !d.frames.due(now) {
+		return
+	}
+	if PatternNone != d.test.pattern {
+		d.frames.run(animatePage, now, func() { d.drawPattern(now) })
+		return
+	}
+	if nil != d.splash.image {
+		d.frames.run(animateSplash, now, func() { d.splash.step(d, now) })
+		return
+	}
+	if d.notice.shown {
+		d.frames.run(animateNotice, now, func() { d.notice.animate(d, now) })
+		return
+	}
+	if a, ok := d.page.current().(Animator); ok {
+		d.frames.run(animatePage, now, func() { a.Animate(d, now) })
+	}
+	if d.news && nil != d.page.current() {
+		d.frames.run(animateTicker, now, func() { d.ticker.step(d, now) })
 	}
 }
 
@@ -173,22 +393,41 @@ func (d *Display) fillRect(x, y, w, h int16, c color.RGBA) {
 	if ok, x, y, w, h = d.clipRect(x, y, w, h); ok {
 		for row := y; row < y+h; row++ {
 			for col := x; col < x+w; col++ {
-				d.hub.SetPixel(col, row, c)
+				d.setPixel(col, row, c)
 			}
 		}
 	}
 }
 
-func (s *timeStamp) set(t time.Time) (new bool, dow, doy, tim string) {
-	p := time.Time(*s)
-	new = p.IsZero()
-	if new || p.Weekday() != t.Weekday() {
-		dow = t.Weekday().String()
+// headlines returns all of the given news headlines joined into a single line.
+func headlines(news model.News) string {
+	line := ""
+	for _, h := range news.Headline {
+		if "" == h {
+			break
+		}
+		if "" != line {
+			line += headlineSeparator
+		}
+		line += h
 	}
-	if new || p.YearDay() != t.YearDay() {
-		doy = t.Format("January 2")
+	return line
+}
+
+// headlineSeparator is the text between each headline joined by headlines.
+const headlineSeparator = "  +  "
+
+// tintSeparators appends to t the tints of the headlines joined in line, in
+// color text, and of the separators between them, in color sep.
+func tintSeparators(t []tint, line string, text, sep color.RGBA) []tint {
+	t = append(t, tint{at: 0, color: text})
+	for i := 0; ; {
+		k := strings.Index(line[i:], headlineSeparator)
+		if k < 0 {
+			return t
+		}
+		i += k
+		t = append(t, tint{at: i, color: sep}, tint{at: i + len(headlineSeparator), color: text})
+		i += len(headlineSeparator)
 	}
-	tim = t.Format("15:04:05")
-	*s = timeStamp(t) // update the saved timestamp
-	return
 }
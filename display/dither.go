@@ -0,0 +1,46 @@
+package display
+
+import "image/color"
+
+// bayer is the threshold matrix of 4×4 ordered dithering, in sixteenths of the
+// difference between adjacent levels of color depth.
+var bayer = [4][4]uint16{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// ditherStep returns the difference between adjacent levels of each 8-bit color
+// channel at the given color depth, or 0 if dithering is disabled.
+func ditherStep(enable bool, depth uint16) uint16 {
+	if !enable || depth >= 8 {
+		return 0
+	}
+	return 256 >> depth
+}
+
+// setPixel sets the pixel at x, y to color c. If dithering is enabled, the
+// channels of c are offset by the threshold of the pixel's position in the
+// Bayer matrix before the driver truncates them to the color depth, so that
+// areas of a color between two levels are drawn as a fine pattern of both
+// levels instead of a band of the lower level.
+func (d *Display) setPixel(x, y int16, c color.RGBA) {
+	if 0 != d.step {
+		t := bayer[y&3][x&3] * d.step / 16
+		c.R, c.G, c.B = ditherChannel(c.R, t), ditherChannel(c.G, t), ditherChannel(c.B, t)
+	}
+	d.hub.SetPixel(x, y, c)
+}
+
+// ditherChannel returns the color channel v offset by threshold t, saturated
+// at the maximum intensity.
+func ditherChannel(v uint8, t uint16) uint8 {
+	if 0 == v {
+		return 0 // keep black, so that erased regions remain dark
+	}
+	if u := uint16(v) + t; u < 0xFF {
+		return uint8(u)
+	}
+	return 0xFF
+}
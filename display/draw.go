@@ -0,0 +1,65 @@
+package display
+
+import "image/color"
+
+// drawLine draws a line from x0, y0 to x1, y1, inclusive, with Bresenham's
+// algorithm. Pixels beyond the bounds of the display are not drawn.
+func (d *Display) drawLine(x0, y0, x1, y1 int16, c color.RGBA) {
+	dx, sx := x1-x0, int16(1)
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	dy, sy := y1-y0, int16(1)
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+	err := dx - dy
+	for {
+		d.plot(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x0 += sx
+		}
+		if e2 < dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawCircle draws the outline of a circle of radius r centered at cx, cy, with
+// the midpoint circle algorithm. Pixels beyond the bounds of the display are not
+// drawn.
+func (d *Display) drawCircle(cx, cy, r int16, c color.RGBA) {
+	x, y, err := r, int16(0), 1-r
+	for x >= y {
+		d.plot(cx+x, cy+y, c)
+		d.plot(cx+y, cy+x, c)
+		d.plot(cx-y, cy+x, c)
+		d.plot(cx-x, cy+y, c)
+		d.plot(cx-x, cy-y, c)
+		d.plot(cx-y, cy-x, c)
+		d.plot(cx+y, cy-x, c)
+		d.plot(cx+x, cy-y, c)
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+}
+
+// plot sets the pixel at x, y to color c, if it is within the bounds of the
+// display.
+func (d *Display) plot(x, y int16, c color.RGBA) {
+	width, height := d.hub.Size()
+	if x >= 0 && y >= 0 && x < width && y < height {
+		d.setPixel(x, y, c)
+	}
+}
@@ -0,0 +1,54 @@
+package display
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// dualPage shows the time and date in UTC above the local time and date, each
+// labeled with its zone, e.g., for logging radio contacts or coordinating with
+// others across time zones. The UTC date is formatted as in ISO 8601, and the
+// local date as configured (see Config.Locale).
+type dualPage struct {
+	second time.Time // time drawn
+	abbr   string    // abbreviation of the local time zone drawn
+}
+
+func (p *dualPage) Active(data model.Model) bool { return true }
+
+func (p *dualPage) Draw(d *Display, data model.Model, clear bool) {
+	second := data.Time.Truncate(time.Second)
+	if !clear && second.Equal(p.second) {
+		return
+	}
+	p.second = second
+
+	abbr, _ := data.Time.Zone()
+	if "" == abbr {
+		abbr = "Local"
+	}
+	if abbr != p.abbr {
+		// the labels are only drawn when clear, e.g., on changing to or from
+		// daylight saving time.
+		if !clear {
+			d.clearPage()
+			clear = true
+		}
+		p.abbr = abbr
+	}
+
+	utc := data.Time.UTC()
+	d.valueRow(1, "UTC", appendClock(d.text(), utc, true), clear)
+	d.valueRow(2, "", appendISODate(d.text(), utc), clear)
+	d.valueRow(3, abbr, appendClock(d.text(), data.Time, true), clear)
+	d.valueRow(4, "", appendDate(d.text(), data.Time, d.locale, true), clear)
+}
+
+// appendISODate appends the date of t to b, formatted as "2006-01-02".
+func appendISODate(b []byte, t time.Time) []byte {
+	y, m, day := t.Date()
+	b = append(appendTwo(appendTwo(b, y/100), y%100), '-')
+	b = append(appendTwo(b, int(m)), '-')
+	return appendTwo(b, day)
+}
@@ -0,0 +1,46 @@
+package display
+
+// Face is a style of clock page.
+type Face uint8
+
+// Constants defining each possible Face.
+const (
+	FaceDigital Face = iota // time, weekday, and date in text
+	FaceAnalog              // hands on a dial
+	FaceBinary              // binary-coded decimal digits as columns of dots
+	FaceWords               // grid of letters spelling the time in words
+	FaceDual                // UTC and local time, each with its date
+)
+
+// DefaultFaces defines the clock pages shown, unless configured otherwise.
+var DefaultFaces = []Face{FaceDigital}
+
+// String returns the name of the page showing the Face.
+func (f Face) String() string {
+	switch f {
+	case FaceAnalog:
+		return "analog"
+	case FaceBinary:
+		return "binary"
+	case FaceWords:
+		return "words"
+	case FaceDual:
+		return "dual"
+	}
+	return "clock"
+}
+
+// page returns a new Page drawing the Face.
+func (f Face) page() Page {
+	switch f {
+	case FaceAnalog:
+		return &analogPage{}
+	case FaceBinary:
+		return &binaryPage{}
+	case FaceWords:
+		return &wordsPage{}
+	case FaceDual:
+		return &dualPage{}
+	}
+	return &clockPage{}
+}
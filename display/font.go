@@ -0,0 +1,125 @@
+package display
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"tinygo.org/x/tinyfont"
+)
+
+// font is the font of all text drawn on the display: TomThumb, extended with a
+// degree sign, an ellipsis, the Latin-1 supplement, and a few common
+// typographic marks.
+//
+// Glyphs of 3×5 pixels leave no room for diacritics, so accented letters are
+// drawn as the letters they are based on, e.g., "é" as "e". Runes the font does
+// not contain are drawn by drawText as the placeholder, a hollow box. Text drawn
+// by tinyfont, e.g., with tinyfont.WriteLine, shows them as blanks instead, since
+// tinyfont substitutes an empty glyph, though with the advance of the first glyph
+// of the font, the placeholder, so that text is measured alike either way.
+var font tinyfont.Font
+
+// placeholder is the glyph drawn by drawText in place of any rune not contained
+// in font.
+var placeholder = tinyfont.Glyph{
+	Rune: 0, Width: 3, Height: 5, XAdvance: 4, XOffset: 0, YOffset: -5,
+	Bitmaps: []byte{0xF6, 0xDE}, // 111 101 101 101 111
+}
+
+// degree is the glyph of the degree sign, U+00B0.
+var degree = tinyfont.Glyph{
+	Rune: '°', Width: 3, Height: 3, XAdvance: 4, XOffset: 0, YOffset: -5,
+	Bitmaps: []byte{0x55, 0x00}, // 010 101 010
+}
+
+// ellipsisGlyph is the glyph of the horizontal ellipsis, U+2026, appended to
+// text shortened by ellipsis.
+var ellipsisGlyph = tinyfont.Glyph{
+	Rune: ellipsisRune, Width: 5, Height: 1, XAdvance: 6, XOffset: 0, YOffset: -1,
+	Bitmaps: []byte{0xA8}, // 10101
+}
+
+// latin1 maps each rune of the Latin-1 supplement, beginning with U+00A0, to
+// the ASCII rune drawn in its place, or to 0 if it has no equivalent.
+const latin1 = "" +
+	" !cL\x00Y|\x00\"\x00a<\x00-\x00-" + // U+00A0
+	"\x00\x0023'u\x00.,1o>\x00\x00\x00?" + // U+00B0, the degree sign has its own glyph
+	"AAAAAAACEEEEIIII" + // U+00C0
+	"DNOOOOOxOUUUUYPs" + // U+00D0
+	"aaaaaaaceeeeiiii" + // U+00E0
+	"dnooooo/ouuuuypy" // U+00F0
+
+// marks maps common typographic marks, found in forecast text and headlines,
+// to the ASCII rune drawn in their place.
+var marks = map[rune]rune{
+	'‐': '-', '‑': '-', '‒': '-', '–': '-', '—': '-', '−': '-',
+	'‘': '\'', '’': '\'', '‚': ',', '“': '"', '”': '"', '„': '"',
+	'•': '*', '′': '\'', '″': '"',
+}
+
+func init() {
+	font = tinyfont.TomThumb
+	glyphs := make([]tinyfont.Glyph, 0, len(font.Glyphs)+len(latin1)+len(marks)+1)
+	glyphs = append(glyphs, placeholder)
+	glyphs = append(glyphs, tinyfont.TomThumb.Glyphs...)
+	glyphs = append(glyphs, degree, ellipsisGlyph)
+	extend := func(r, base rune) {
+		if g, ok := lookup(tinyfont.TomThumb.Glyphs, base); ok {
+			g.Rune = r
+			glyphs = append(glyphs, g)
+		}
+	}
+	for i := 0; i < len(latin1); i++ {
+		if 0 != latin1[i] {
+			extend(rune(0xA0+i), rune(latin1[i]))
+		}
+	}
+	for r, base := range marks {
+		extend(r, base)
+	}
+	// TomThumb may already contain some of the extended runes, so keep only the
+	// first glyph of each rune, preferring those of TomThumb.
+	sort.Stable(byRune(glyphs))
+	n := 0
+	for i, g := range glyphs {
+		if 0 == i || g.Rune != glyphs[n-1].Rune {
+			glyphs[n] = g
+			n++
+		}
+	}
+	font.Glyphs = glyphs[:n]
+}
+
+// byRune sorts glyphs by rune.
+type byRune []tinyfont.Glyph
+
+func (g byRune) Len() int           { return len(g) }
+func (g byRune) Less(i, j int) bool { return g[i].Rune < g[j].Rune }
+func (g byRune) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+
+// lookup returns the glyph of rune r in the given glyphs, sorted by rune.
+func lookup(glyphs []tinyfont.Glyph, r rune) (tinyfont.Glyph, bool) {
+	i := sort.Search(len(glyphs), func(i int) bool { return glyphs[i].Rune >= r })
+	if i < len(glyphs) && r == glyphs[i].Rune {
+		return glyphs[i], true
+	}
+	return tinyfont.Glyph{}, false
+}
+
+// glyph returns the glyph of rune r in font, or the placeholder if missing.
+func glyph(r rune) tinyfont.Glyph {
+	if g, ok := lookup(font.Glyphs, r); ok {
+		return g
+	}
+	return placeholder
+}
+
+// decode returns the first rune of UTF-8 encoded text and its length in bytes.
+// Invalid encodings are decoded one byte at a time as the placeholder.
+func decode(text []byte) (rune, int) {
+	r, n := utf8.DecodeRune(text)
+	if utf8.RuneError == r {
+		return 0, 1
+	}
+	return r, n
+}
@@ -0,0 +1,132 @@
+package display
+
+import (
+	"image/color"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/calendar"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// The content of most pages is redrawn every time the Model data changes, which
+// is at least once per second. To avoid garbage collection pauses, text that
+// varies is formatted into the reusable buffer of the Display (see text) using
+// the append functions below and the strconv Append functions, and then drawn
+// with drawText. None of these allocate.
+
+// text returns the empty text buffer of the Display, to which text may be
+// appended. The buffer is shared by all pages, so it is only valid until the
+// next call to text.
+func (d *Display) text() []byte {
+	return d.buf[:0]
+}
+
+// drawText is equivalent to tinyfont.WriteLine using the default font, but with
+// UTF-8 encoded text given as a byte slice.
+func drawText(disp drivers.Displayer, x, y int16, text []byte, c color.RGBA) {
+	for i := 0; i < len(text); {
+		r, n := decode(text[i:])
+		g := glyph(r)
+		tinyfont.DrawChar(disp, &font, x, y, g.Rune, c)
+		x += int16(g.XAdvance)
+		i += n
+	}
+}
+
+// textWidth returns the width of text drawn with drawText, equivalent to the
+// outer width returned by tinyfont.LineWidth.
+func textWidth(text []byte) int16 {
+	w := int16(0)
+	for i := 0; i < len(text); {
+		r, n := decode(text[i:])
+		w += int16(glyph(r).XAdvance)
+		i += n
+	}
+	return w
+}
+
+// appendInt appends the decimal form of i to b.
+func appendInt(b []byte, i int) []byte {
+	return strconv.AppendInt(b, int64(i), 10)
+}
+
+// appendFixed appends the decimal form of f to b, with prec digits following
+// the decimal point.
+func appendFixed(b []byte, f float32, prec int) []byte {
+	return strconv.AppendFloat(b, float64(f), 'f', prec, 32)
+}
+
+// appendTwo appends i to b as exactly two decimal digits, e.g., "07".
+func appendTwo(b []byte, i int) []byte {
+	return append(b, byte('0'+i/10%10), byte('0'+i%10))
+}
+
+// appendClock appends the time of day of t to b, formatted as "15:04", or as
+// "15:04:05" if seconds is true.
+func appendClock(b []byte, t time.Time, seconds bool) []byte {
+	h, m, s := t.Clock()
+	b = append(appendTwo(b, h), ':')
+	b = appendTwo(b, m)
+	if seconds {
+		b = appendTwo(append(b, ':'), s)
+	}
+	return b
+}
+
+// appendDate appends the date of t to b in the order of the given Locale, e.g.,
+// "January 2" or "2 January", with the month abbreviated if short is true, and
+// followed by the week number if the Locale shows it, e.g., "Jan 2 W01".
+func appendDate(b []byte, t time.Time, locale model.Locale, short bool) []byte {
+	month := t.Month().String()
+	if short {
+		month = month[:3]
+	}
+	if locale.DayFirst() {
+		b = append(appendInt(b, t.Day()), ' ')
+		b = append(b, month...)
+	} else {
+		b = append(append(b, month...), ' ')
+		b = appendInt(b, t.Day())
+	}
+	if locale.ShowWeek() {
+		b = appendTwo(append(b, " W"...), locale.Week(t))
+	}
+	return b
+}
+
+// appendDuration appends d formatted as hours and minutes, e.g., "2h05m", or
+// minutes only if less than an hour, e.g., "12m".
+func appendDuration(b []byte, d time.Duration) []byte {
+	m := int(d.Round(time.Minute) / time.Minute)
+	if m < 60 {
+		return append(appendInt(b, m), 'm')
+	}
+	b = append(appendInt(b, m/60), 'h')
+	return append(appendTwo(b, m%60), 'm')
+}
+
+// appendUptime appends d formatted as days and hours, e.g., "3d04h", or as for
+// appendDuration if less than a day.
+func appendUptime(b []byte, d time.Duration) []byte {
+	h := int(d / time.Hour)
+	if h < 24 {
+		return appendDuration(b, d)
+	}
+	b = append(appendInt(b, h/24), 'd')
+	return append(appendTwo(b, h%24), 'h')
+}
+
+// appendCalendarDate appends the date of an alternative calendar to b in the
+// order of the given Locale, e.g., "Ram 2 1447" or "2 Ram 1447".
+func appendCalendarDate(b []byte, date calendar.Date, locale model.Locale) []byte {
+	if locale.DayFirst() {
+		b = append(append(appendInt(b, date.Day), ' '), date.Month...)
+	} else {
+		b = appendInt(append(append(b, date.Month...), ' '), date.Day)
+	}
+	return appendInt(append(b, ' '), date.Year)
+}
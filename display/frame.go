@@ -0,0 +1,72 @@
+package display
+
+import "time"
+
+// Default constants for frame timing.
+const (
+	DefaultFrameRate = 30                   // frames per second
+	DefaultBudget    = 4 * time.Millisecond // of each animation per frame
+)
+
+// Animations drawn in each frame, each with its own budget.
+const (
+	animateSplash = iota // splash image shown while connecting
+	animateNotice        // flashing, scrolling, and icon of the notice overlay
+	animatePage          // animated content of the current page
+	animateTicker        // news headlines scrolling across the bottom row
+	animations
+)
+
+// frames is the central timing authority of every animation, e.g., marquees
+// and flashing notices. Animations are only advanced once per frame, at the
+// configured frame rate, rather than on every iteration of the run loop.
+//
+// Each animation is allowed a budget of time per frame. An animation exceeding
+// its budget is suspended for a number of frames in proportion to its overrun,
+// so that no animation, however expensive, may consume more than its share of
+// the run loop, which must also synchronize the time and poll feeds.
+type frames struct {
+	period time.Duration // between frames
+	budget time.Duration // of each animation per frame
+	next   time.Time     // when the next frame is due
+	resume [animations]time.Time
+}
+
+// newFrames returns frames at the given rate, in frames per second, allowing
+// each animation the given budget.
+func newFrames(rate int, budget time.Duration) frames {
+	if rate <= 0 {
+		rate = DefaultFrameRate
+	}
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+	return frames{period: time.Second / time.Duration(rate), budget: budget}
+}
+
+// due returns true if a frame is due at time now, in which case the following
+// frame is scheduled. Frames missed, e.g., while a feed was polled, are skipped
+// rather than drawn in quick succession.
+func (f *frames) due(now time.Time) bool {
+	if now.Before(f.next) {
+		return false
+	}
+	f.next = f.next.Add(f.period)
+	if f.next.Before(now) {
+		f.next = now.Add(f.period)
+	}
+	return true
+}
+
+// run calls animate for the given animation, unless it is suspended, and then
+// suspends it if it exceeded its budget.
+func (f *frames) run(a int, now time.Time, animate func()) {
+	if now.Before(f.resume[a]) {
+		return
+	}
+	start := time.Now()
+	animate()
+	if over := time.Since(start) - f.budget; over > 0 {
+		f.resume[a] = now.Add(f.period * (over/f.budget + 1))
+	}
+}
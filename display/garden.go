@@ -0,0 +1,42 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// gardenPage reminds to water the garden when watering is recommended.
+type gardenPage struct{}
+
+func (p *gardenPage) Active(data model.Model) bool { return data.Garden.Water }
+
+func (p *gardenPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, "Garden",
+			d.theme.Title)
+		tinyfont.WriteLine(d.hub, &font, 0, 2+3*rowHeight,
+			"Water today", d.theme.Text)
+		tinyfont.WriteLine(d.hub, &font, 0, 2+4*rowHeight,
+			"Dry by", d.theme.Text)
+	}
+
+	v, sym := d.units.Rain(data.Garden.Deficit)
+	prec := 0
+	if "mm" != sym {
+		prec = 1 // inches
+	}
+	deficit := append(append(appendFixed(d.text(), v, prec), ' '), sym...)
+	var (
+		valueWidth     int16 = 6 * 4
+		px, py, pw, ph int16 = width - valueWidth, 2 + 3*rowHeight, valueWidth, rowHeight
+	)
+	d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	drawText(d.hub, width-textWidth(deficit), 2+4*rowHeight, deficit,
+		d.theme.Value)
+}
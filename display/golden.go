@@ -0,0 +1,131 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/astro"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// period is an interval of time, e.g., golden hour.
+type period struct {
+	name       string
+	start, end time.Time
+}
+
+// session contains the consecutive blue and golden hours of a single morning
+// or evening, in chronological order.
+type session struct {
+	name   string
+	period [2]period
+}
+
+// goldenPage shows the times of the next blue hour and golden hour, and a
+// countdown to the next of them, for photographers.
+type goldenPage struct {
+	day      int        // day of year of sessions, or 0 if not yet computed
+	sessions [3]session // this morning, this evening, and tomorrow morning
+	valid    [3]bool    // sun crosses all elevations of the session
+	shown    *session
+}
+
+func (p *goldenPage) Active(data model.Model) bool { return data.Location.Known() }
+
+func (p *goldenPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+	now := data.Time
+
+	if p.day != now.YearDay() {
+		p.compute(data.Location, now)
+	}
+
+	s := p.next(now)
+	if s != p.shown {
+		p.shown, clear = s, true
+	}
+	if clear {
+		d.fillRect(0, 2, width, 3*rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+		if nil == s {
+			tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight,
+				"Golden hour", d.theme.Title)
+			tinyfont.WriteLine(d.hub, &font, 0, 2+2*rowHeight,
+				"None today", d.theme.Text)
+			return
+		}
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, s.name,
+			d.theme.Title)
+		for i, pd := range s.period {
+			ty := 2 + int16(i+2)*rowHeight
+			tinyfont.WriteLine(d.hub, &font, 0, ty, pd.name, d.theme.Text)
+			span := appendClock(append(appendClock(d.text(), pd.start, false), '-'),
+				pd.end, false)
+			drawText(d.hub, width-textWidth(span), ty, span, d.theme.Value)
+		}
+	}
+	if nil == s {
+		return
+	}
+
+	// countdown to the start of the next period, or to the end of the current
+	countdown := d.text()
+	for _, pd := range s.period {
+		if now.Before(pd.start) {
+			countdown = append(append(countdown, pd.name...), " in "...)
+			countdown = appendDuration(countdown, pd.start.Sub(now))
+			break
+		}
+		if now.Before(pd.end) {
+			countdown = append(append(countdown, pd.name...), ' ')
+			countdown = append(appendDuration(countdown, pd.end.Sub(now)), " left"...)
+			break
+		}
+	}
+	d.fillRect(0, 2+3*rowHeight, width, rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	drawText(d.hub, 0, 2+4*rowHeight, countdown, d.theme.Value)
+}
+
+// compute determines the sessions of this morning, this evening, and tomorrow
+// morning, relative to time now.
+func (p *goldenPage) compute(loc model.Location, now time.Time) {
+	p.day, p.shown = now.YearDay(), nil
+	p.sessions[0], p.valid[0] = newSession("Morning", loc, now, true)
+	p.sessions[1], p.valid[1] = newSession("Evening", loc, now, false)
+	p.sessions[2], p.valid[2] = newSession("Tomorrow", loc, now.AddDate(0, 0, 1), true)
+}
+
+// next returns the first session that has not yet ended as of time now, or nil
+// if none occur.
+func (p *goldenPage) next(now time.Time) *session {
+	for i := range p.sessions {
+		if p.valid[i] && now.Before(p.sessions[i].period[1].end) {
+			return &p.sessions[i]
+		}
+	}
+	return nil
+}
+
+// newSession returns the morning (if rising) or evening session on the day of
+// time t, and false if the sun does not cross all of its elevations that day.
+func newSession(name string, loc model.Location, t time.Time, rising bool) (session, bool) {
+	elev := [3]float64{astro.BlueLow, astro.GoldenLow, astro.GoldenHigh}
+	kind := [2]string{"Blue", "Gold"}
+	if !rising {
+		elev[0], elev[2] = elev[2], elev[0]
+		kind[0], kind[1] = kind[1], kind[0]
+	}
+	var at [3]time.Time
+	for i, e := range elev {
+		var ok bool
+		if at[i], ok = astro.SunTime(t, loc.Latitude, loc.Longitude, e, rising); !ok {
+			return session{}, false
+		}
+	}
+	return session{name: name, period: [2]period{
+		{name: kind[0], start: at[0], end: at[1]},
+		{name: kind[1], start: at[1], end: at[2]},
+	}}, true
+}
@@ -0,0 +1,104 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/history"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Dimensions of the history charts on the health page.
+const (
+	chartBar    = 3  // width of each bar, px
+	chartGap    = 1  // space between bars, px
+	chartHeight = 12 // px
+	chartMin    = 50 // minimum full-scale value, so low values remain low
+)
+
+// healthPage shows the current air quality and pollen, with a bar chart of the
+// daily maximum of each over the past week.
+type healthPage struct{}
+
+func (p *healthPage) Active(data model.Model) bool { return data.Health.Valid() }
+
+// Urgency preempts the carousel while the air quality is hazardous.
+func (p *healthPage) Urgency(data model.Model) Urgency {
+	if data.Health.AQI > 300 {
+		return UrgencyCritical
+	}
+	return UrgencyNone
+}
+
+func (p *healthPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+	h := data.Health
+
+	if !clear {
+		d.fillRect(0, 2, width, 4*rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	}
+
+	aqi := appendInt(append(d.text(), "AQI "...), int(h.AQI+0.5))
+	drawText(d.hub, 0, 2+rowHeight, aqi, aqiColor(h.AQI))
+	tinyfont.WriteLine(d.hub, &font, 0, 2+4*rowHeight, "7d AQI",
+		d.theme.Text)
+	chart(d, 0, 2+rowHeight+1, &h.AQIHistory, aqiColor)
+
+	if h.HasPollen {
+		x := width - history.Days*(chartBar+chartGap) + chartGap
+		pollen := appendInt(append(d.text(), "Pol "...), int(h.Pollen+0.5))
+		drawText(d.hub, width-textWidth(pollen), 2+rowHeight, pollen,
+			d.theme.Value)
+		tinyfont.WriteLine(d.hub, &font, x, 2+4*rowHeight, "Pollen",
+			d.theme.Text)
+		chart(d, x, 2+rowHeight+1, &h.PollenHistory, func(float32) color.RGBA {
+			return d.theme.Value
+		})
+	}
+}
+
+// chart draws a bar chart of the given history, oldest day leftmost, with its
+// top-left corner at x, y. Each bar is scaled relative to the greatest value in
+// the history and colored by the given function of its value.
+func chart(d *Display, x, y int16, h *history.Daily, colorOf func(float32) color.RGBA) {
+	scale := float32(chartMin)
+	for ago := 0; ago < history.Days; ago++ {
+		if v, ok := h.Get(ago); ok && v > scale {
+			scale = v
+		}
+	}
+	for ago := history.Days - 1; ago >= 0; ago-- {
+		bx := x + int16(history.Days-1-ago)*(chartBar+chartGap)
+		// baseline, so that days without values are still evident
+		d.fillRect(bx, y+chartHeight-1, chartBar, 1,
+			color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xFF})
+		v, ok := h.Get(ago)
+		if !ok {
+			continue
+		}
+		bh := int16(v/scale*chartHeight + 0.5)
+		if bh < 1 {
+			bh = 1
+		}
+		d.fillRect(bx, y+chartHeight-bh, chartBar, bh, colorOf(v))
+	}
+}
+
+// aqiColor returns the color of the US AQI category containing aqi.
+func aqiColor(aqi float32) color.RGBA {
+	switch {
+	case aqi <= 50:
+		return color.RGBA{R: 0x00, G: 0xE4, B: 0x00, A: 0xFF} // good
+	case aqi <= 100:
+		return color.RGBA{R: 0xFF, G: 0xFF, B: 0x00, A: 0xFF} // moderate
+	case aqi <= 150:
+		return color.RGBA{R: 0xFF, G: 0x7E, B: 0x00, A: 0xFF} // unhealthy for some
+	case aqi <= 200:
+		return color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF} // unhealthy
+	case aqi <= 300:
+		return color.RGBA{R: 0x8F, G: 0x3F, B: 0x97, A: 0xFF} // very unhealthy
+	}
+	return color.RGBA{R: 0x7E, G: 0x00, B: 0x23, A: 0xFF} // hazardous
+}
@@ -0,0 +1,40 @@
+package display
+
+import (
+	"image/color"
+	"sort"
+)
+
+// iconSize defines the width and height of each icon, in pixels.
+const iconSize = 8
+
+// icon is a monochrome bitmap. Each element is a row of pixels, with the most
+// significant bit leftmost.
+type icon [iconSize]uint8
+
+// icons maps the name of each icon to its bitmap. The bitmaps are only compiled
+// in unless excluded by build tag "noicons", in which case notices are shown
+// without icons.
+var icons = map[string]*icon{}
+
+// draw renders the icon with its top-left corner at x, y using color c.
+// Unset pixels are left unchanged.
+func (ic *icon) draw(d *Display, x, y int16, c color.RGBA) {
+	for row, bits := range ic {
+		for col := int16(0); col < iconSize; col++ {
+			if 0 != bits&(0x80>>col) {
+				d.hub.SetPixel(x+col, y+int16(row), c)
+			}
+		}
+	}
+}
+
+// Icons returns the names of the icons compiled in, in lexical order.
+func Icons() []string {
+	name := make([]string, 0, len(icons))
+	for n := range icons {
+		name = append(name, n)
+	}
+	sort.Strings(name)
+	return name
+}
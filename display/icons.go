@@ -0,0 +1,89 @@
+//go:build !noicons
+// +build !noicons
+
+package display
+
+func init() {
+	icons = map[string]*icon{
+		"alert": {
+			0b00011000,
+			0b00111100,
+			0b00100100,
+			0b01100110,
+			0b01100110,
+			0b11111111,
+			0b11100111,
+			0b11111111,
+		},
+		"bell": {
+			0b00011000,
+			0b00111100,
+			0b01111110,
+			0b01111110,
+			0b01111110,
+			0b11111111,
+			0b00000000,
+			0b00011000,
+		},
+		"door": {
+			0b01111110,
+			0b01000010,
+			0b01000010,
+			0b01000010,
+			0b01001010,
+			0b01000010,
+			0b01000010,
+			0b01111110,
+		},
+		"motion": {
+			0b00000000,
+			0b00111100,
+			0b01000010,
+			0b10011001,
+			0b10011001,
+			0b01000010,
+			0b00111100,
+			0b00000000,
+		},
+		"package": {
+			0b11111111,
+			0b10011001,
+			0b10011001,
+			0b11111111,
+			0b10000001,
+			0b10000001,
+			0b10000001,
+			0b11111111,
+		},
+		"person": {
+			0b00011000,
+			0b00111100,
+			0b00011000,
+			0b01111110,
+			0b00011000,
+			0b00011000,
+			0b00100100,
+			0b01000010,
+		},
+		"satellite": {
+			0b11000011,
+			0b11100111,
+			0b01011010,
+			0b00111100,
+			0b00111100,
+			0b01011010,
+			0b11100111,
+			0b11000011,
+		},
+		"wind": {
+			0b00000100,
+			0b11111010,
+			0b00000100,
+			0b11111100,
+			0b00000000,
+			0b11111000,
+			0b00000100,
+			0b11111000,
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ardnew/weatherhub/asset"
+)
+
+// splashName defines the name of the image shown while connecting.
+const splashName = "splash"
+
+// SetImages replaces the images supplied at runtime (see package asset), e.g.,
+// custom notice icons, and redraws the current page entirely.
+func (d *Display) SetImages(images []asset.Image) {
+	d.images = make(map[string]*asset.Image, len(images))
+	for i := range images {
+		d.images[images[i].Name] = &images[i]
+	}
+	d.page.redraw()
+	d.notice.shown = false
+}
+
+// drawImage draws the given frame of an image with its top-left corner at x, y.
+// Transparent pixels are left unchanged.
+func (d *Display) drawImage(im *asset.Image, frame int, x, y int16) {
+	for row := int16(0); row < im.Height; row++ {
+		for col := int16(0); col < im.Width; col++ {
+			if c, ok := im.At(frame, col, row); ok {
+				d.setPixel(x+col, y+row, c)
+			}
+		}
+	}
+}
+
+// drawSplash draws the splash image, if any, centered above the status line
+// at the bottom of the panel, and plays it if animated.
+func (d *Display) drawSplash() {
+	if im, ok := d.images[splashName]; ok {
+		width, height := d.hub.Size()
+		d.splash.set(d, im, (width-im.Width)/2, (height-rowHeight-2-im.Height)/2,
+			color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	}
+}
+
+// animation plays the frames of an image at a fixed position, looping
+// continuously, e.g., an animated notice icon.
+type animation struct {
+	image *asset.Image // nil if none
+	x, y  int16
+	bg    color.RGBA // behind transparent pixels
+	start time.Time  // when the first frame was shown
+	frame int        // shown
+}
+
+// set draws the first frame of the given image at x, y, and begins playing it.
+func (a *animation) set(d *Display, im *asset.Image, x, y int16, bg color.RGBA) {
+	*a = animation{image: im, x: x, y: y, bg: bg}
+	d.drawImage(im, 0, x, y)
+}
+
+// stop stops playing the image, which is left as drawn.
+func (a *animation) stop() { a.image = nil }
+
+// step redraws the image if its frame shown at time now has changed.
+func (a *animation) step(d *Display, now time.Time) {
+	if nil == a.image || a.image.Frames < 2 {
+		return
+	}
+	if a.start.IsZero() {
+		a.start = now
+	}
+	if f := a.image.FrameAt(now.Sub(a.start)); f != a.frame {
+		a.frame = f
+		d.fillRect(a.x, a.y, a.image.Width, a.image.Height, a.bg)
+		d.drawImage(a.image, f, a.x, a.y)
+	}
+}
@@ -0,0 +1,61 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// comfortColors maps each Comfort level to the color of its indicator.
+var comfortColors = [...]color.RGBA{
+	model.ComfortUnknown: {R: 0x80, G: 0x80, B: 0x80, A: 0xFF},
+	model.ComfortDry:     {R: 0x40, G: 0x80, B: 0xFF, A: 0xFF},
+	model.ComfortGood:    {R: 0x00, G: 0xFF, B: 0x00, A: 0xFF},
+	model.ComfortHumid:   {R: 0xFF, G: 0xC0, B: 0x00, A: 0xFF},
+	model.ComfortMold:    {R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+}
+
+// indoorPage shows the indoor temperature, humidity, and dew point, with an
+// indicator of comfort colored by level.
+type indoorPage struct{}
+
+func (p *indoorPage) Active(data model.Model) bool { return data.Indoor.Valid() }
+
+func (p *indoorPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+	in := data.Indoor
+
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, "Indoor",
+			d.theme.Title)
+		tinyfont.WriteLine(d.hub, &font, 0, 2+2*rowHeight, "Temp",
+			d.theme.Text)
+		tinyfont.WriteLine(d.hub, &font, 0, 2+3*rowHeight, "Dew pt",
+			d.theme.Text)
+	}
+
+	var (
+		valueWidth     int16 = 9 * 4 // e.g., "21.5C 48%"
+		px, py, pw, ph int16 = width - valueWidth, 2 + rowHeight, valueWidth, 2 * rowHeight
+	)
+	d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	d.fillRect(0, 2+3*rowHeight, width, rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+
+	t, _ := d.units.Temp(in.Temperature)
+	temp := append(append(appendFixed(d.text(), t, 1), d.units.TempLetter()), ' ')
+	temp = append(appendInt(temp, int(in.Humidity+0.5)), '%')
+	drawText(d.hub, width-textWidth(temp), 2+2*rowHeight, temp, d.theme.Value)
+
+	t, sym := d.units.Temp(in.DewPoint)
+	dew := append(appendFixed(d.text(), t, 1), sym...)
+	drawText(d.hub, width-textWidth(dew), 2+3*rowHeight, dew, d.theme.Value)
+
+	// comfort indicator: a colored block followed by the level's description
+	c := comfortColors[in.Comfort]
+	d.fillRect(0, 2+3*rowHeight+1, 4, rowHeight-2, c)
+	tinyfont.WriteLine(d.hub, &font, 6, 2+4*rowHeight,
+		in.Comfort.String(), c)
+}
@@ -0,0 +1,61 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+)
+
+// drawLarge draws text at x, y (the baseline, as with drawText) with each pixel
+// of the font scaled to a square of scale pixels, so that text may be read from
+// across a room. The cell of each glyph is erased before the glyph is drawn.
+//
+// Text is kept legible against the graphics surrounding it (see
+// Config.Contrast), in which case an outline is drawn within the erased cells.
+func (d *Display) drawLarge(x, y int16, text []byte, scale int16, c color.RGBA) {
+	if 0 == len(text) {
+		return
+	}
+	edge, style := edgeColor(c), StylePlain
+	if ContrastOff != d.config.Contrast {
+		h := rowHeight * scale
+		bg := d.surrounding(x-1, y-h-1, largeWidth(text, scale)+2, h+2)
+		c, edge, style = d.legible(c, bg, style)
+	}
+	black := color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+	for len(text) > 0 {
+		r, n := decode(text)
+		text = text[n:]
+		g := glyph(r)
+		adv := int16(g.XAdvance) * scale
+		d.fillRect(x, y-rowHeight*scale, adv, rowHeight*scale, black)
+		if StyleOutline == style {
+			for _, o := range outline {
+				d.drawGlyph(x+o[0], y+o[1], g, scale, edge)
+			}
+		}
+		d.drawGlyph(x, y, g, scale, c)
+		x += adv
+	}
+}
+
+// drawGlyph draws the pixels of g at x, y, each scaled to a square of scale
+// pixels.
+func (d *Display) drawGlyph(x, y int16, g tinyfont.Glyph, scale int16, c color.RGBA) {
+	w, h := int16(g.Width), int16(g.Height)
+	for row := int16(0); row < h; row++ {
+		for col := int16(0); col < w; col++ {
+			bit := row*w + col
+			if 0 != g.Bitmaps[bit/8]&(0x80>>uint(bit%8)) {
+				d.fillRect(x+(int16(g.XOffset)+col)*scale,
+					y+(int16(g.YOffset)+row)*scale, scale, scale, c)
+			}
+		}
+	}
+}
+
+// largeWidth returns the width of text drawn by drawLarge with the given scale,
+// in pixels.
+func largeWidth(text []byte, scale int16) int16 {
+	return textWidth(text) * scale
+}
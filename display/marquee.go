@@ -0,0 +1,138 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinyfont"
+)
+
+// Default constants for marquee scrolling.
+const (
+	DefaultScrollRate = 60 * time.Millisecond // time per pixel scrolled
+	marqueeGap        = 16                    // px between repetitions of text
+)
+
+// marquee is a widget showing a single line of text within a rectangular
+// region of the display. If the text is wider than the region, it scrolls
+// continuously from right to left at the scroll rate of the Display (see
+// Config.ScrollRate). The time elapsed between frames is accumulated, so that
+// rates slower than one pixel per frame advance evenly rather than in fits.
+//
+// The text is drawn in a single color, unless tinted, in which case spans of
+// it are drawn in colors of their own.
+type marquee struct {
+	x, y, w int16 // left edge, baseline, and width of the region
+	color   color.RGBA
+	tint    []tint     // colors of spans of text, in order, or nil if none
+	bg      color.RGBA // background color of the region
+	style   TextStyle
+	text    string
+	width   int16 // width of text, in pixels
+	offset  int16 // number of pixels scrolled
+	last    time.Time
+	elapsed time.Duration // since the last pixel scrolled, less than the rate
+	clip    region        // kept to avoid allocating a region on each draw
+}
+
+// tint is the color of the text of a marquee beginning at a byte offset of the
+// text, until that of the next tint, if any.
+type tint struct {
+	at    int
+	color color.RGBA
+}
+
+// set changes the text and color shown by the marquee, removing any tints.
+// Scrolling restarts only if the text differs from what is currently shown.
+func (m *marquee) set(text string, c color.RGBA) {
+	if text != m.text {
+		// tinyfont.LineWidth panics on empty text, e.g., a row of scores emptied
+		// when fewer games are listed.
+		var w uint32
+		if "" != text {
+			_, w = tinyfont.LineWidth(&font, text)
+		}
+		m.text, m.width, m.offset, m.elapsed = text, int16(w), 0, 0
+	}
+	m.color, m.tint = c, nil
+}
+
+// draw renders the marquee at its current scroll position.
+func (m *marquee) draw(d *Display) {
+	d.fillRect(m.x, m.y-rowHeight, m.w, rowHeight, m.bg)
+	if "" == m.text {
+		return
+	}
+	m.clip = region{Displayer: d.hub, x: m.x, y: m.y - rowHeight, w: m.w, h: rowHeight}
+	r := &m.clip
+	m.write(d, r, m.x-m.offset)
+	if m.width > m.w {
+		// draw the following repetition of text as the current one scrolls away
+		span := m.width + marqueeGap
+		m.write(d, r, m.x-m.offset+span)
+	}
+}
+
+// write draws the text with its left edge at x, each span in its own color,
+// kept legible against the background of the region (see Config.Contrast).
+func (m *marquee) write(d *Display, r *region, x int16) {
+	bg := luminance(m.bg)
+	if 0 == len(m.tint) {
+		c, edge, style := d.legible(m.color, bg, m.style)
+		writeStyled(r, x, m.y, m.text, c, edge, style)
+		return
+	}
+	c := m.color
+	for i, k := 0, 0; i < len(m.text); {
+		for k < len(m.tint) && m.tint[k].at <= i {
+			c, k = m.tint[k].color, k+1
+		}
+		end := len(m.text)
+		if k < len(m.tint) {
+			end = m.tint[k].at
+		}
+		fg, edge, style := d.legible(c, bg, m.style)
+		writeStyled(r, x, m.y, m.text[i:end], fg, edge, style)
+		_, w := tinyfont.LineWidth(&font, m.text[i:end])
+		x, i = x+int16(w), end
+	}
+}
+
+// step advances the scroll position according to the elapsed time and redraws
+// the marquee if it has moved. Text that fits within the region never moves.
+func (m *marquee) step(d *Display, now time.Time) {
+	if m.width <= m.w {
+		return
+	}
+	if m.last.IsZero() {
+		m.last = now
+		return
+	}
+	// a marquee not stepped for a while, e.g., while hidden, resumes where it
+	// stopped rather than leaping ahead.
+	if gap := now.Sub(m.last); gap < time.Second {
+		m.elapsed += gap
+	}
+	m.last = now
+	rate := d.config.ScrollRate
+	n := int16(m.elapsed / rate)
+	if 0 == n {
+		return
+	}
+	m.elapsed -= time.Duration(n) * rate
+	m.offset = (m.offset + n) % (m.width + marqueeGap)
+	m.draw(d)
+}
+
+// region is a Displayer that discards all pixels outside of a rectangle.
+type region struct {
+	drivers.Displayer
+	x, y, w, h int16
+}
+
+func (r *region) SetPixel(x, y int16, c color.RGBA) {
+	if x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h {
+		r.Displayer.SetPixel(x, y, c)
+	}
+}
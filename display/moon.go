@@ -0,0 +1,104 @@
+package display
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/astro"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// moonRadius defines the radius of the moon drawn on the lunar calendar page.
+const moonRadius = 6
+
+// Colors of the lit and unlit parts of the moon.
+var (
+	moonLit  = color.RGBA{R: 0xFF, G: 0xF0, B: 0xC0, A: 0xFF}
+	moonDark = color.RGBA{R: 0x18, G: 0x18, B: 0x20, A: 0xFF}
+)
+
+// moonPage shows a small lunar calendar: the current phase of the moon, the
+// dates of this month's new and full moons, and the moon's zodiac sign.
+type moonPage struct {
+	day int // day of year drawn, or 0 if the page must be redrawn
+}
+
+// Active reports whether the time is known, since the phase and the dates of
+// the month's new and full moons are all computed from it.
+func (p *moonPage) Active(data model.Model) bool { return !data.Time.IsZero() }
+
+func (p *moonPage) Draw(d *Display, data model.Model, clear bool) {
+
+	// the phase and sign change slowly, so the page is only redrawn daily.
+	if !clear && p.day == data.Time.YearDay() {
+		return
+	}
+	if !clear {
+		d.clearPage()
+	}
+	p.day = data.Time.YearDay()
+
+	width, _ := d.hub.Size()
+
+	tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight,
+		data.Time.Month().String()[:3], d.theme.Title)
+	sign := astro.Zodiac(astro.MoonLongitude(data.Time))
+	_, sw := tinyfont.LineWidth(&font, sign)
+	tinyfont.WriteLine(d.hub, &font, width-int16(sw), 2+rowHeight,
+		sign, d.theme.Value)
+
+	drawMoon(d, moonRadius+1, 2+rowHeight+moonRadius+3, moonRadius,
+		astro.MoonAge(data.Time))
+
+	for i, ph := range astro.Phases(data.Time) {
+		label := "New "
+		if astro.FullMoon == ph.Lunation {
+			label = "Full "
+		}
+		c := d.theme.Text
+		if ph.Time.YearDay() == data.Time.YearDay() {
+			c = d.theme.Value
+		}
+		tinyfont.WriteLine(d.hub, &font, 2*moonRadius+6,
+			2+int16(i+2)*rowHeight, label+strconv.Itoa(ph.Time.Day()), c)
+	}
+}
+
+// drawMoon renders the moon with the given age (days since new moon) as a disk
+// of radius r whose center is the top-left corner of pixel cx, cy.
+//
+// The lit part is bounded by the limb on one side and by the terminator on the
+// other. The terminator is half of an ellipse whose width varies with the age.
+// The moon is drawn as seen from the northern hemisphere, so a waxing moon is
+// lit on the right.
+func drawMoon(d *Display, cx, cy, r int16, age float64) {
+	angle := 2 * math.Pi * age / astro.SynodicMonth
+	waxing := angle < math.Pi
+	term := math.Cos(angle) // terminator position, as fraction of half-width
+	if !waxing {
+		term = -term
+	}
+	// sample the center of each pixel, so the disk spans 2r pixels
+	for dy := -r; dy < r; dy++ {
+		ny := (float64(dy) + 0.5) / float64(r)
+		half := math.Sqrt(1 - ny*ny) // half-width of the disk at this row
+		for dx := -r; dx < r; dx++ {
+			nx := (float64(dx) + 0.5) / float64(r)
+			if nx < -half || nx > half {
+				continue
+			}
+			lit := nx > half*term // lit from the right limb
+			if !waxing {
+				lit = nx < half*term // lit from the left limb
+			}
+			c := moonDark
+			if lit {
+				c = moonLit
+			}
+			d.setPixel(cx+dx, cy+dy, c)
+		}
+	}
+}
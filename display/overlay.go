@@ -0,0 +1,126 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// overlayRows defines the most rows across which the text of a Notice is
+// wrapped.
+const overlayRows = 3
+
+// overlay shows the queued Notice of highest priority in place of the current
+// page. Notices too long to fit on a single row are wrapped across several, and
+// those too long to fit on overlayRows rows scroll horizontally.
+type overlay struct {
+	shown  bool
+	notice model.Notice
+	line   marquee
+	rows   [overlayRows]string // wrapped text, kept to avoid allocating
+	start  time.Time           // when flashing began
+	on     bool                // display is in the flashed phase of the Flash pattern
+	icon   animation           // image supplied at runtime in place of the icon
+}
+
+// flashTiming returns the period of the given Flash pattern, and the portion of
+// each period spent in the flashed phase.
+func flashTiming(f model.Flash) (period, on time.Duration) {
+	switch f {
+	case model.FlashBlink, model.FlashBorder:
+		return 500 * time.Millisecond, 250 * time.Millisecond
+	case model.FlashStrobe:
+		return 200 * time.Millisecond, 50 * time.Millisecond
+	}
+	return 0, 0
+}
+
+func (o *overlay) draw(d *Display, n model.Notice) {
+	if o.shown && drawnAlike(n, o.notice) {
+		return // already drawn, animate handles flashing and scrolling
+	}
+	o.shown, o.notice, o.start, o.on = true, n, time.Time{}, false
+	o.paint(d)
+}
+
+// paint draws the entire overlay using the current phase of its Flash pattern.
+func (o *overlay) paint(d *Display) {
+
+	width, height := d.hub.Size()
+
+	fg, bg := o.notice.Color, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+	if o.on && model.FlashBorder != o.notice.Flash {
+		fg, bg = bg, fg // inverted
+	}
+	d.fillRect(0, 0, width, height, bg)
+	if o.on && model.FlashBorder == o.notice.Flash {
+		d.fillRect(0, 0, width, 1, fg)
+		d.fillRect(0, height-1, width, 1, fg)
+		d.fillRect(0, 0, 1, height, fg)
+		d.fillRect(width-1, 0, 1, height, fg)
+	}
+
+	// the icon, if any, is shown at left with text filling the remainder. An
+	// image supplied at runtime replaces the built-in icon of the same name.
+	left := int16(0)
+	o.icon.stop()
+	if im, ok := d.images[o.notice.Icon]; ok {
+		o.icon.set(d, im, 2, (height-im.Height)/2, bg)
+		left = 2 + im.Width + 2
+	} else if ic, ok := icons[o.notice.Icon]; ok {
+		ic.draw(d, 2, (height-iconSize)/2, fg)
+		left = 2 + iconSize + 2
+	}
+
+	// center the text if it fits, wrapped if necessary, otherwise scroll across
+	// the available width.
+	space := width - left - 1
+	if rows, rest := wrap(o.rows[:0], o.notice.Text, space); len(rows) > 1 && "" == rest {
+		o.line.set("", fg) // nothing to scroll
+		top := (height - int16(len(rows))*rowHeight) / 2
+		for i, row := range rows {
+			x := left + (space-stringWidth(row))/2
+			tinyfont.WriteLine(d.hub, &font, x, top+int16(i+1)*rowHeight, row, fg)
+		}
+		return
+	}
+	o.line.x, o.line.y, o.line.w = left, height/2+rowHeight/2, space
+	// tinyfont.LineWidth panics on empty text, which is centered as if of zero
+	// width.
+	var w uint32
+	if "" != o.notice.Text {
+		_, w = tinyfont.LineWidth(&font, o.notice.Text)
+	}
+	if int16(w) < space {
+		o.line.x, o.line.w = left+(space-int16(w))/2, int16(w)
+	}
+	o.line.bg = bg
+	o.line.set(o.notice.Text, fg)
+	o.line.draw(d)
+}
+
+// drawnAlike returns true if notices a and b are drawn alike, i.e., the same
+// Notice unchanged. Posted alone cannot tell, since it is kept to the second, so
+// that a Notice replaced within the same second is posted at the same time.
+func drawnAlike(a, b model.Notice) bool {
+	return a.ID == b.ID && a.Posted.Equal(b.Posted) && a.Text == b.Text &&
+		a.Icon == b.Icon && a.Color == b.Color && a.Flash == b.Flash
+}
+
+func (o *overlay) animate(d *Display, now time.Time) {
+	if period, on := flashTiming(o.notice.Flash); period > 0 {
+		if o.start.IsZero() {
+			o.start = now
+		}
+		if phase := now.Sub(o.start)%period < on; phase != o.on {
+			o.on = phase
+			o.paint(d)
+			return
+		}
+	}
+	o.icon.step(d, now)
+	o.line.step(d, now)
+}
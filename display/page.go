@@ -0,0 +1,226 @@
+package display
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// DefaultDwell defines how long each page is shown before the carousel advances
+// to the next active page.
+const DefaultDwell = 10 * time.Second
+
+// rowHeight defines the height of a single row of text in the default font.
+const rowHeight = 6
+
+// Page is a full-screen view of Model data shown while the system time is
+// synchronized. The active pages are shown in turn by a carousel.
+type Page interface {
+	// Active returns true if the page has something to show for the given Model
+	// data and should be included in the carousel.
+	Active(data model.Model) bool
+	// Draw renders the page using the given Model data. If clear is true, the
+	// display has just been cleared and the entire page must be redrawn.
+	// Otherwise, the page only needs to redraw content that has changed.
+	Draw(d *Display, data model.Model, clear bool)
+}
+
+// Animator is implemented by pages with content that changes between updates
+// of the Model data, such as scrolling text.
+type Animator interface {
+	// Animate redraws any animated content of the page as of the given time.
+	Animate(d *Display, now time.Time)
+}
+
+// Standby is implemented by pages only shown while every other page is hidden,
+// e.g., by a scene showing only that page, rather than in the carousel.
+type Standby interface {
+	Standby()
+}
+
+// Holder is implemented by pages that keep the carousel from advancing while
+// they are in use, such as a running stopwatch.
+type Holder interface {
+	// Hold returns true if the carousel should show the page, and remain on it,
+	// with the given Model data.
+	Hold(data model.Model) bool
+}
+
+// Urgency is how urgently a page must be shown, e.g., while an alert is in
+// effect, which preempts the carousel.
+type Urgency uint8
+
+// Constants defining each Urgency, in increasing order.
+const (
+	UrgencyNone     Urgency = iota // shown in turn by the carousel
+	UrgencyElevated                // preempts the carousel
+	UrgencyCritical                // preempts the carousel and pages of UrgencyElevated
+)
+
+// Urgent is implemented by pages that preempt the carousel while urgent. The
+// carousel shows the active page of greatest Urgency immediately, remains on it
+// while it is urgent, and then returns to the page it interrupted, for the
+// remainder of its dwell. Pages holding the display (see Holder) are shown in
+// place of urgent pages, since they are shown at the request of the user.
+type Urgent interface {
+	// Urgency returns how urgently the page must be shown with the given Model
+	// data.
+	Urgency(data model.Model) Urgency
+}
+
+// namedPage is a Page and the name used to hide it from the carousel.
+type namedPage struct {
+	name string
+	page Page
+}
+
+// carousel selects which of its pages is currently shown.
+type carousel struct {
+	page   []Page
+	name   []string
+	hidden []bool    // page is never shown, regardless of Active
+	index  int       // index of current page, or -1 if none shown
+	since  time.Time // when current page was first shown
+	dwell  time.Duration
+	dwells []time.Duration // of each page, or zero for dwell
+	pinned bool            // current page is shown until unpinned
+	resume int             // index of page interrupted by a preempting page, or -1 if none
+	shown  time.Duration   // of the page interrupted, before it was interrupted
+	stale  bool            // current page must be redrawn entirely
+}
+
+func newCarousel(dwell time.Duration, page ...namedPage) *carousel {
+	c := &carousel{
+		page:   make([]Page, len(page)),
+		name:   make([]string, len(page)),
+		hidden: make([]bool, len(page)),
+		dwells: make([]time.Duration, len(page)),
+		index:  -1,
+		resume: -1,
+		dwell:  dwell,
+	}
+	for i, p := range page {
+		c.page[i], c.name[i] = p.page, p.name
+	}
+	return c
+}
+
+// active returns true if the page at index k should be shown with the given
+// Model data.
+func (c *carousel) active(k int, data model.Model) bool {
+	if _, ok := c.page[k].(Standby); ok && !c.alone(k) {
+		return false
+	}
+	return !c.hidden[k] && c.page[k].Active(data)
+}
+
+// alone returns true if every page other than the page at index k is hidden.
+func (c *carousel) alone(k int) bool {
+	for i, hidden := range c.hidden {
+		if i != k && !hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// next returns the page that should be drawn with the given Model data, and
+// whether that page differs from the page drawn previously.
+// Returns a nil Page if no pages are active.
+func (c *carousel) next(data model.Model) (Page, bool) {
+	stale := c.stale
+	c.stale = false
+	if k := c.preempt(data); k >= 0 {
+		if k != c.index {
+			if c.resume < 0 && c.index >= 0 {
+				c.resume, c.shown = c.index, data.Time.Sub(c.since)
+			}
+			c.index, c.since = k, data.Time
+			return c.page[k], true
+		}
+		return c.page[k], stale
+	}
+	if c.resume >= 0 {
+		// return to the page interrupted, for the remainder of its dwell, or else
+		// advance from it.
+		k := c.resume
+		c.resume = -1
+		if c.active(k, data) {
+			c.index, c.since = k, data.Time.Add(-c.shown)
+			return c.page[k], true
+		}
+		c.index, stale = k, true
+	}
+	if c.index >= 0 && c.active(c.index, data) {
+		if c.pinned || data.Time.Sub(c.since) < c.dwellOf(c.index) {
+			return c.page[c.index], stale
+		}
+	}
+	c.pinned = false // the pinned page is no longer active
+	// advance to the next active page, or remain on the current page if no other
+	// page is active.
+	prev := c.index
+	for i := 1; i <= len(c.page); i++ {
+		k := (prev + i) % len(c.page)
+		if c.active(k, data) {
+			c.index, c.since = k, data.Time
+			return c.page[k], stale || k != prev
+		}
+	}
+	c.index = -1
+	return nil, false
+}
+
+// preempt returns the index of the page preempting the carousel with the given
+// Model data: the first page holding the display, or else the first active page
+// of greatest Urgency. Returns -1 if no page preempts the carousel.
+func (c *carousel) preempt(data model.Model) int {
+	best, urgency := -1, UrgencyNone
+	for k, p := range c.page {
+		if h, ok := p.(Holder); ok && c.active(k, data) && h.Hold(data) {
+			return k
+		}
+		if u, ok := p.(Urgent); ok && c.active(k, data) {
+			if v := u.Urgency(data); v > urgency {
+				best, urgency = k, v
+			}
+		}
+	}
+	return best
+}
+
+// dwellOf returns how long the page at index k is shown before the carousel
+// advances.
+func (c *carousel) dwellOf(k int) time.Duration {
+	if 0 != c.dwells[k] {
+		return c.dwells[k]
+	}
+	return c.dwell
+}
+
+// current returns the page most recently returned by next, or nil if no page
+// has been drawn since the carousel was reset.
+func (c *carousel) current() Page {
+	if c.index < 0 {
+		return nil
+	}
+	return c.page[c.index]
+}
+
+// redraw causes the next call to next to report that the current page must be
+// redrawn entirely.
+func (c *carousel) redraw() {
+	c.stale = true
+}
+
+// reset causes the next call to next to start the carousel over from the first
+// page.
+func (c *carousel) reset() {
+	c.index, c.resume = -1, -1
+}
+
+// skip causes the next call to next to advance to the next active page, as if
+// the current page had been shown for its entire dwell, unpinning it.
+func (c *carousel) skip() {
+	c.since, c.pinned = time.Time{}, false
+}
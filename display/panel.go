@@ -0,0 +1,157 @@
+package display
+
+import (
+	"errors"
+	"image/color"
+
+	"tinygo.org/x/drivers/rgb75"
+)
+
+var (
+	ErrArea = errors.New("active area exceeds the panel")
+)
+
+// DefaultBrightness defines the brightness of the panel, in percent, unless
+// configured otherwise.
+const DefaultBrightness = 100
+
+// Area is a rectangle of the panel, in pixels from its top-left corner.
+type Area struct {
+	X, Y          int16
+	Width, Height int16
+}
+
+// active returns the Area of the panel of the given size drawn on. The zero
+// Area is the entire panel, and an Area given only by its Width and Height is
+// centered on the panel.
+func (a Area) active(width, height int16) (Area, error) {
+	if 0 == a.Width || 0 == a.Height {
+		return Area{Width: width, Height: height}, nil
+	}
+	if 0 == a.X && 0 == a.Y {
+		a.X, a.Y = (width-a.Width)/2, (height-a.Height)/2
+	}
+	if a.X < 0 || a.Y < 0 || a.Width < 0 || a.Height < 0 ||
+		a.X+a.Width > width || a.Y+a.Height > height {
+		return Area{}, ErrArea
+	}
+	return a, nil
+}
+
+// driver is the subset of the methods of the HUB75 device driver used by the
+// panel, so that pages may also be drawn on an image in memory, e.g., to compare
+// them with the images expected in tests.
+type driver interface {
+	Configure(config rgb75.Config) error
+	SetPixel(x, y int16, c color.RGBA)
+	GetPixel(x, y int16) color.RGBA
+	Display() error
+	ClearDisplay()
+	Resume()
+	Pause()
+}
+
+// panel wraps the HUB75 device driver, scaling the intensity of every pixel
+// drawn by the brightness of the panel.
+//
+// Only the active Area of the panel is drawn on, e.g., to avoid a bezel or the
+// edges of a diffuser. Pages are given the size of the Area rather than that
+// of the panel, and lay out their content within it, while the remainder of
+// the panel is kept dark.
+//
+// The rgb75 driver has no control of the panel's output enable duty cycle, so
+// brightness is reduced by scaling colors. Dim colors lose precision at low
+// color depth, so brightness below 20% or so is barely distinguishable.
+type panel struct {
+	driver
+	area  Area   // active area, drawn on
+	level uint16 // brightness, percent
+	off   bool   // all pixels are dark, see SetPower
+}
+
+// Size returns the size of the active area, in pixels.
+func (p *panel) Size() (int16, int16) { return p.area.Width, p.area.Height }
+
+// SetPixel sets the pixel at x, y of the active area to color c, scaled by the
+// brightness. Nothing is drawn outside of the active area, or while the panel
+// is off.
+func (p *panel) SetPixel(x, y int16, c color.RGBA) {
+	if x < 0 || y < 0 || x >= p.area.Width || y >= p.area.Height {
+		return
+	}
+	p.setPanelPixel(p.area.X+x, p.area.Y+y, c)
+}
+
+// getPixel returns the color of the pixel at x, y of the active area, as drawn
+// before scaling by the brightness, or black if outside of the active area.
+func (p *panel) getPixel(x, y int16) color.RGBA {
+	if x < 0 || y < 0 || x >= p.area.Width || y >= p.area.Height {
+		return color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+	}
+	c := p.driver.GetPixel(p.area.X+x, p.area.Y+y)
+	if p.level < 100 && p.level > 0 {
+		c.R = uint8(min255(uint16(c.R) * 100 / p.level))
+		c.G = uint8(min255(uint16(c.G) * 100 / p.level))
+		c.B = uint8(min255(uint16(c.B) * 100 / p.level))
+	}
+	return c
+}
+
+// min255 returns v, or 255 if v is greater.
+func min255(v uint16) uint16 {
+	if v > 0xFF {
+		return 0xFF
+	}
+	return v
+}
+
+// setPanelPixel sets the pixel at x, y of the entire panel, regardless of the
+// active area, to color c, scaled by the brightness. Nothing is drawn while the
+// panel is off.
+func (p *panel) setPanelPixel(x, y int16, c color.RGBA) {
+	if p.off {
+		return
+	}
+	if p.level < 100 {
+		c.R = uint8(uint16(c.R) * p.level / 100)
+		c.G = uint8(uint16(c.G) * p.level / 100)
+		c.B = uint8(uint16(c.B) * p.level / 100)
+	}
+	p.driver.SetPixel(x, y, c)
+}
+
+// Brightness returns the brightness of the panel, in percent.
+func (d *Display) Brightness() int { return int(d.hub.level) }
+
+// SetBrightness changes the brightness of the panel to the given percent,
+// clamped to 1-100, and redraws the current page entirely.
+func (d *Display) SetBrightness(percent int) {
+	if percent < 1 {
+		percent = 1
+	} else if percent > 100 {
+		percent = 100
+	}
+	if uint16(percent) != d.hub.level {
+		d.hub.level = uint16(percent)
+		d.hub.ClearDisplay()
+		d.page.redraw()
+		d.notice.shown = false
+	}
+}
+
+// Power returns true if the panel is on.
+func (d *Display) Power() bool { return !d.hub.off }
+
+// SetPower turns the panel on or off. While off, pages are still updated, but
+// nothing is drawn, and the current page is redrawn entirely once turned on.
+func (d *Display) SetPower(on bool) {
+	if on == !d.hub.off {
+		return
+	}
+	d.hub.ClearDisplay()
+	d.hub.off = !on
+	if on {
+		d.page.redraw()
+		d.notice.shown = false
+	}
+}
@@ -0,0 +1,158 @@
+package display
+
+import (
+	"image/color"
+	"time"
+)
+
+// Pattern is a test pattern covering the entire panel, in place of any page,
+// to diagnose faulty panels: dead or stuck pixels, ghosting, and rows or
+// columns addressed incorrectly.
+type Pattern uint8
+
+// Constants defining each Pattern.
+const (
+	PatternNone     Pattern = iota // pages are shown
+	PatternGradient                // ramps of white, red, green, and blue, left to right
+	PatternChecker                 // alternating lit and dark pixels
+	PatternRed                     // every pixel fully red
+	PatternGreen                   // every pixel fully green
+	PatternBlue                    // every pixel fully blue
+	PatternWhite                   // every pixel fully white
+	PatternSweep                   // a lit row sweeping down, then a lit column sweeping right
+)
+
+// sweepStep defines how long each row or column is lit by PatternSweep.
+const sweepStep = 100 * time.Millisecond
+
+// String returns the name of the Pattern.
+func (p Pattern) String() string {
+	switch p {
+	case PatternGradient:
+		return "gradient"
+	case PatternChecker:
+		return "checker"
+	case PatternRed:
+		return "red"
+	case PatternGreen:
+		return "green"
+	case PatternBlue:
+		return "blue"
+	case PatternWhite:
+		return "white"
+	case PatternSweep:
+		return "sweep"
+	}
+	return "off"
+}
+
+// ParsePattern parses the name of a Pattern, as returned by String.
+func ParsePattern(s string) (Pattern, bool) {
+	for p := PatternNone; p <= PatternSweep; p++ {
+		if s == p.String() {
+			return p, true
+		}
+	}
+	return PatternNone, false
+}
+
+// testPattern is the state of the Pattern shown.
+type testPattern struct {
+	pattern Pattern
+	step    int // of PatternSweep drawn, or -1 if none
+}
+
+// Pattern returns the test pattern shown, or PatternNone if pages are shown.
+func (d *Display) Pattern() Pattern { return d.test.pattern }
+
+// SetPattern shows the given test pattern across the entire panel, including
+// any area outside of the active area, until PatternNone restores the pages.
+// The pattern is shown regardless of the Model data, and at the brightness of
+// the panel.
+func (d *Display) SetPattern(p Pattern) {
+	if p == d.test.pattern {
+		return
+	}
+	d.test.pattern, d.test.step = p, -1
+	d.clearPage()
+	if PatternNone == p {
+		d.page.redraw()
+		d.notice.shown = false
+		return
+	}
+	d.drawPattern(time.Now())
+}
+
+// drawPattern draws the test pattern as of time now, which only changes the
+// panel if the pattern is animated.
+func (d *Display) drawPattern(now time.Time) {
+	w, h := d.config.Width, d.config.Height
+	var at func(x, y int16) color.RGBA // color of each pixel of static patterns
+	switch d.test.pattern {
+	case PatternSweep:
+		// lights each row, then each column, erasing the one lit before.
+		n := int(h + w)
+		step := int(now.UnixNano()/int64(sweepStep)) % n
+		if step == d.test.step {
+			return
+		}
+		if prev := d.test.step; prev >= 0 {
+			d.sweepLine(prev, color.RGBA{})
+		}
+		d.test.step = step
+		d.sweepLine(step, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+		return
+	case PatternGradient:
+		at = func(x, y int16) color.RGBA {
+			v := uint8(int(x) * 0xFF / int(w-1))
+			switch 4 * y / h {
+			case 0:
+				return color.RGBA{R: v, G: v, B: v, A: 0xFF}
+			case 1:
+				return color.RGBA{R: v, A: 0xFF}
+			case 2:
+				return color.RGBA{G: v, A: 0xFF}
+			}
+			return color.RGBA{B: v, A: 0xFF}
+		}
+	case PatternChecker:
+		at = func(x, y int16) color.RGBA {
+			if 0 == (x+y)&1 {
+				return color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+			}
+			return color.RGBA{}
+		}
+	case PatternRed:
+		at = func(x, y int16) color.RGBA { return color.RGBA{R: 0xFF, A: 0xFF} }
+	case PatternGreen:
+		at = func(x, y int16) color.RGBA { return color.RGBA{G: 0xFF, A: 0xFF} }
+	case PatternBlue:
+		at = func(x, y int16) color.RGBA { return color.RGBA{B: 0xFF, A: 0xFF} }
+	case PatternWhite:
+		at = func(x, y int16) color.RGBA { return color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF} }
+	}
+	if d.test.step >= 0 {
+		return // static patterns are drawn once
+	}
+	d.test.step = 0
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			d.hub.setPanelPixel(x, y, at(x, y))
+		}
+	}
+}
+
+// sweepLine draws the row (if i is less than the height of the panel) or else
+// the column of PatternSweep with the given index in color c.
+func (d *Display) sweepLine(i int, c color.RGBA) {
+	w, h := d.config.Width, d.config.Height
+	if i < int(h) {
+		for x := int16(0); x < w; x++ {
+			d.hub.setPanelPixel(x, int16(i), c)
+		}
+		return
+	}
+	for y := int16(0); y < h; y++ {
+		d.hub.setPanelPixel(int16(i)-h, y, c)
+	}
+}
@@ -0,0 +1,110 @@
+package display
+
+import (
+	"errors"
+	"image/color"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/theme"
+)
+
+var (
+	ErrUnknownFace     = errors.New("unknown clock face")
+	ErrUnknownCalendar = errors.New("unknown calendar")
+)
+
+// ClockFace is a clock page contributed by a package other than display, e.g.,
+// a pixel-art theme, without modifying this package. Each is registered by name
+// from an init function of the package contributing it:
+//
+//	func init() {
+//		display.RegisterFace("sunrise", func() display.ClockFace { return &sunrise{} })
+//	}
+//
+// and is shown by naming it in Config.Plugins, once the contributing package is
+// imported by the program, e.g., with a blank import from a feature file.
+type ClockFace interface {
+	// Draw renders the face on the given Canvas using the given Model data. If
+	// clear is true, the Canvas has just been cleared and the entire face must
+	// be redrawn. Otherwise, only content that has changed needs redrawing.
+	Draw(c Canvas, data model.Model, clear bool)
+}
+
+// ClockFaceAnimator is implemented by a ClockFace with content that changes
+// between updates of the Model data, e.g., a blinking colon.
+type ClockFaceAnimator interface {
+	// Animate redraws any animated content of the face as of the given time.
+	Animate(c Canvas, now time.Time)
+}
+
+// faces maps the name of each registered ClockFace to its constructor.
+var faces = map[string]func() ClockFace{}
+
+// RegisterFace registers the constructor of a ClockFace with the given name,
+// which is also the name of its page, e.g., to hide it from the carousel. The
+// constructor is called once by each Display showing the face.
+func RegisterFace(name string, newFace func() ClockFace) {
+	faces[name] = newFace
+}
+
+// Canvas is the surface a ClockFace draws on, which is the active area of the
+// panel. It implements drivers.Displayer, so that it may also be drawn on with
+// tinyfont and tinydraw. Colors are scaled by the brightness of the panel and
+// dithered as configured.
+type Canvas struct {
+	d *Display
+}
+
+// Size returns the width and height of the Canvas, in pixels.
+func (c Canvas) Size() (int16, int16) { return c.d.hub.Size() }
+
+// SetPixel sets the pixel at x, y to the given color.
+func (c Canvas) SetPixel(x, y int16, col color.RGBA) { c.d.setPixel(x, y, col) }
+
+// Display does nothing, since pixels are shown as they are set.
+func (c Canvas) Display() error { return nil }
+
+// Theme returns the colors of the theme in effect, which faces should use so
+// that they follow the theme of holidays and the time of day.
+func (c Canvas) Theme() *theme.Theme { return c.d.theme }
+
+// Fill fills the given rectangle with the given color.
+func (c Canvas) Fill(x, y, w, h int16, col color.RGBA) { c.d.fillRect(x, y, w, h, col) }
+
+// Line draws a line from x0, y0 to x1, y1 with the given color.
+func (c Canvas) Line(x0, y0, x1, y1 int16, col color.RGBA) { c.d.drawLine(x0, y0, x1, y1, col) }
+
+// Circle draws a circle of radius r centered at cx, cy with the given color.
+func (c Canvas) Circle(cx, cy, r int16, col color.RGBA) { c.d.drawCircle(cx, cy, r, col) }
+
+// Text draws the given text in the font of every other page, with its
+// baseline at y. Each row of text is 6 pixels tall. Text drawn over graphics
+// of too little contrast is outlined or recolored (see Config.Contrast).
+func (c Canvas) Text(x, y int16, text string, col color.RGBA) {
+	c.d.drawLegible(x, y, text, col)
+}
+
+// StyledText draws the given text as Text, but edged in black or white,
+// whichever contrasts with col, as given by style, e.g., to remain legible over
+// an animation beneath it.
+func (c Canvas) StyledText(x, y int16, text string, col color.RGBA, style TextStyle) {
+	writeStyled(c.d.hub, x, y, text, col, edgeColor(col), style)
+}
+
+// facePage is the Page showing a registered ClockFace.
+type facePage struct {
+	face ClockFace
+}
+
+func (p *facePage) Active(data model.Model) bool { return true }
+
+func (p *facePage) Draw(d *Display, data model.Model, clear bool) {
+	p.face.Draw(Canvas{d: d}, data, clear)
+}
+
+func (p *facePage) Animate(d *Display, now time.Time) {
+	if a, ok := p.face.(ClockFaceAnimator); ok {
+		a.Animate(Canvas{d: d}, now)
+	}
+}
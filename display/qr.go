@@ -0,0 +1,74 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/codec/qr"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// qrQuiet defines the widest margin of light modules drawn around a QR code,
+// which scanners need to find it. Codes too large for the full margin on the
+// panel are drawn with as much margin as fits.
+const qrQuiet = 4
+
+// qrPage shows a QR code at the left of the panel, one pixel per module, with
+// its caption wrapped at the right. The carousel remains on the page while the
+// code is shown.
+type qrPage struct {
+	text string   // encoded by code
+	code *qr.Code // nil if text cannot be encoded
+	rows [5]string
+}
+
+func (p *qrPage) Active(data model.Model) bool { return data.QR.Shown(data.Time) }
+
+func (p *qrPage) Hold(data model.Model) bool { return data.QR.Shown(data.Time) }
+
+func (p *qrPage) Draw(d *Display, data model.Model, clear bool) {
+	if !clear && data.QR.Text == p.text {
+		return
+	}
+	if data.QR.Text != p.text {
+		p.text = data.QR.Text
+		p.code, _ = qr.Encode(p.text)
+		if !clear {
+			d.clearPage()
+		}
+	}
+
+	width, height := d.hub.Size()
+	left := int16(0)
+	if nil != p.code {
+		size := int16(p.code.Size)
+		quiet := (height - size) / 2
+		if quiet > qrQuiet {
+			quiet = qrQuiet
+		} else if quiet < 0 {
+			quiet = 0
+		}
+		top := (height - size) / 2
+		d.fillRect(0, top-quiet, size+2*quiet, size+2*quiet,
+			color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+		for y := 0; y < p.code.Size; y++ {
+			for x := 0; x < p.code.Size; x++ {
+				if p.code.Dark(x, y) {
+					d.setPixel(quiet+int16(x), top+int16(y),
+						color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+				}
+			}
+		}
+		left = size + 2*quiet + 1
+	}
+
+	// caption, centered in the remaining space
+	space := width - left
+	rows, _ := wrap(p.rows[:0], data.QR.Caption, space)
+	top := (height - int16(len(rows))*rowHeight) / 2
+	for i, row := range rows {
+		x := left + (space-stringWidth(row))/2
+		tinyfont.WriteLine(d.hub, &font, x, top+int16(i+1)*rowHeight, row, d.theme.Text)
+	}
+}
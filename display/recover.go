@@ -0,0 +1,65 @@
+package display
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// DefaultRetry defines how often the panel is reconfigured while every Timing
+// preset fails to configure.
+const DefaultRetry = 5 * time.Second
+
+// reconfigure pauses the panel and configures it with the given Timing preset.
+// If the driver fails, e.g., because the timer it scans the panel with is in
+// use, the preset previously in effect is tried instead, and then TimingSafe.
+// Each failure is recorded in the journal, and the error of the given preset
+// is returned.
+//
+// If every preset fails, the panel remains paused rather than halting the
+// program, and Animate tries again after DefaultRetry. Pages are still updated
+// in the meantime, and the current page is redrawn entirely once recovered.
+func (d *Display) reconfigure(t Timing) error {
+	d.hub.Pause()
+	var fail error
+	for i, try := range [...]Timing{t, d.timing, TimingSafe} {
+		if i > 0 && (try == t || (i > 1 && try == d.timing)) {
+			continue // already tried
+		}
+		scan := try.apply(d.config)
+		if err := d.hub.Configure(scan.Config); nil != err {
+			journal.Println("display: " + try.String() + ": " + err.Error())
+			if 0 == i {
+				fail = err
+			}
+			continue
+		}
+		d.step = ditherStep(d.config.Dither, scan.ColorDepth)
+		d.hub.ClearDisplay()
+		d.hub.Resume()
+		d.page.redraw()
+		d.notice.shown = false
+		d.timing, d.fault = try, time.Time{}
+		model.Set(func(m *model.Model) {
+			m.Diagnostics.Timing = try.String()
+		})
+		return fail
+	}
+	d.fault = time.Now()
+	return fail
+}
+
+// retry reconfigures the panel if it could not be configured previously and
+// DefaultRetry has elapsed. Returns true if the panel remains faulted, in which
+// case nothing should be drawn.
+func (d *Display) retry(now time.Time) bool {
+	if d.fault.IsZero() {
+		return false
+	}
+	if now.Sub(d.fault) >= DefaultRetry {
+		journal.Println("display: reconfiguring")
+		d.reconfigure(d.timing)
+	}
+	return !d.fault.IsZero()
+}
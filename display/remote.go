@@ -0,0 +1,50 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// remotePage shows the temperature and humidity of each remote sensor, one per
+// row. The name of a sensor whose battery is low is shown in red.
+type remotePage struct{}
+
+func (p *remotePage) Active(data model.Model) bool { return data.Remote.Any() }
+
+func (p *remotePage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+
+	row := int16(1)
+	for _, r := range data.Remote {
+		if !r.Valid() {
+			continue
+		}
+		ty := 2 + row*rowHeight
+		row++
+		d.fillRect(0, ty-rowHeight, width, rowHeight,
+			color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+
+		t, sym := d.units.Temp(r.Temperature)
+		value := append(appendFixed(d.text(), t, 1), sym[len(sym)-1])
+		if model.NoHumidity != r.Humidity {
+			value = append(appendInt(append(value, ' '), int(r.Humidity)), '%')
+		}
+		vx := width - textWidth(value)
+		drawText(d.hub, vx, ty, value, d.theme.Value)
+
+		c := d.theme.Text
+		if r.LowBattery {
+			c = color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF}
+		}
+		tinyfont.WriteLine(d.hub, &font, 0, ty, ellipsis(r.Name, vx-2), c)
+	}
+	// erase the rows of any sensors no longer shown
+	for ; row <= model.MaxRemotes; row++ {
+		d.fillRect(0, 2+(row-1)*rowHeight, width, rowHeight,
+			color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	}
+}
@@ -0,0 +1,165 @@
+package display
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/rgb75"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// update rewrites the expected images with those drawn, e.g., after changing
+// the layout of a page intentionally:
+//
+//	go test ./display -update
+var update = flag.Bool("update", false, "rewrite the images in testdata")
+
+// canvas is a driver drawing on an image in memory, in place of the panel.
+type canvas struct {
+	*image.RGBA
+}
+
+func newCanvas() canvas {
+	c := canvas{image.NewRGBA(image.Rect(0, 0, DefaultWidth, DefaultHeight))}
+	c.ClearDisplay()
+	return c
+}
+
+func (c canvas) Configure(config rgb75.Config) error { return nil }
+func (c canvas) Display() error                      { return nil }
+func (c canvas) Resume()                             {}
+func (c canvas) Pause()                              {}
+
+// SetPixel ignores the alpha of px, as does the panel, so that the image is
+// opaque.
+func (c canvas) SetPixel(x, y int16, px color.RGBA) {
+	c.SetRGBA(int(x), int(y), color.RGBA{R: px.R, G: px.G, B: px.B, A: 0xFF})
+}
+
+func (c canvas) GetPixel(x, y int16) color.RGBA { return c.RGBAAt(int(x), int(y)) }
+
+func (c canvas) ClearDisplay() {
+	for i := range c.Pix {
+		c.Pix[i] = 0x00
+		if 3 == i%4 {
+			c.Pix[i] = 0xFF
+		}
+	}
+}
+
+// sample returns the Model shown by each image, on an ordinary day, i.e., not a
+// holiday with a theme of its own.
+func sample(status model.Status) model.Model {
+	now := time.Date(2024, time.May, 15, 10, 9, 30, 0, time.FixedZone("EDT", -4*3600))
+	return model.Model{
+		Time:   now,
+		Status: status,
+		Weather: model.Weather{
+			Updated: now,
+			Current: model.Conditions{
+				Temperature: 21.5,
+				Humidity:    48,
+				WindSpeed:   3.2,
+				Code:        model.CodeMainlyClear,
+			},
+		},
+	}
+}
+
+// compare fails the test unless the image drawn matches testdata/<name>.png,
+// or rewrites the expected image if requested by flag -update.
+func compare(t *testing.T, name string, got canvas) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		f, err := os.Create(path)
+		if nil != err {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := png.Encode(f, got); nil != err {
+			t.Fatal(err)
+		}
+		return
+	}
+	f, err := os.Open(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if want.Bounds() != got.Bounds() {
+		t.Fatalf("%s: size %v, want %v", name, got.Bounds(), want.Bounds())
+	}
+	var diff int
+	var first image.Point
+	for y := 0; y < DefaultHeight; y++ {
+		for x := 0; x < DefaultWidth; x++ {
+			w := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+			if w != got.RGBAAt(x, y) {
+				if 0 == diff {
+					first = image.Pt(x, y)
+				}
+				diff++
+			}
+		}
+	}
+	if diff > 0 {
+		t.Errorf("%s: %d pixels differ from %s, first at %v: %v, want %v",
+			name, diff, path, first, got.RGBAAt(first.X, first.Y),
+			color.RGBAModel.Convert(want.At(first.X, first.Y)))
+	}
+}
+
+func TestRenderStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status model.Status
+	}{
+		{"disconnected", model.StatusDisconnected},
+		{"connecting", model.StatusConnecting},
+		{"unsynchronized", model.StatusUnsynchronized},
+	} {
+		c := newCanvas()
+		d, err := newDisplay(c, Config{})
+		if nil != err {
+			t.Fatal(err)
+		}
+		d.Update(sample(tc.status))
+		compare(t, "status-"+tc.name, c)
+	}
+}
+
+func TestRenderPage(t *testing.T) {
+	faces := []Face{FaceDigital, FaceAnalog, FaceBinary, FaceDual}
+	for _, name := range []string{"clock", "analog", "binary", "dual", "moon"} {
+		c := newCanvas()
+		d, err := newDisplay(c, Config{Faces: faces})
+		if nil != err {
+			t.Fatal(err)
+		}
+		data := sample(model.StatusSynchronized)
+		d.theme = d.themes.For(data.Time)
+		k := 0
+		for k < len(d.page.name) && name != d.page.name[k] {
+			k++
+		}
+		if k == len(d.page.name) {
+			t.Fatalf("no page %q", name)
+		}
+		// drawn as on its first appearance in the carousel.
+		d.clearPage()
+		d.page.page[k].Draw(d, data, true)
+		compare(t, "page-"+name, c)
+	}
+}
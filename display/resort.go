@@ -0,0 +1,89 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// skiPage shows the snow conditions at the configured ski resort in season.
+type skiPage struct{}
+
+func (p *skiPage) Active(data model.Model) bool { return data.Ski.Active }
+
+func (p *skiPage) Draw(d *Display, data model.Model, clear bool) {
+	ski := data.Ski
+	if clear {
+		title := ski.Name
+		if "" == title {
+			title = "Ski"
+		}
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, title,
+			d.theme.Title)
+	}
+	depth, sym := d.units.Snow(ski.Depth)
+	d.valueRow(2, "Depth", append(appendInt(d.text(), int(depth+0.5)), sym...), clear)
+	fall, sym := d.units.Snow(ski.Snowfall)
+	d.valueRow(3, "New", append(appendInt(d.text(), int(fall+0.5)), sym...), clear)
+	temp, sym := d.units.Temp(ski.Temperature)
+	d.valueRow(4, "Temp", append(appendInt(d.text(), int(temp)), sym...), clear)
+}
+
+// surfPage shows the wave conditions at the configured surf break in season.
+type surfPage struct{}
+
+func (p *surfPage) Active(data model.Model) bool { return data.Surf.Active }
+
+func (p *surfPage) Draw(d *Display, data model.Model, clear bool) {
+	surf := data.Surf
+	if clear {
+		title := surf.Name
+		if "" == title {
+			title = "Surf"
+		}
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, title,
+			d.theme.Title)
+	}
+	waves, sym := d.units.Elevation(surf.Height)
+	d.valueRow(2, "Waves", append(appendFixed(d.text(), waves, 1), sym...), clear)
+	d.valueRow(3, "Period", append(appendInt(d.text(), int(surf.Period+0.5)), 's'), clear)
+	d.valueRow(4, "From", append(d.text(), compass(surf.Direction)...), clear)
+}
+
+// valueRow draws a row of text with a label on the left and a value aligned to
+// the right, replacing the value previously drawn. The label is only drawn if
+// the page was cleared.
+func (d *Display) valueRow(row int16, label string, value []byte, clear bool) {
+	width, _ := d.hub.Size()
+	ty := 2 + row*rowHeight
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, ty, label, d.theme.Text)
+	}
+	// tinyfont.LineWidth panics on empty text, e.g., the second row of a value.
+	var lw uint32
+	if "" != label {
+		_, lw = tinyfont.LineWidth(&font, label)
+	}
+	d.fillRect(int16(lw)+1, ty-rowHeight, width-int16(lw)-1, rowHeight,
+		color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	drawText(d.hub, width-textWidth(value), ty, value, d.theme.Value)
+}
+
+// compassPoints defines the names of the 16 points of the compass, clockwise
+// from north.
+var compassPoints = [16]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// compass returns the point of the compass nearest the given direction, in
+// degrees clockwise from north.
+func compass(deg float32) string {
+	i := int(deg/22.5+0.5) % 16
+	if i < 0 {
+		i += 16
+	}
+	return compassPoints[i]
+}
@@ -0,0 +1,104 @@
+package display
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ardnew/weatherhub/theme"
+)
+
+var (
+	ErrUnknownScene = errors.New("unknown scene")
+)
+
+// Scene is a named bundle of display settings applied together, e.g., a dim
+// red clock for watching a movie. The zero value of each field restores the
+// configuration of the Display: its brightness, its theme, and every page.
+type Scene struct {
+	Name       string
+	Brightness int      // percent, 1-100
+	Theme      string   // name of a built-in theme, used even on special dates
+	Pages      []string // names of the only pages shown in the carousel
+}
+
+// DefaultScenes defines the scenes available unless configured otherwise.
+var DefaultScenes = []Scene{
+	{Name: "normal"},
+	{Name: "movie", Brightness: 5, Theme: theme.Night.Name, Pages: []string{"clock"}},
+	{Name: "party", Brightness: 100, Theme: "new-year"},
+	{Name: "ambient", Brightness: 5, Pages: []string{"wash"}},
+}
+
+// Scenes returns the names of the scenes, in the order configured.
+func (d *Display) Scenes() []string {
+	name := make([]string, len(d.config.Scenes))
+	for i, s := range d.config.Scenes {
+		name[i] = s.Name
+	}
+	return name
+}
+
+// Scene returns the name of the scene most recently applied, or "" if none.
+func (d *Display) Scene() string { return d.scene }
+
+// SetScene applies the scene with the given name. The theme and hidden pages of
+// the scene remain in effect until either is changed, e.g., by the Settings.
+// Returns ErrUnknownScene, without changing anything, if name is unknown or the
+// scene names an unknown theme or page.
+func (d *Display) SetScene(name string) error {
+	var s *Scene
+	for i := range d.config.Scenes {
+		if name == d.config.Scenes[i].Name {
+			s = &d.config.Scenes[i]
+		}
+	}
+	if nil == s {
+		return ErrUnknownScene
+	}
+
+	config := d.config.Theme
+	if "" != s.Theme {
+		t, ok := theme.Lookup(s.Theme)
+		if !ok {
+			return ErrUnknownScene
+		}
+		config = theme.Config{Base: t, NoHolidays: true}
+	}
+	for _, n := range s.Pages {
+		if !contains(d.page.name, n) {
+			return ErrUnknownScene
+		}
+	}
+	var hidden []string
+	if 0 != len(s.Pages) {
+		for _, n := range d.page.name {
+			if !contains(s.Pages, n) {
+				hidden = append(hidden, n)
+			}
+		}
+	}
+
+	brightness := s.Brightness
+	if 0 == brightness {
+		brightness = d.config.Brightness
+	}
+	d.SetBrightness(brightness)
+	d.SetHidden(hidden) // cannot fail, since every name is known
+	d.themes = theme.New(config)
+	// selected now rather than by the next Update, since pages may be drawn or
+	// animated before then.
+	d.theme = d.themes.For(time.Now())
+	d.page.redraw()
+	d.scene = name
+	return nil
+}
+
+// contains returns true if name is one of names.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package display
+
+import (
+	"image/color"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// schedulePage shows the times of today's sun-relative events, e.g., prayer
+// times, with the next event highlighted and those that have occurred dimmed.
+type schedulePage struct {
+	shown model.Schedule // schedule drawn
+	next  int            // index of the next event drawn
+}
+
+func (p *schedulePage) Active(data model.Model) bool { return data.Schedule.Active }
+
+func (p *schedulePage) Draw(d *Display, data model.Model, clear bool) {
+
+	sched := data.Schedule
+	next := sched.Next(data.Time)
+	if sched != p.shown || next != p.next {
+		p.shown, p.next, clear = sched, next, true
+	}
+	if !clear {
+		return
+	}
+
+	width, _ := d.hub.Size()
+	d.fillRect(0, 2, width, int16(len(sched.Event))*rowHeight,
+		color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	for i, e := range sched.Event {
+		if "" == e.Name {
+			continue
+		}
+		name, at := d.theme.Text, d.theme.Value
+		switch {
+		case i == next:
+			name = d.theme.Title
+		case !e.Time.IsZero() && !e.Time.After(data.Time):
+			name, at = dim(name), dim(at)
+		}
+		ty := 2 + int16(i+1)*rowHeight
+		drawText(d.hub, 0, ty, append(d.text(), e.Name...), name)
+		clock := append(d.text(), "--:--"...)
+		if !e.Time.IsZero() {
+			clock = appendClock(d.text(), e.Time, false)
+		}
+		drawText(d.hub, width-textWidth(clock), ty, clock, at)
+	}
+}
+
+// dim returns the color c at half its brightness.
+func dim(c color.RGBA) color.RGBA {
+	return color.RGBA{R: c.R / 2, G: c.G / 2, B: c.B / 2, A: c.A}
+}
@@ -0,0 +1,52 @@
+package display
+
+import (
+	"time"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// scoresPage shows the current score of each game reported by the scoreboard.
+// Rows too long to fit on the display scroll horizontally.
+type scoresPage struct {
+	row  [model.MaxGames]marquee
+	game [model.MaxGames]model.Game // games shown in each row
+}
+
+func (p *scoresPage) Active(data model.Model) bool { return data.Scores.Active() }
+
+func (p *scoresPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, "Scores",
+			d.theme.Title)
+	}
+
+	for i, g := range data.Scores.Game {
+		m := &p.row[i]
+		if clear || g != p.game[i] {
+			// joining the fields allocates, so only join them when changed.
+			p.game[i] = g
+			text := ""
+			if "" != g.Home || "" != g.Away {
+				text = g.Away + " " + g.AwayScore + " " + g.Home + " " + g.HomeScore
+				if "" != g.Status {
+					text += " " + g.Status
+				}
+			}
+			m.set(text, d.theme.Text)
+		}
+		m.x, m.y, m.w = 0, 2+int16(i+2)*rowHeight, width
+		m.draw(d)
+	}
+}
+
+func (p *scoresPage) Animate(d *Display, now time.Time) {
+	for i := range p.row {
+		p.row[i].step(d, now)
+	}
+}
@@ -0,0 +1,107 @@
+package display
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/theme"
+)
+
+var (
+	ErrUnknownPage  = errors.New("unknown page")
+	ErrUnknownTheme = errors.New("unknown theme")
+)
+
+// Pages returns the names of the pages of the carousel, in the order shown.
+func (d *Display) Pages() []string {
+	return d.page.name
+}
+
+// SetHidden omits the pages with the given names from the carousel, and shows
+// all others. The current page is redrawn entirely, in case it was hidden.
+// Returns ErrUnknownPage, without hiding any page, if any name is unknown.
+func (d *Display) SetHidden(name []string) error {
+	hidden := make([]bool, len(d.page.name))
+	for _, n := range name {
+		k := 0
+		for k < len(d.page.name) && n != d.page.name[k] {
+			k++
+		}
+		if k == len(d.page.name) {
+			return ErrUnknownPage
+		}
+		hidden[k] = true
+	}
+	d.page.hidden = hidden
+	d.page.redraw()
+	return nil
+}
+
+// NextPage advances the carousel to the next page on the next Update, unless
+// a page holding the display, e.g., a running stopwatch, is shown. A pinned
+// page is unpinned.
+func (d *Display) NextPage() {
+	d.page.skip()
+}
+
+// Pinned returns true if the current page is pinned.
+func (d *Display) Pinned() bool { return d.page.pinned }
+
+// SetPinned pins the current page, so that the carousel remains on it until
+// unpinned, e.g., to watch the weather while a storm passes, or unpins it.
+// The page is unpinned by itself once it is no longer active, e.g., hidden.
+// A page holding the display, e.g., a running stopwatch, is still shown in
+// place of a pinned page.
+func (d *Display) SetPinned(pinned bool) {
+	d.page.pinned = pinned && d.page.index >= 0
+}
+
+// Locale returns the conventions of dates shown by pages.
+func (d *Display) Locale() model.Locale { return d.locale }
+
+// SetLocale changes the conventions of dates shown by pages, each default field
+// of locale restoring that of the configured Locale, and redraws the current
+// page entirely.
+func (d *Display) SetLocale(locale model.Locale) {
+	if locale = locale.Or(d.config.Locale); locale != d.locale {
+		d.locale = locale
+		d.page.redraw()
+	}
+}
+
+// Units returns the units of quantities shown by pages.
+func (d *Display) Units() model.Units { return d.units }
+
+// SetUnits changes the units of quantities shown by pages, or restores the
+// configured units if units is the zero value, and redraws the current page
+// entirely.
+func (d *Display) SetUnits(units model.Units) {
+	if (model.Units{}) == units {
+		units = d.config.Units
+	}
+	if units != d.units {
+		d.units = units
+		d.page.redraw()
+	}
+}
+
+// SetTheme changes the theme used on ordinary days to the built-in theme with
+// the given name, or restores the configured theme if name is empty. Themes of
+// special dates still take precedence.
+func (d *Display) SetTheme(name string) error {
+	config := d.config.Theme
+	if "" != name {
+		t, ok := theme.Lookup(name)
+		if !ok {
+			return ErrUnknownTheme
+		}
+		config.Base = t
+	}
+	d.themes = theme.New(config)
+	// selected now rather than by the next Update, since pages may be drawn or
+	// animated before then.
+	d.theme = d.themes.For(time.Now())
+	d.page.redraw()
+	return nil
+}
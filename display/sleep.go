@@ -0,0 +1,46 @@
+package display
+
+import (
+	"time"
+)
+
+// sleep holds the hours of the day the panel is off (see SetSleep).
+type sleep struct {
+	from, until int
+	asleep      bool // the panel was turned off as the window began
+	known       bool // asleep was determined since the window last changed
+}
+
+// SetSleep changes the hours of the day the panel is off, from the beginning of
+// hour from until the beginning of hour until, 0-23, which may span midnight.
+// The panel never sleeps if both are equal.
+//
+// The panel is only turned off and on as the window begins and ends, so that it
+// may still be turned on or off otherwise in between, e.g., by Home Assistant.
+func (d *Display) SetSleep(from, until int) {
+	if d.sleep.asleep {
+		d.SetPower(true)
+	}
+	d.sleep = sleep{from: from, until: until}
+}
+
+// doze turns the panel off or on if the sleep window began or ended before the
+// given time of day.
+func (d *Display) doze(now time.Time) {
+	s := &d.sleep
+	if s.from == s.until {
+		return
+	}
+	h := now.Hour()
+	in := h >= s.from && h < s.until
+	if s.from > s.until {
+		in = h >= s.from || h < s.until // spans midnight
+	}
+	if s.known && in == s.asleep {
+		return
+	}
+	if in || s.known {
+		d.SetPower(!in)
+	}
+	s.known, s.asleep = true, in
+}
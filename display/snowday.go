@@ -0,0 +1,56 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// snowdayPage shows the "snow day-o-meter", a gauge of the chance that school
+// is canceled on the next school morning.
+type snowdayPage struct{}
+
+func (p *snowdayPage) Active(data model.Model) bool { return data.SnowDay.Chance > 0 }
+
+func (p *snowdayPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+	sd := data.SnowDay
+
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight,
+			"Snow day "+sd.Morning.Weekday().String()[:3]+"?", d.theme.Title)
+	}
+
+	// gauge: a bar filled in proportion to the chance, from cool to hot colors
+	var (
+		gx, gy, gw, gh int16 = 0, 2 + rowHeight + 1, width - 4*4, rowHeight - 2
+		fill                 = gw * int16(sd.Chance) / 100
+	)
+	d.fillRect(gx, gy, gw, gh, color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xFF})
+	d.fillRect(gx, gy, fill, gh, gaugeColor(sd.Chance))
+
+	pct := append(appendInt(d.text(), int(sd.Chance)), '%')
+	d.fillRect(gx+gw, gy-1, width-gw, rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	drawText(d.hub, width-textWidth(pct), 2+2*rowHeight, pct, d.theme.Value)
+
+	// details: expected snowfall, overnight low, and ice
+	snow, sym := d.units.Snow(sd.Snow)
+	detail := append(append(appendFixed(d.text(), snow, 1), sym...), ' ')
+	low, sym := d.units.Temp(sd.Low)
+	detail = append(appendInt(detail, int(low)), sym...)
+	if sd.Ice {
+		detail = append(detail, " ice"...)
+	}
+	d.fillRect(0, 2+2*rowHeight, width, rowHeight, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+	drawText(d.hub, 0, 2+3*rowHeight, detail, d.theme.Text)
+}
+
+// gaugeColor returns a color ranging from blue (0%) to white (100%), in the
+// spirit of ever-deeper snow.
+func gaugeColor(chance uint8) color.RGBA {
+	c := uint8(uint16(chance) * 0xFF / 100)
+	return color.RGBA{R: c, G: 0x40 + uint8(uint16(c)*3/4), B: 0xFF, A: 0xFF}
+}
@@ -0,0 +1,89 @@
+package display
+
+import (
+	"image/color"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// stopwatchScale defines the scale of the digits of the stopwatch page, which
+// span rows 2 and 3 of text.
+const stopwatchScale = 2
+
+// stopwatchPage shows the elapsed time of the Stopwatch in large digits, with
+// centisecond precision, and the duration of the most recent lap. The carousel
+// remains on the page while the Stopwatch is running.
+type stopwatchPage struct {
+	watch model.Stopwatch
+	shown [12]byte // elapsed time drawn
+	n     int      // length of shown, or 0 if not drawn
+	laps  int      // number of laps drawn
+}
+
+func (p *stopwatchPage) Active(data model.Model) bool { return data.Stopwatch.Active() }
+
+func (p *stopwatchPage) Hold(data model.Model) bool { return data.Stopwatch.Running }
+
+func (p *stopwatchPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+
+	p.watch = data.Stopwatch
+	if clear {
+		p.n = 0
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, "Stopwatch",
+			d.theme.Title)
+	}
+
+	// the lap number changes, so the entire row is redrawn.
+	if clear || p.watch.Laps != p.laps {
+		p.laps = p.watch.Laps
+		d.fillRect(0, 2+3*rowHeight, width, rowHeight,
+			color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+		if lap, ok := p.watch.Lap(p.watch.Laps); ok {
+			d.valueRow(4, "Lap "+strconv.Itoa(p.watch.Laps),
+				appendStopwatch(d.text(), lap), true)
+		}
+	}
+	p.Animate(d, time.Now())
+}
+
+// Animate redraws the digits of the elapsed time that have changed.
+func (p *stopwatchPage) Animate(d *Display, now time.Time) {
+
+	width, _ := d.hub.Size()
+
+	var text [12]byte
+	t := appendStopwatch(text[:0], p.watch.At(now))
+	if len(t) != p.n {
+		// the positions of the digits have changed, so erase them all.
+		d.fillRect(0, 2+rowHeight, width, 2*rowHeight,
+			color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+		p.n = 0
+	}
+	x := (width - largeWidth(t, stopwatchScale)) / 2
+	for i := range t {
+		if i >= p.n || t[i] != p.shown[i] {
+			d.drawLarge(x, 2+3*rowHeight, t[i:i+1], stopwatchScale, d.theme.Time)
+		}
+		x += largeWidth(t[i:i+1], stopwatchScale)
+	}
+	p.n = copy(p.shown[:], t)
+}
+
+// appendStopwatch appends the elapsed time e to b, formatted as "04:05.67" if
+// less than an hour, otherwise as "1:04:05".
+func appendStopwatch(b []byte, e time.Duration) []byte {
+	cs := int(e / (10 * time.Millisecond))
+	h, m, s := cs/360000, cs/6000%60, cs/100%60
+	if h > 0 {
+		b = append(appendInt(b, h), ':')
+		return appendTwo(append(appendTwo(b, m), ':'), s)
+	}
+	b = append(appendTwo(b, m), ':')
+	return appendTwo(append(appendTwo(b, s), '.'), cs%100)
+}
@@ -0,0 +1,48 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinyfont"
+)
+
+// TextStyle is the rendering of a text field, which may be edged in a
+// contrasting color to remain legible over graphics or animation beneath it,
+// at the cost of drawing the text several times.
+type TextStyle uint8
+
+// Constants defining each TextStyle.
+const (
+	StylePlain   TextStyle = iota // text alone
+	StyleOutline                  // text surrounded by a 1-pixel outline
+	StyleShadow                   // text over a shadow offset 1 pixel down and right
+)
+
+// outline defines the offsets at which the edge of StyleOutline is drawn.
+var outline = [8][2]int16{
+	{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1},
+}
+
+// edgeColor returns the color of the outline or shadow of text in color c:
+// black for bright text, otherwise white.
+func edgeColor(c color.RGBA) color.RGBA {
+	if luminance(c) >= 0x80 {
+		return color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00}
+	}
+	return color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+}
+
+// writeStyled draws text with its baseline at y in color c, as
+// tinyfont.WriteLine, edged in color edge as given by style.
+func writeStyled(disp drivers.Displayer, x, y int16, text string, c, edge color.RGBA, style TextStyle) {
+	switch style {
+	case StyleOutline:
+		for _, o := range outline {
+			tinyfont.WriteLine(disp, &font, x+o[0], y+o[1], text, edge)
+		}
+	case StyleShadow:
+		tinyfont.WriteLine(disp, &font, x+1, y+1, text, edge)
+	}
+	tinyfont.WriteLine(disp, &font, x, y, text, c)
+}
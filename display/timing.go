@@ -0,0 +1,74 @@
+package display
+
+// Timing is a preset of the parameters controlling how the HUB75 panel is
+// scanned.
+//
+// The rgb75 driver derives its refresh rate and the blanking interval of each
+// row from the color depth: every additional bit of depth doubles the time
+// spent on the longest bit plane, halving the refresh rate. Panels that
+// flicker at the default depth are improved with fewer bits, and panels that
+// ghost or tear while the pages are being drawn are improved with double
+// buffering, at the cost of twice the memory.
+type Timing uint8
+
+// Constants defining each Timing preset.
+const (
+	TimingDefault Timing = iota // Config ColorDepth and DoubleBuffer, as given
+	TimingSmooth                // 3-bit depth, highest refresh rate
+	TimingColor                 // 5-bit depth, for panels with little flicker
+	TimingSafe                  // 3-bit depth with double buffering
+)
+
+// String returns the name of the Timing preset.
+func (t Timing) String() string {
+	switch t {
+	case TimingSmooth:
+		return "smooth"
+	case TimingColor:
+		return "color"
+	case TimingSafe:
+		return "safe"
+	}
+	return "default"
+}
+
+// ParseTiming parses the name of a Timing preset: "default", "smooth",
+// "color", or "safe".
+func ParseTiming(s string) (Timing, bool) {
+	switch s {
+	case "default":
+		return TimingDefault, true
+	case "smooth":
+		return TimingSmooth, true
+	case "color":
+		return TimingColor, true
+	case "safe":
+		return TimingSafe, true
+	}
+	return TimingDefault, false
+}
+
+// apply returns a copy of the given configuration with the color depth and
+// buffering of the Timing preset.
+func (t Timing) apply(config Config) Config {
+	switch t {
+	case TimingSmooth:
+		config.ColorDepth, config.DoubleBuffer = 3, false
+	case TimingColor:
+		config.ColorDepth, config.DoubleBuffer = 5, false
+	case TimingSafe:
+		config.ColorDepth, config.DoubleBuffer = 3, true
+	}
+	return config
+}
+
+// Timing returns the Timing preset currently in effect.
+func (d *Display) Timing() Timing { return d.timing }
+
+// SetTiming reconfigures the panel using the given Timing preset. The panel is
+// paused while reconfigured, and the current page is then redrawn entirely.
+// If the panel cannot be configured with the preset, the preset previously in
+// effect is restored (see reconfigure).
+func (d *Display) SetTiming(t Timing) error {
+	return d.reconfigure(t)
+}
@@ -0,0 +1,43 @@
+package display
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// transitPage shows the next departures from the configured transit stop
+// during commute hours.
+type transitPage struct{}
+
+func (p *transitPage) Active(data model.Model) bool { return data.Transit.Active }
+
+func (p *transitPage) Draw(d *Display, data model.Model, clear bool) {
+
+	width, _ := d.hub.Size()
+
+	if clear {
+		tinyfont.WriteLine(d.hub, &font, 0, 2+rowHeight, "Departures",
+			d.theme.Title)
+	}
+
+	// the countdown changes every minute, so always redraw the departure rows.
+	for i, dep := range data.Transit.Departure {
+		var (
+			ty             int16 = 2 + int16(i+3)*rowHeight
+			px, py, pw, ph int16 = 0, ty - rowHeight, width, rowHeight
+		)
+		d.fillRect(px, py, pw, ph, color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x00})
+		if "" == dep.Route {
+			continue
+		}
+		tinyfont.WriteLine(d.hub, &font, 0, ty, dep.Route, d.theme.Text)
+		due := append(d.text(), "Due"...)
+		if mins := int(dep.Time.Sub(data.Time).Minutes()); mins > 0 {
+			due = append(appendInt(d.text(), mins), " min"...)
+		}
+		drawText(d.hub, width-textWidth(due), ty, due, d.theme.Value)
+	}
+}
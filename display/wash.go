@@ -0,0 +1,97 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// washPeriod defines how long a wave of the color wash takes to cross the
+// panel.
+const washPeriod = 64 * time.Second
+
+// washStop is a temperature and its color in the gradient of the color wash.
+type washStop struct {
+	temp  float32 // degrees Celsius
+	color color.RGBA
+}
+
+// washGradient defines the colors of temperatures, from deep blue for bitter
+// cold to red for extreme heat. Temperatures between stops are interpolated.
+var washGradient = [...]washStop{
+	{-20, color.RGBA{R: 0x00, G: 0x00, B: 0x60, A: 0xFF}},
+	{-5, color.RGBA{R: 0x00, G: 0x40, B: 0xFF, A: 0xFF}},
+	{10, color.RGBA{R: 0x00, G: 0xC0, B: 0x80, A: 0xFF}},
+	{20, color.RGBA{R: 0xFF, G: 0xC0, B: 0x00, A: 0xFF}},
+	{30, color.RGBA{R: 0xFF, G: 0x40, B: 0x00, A: 0xFF}},
+	{40, color.RGBA{R: 0xC0, G: 0x00, B: 0x00, A: 0xFF}},
+}
+
+// washPage fills the panel with the color of the current temperature, drifting
+// slowly between lighter and darker shades, so that the display conveys the
+// weather even when too dim to read, e.g., at night.
+//
+// The page is a Standby, only shown while every other page is hidden, e.g., by
+// the "ambient" scene (see DefaultScenes).
+type washPage struct {
+	color color.RGBA // of the current temperature
+	step  int64      // of the wave drawn, or -1 to draw it again
+}
+
+func (p *washPage) Active(data model.Model) bool { return data.Weather.Valid() }
+
+func (p *washPage) Standby() {}
+
+func (p *washPage) Draw(d *Display, data model.Model, clear bool) {
+	if c := washColor(data.Weather.Current.Temperature); clear || c != p.color {
+		p.color, p.step = c, -1
+		p.Animate(d, data.Time)
+	}
+}
+
+// Animate shifts the wave of lighter and darker shades one column across the
+// panel at a time.
+func (p *washPage) Animate(d *Display, now time.Time) {
+	width, height := d.hub.Size()
+	step := now.UnixNano() / int64(washPeriod/time.Duration(width))
+	if step == p.step {
+		return
+	}
+	p.step = step
+	for x := int16(0); x < width; x++ {
+		// a triangle wave across the panel, shading the color from 5/8 to full.
+		t := int((int64(x) + step) % int64(width))
+		if t > int(width)/2 {
+			t = int(width) - t
+		}
+		shade, full := 5*int(width)/2+3*t, 4*int(width)
+		c := p.color
+		c.R = uint8(int(c.R) * shade / full)
+		c.G = uint8(int(c.G) * shade / full)
+		c.B = uint8(int(c.B) * shade / full)
+		d.fillRect(x, 0, 1, height, c)
+	}
+}
+
+// washColor returns the color of the given temperature, in degrees Celsius, in
+// the gradient of the color wash.
+func washColor(temp float32) color.RGBA {
+	if temp <= washGradient[0].temp {
+		return washGradient[0].color
+	}
+	for i := 1; i < len(washGradient); i++ {
+		hi := washGradient[i]
+		if temp < hi.temp {
+			lo := washGradient[i-1]
+			f := (temp - lo.temp) / (hi.temp - lo.temp)
+			return color.RGBA{
+				R: uint8(float32(lo.color.R) + f*(float32(hi.color.R)-float32(lo.color.R))),
+				G: uint8(float32(lo.color.G) + f*(float32(hi.color.G)-float32(lo.color.G))),
+				B: uint8(float32(lo.color.B) + f*(float32(hi.color.B)-float32(lo.color.B))),
+				A: 0xFF,
+			}
+		}
+	}
+	return washGradient[len(washGradient)-1].color
+}
@@ -0,0 +1,118 @@
+package display
+
+import (
+	"image/color"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// wordGrid defines the letters of the word clock page, one string per row of
+// text, in which every word needed to spell the time to the nearest five
+// minutes may be lit. Some words share letters, e.g., "EIGHTWO", so that the
+// grid fits the display. Letters not in any word are filler.
+var wordGrid = [5]string{
+	"QUARTERTWENTYTEN",
+	"FIVEHALFPASTXTOZ",
+	"ONEIGHTWOFOURSIX",
+	"THREELEVENTWELVE",
+	"SEVENINEFIVETENX",
+}
+
+// word is the position of a word in wordGrid.
+type word struct {
+	row, col, len int16
+}
+
+// Words of wordGrid spelling the minutes past or to the hour.
+var (
+	wordQuarter = word{0, 0, 7}
+	wordTwenty  = word{0, 7, 6}
+	wordTen     = word{0, 13, 3}
+	wordFive    = word{1, 0, 4}
+	wordHalf    = word{1, 4, 4}
+	wordPast    = word{1, 8, 4}
+	wordTo      = word{1, 13, 2}
+)
+
+// wordHours defines the words of wordGrid spelling each hour, 1 through 12.
+var wordHours = [12]word{
+	{2, 0, 3}, {2, 6, 3}, {3, 0, 5}, {2, 9, 4}, {4, 8, 4}, {2, 13, 3},
+	{4, 0, 5}, {2, 2, 5}, {4, 4, 4}, {4, 12, 3}, {3, 4, 6}, {3, 10, 6},
+}
+
+// wordDark defines the color of letters not lit, so that the grid remains
+// visible.
+var wordDark = color.RGBA{R: 0x18, G: 0x18, B: 0x20, A: 0xFF}
+
+// wordsPage shows the time of day to the nearest five minutes in words, e.g.,
+// "TWENTY FIVE TO SIX", by lighting letters in a grid.
+type wordsPage struct {
+	slot int // five-minute interval of the day drawn
+}
+
+func (p *wordsPage) Active(data model.Model) bool { return true }
+
+func (p *wordsPage) Draw(d *Display, data model.Model, clear bool) {
+
+	t := data.Time
+	slot := (t.Hour()*60 + t.Minute() + 2) / 5 // nearest, rounded half up
+	if !clear && slot == p.slot {
+		return
+	}
+	p.slot = slot
+
+	// every letter is redrawn in its color, replacing the pixels of the same
+	// glyph drawn previously, so nothing needs to be erased.
+	var lit [len(wordGrid)]uint16 // bit set for each lit column of each row
+	for _, w := range spell(slot) {
+		for c := w.col; c < w.col+w.len; c++ {
+			lit[w.row] |= 1 << uint(c)
+		}
+	}
+	width, _ := d.hub.Size()
+	x0 := (width - int16(len(wordGrid[0]))*4) / 2
+	for r, letters := range wordGrid {
+		for c := 0; c < len(letters); c++ {
+			col := wordDark
+			if 0 != lit[r]&(1<<uint(c)) {
+				col = d.theme.Time
+			}
+			drawText(d.hub, x0+int16(c)*4, 5+int16(r)*rowHeight,
+				[]byte{letters[c]}, col)
+		}
+	}
+}
+
+// spell returns the words spelling the time of day of the given five-minute
+// interval, e.g., slot 7 (00:35) is "TWENTY FIVE TO ONE".
+func spell(slot int) []word {
+	minute, hour := slot%12*5, slot/12
+	var w []word
+	switch {
+	case 0 == minute:
+	case minute <= 30:
+		w = append(minuteWords(minute), wordPast)
+	default:
+		w = append(minuteWords(60-minute), wordTo)
+		hour++
+	}
+	return append(w, wordHours[(hour+11)%12])
+}
+
+// minuteWords returns the words spelling the given minutes, a multiple of five
+// no greater than 30.
+func minuteWords(minute int) []word {
+	switch minute {
+	case 5:
+		return []word{wordFive}
+	case 10:
+		return []word{wordTen}
+	case 15:
+		return []word{wordQuarter}
+	case 20:
+		return []word{wordTwenty}
+	case 25:
+		return []word{wordTwenty, wordFive}
+	}
+	return []word{wordHalf}
+}
@@ -0,0 +1,97 @@
+package display
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/tz"
+)
+
+// MaxClocks defines the number of time zones shown by the world clock page.
+const MaxClocks = 4
+
+var (
+	ErrTooManyClocks = errors.New("too many world clocks")
+	ErrUnknownZone   = errors.New("unknown time zone")
+)
+
+// labelWidth defines the width of the labels of the world clock page, in
+// pixels, beyond which they are shortened.
+const labelWidth = 32
+
+// worldPage shows the local time in each of several time zones, e.g.,
+// "Tokyo 04:30+1", with the difference of its date from the local date, if any.
+// The page is only shown if any time zones are configured.
+type worldPage struct {
+	clocks []model.WorldClock
+	minute time.Time // minute drawn
+}
+
+func (p *worldPage) Active(data model.Model) bool { return 0 != len(p.clocks) }
+
+func (p *worldPage) Draw(d *Display, data model.Model, clear bool) {
+	minute := data.Time.Truncate(time.Minute)
+	if !clear && minute.Equal(p.minute) {
+		return
+	}
+	p.minute = minute
+
+	today := civil(data.Time)
+	for i, c := range p.clocks {
+		z, ok := tz.Lookup(c.Zone)
+		if !ok {
+			continue // rejected by SetClocks
+		}
+		t := data.Time.In(z.Location(data.Time))
+		text := appendClock(d.text(), t, false)
+		switch day := civil(t); {
+		case day.After(today):
+			text = append(text, "+1"...)
+		case day.Before(today):
+			text = append(text, "-1"...)
+		}
+		d.valueRow(int16(1+i), ellipsis(c.Label, labelWidth), text, clear)
+	}
+}
+
+// civil returns the date of time t in its own zone, as midnight UTC, so that
+// dates of different zones may be compared.
+func civil(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Clocks returns the time zones shown by the world clock page.
+func (d *Display) Clocks() []model.WorldClock { return d.world.clocks }
+
+// SetClocks changes the time zones shown by the world clock page, or restores
+// the configured time zones if clocks is empty, and redraws the current page
+// entirely. Returns ErrTooManyClocks or ErrUnknownZone, without changing any
+// time zone, if more than MaxClocks are given or any zone is not defined in
+// package tz.
+func (d *Display) SetClocks(clocks []model.WorldClock) error {
+	if 0 == len(clocks) {
+		clocks = d.config.Clocks
+	}
+	if err := ValidClocks(clocks); nil != err {
+		return err
+	}
+	d.world.clocks = clocks
+	d.page.redraw()
+	return nil
+}
+
+// ValidClocks returns ErrTooManyClocks or ErrUnknownZone if the given time zones
+// cannot be shown by the world clock page.
+func ValidClocks(clocks []model.WorldClock) error {
+	if len(clocks) > MaxClocks {
+		return ErrTooManyClocks
+	}
+	for _, c := range clocks {
+		if _, ok := tz.Lookup(c.Zone); !ok {
+			return ErrUnknownZone
+		}
+	}
+	return nil
+}
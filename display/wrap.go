@@ -0,0 +1,67 @@
+package display
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ellipsisRune is appended to text shortened by ellipsis.
+const ellipsisRune = '…'
+
+// stringWidth returns the width of text drawn with tinyfont.WriteLine, in
+// pixels, equivalent to textWidth.
+func stringWidth(text string) int16 {
+	w := int16(0)
+	for _, r := range text {
+		w += int16(glyph(r).XAdvance)
+	}
+	return w
+}
+
+// wrap breaks text into lines no wider than width pixels, appending them to
+// line. Lines are broken at spaces, and words wider than width are broken
+// wherever they reach it. At most cap(line) lines are appended, so that wrap
+// does not allocate, and any text not appended is returned as rest.
+func wrap(line []string, text string, width int16) (lines []string, rest string) {
+	for {
+		text = strings.TrimLeft(text, " ")
+		if "" == text || len(line) == cap(line) {
+			return line, text
+		}
+		n, w, brk := len(text), int16(0), -1
+		for i, r := range text {
+			if ' ' == r {
+				brk = i
+			}
+			if w += int16(glyph(r).XAdvance); w > width && ' ' != r {
+				n = i
+				if brk > 0 {
+					n = brk
+				}
+				break
+			}
+		}
+		if 0 == n {
+			_, n = utf8.DecodeRuneInString(text) // width is less than one glyph
+		}
+		line = append(line, strings.TrimRight(text[:n], " "))
+		text = text[n:]
+	}
+}
+
+// ellipsis returns text, shortened with a trailing ellipsis if it is wider than
+// width pixels. Text is only copied (allocating) when shortened.
+func ellipsis(text string, width int16) string {
+	if stringWidth(text) <= width {
+		return text
+	}
+	width -= int16(glyph(ellipsisRune).XAdvance)
+	n, w := 0, int16(0)
+	for i, r := range text {
+		if w += int16(glyph(r).XAdvance); w > width {
+			n = i
+			break
+		}
+	}
+	return strings.TrimRight(text[:n], " ") + string(ellipsisRune)
+}
@@ -0,0 +1,36 @@
+//go:build ble && ethernet
+// +build ble,ethernet
+
+package main
+
+import (
+	"machine"
+
+	"github.com/ardnew/weatherhub/ble"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/feed/beacon"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// feature "ble" scans for BLE temperature and humidity beacons with the NINA
+// coprocessor in its BLE mode, which is free since the network link is the
+// W5500 (see package wifi).
+func init() {
+	run.Register(run.Feature{
+		Name:  "ble",
+		Stage: run.StageFeed,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			scanner := ble.New(machine.UART2, ble.Config{
+				TX:     machine.NINA_TX,
+				RX:     machine.NINA_RX,
+				RTS:    machine.NINA_GPIO0,
+				Reset:  machine.NINA_RESETN,
+				Select: machine.NINA_CS,
+			})
+			if err := scanner.Configure(); nil != err {
+				return nil, err
+			}
+			return []feed.Feed{beacon.New(scanner, beacon.Config{})}, nil
+		},
+	})
+}
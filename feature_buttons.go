@@ -0,0 +1,36 @@
+//go:build !nobuttons
+// +build !nobuttons
+
+package main
+
+import (
+	"machine"
+
+	"github.com/ardnew/weatherhub/button"
+	"github.com/ardnew/weatherhub/demo"
+	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/run"
+	"github.com/ardnew/weatherhub/stopwatch"
+)
+
+// feature "buttons" operates the stopwatch with the buttons of the device: the
+// up button starts and stops it, and the down button records a lap while it is
+// running, or resets it while it is stopped. Holding the up button starts or
+// stops demo mode, and holding the down button pins or unpins the current page.
+func init() {
+	run.Register(run.Feature{
+		Name:  "buttons",
+		Stage: run.StageFeed,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			return []feed.Feed{
+				button.NewHold(machine.BUTTON_UP, stopwatch.Toggle, func() {
+					demo.Toggle(display.Icons())
+				}, button.Config{}),
+				button.NewHold(machine.BUTTON_DOWN, stopwatch.LapOrReset, func() {
+					env.Display.SetPinned(!env.Display.Pinned())
+				}, button.Config{}),
+			}, nil
+		},
+	})
+}
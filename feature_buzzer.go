@@ -0,0 +1,86 @@
+//go:build !nobuzzer
+// +build !nobuzzer
+
+package main
+
+import (
+	"machine"
+
+	"github.com/ardnew/weatherhub/buzzer"
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/feed/chime"
+	"github.com/ardnew/weatherhub/feed/sound"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// feature "buzzer" provides the piezo buzzer connected to pin A1, the hourly
+// chime, and the melodies of alarms and alerts played on it. The chime is
+// configured by the Settings, and is silent while the display sleeps.
+func init() {
+	run.Register(run.Feature{
+		Name:  "buzzer",
+		Stage: run.StageService,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			b, err := buzzer.New(machine.TCC1, machine.A1)
+			if nil != err {
+				return nil, err
+			}
+			env.Buzzer = b
+			hourly, err := chime.New(b, chimeConfig(config.Get()))
+			if nil != err {
+				return nil, err
+			}
+			config.Watch(func(prev, next config.Settings) {
+				if next.Chime != prev.Chime || next.Sleep != prev.Sleep ||
+					next.Melody.Chime != prev.Melody.Chime {
+					if err := hourly.Set(chimeConfig(next)); nil != err {
+						journal.Println("error: melody: " + err.Error())
+					}
+				}
+			})
+			alerts, err := sound.New(b, soundConfig(config.Get()))
+			if nil != err {
+				return nil, err
+			}
+			config.Watch(func(prev, next config.Settings) {
+				if next.Melody != prev.Melody {
+					if err := alerts.Set(soundConfig(next)); nil != err {
+						journal.Println("error: melody: " + err.Error())
+					}
+				}
+			})
+			return []feed.Feed{b, hourly, alerts}, nil
+		},
+	})
+}
+
+// chimeConfig returns the configuration of the hourly chime given by the
+// Settings, whose quiet hours are those the display sleeps.
+func chimeConfig(s config.Settings) chime.Config {
+	return chime.Config{
+		Off:        !s.Chime.On,
+		Melody:     s.Melody.Chime,
+		Count:      s.Chime.Count,
+		QuietFrom:  s.Sleep.From,
+		QuietUntil: s.Sleep.Until,
+		Days:       s.Chime.Days.Weekdays(),
+	}
+}
+
+// soundConfig returns the melodies of alarms and alerts given by the Settings,
+// each the default if not given.
+func soundConfig(s config.Settings) sound.Config {
+	alarm, alert := sound.DefaultAlarm, sound.DefaultAlert
+	if "" != s.Melody.Alarm {
+		alarm = s.Melody.Alarm
+	}
+	if "" != s.Melody.Alert {
+		alert = s.Melody.Alert
+	}
+	return sound.Config{Melodies: []sound.Melody{
+		{Prefix: "wind", Song: alarm},
+		{Prefix: "advisory:", Song: alert},
+	}}
+}
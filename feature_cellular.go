@@ -0,0 +1,28 @@
+//go:build cellular && !ethernet && !fake
+// +build cellular,!ethernet,!fake
+
+package main
+
+import (
+	"machine"
+
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/run"
+	"github.com/ardnew/weatherhub/wifi/cellular"
+)
+
+// feature "cellular" falls back to a cellular modem on the UART pins of the
+// board when no access point can be joined.
+func init() {
+	run.Register(run.Feature{
+		Name:  "cellular",
+		Stage: run.StageService,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			env.WiFi.SetFallback(cellular.New(machine.UART1, cellular.Config{
+				TX: machine.UART_TX_PIN,
+				RX: machine.UART_RX_PIN,
+			}))
+			return nil, nil
+		},
+	})
+}
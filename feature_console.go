@@ -0,0 +1,21 @@
+//go:build !noconsole
+// +build !noconsole
+
+package main
+
+import (
+	"github.com/ardnew/weatherhub/console"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// feature "console" provides the interactive shell on the serial port.
+func init() {
+	run.Register(run.Feature{
+		Name:  "console",
+		Stage: run.StageFeed,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			return []feed.Feed{console.New()}, nil
+		},
+	})
+}
@@ -0,0 +1,31 @@
+//go:build esphome && !nomqtt
+// +build esphome,!nomqtt
+
+package main
+
+import (
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/feed/esphome"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// feature "esphome" announces the sensors of the Model and the power of the
+// display to Home Assistant, as an ESPHome device using MQTT would.
+func init() {
+	run.Register(run.Feature{
+		Name:  "esphome",
+		Stage: run.StageFeed,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			e, err := esphome.New(env.Broker, esphome.Config{}, esphome.Switch{
+				ID:   "display",
+				Name: "Display",
+				Get:  env.Display.Power,
+				Set:  env.Display.SetPower,
+			})
+			if nil != err {
+				return nil, err
+			}
+			return []feed.Feed{e}, nil
+		},
+	})
+}
@@ -0,0 +1,10 @@
+//go:build fake
+// +build fake
+
+package main
+
+// feature "fake" replaces the network with an emulation (see package wifi/fake),
+// and answers the forecast and geo feeds with synthetic weather, so that the
+// display can be developed with no network hardware at all. Other services are
+// unavailable, replying 404 Not Found.
+import _ "github.com/ardnew/weatherhub/feed/forecast/fake"
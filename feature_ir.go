@@ -0,0 +1,56 @@
+//go:build ir && !lora && !ethernet
+// +build ir,!lora,!ethernet
+
+package main
+
+import (
+	"io"
+	"machine"
+
+	"github.com/ardnew/weatherhub/command"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/ir"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// irKeys defines the command line performed by each button of the remote, here
+// the common 21-button "car MP3" remote. The codes of buttons not listed are
+// logged when pressed, so that other remotes can be mapped.
+var irKeys = map[ir.Code]string{
+	{Address: 0x00, Command: 0x40}: "page next",          // >>|
+	{Address: 0x00, Command: 0x43}: "sync now",           // >||
+	{Address: 0x00, Command: 0x07}: "set brightness 10",  // -
+	{Address: 0x00, Command: 0x15}: "set brightness 100", // +
+	{Address: 0x00, Command: 0x45}: "stopwatch start",    // CH-
+	{Address: 0x00, Command: 0x46}: "stopwatch stop",     // CH
+	{Address: 0x00, Command: 0x47}: "stopwatch reset",    // CH+
+	{Address: 0x00, Command: 0x0C}: "set brightness 30",  // 1
+	{Address: 0x00, Command: 0x18}: "set brightness 60",  // 2
+}
+
+// feature "ir" performs command lines with an infrared remote, using a 38 kHz
+// receiver module on pin A4 of the analog header, which is shared with the SPI
+// bus of the "lora" and "ethernet" features.
+func init() {
+	run.Register(run.Feature{
+		Name:  "ir",
+		Stage: run.StageFeed,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			r, err := ir.New(machine.A4, func(c ir.Code) {
+				line, ok := irKeys[c]
+				if !ok {
+					journal.Println("ir: unmapped code " + c.String())
+					return
+				}
+				if err := command.Exec(io.Discard, line); nil != err {
+					journal.Println("ir: " + line + ": " + err.Error())
+				}
+			})
+			if nil != err {
+				return nil, err
+			}
+			return []feed.Feed{r}, nil
+		},
+	})
+}
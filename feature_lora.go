@@ -0,0 +1,35 @@
+//go:build lora && !ethernet
+// +build lora,!ethernet
+
+package main
+
+import (
+	"machine"
+
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/feed/remote"
+	"github.com/ardnew/weatherhub/lora"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// feature "lora" receives the measurements of remote sensors with an SX127x
+// LoRa radio on the SPI bus of the analog header.
+func init() {
+	run.Register(run.Feature{
+		Name:  "lora",
+		Stage: run.StageFeed,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			machine.SPI0.Configure(machine.SPIConfig{
+				Frequency: 8 * 1e6,
+				SDO:       machine.A3,
+				SDI:       machine.A4,
+				SCK:       machine.A2,
+			})
+			radio := &lora.Device{SPI: machine.SPI0, CS: machine.A0}
+			if err := radio.Configure(lora.Config{}); nil != err {
+				return nil, err
+			}
+			return []feed.Feed{remote.New(radio, remote.Config{})}, nil
+		},
+	})
+}
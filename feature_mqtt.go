@@ -0,0 +1,33 @@
+//go:build !nomqtt
+// +build !nomqtt
+
+package main
+
+import (
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/feed/control"
+	"github.com/ardnew/weatherhub/feed/message"
+	"github.com/ardnew/weatherhub/run"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// feature "mqtt" provides the MQTT client, the message feed subscribed to
+// notices published by other devices, and the control feed performing command
+// lines published to the device.
+func init() {
+	run.Register(run.Feature{
+		Name:  "mqtt",
+		Stage: run.StageService,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			env.Broker = mqtt.New(env.WiFi, mqtt.Config{})
+			if _, err := message.New(env.Broker, message.Config{}); nil != err {
+				return nil, err
+			}
+			ctrl, err := control.New(env.Broker, control.Config{})
+			if nil != err {
+				return nil, err
+			}
+			return []feed.Feed{env.Broker, ctrl}, nil
+		},
+	})
+}
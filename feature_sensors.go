@@ -0,0 +1,31 @@
+//go:build !nomqtt && !nosensors
+// +build !nomqtt,!nosensors
+
+package main
+
+import (
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/feed/indoor"
+	"github.com/ardnew/weatherhub/feed/wind"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// feature "sensors" provides the feeds of local sensors, which publish their
+// readings to the MQTT broker.
+func init() {
+	run.Register(run.Feature{
+		Name:  "sensors",
+		Stage: run.StageFeed,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			gusts, err := wind.New(env.Broker, wind.Config{})
+			if nil != err {
+				return nil, err
+			}
+			room, err := indoor.New(env.Broker, indoor.Config{})
+			if nil != err {
+				return nil, err
+			}
+			return []feed.Feed{gusts, room}, nil
+		},
+	})
+}
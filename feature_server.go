@@ -0,0 +1,36 @@
+//go:build !noserver
+// +build !noserver
+
+package main
+
+import (
+	"github.com/ardnew/weatherhub/api"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/run"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// feature "server" provides the HTTP server and its API endpoints.
+func init() {
+	run.Register(run.Feature{
+		Name:  "server",
+		Stage: run.StageService,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			env.Server = http.NewServer(env.WiFi, http.ServerConfig{})
+			api.Notify(env.Server, api.NotifyConfig{})
+			api.Display(env.Server, env.Display)
+			api.Command(env.Server)
+			api.DegreeDays(env.Server)
+			api.Config(env.Server, env.Display)
+			api.UI(env.Server)
+			api.Metrics(env.Server)
+			status := api.StatusConfig{
+				Name:     env.WiFi.Name,
+				Features: run.Features(),
+			}
+			api.Status(env.Server, status)
+			push := api.Push(env.Server, api.PushConfig{Status: status})
+			return []feed.Feed{env.Server, push}, nil
+		},
+	})
+}
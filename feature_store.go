@@ -0,0 +1,62 @@
+//go:build !nostore
+// +build !nostore
+
+package main
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers/flash"
+
+	"github.com/ardnew/weatherhub/api"
+	"github.com/ardnew/weatherhub/asset"
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// feature "store" saves the settings changed at runtime, including credentials,
+// to the QSPI flash chip of the board, so that they survive power loss. It also
+// counts the times the device has started, and loads images supplied at runtime
+// from the region of flash preceding the settings (see package asset), which
+// are uploaded with the HTTP API if the "server" feature is compiled in.
+func init() {
+	run.Register(run.Feature{
+		Name:  "store",
+		Stage: run.StageService,
+		Init: func(env *run.Env) ([]feed.Feed, error) {
+			dev := flash.NewQSPI(
+				machine.QSPI_CS, machine.QSPI_SCK,
+				machine.QSPI_DATA0, machine.QSPI_DATA1,
+				machine.QSPI_DATA2, machine.QSPI_DATA3)
+			err := dev.Configure(&flash.DeviceConfig{
+				Identifier: flash.DefaultDeviceIdentifier,
+			})
+			if nil != err {
+				return nil, err
+			}
+			boots, err := config.CountBoot(dev)
+			if nil != err {
+				return nil, err
+			}
+			model.Mod(func(m *model.Model) { m.Diagnostics.Boots = boots })
+			region, err := asset.NewRegion(dev, dev.Size()/dev.EraseBlockSize()-config.Reserved)
+			if nil != err {
+				return nil, err
+			}
+			if images, err := region.Load(); nil != err {
+				journal.Println("error: assets: " + err.Error())
+			} else {
+				env.Display.SetImages(images)
+			}
+			// the server, if any, was initialized first, since features of the same
+			// stage are initialized in order of the names of their files.
+			if nil != env.Server {
+				api.Assets(env.Server, region, env.Display)
+			}
+			return nil, config.Open(dev)
+		},
+	})
+}
@@ -0,0 +1,160 @@
+// Package accuracy tracks how well the forecast predicted each day's high and
+// low temperature, and optionally corrects the forecast by its recent bias.
+//
+// The high and low of each day, as forecast by the last forecast retrieved the
+// day before, are compared with the highest and lowest temperature of the
+// current conditions observed that day. The errors of the past several days
+// (see package history) are averaged into the bias of the forecast, which may
+// be subtracted from the forecast highs and lows of every day once enough days
+// have been compared.
+//
+// Days that were not observed from shortly after midnight are not compared,
+// since their observed high or low may have been missed.
+package accuracy
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Default constants for Accuracy configuration.
+const (
+	DefaultMinDays = 3
+)
+
+// lateStart defines how long after midnight observation of a day may begin for
+// the day to be compared.
+const lateStart = time.Hour
+
+// Config defines whether the forecast is corrected by its bias, and how many
+// days must be compared before it is.
+type Config struct {
+	Correct bool // subtract the bias from forecast highs and lows
+	MinDays int  // days compared before the forecast is corrected
+}
+
+// forecast is the high and low forecast for a day.
+type forecast struct {
+	date      time.Time // local midnight; zero if not forecast
+	high, low float32
+}
+
+// Accuracy is a Feed that compares the forecast with the current conditions
+// each time the forecast is updated.
+type Accuracy struct {
+	config    Config
+	updated   time.Time // Weather.Updated most recently seen
+	corrected [model.MaxDays]model.Day
+
+	day       time.Time // local midnight of the day observed
+	since     time.Time // when observation of the day began
+	high, low float32   // observed so far
+	observed  bool
+
+	today    forecast // forecast the day before for the day observed
+	tomorrow forecast // most recent forecast for the following day
+}
+
+// New returns a new Accuracy using the given configuration.
+func New(config Config) *Accuracy {
+
+	if config.MinDays <= 0 {
+		config.MinDays = DefaultMinDays
+	}
+
+	return &Accuracy{config: config}
+}
+
+// Sync compares the previous day once it has ended, and records the current
+// conditions and forecast each time the forecast is updated.
+func (a *Accuracy) Sync() error {
+
+	data := model.Peek()
+	if !data.Weather.Valid() || data.Weather.Updated.Equal(a.updated) {
+		return nil
+	}
+	a.updated = data.Weather.Updated
+
+	now := data.Time
+	today := midnight(now)
+	if !today.Equal(a.day) {
+		if a.observed && !a.day.IsZero() {
+			a.compare()
+		}
+		a.day, a.since, a.observed = today, now, false
+		if a.tomorrow.date.Equal(today) {
+			a.today = a.tomorrow
+		} else {
+			a.today = forecast{}
+		}
+	}
+
+	// observe the current conditions
+	t := data.Weather.Current.Temperature
+	if !a.observed || t > a.high {
+		a.high = t
+	}
+	if !a.observed || t < a.low {
+		a.low = t
+	}
+	a.observed = true
+
+	// the forecast is unchanged since it was corrected if the forecast service
+	// reported that it was not modified.
+	if data.Weather.Day == a.corrected {
+		return nil
+	}
+	next := today.AddDate(0, 0, 1)
+	for _, d := range data.Weather.Day {
+		if midnight(d.Date).Equal(next) {
+			a.tomorrow = forecast{date: next, high: d.High, low: d.Low}
+		}
+	}
+	a.correct()
+	return nil
+}
+
+// compare records the errors of the forecast of the day observed, and updates
+// the bias of the forecast.
+func (a *Accuracy) compare() {
+	if !a.today.date.Equal(a.day) || a.since.Sub(a.day) > lateStart {
+		return // not forecast, or not observed from midnight
+	}
+	model.Set(func(m *model.Model) {
+		acc := &m.Accuracy
+		acc.Date = a.day
+		acc.ForecastHigh, acc.ForecastLow = a.today.high, a.today.low
+		acc.ObservedHigh, acc.ObservedLow = a.high, a.low
+		acc.HighError.Sum(a.day, a.today.high-a.high)
+		acc.LowError.Sum(a.day, a.today.low-a.low)
+		acc.HighBias, acc.Days = acc.HighError.Mean()
+		acc.LowBias, _ = acc.LowError.Mean()
+	})
+}
+
+// correct subtracts the bias from the forecast highs and lows, if configured
+// and enough days have been compared.
+func (a *Accuracy) correct() {
+	acc := model.Peek().Accuracy
+	if !a.config.Correct || acc.Days < a.config.MinDays {
+		a.corrected = model.Peek().Weather.Day
+		return
+	}
+	model.Set(func(m *model.Model) {
+		for i := range m.Weather.Day {
+			if !m.Weather.Day[i].Date.IsZero() {
+				m.Weather.Day[i].High -= acc.HighBias
+				m.Weather.Day[i].Low -= acc.LowBias
+			}
+		}
+		m.Accuracy.Corrected = true
+		a.corrected = m.Weather.Day
+	})
+}
+
+// midnight returns local midnight of the day of time t.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,195 @@
+// Package advisory evaluates local weather rules against the hourly forecast,
+// posting a Notice (see package notify) when any rule is triggered.
+//
+// Rules are simple thresholds, e.g., a frost warning when the temperature falls
+// below 2 °C, or a heat warning when the heat index rises above 40 °C. They are
+// independent of any official weather alerts.
+package advisory
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ardnew/weatherhub/meteo"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+)
+
+// Default constants for Advisory configuration.
+const (
+	DefaultHours    = model.MaxHours
+	DefaultDuration = 20 * time.Second
+	DefaultPriority = model.PriorityHigh
+	DefaultIcon     = "alert"
+)
+
+// Field identifies the forecast quantity compared by a Rule.
+type Field uint8
+
+// Constants defining each possible Field.
+const (
+	FieldTemperature Field = iota // degrees Celsius
+	FieldHeatIndex                // degrees Celsius
+	FieldWindGust                 // meters per second
+	FieldChance                   // probability of precipitation, percent
+	FieldRain                     // precipitation, millimeters per hour
+)
+
+// Op defines how a Field is compared to the threshold of a Rule.
+type Op uint8
+
+// Constants defining each possible Op.
+const (
+	OpBelow Op = iota
+	OpAbove
+)
+
+// Rule defines a condition of the hourly forecast and the Notice posted when it
+// is met by any of the hours within the rule's horizon.
+type Rule struct {
+	Name      string // unique name identifying the rule's notice
+	Text      string // notice text, followed by the time of the first such hour
+	Field     Field
+	Op        Op
+	Threshold float32
+	Hours     int // forecast horizon, in hours from now
+	Icon      string
+	Color     color.RGBA
+	Flash     model.Flash
+	Priority  model.Priority
+	Duration  time.Duration // how long the notice is shown
+}
+
+// DefaultRules defines the rules evaluated if none are configured.
+var DefaultRules = []Rule{
+	{
+		Name:      "frost",
+		Text:      "Frost warning",
+		Field:     FieldTemperature,
+		Op:        OpBelow,
+		Threshold: 2,
+		Color:     color.RGBA{R: 0x80, G: 0xC0, B: 0xFF, A: 0xFF},
+	},
+	{
+		Name:      "heat",
+		Text:      "Heat warning",
+		Field:     FieldHeatIndex,
+		Op:        OpAbove,
+		Threshold: 40,
+		Color:     color.RGBA{R: 0xFF, G: 0x60, B: 0x00, A: 0xFF},
+		Flash:     model.FlashBorder,
+	},
+}
+
+// Config defines the rules evaluated by Advisory.
+type Config struct {
+	Rules []Rule
+}
+
+// Advisory is a Feed that evaluates its rules each time the forecast is
+// updated.
+type Advisory struct {
+	rules   []Rule
+	active  []bool // whether each rule was triggered by the previous forecast
+	updated time.Time
+}
+
+// New returns a new Advisory with the given configuration.
+func New(config Config) *Advisory {
+
+	if nil == config.Rules {
+		config.Rules = DefaultRules
+	}
+
+	rules := make([]Rule, len(config.Rules))
+	for i, r := range config.Rules {
+		if r.Hours <= 0 || r.Hours > model.MaxHours {
+			r.Hours = DefaultHours
+		}
+		if r.Duration == 0 {
+			r.Duration = DefaultDuration
+		}
+		if r.Priority == model.PriorityNone {
+			r.Priority = DefaultPriority
+		}
+		if r.Icon == "" {
+			r.Icon = DefaultIcon
+		}
+		rules[i] = r
+	}
+
+	return &Advisory{rules: rules, active: make([]bool, len(rules))}
+}
+
+// Sync evaluates all rules if the forecast has been updated since the previous
+// call. A rule's notice is posted when the rule becomes triggered, and it is
+// dismissed when the rule is no longer triggered.
+func (a *Advisory) Sync() error {
+
+	data := model.Peek()
+	if !data.Weather.Valid() || !data.Weather.Updated.After(a.updated) {
+		return nil
+	}
+	a.updated = data.Weather.Updated
+
+	for i, r := range a.rules {
+		id := "advisory:" + r.Name
+		at, ok := r.eval(data.Weather, data.Time)
+		if ok && !a.active[i] {
+			notify.Post(model.Notice{
+				ID:       id,
+				Text:     r.Text + " " + at.Format("3pm"),
+				Icon:     r.Icon,
+				Color:    r.Color,
+				Flash:    r.Flash,
+				Priority: r.Priority,
+				Dismiss:  model.DismissShown,
+				Duration: r.Duration,
+			})
+		} else if !ok && a.active[i] {
+			notify.Dismiss(id)
+		}
+		a.active[i] = ok
+	}
+	return nil
+}
+
+// eval returns the time of the first forecast hour within the rule's horizon
+// that meets its condition, and true if such an hour exists.
+func (r *Rule) eval(w model.Weather, now time.Time) (time.Time, bool) {
+	end := now.Add(time.Duration(r.Hours) * time.Hour)
+	for _, h := range w.Hour {
+		// include the current hour, which began before now
+		if h.Time.IsZero() || h.Time.Add(time.Hour).Before(now) {
+			continue
+		}
+		if h.Time.After(end) {
+			break
+		}
+		if r.met(value(h, r.Field)) {
+			return h.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) met(v float32) bool {
+	if OpAbove == r.Op {
+		return v > r.Threshold
+	}
+	return v < r.Threshold
+}
+
+func value(h model.Hour, field Field) float32 {
+	switch field {
+	case FieldHeatIndex:
+		return meteo.HeatIndex(h.Temperature, float32(h.Humidity))
+	case FieldWindGust:
+		return h.WindGust
+	case FieldChance:
+		return float32(h.Chance)
+	case FieldRain:
+		return h.Rain
+	}
+	return h.Temperature
+}
@@ -0,0 +1,121 @@
+// Package air implements a Feed of the current air quality and pollen at the
+// device Location, recording the maximum of each day in the Model's history.
+//
+// Air quality is retrieved from the Open-Meteo air quality API, which requires
+// no API key. Pollen is only reported for some regions (e.g., Europe).
+package air
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Air configuration.
+const (
+	DefaultURL = "https://air-quality-api.open-meteo.com/v1/air-quality" +
+		"?latitude={lat}&longitude={lon}" +
+		"&current=us_aqi,alder_pollen,birch_pollen,grass_pollen,mugwort_pollen,olive_pollen,ragweed_pollen"
+	DefaultInterval = 30 * time.Minute
+)
+
+// Config defines the air quality service and how often it is polled.
+type Config struct {
+	URL      string // placeholders {lat} and {lon} are replaced with Location
+	Interval time.Duration
+}
+
+// Air polls an air quality service.
+type Air struct {
+	client   *http.Client
+	config   Config
+	lastSync time.Time
+}
+
+// New returns a new Air using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Air {
+
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Air{client: client, config: config}
+}
+
+// Sync polls the air quality service if the Location is known and the polling
+// interval has elapsed.
+func (a *Air) Sync() error {
+
+	data := model.Peek()
+	if !data.Location.Known() {
+		return nil // wait for geolocation
+	}
+	if !feed.Due(data.Time, a.lastSync, a.config.Interval) {
+		return nil
+	}
+	a.lastSync = data.Time
+
+	lat := strconv.FormatFloat(data.Location.Latitude, 'f', 4, 64)
+	lon := strconv.FormatFloat(data.Location.Longitude, 'f', 4, 64)
+	url := strings.Replace(a.config.URL, "{lat}", lat, 1)
+	url = strings.Replace(url, "{lon}", lon, 1)
+
+	var aqi, pollen float32
+	var hasAQI, hasPollen bool
+	modified, err := feed.FetchJSONIfModified(a.client, url, func(path []byte, kind json.Kind, value []byte) error {
+		if json.Number != kind || !bytes.HasPrefix(path, []byte("current.")) {
+			return nil // ignore units, unreported values, etc.
+		}
+		v, err := strconv.ParseFloat(string(value), 32)
+		if nil != err {
+			return err
+		}
+		switch field := path[len("current."):]; {
+		case "us_aqi" == string(field):
+			aqi, hasAQI = float32(v), true
+		case bytes.HasSuffix(field, []byte("_pollen")):
+			if !hasPollen || float32(v) > pollen {
+				pollen, hasPollen = float32(v), true
+			}
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+	if !modified {
+		// the current values still hold, but must be recorded in the history in
+		// case the day has changed since they were retrieved.
+		model.Set(func(m *model.Model) {
+			m.Health.Updated = data.Time
+			m.Health.AQIHistory.Max(data.Time, m.Health.AQI)
+			if m.Health.HasPollen {
+				m.Health.PollenHistory.Max(data.Time, m.Health.Pollen)
+			}
+		})
+		return nil
+	}
+	if !hasAQI {
+		return nil
+	}
+
+	model.Set(func(m *model.Model) {
+		m.Health.Updated = data.Time
+		m.Health.AQI = aqi
+		m.Health.AQIHistory.Max(data.Time, aqi)
+		m.Health.Pollen, m.Health.HasPollen = pollen, hasPollen
+		if hasPollen {
+			m.Health.PollenHistory.Max(data.Time, pollen)
+		}
+	})
+	return nil
+}
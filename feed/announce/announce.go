@@ -0,0 +1,112 @@
+// Package announce broadcasts a small UDP status datagram on the local network
+// periodically, so that discovery tools and companion apps can find each device
+// without mDNS, which the WiFi coprocessor does not provide.
+//
+// Each datagram is a JSON object, e.g.:
+//
+//	{"name": "weatherhub", "ip": "192.168.1.23", "status": "synchronized",
+//	 "version": "1.4.0", "uptime": 3600}
+//
+// where uptime is given in seconds. A listener need only bind the configured
+// port, e.g., with "socat -u UDP-RECV:4210 -".
+//
+// Since a broadcast cannot negotiate its encoding with its listeners, the same
+// object is also broadcast encoded as CBOR (see package cbor) to a second port,
+// so that each listener chooses the encoding by the port it binds, e.g., CBOR
+// for listeners that are themselves microcontrollers.
+package announce
+
+import (
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+
+	"github.com/ardnew/weatherhub/codec/cbor"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi"
+)
+
+// Default constants for Announce configuration.
+const (
+	DefaultPort     = 4210
+	DefaultCBORPort = 4211
+	DefaultInterval = time.Minute
+)
+
+// Config defines the UDP ports datagrams are sent to, how often, and the
+// firmware version they report.
+type Config struct {
+	Port     int // port of the JSON datagrams
+	CBORPort int // port of the CBOR datagrams
+	Interval time.Duration
+	Version  string
+}
+
+// Announce is a Feed that broadcasts the status datagram periodically.
+type Announce struct {
+	wifi     *wifi.WiFi
+	config   Config
+	lastSync time.Time
+}
+
+// New returns a new Announce using the given WiFi and configuration.
+func New(w *wifi.WiFi, config Config) *Announce {
+
+	if config.Port == 0 {
+		config.Port = DefaultPort
+	}
+	if config.CBORPort == 0 {
+		config.CBORPort = DefaultCBORPort
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Announce{wifi: w, config: config}
+}
+
+// Sync broadcasts the status datagram if the interval has elapsed.
+func (a *Announce) Sync() error {
+
+	data := model.Peek()
+	if !feed.Expired(data.Time, a.lastSync, a.config.Interval) {
+		return nil
+	}
+	a.lastSync = data.Time
+
+	uptime := int64(data.Diagnostics.Uptime() / time.Second)
+	payload := []byte(`{"name": "` + a.wifi.Name() +
+		`", "ip": "` + data.IP.String() +
+		`", "status": "` + data.Status.String() +
+		`", "version": "` + a.config.Version +
+		`", "uptime": ` + strconv.FormatInt(uptime, 10) + `}`)
+	if err := a.broadcast(a.config.Port, payload); nil != err {
+		return err
+	}
+
+	payload = cbor.AppendMap(payload[:0], 5)
+	payload = cbor.AppendString(cbor.AppendString(payload, "name"), a.wifi.Name())
+	payload = cbor.AppendString(cbor.AppendString(payload, "ip"), data.IP.String())
+	payload = cbor.AppendString(cbor.AppendString(payload, "status"), data.Status.String())
+	payload = cbor.AppendString(cbor.AppendString(payload, "version"), a.config.Version)
+	payload = cbor.AppendInt(cbor.AppendString(payload, "uptime"), uptime)
+	return a.broadcast(a.config.CBORPort, payload)
+}
+
+// broadcast sends the given datagram to the given port of all hosts on the
+// local network.
+func (a *Announce) broadcast(port int, payload []byte) error {
+	radd := &net.UDPAddr{IP: net.IP{255, 255, 255, 255}, Port: port}
+	ladd := &net.UDPAddr{Port: port}
+	return a.wifi.Do(func() error {
+		conn, err := net.DialUDP("udp", ladd, radd)
+		if nil != err {
+			return err
+		}
+		defer conn.Close()
+		_, err = conn.Write(payload)
+		return err
+	})
+}
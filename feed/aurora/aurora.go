@@ -0,0 +1,174 @@
+// Package aurora implements a Feed of the planetary K-index (Kp), a measure of
+// geomagnetic activity, from the NOAA Space Weather Prediction Center.
+//
+// The aurora is visible farther from the poles as Kp increases. The equatorward
+// extent of the auroral oval is approximated from the geomagnetic latitude of
+// the device Location: at Kp 0 it reaches about 66.5° magnetic latitude, and it
+// extends about 2° farther for each unit of Kp. A Notice is posted when Kp
+// reaches the configured threshold.
+package aurora
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/meteo"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Aurora configuration.
+const (
+	DefaultURL      = "https://services.swpc.noaa.gov/products/noaa-planetary-k-index.json"
+	DefaultInterval = 30 * time.Minute
+	DefaultLatitude = 50 // geomagnetic degrees
+	DefaultDuration = 30 * time.Second
+)
+
+// Constants of the auroral oval approximation.
+const (
+	ovalLatitude = 66.5 // geomagnetic latitude reached at Kp 0, degrees
+	ovalPerKp    = 2.0  // equatorward extent per unit Kp, degrees
+)
+
+// noticeID identifies the notices posted by Aurora.
+const noticeID = "aurora"
+
+// DefaultColor defines the color of aurora notices.
+var DefaultColor = color.RGBA{R: 0x40, G: 0xFF, B: 0x80, A: 0xFF}
+
+// Config defines the K-index service and when the aurora is shown.
+//
+// The service must reply with a JSON array of rows, the first being a header,
+// with the time and Kp in the first two columns of each row.
+type Config struct {
+	URL       string
+	Interval  time.Duration
+	Latitude  float64 // minimum geomagnetic latitude at which the page is shown
+	Threshold float32 // Kp at which a notice is posted, 0 if aurora is possible
+	Duration  time.Duration
+	Color     color.RGBA
+}
+
+// Aurora polls the K-index service.
+type Aurora struct {
+	client   *http.Client
+	config   Config
+	notified bool // threshold notice posted for current activity
+	lastSync time.Time
+}
+
+// New returns a new Aurora using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Aurora {
+
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Latitude == 0 {
+		config.Latitude = DefaultLatitude
+	}
+	if config.Duration == 0 {
+		config.Duration = DefaultDuration
+	}
+	if config.Color == (color.RGBA{}) {
+		config.Color = DefaultColor
+	}
+
+	return &Aurora{client: client, config: config}
+}
+
+// Sync polls the K-index service if the Location is known, is far enough
+// poleward, and the polling interval has elapsed.
+func (a *Aurora) Sync() error {
+
+	data := model.Peek()
+	if !data.Location.Known() {
+		return nil
+	}
+	mlat := math.Abs(meteo.Geomagnetic(data.Location.Latitude, data.Location.Longitude))
+	if mlat < a.config.Latitude {
+		return nil // aurora is never visible here
+	}
+	if !feed.Due(data.Time, a.lastSync, a.config.Interval) {
+		return nil
+	}
+	a.lastSync = data.Time
+
+	// the rows are in chronological order, so keep the last Kp of the table.
+	var kp float32
+	found := false
+	err := feed.FetchJSON(a.client, a.config.URL, func(path []byte, kind json.Kind, value []byte) error {
+		if ok, row := json.Match(path, "*.1"); ok && row > 0 {
+			v, err := strconv.ParseFloat(string(value), 32)
+			if nil != err {
+				return err
+			}
+			kp, found = float32(v), true
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+	if !found {
+		return nil
+	}
+
+	req := float32((ovalLatitude - mlat) / ovalPerKp)
+	if req < 0 {
+		req = 0
+	}
+	aur := model.Aurora{
+		Updated:    data.Time,
+		Kp:         kp,
+		Required:   req,
+		Visibility: visibility(kp, req),
+		Show:       true,
+	}
+	model.Set(func(m *model.Model) {
+		m.Aurora = aur
+	})
+
+	threshold := a.config.Threshold
+	if 0 == threshold {
+		threshold = req
+	}
+	if kp >= threshold && !a.notified {
+		a.notified = true
+		notify.Post(model.Notice{
+			ID: noticeID,
+			Text: "Aurora " + aur.Visibility.String() +
+				" Kp " + strconv.FormatFloat(float64(kp), 'f', 1, 32),
+			Color:    a.config.Color,
+			Flash:    model.FlashBorder,
+			Priority: model.PriorityNormal,
+			Dismiss:  model.DismissShown,
+			Duration: a.config.Duration,
+		})
+	} else if kp < threshold {
+		a.notified = false
+	}
+	return nil
+}
+
+// visibility returns the Visibility implied by the K-index kp at a Location
+// where the aurora requires K-index req.
+func visibility(kp, req float32) model.Visibility {
+	switch {
+	case kp >= req+1:
+		return model.VisibilityLikely
+	case kp >= req:
+		return model.VisibilityPossible
+	case kp >= req-1:
+		return model.VisibilityLow
+	}
+	return model.VisibilityNone
+}
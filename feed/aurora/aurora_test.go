@@ -0,0 +1,100 @@
+//go:build fake
+// +build fake
+
+package aurora
+
+import (
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ardnew/weatherhub/meteo"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi"
+	"github.com/ardnew/weatherhub/wifi/fake"
+	"github.com/ardnew/weatherhub/wifi/http"
+	"github.com/ardnew/weatherhub/wifi/network"
+)
+
+// epoch is the time at which the fixtures were recorded.
+var epoch = time.Unix(1624280400, 0).UTC()
+
+// serve answers every request to host with the given file of testdata, and
+// returns the path of the last request.
+func serve(t *testing.T, host, file string) *string {
+	t.Helper()
+	body, err := ioutil.ReadFile("testdata/" + file)
+	if nil != err {
+		t.Fatal(err)
+	}
+	path := new(string)
+	fake.Handle(host, func(p string) (int, []byte) {
+		*path = p
+		return 200, body
+	})
+	return path
+}
+
+// client returns a Client of the emulated network, with the Model at epoch.
+func client(t *testing.T) *http.Client {
+	t.Helper()
+	w, err := wifi.New(wifi.Config{})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := w.Connect(network.AP{SSID: "fake"}); nil != err {
+		t.Fatal(err)
+	}
+	model.Set(func(m *model.Model) {
+		m.Time = epoch
+	})
+	return http.New(w, http.Config{})
+}
+
+func TestSyncReplay(t *testing.T) {
+	path := serve(t, "services.swpc.noaa.gov", "k-index.json")
+	c := client(t)
+	model.Set(func(m *model.Model) {
+		m.Location = model.Location{Latitude: 50.4452, Longitude: -104.6189, Zone: "America/Regina"}
+	})
+
+	if err := New(c, Config{}).Sync(); nil != err {
+		t.Fatal(err)
+	}
+	if "/products/noaa-planetary-k-index.json" != *path {
+		t.Errorf("requested %q", *path)
+	}
+
+	a := model.Peek().Aurora
+	mlat := math.Abs(meteo.Geomagnetic(50.4452, -104.6189))
+	req := float32((ovalLatitude - mlat) / ovalPerKp)
+	if !a.Updated.Equal(epoch) || !a.Show {
+		t.Errorf("Aurora = %+v", a)
+	}
+	// the last row of the table, not the greatest Kp
+	if math.Abs(float64(a.Kp-5.33)) > 1e-3 {
+		t.Errorf("Kp = %v, want 5.33", a.Kp)
+	}
+	if math.Abs(float64(a.Required-req)) > 1e-3 {
+		t.Errorf("Required = %v, want %v", a.Required, req)
+	}
+	if visibility(5.33, req) != a.Visibility {
+		t.Errorf("Visibility = %v, want %v", a.Visibility, visibility(5.33, req))
+	}
+}
+
+func TestSyncEquatorward(t *testing.T) {
+	path := serve(t, "services.swpc.noaa.gov", "k-index.json")
+	c := client(t)
+	model.Set(func(m *model.Model) {
+		m.Location = model.Location{Latitude: 25.7617, Longitude: -80.1918, Zone: "America/New_York"}
+		m.Aurora = model.Aurora{}
+	})
+	if err := New(c, Config{}).Sync(); nil != err {
+		t.Fatal(err)
+	}
+	if "" != *path || model.Peek().Aurora.Show {
+		t.Errorf("polled %q below the configured latitude", *path)
+	}
+}
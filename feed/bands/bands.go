@@ -0,0 +1,150 @@
+// Package bands implements a Feed of the solar indices and HF band conditions
+// used by amateur radio operators to judge propagation.
+//
+// The indices and conditions are retrieved from the solar data XML of HamQSL
+// (hamqsl.com), which is calculated from the solar flux and geomagnetic indices
+// published by NOAA, e.g.:
+//
+//	<solar><solardata>
+//	  <solarflux>75</solarflux><aindex>5</aindex><kindex>1</kindex>
+//	  <calculatedconditions>
+//	    <band name="80m-40m" time="day">Fair</band>
+//	    ...
+package bands
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/xml"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Bands configuration.
+const (
+	HamQSLURL       = "http://www.hamqsl.com/solarxml.php"
+	DefaultInterval = time.Hour // the data is updated every few hours
+)
+
+// Config defines the solar data service and how often it is polled.
+//
+// The Feed is disabled if URL is empty, e.g., HamQSLURL to enable it.
+type Config struct {
+	URL      string
+	Interval time.Duration
+}
+
+// Bands polls the solar data service.
+type Bands struct {
+	client   *http.Client
+	config   Config
+	lastSync time.Time
+}
+
+// New returns a new Bands using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Bands {
+
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Bands{client: client, config: config}
+}
+
+// Sync polls the solar data service if the polling interval has elapsed.
+func (b *Bands) Sync() error {
+
+	if "" == b.config.URL {
+		return nil // feed disabled
+	}
+	data := model.Peek()
+	if !feed.Due(data.Time, b.lastSync, b.config.Interval) {
+		return nil
+	}
+	b.lastSync = data.Time
+
+	res, err := b.client.Get(b.config.URL)
+	if nil != err {
+		return err
+	}
+	defer res.Close()
+	if !res.OK() {
+		return http.ErrStatus
+	}
+
+	bands := model.Bands{Updated: data.Time}
+	var (
+		n     int    // bands named
+		name  []byte // of the band element being read
+		night bool   // band element being read is the condition by night
+	)
+	err = xml.Extract(res.Body, func(path []byte, value []byte) error {
+		var err error
+		switch {
+		case bytes.HasSuffix(path, []byte(".solarflux")):
+			bands.Flux, err = index(value)
+		case bytes.HasSuffix(path, []byte(".aindex")):
+			bands.A, err = index(value)
+		case bytes.HasSuffix(path, []byte(".kindex")):
+			bands.K, err = index(value)
+		case bytes.HasSuffix(path, []byte(".band.@name")):
+			name = append(name[:0], value...)
+		case bytes.HasSuffix(path, []byte(".band.@time")):
+			night = bytes.Equal(value, []byte("night"))
+		case bytes.HasSuffix(path, []byte(".calculatedconditions.band")):
+			n = band(&bands, n, name, night, propagation(value))
+		}
+		return err
+	})
+	if nil != err {
+		return err
+	}
+
+	bands.Active = n > 0
+	model.Set(func(m *model.Model) {
+		m.Bands = bands
+	})
+	return nil
+}
+
+// band records the propagation p of the band with the given name, by night or
+// by day, in the first of the n bands already named with that name, or else in
+// the next, unless all MaxBands are named. Returns the number of bands named.
+func band(bands *model.Bands, n int, name []byte, night bool, p model.Propagation) int {
+	i := 0
+	for i < n && bands.Band[i].Name != string(name) {
+		i++
+	}
+	if i == n {
+		if n == model.MaxBands {
+			return n
+		}
+		bands.Band[i].Name = string(name)
+		n++
+	}
+	if night {
+		bands.Band[i].Night = p
+	} else {
+		bands.Band[i].Day = p
+	}
+	return n
+}
+
+// index returns the integer value of an index, which may be surrounded by
+// whitespace.
+func index(value []byte) (int, error) {
+	return strconv.Atoi(string(bytes.TrimSpace(value)))
+}
+
+// propagation returns the Propagation with the given name, e.g., "Good".
+func propagation(value []byte) model.Propagation {
+	for p := model.PropagationPoor; p <= model.PropagationGood; p++ {
+		if bytes.EqualFold(value, []byte(p.String())) {
+			return p
+		}
+	}
+	return model.PropagationUnknown
+}
@@ -0,0 +1,277 @@
+// Package beacon receives measurements of BLE temperature and humidity beacons
+// by passively scanning their advertisements (see package ble), without
+// pairing.
+//
+// The following advertisement formats are decoded:
+//
+//	service  format
+//	0x181A   Xiaomi LYWSD03MMC with custom firmware, ATC1441 and pvvx formats
+//	0xFE95   Xiaomi MiBeacon, unencrypted only (e.g., LYWSDCGQ, CGG1)
+//	0xFD3D   SwitchBot Meter, Meter Plus, and Outdoor Meter
+//
+// Stock LYWSD03MMC firmware encrypts its MiBeacon advertisements, so that they
+// cannot be decoded without the key of each device.
+//
+// Each beacon heard is given a free slot of the Model, like the sensors of
+// package remote. If any sensors are configured, all other beacons, e.g., of
+// neighbors, are ignored.
+package beacon
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/ble"
+	"github.com/ardnew/weatherhub/history"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Default constants for Beacon configuration.
+const (
+	DefaultAge        = 15 * time.Minute
+	DefaultLowBattery = 2500 // mV, of a CR2032 coin cell
+	DefaultLowPercent = 20
+)
+
+// Service UUIDs of the advertisement formats decoded, and the prefix of sensor
+// IDs of the Model.
+const (
+	uuidEnvironment = 0x181A
+	uuidMiBeacon    = 0xFE95
+	uuidSwitchBot   = 0xFD3D
+	source          = "ble:"
+)
+
+// noPercent is the battery level of a reading that has none.
+const noPercent = 0xFF
+
+// Sensor defines the address and name of a beacon, e.g., "A4:C1:38:0B:12:EF".
+type Sensor struct {
+	Address string
+	Name    string
+}
+
+// Config defines the names of beacons, how long a measurement remains valid,
+// and the battery voltage and level below which a beacon needs attention.
+type Config struct {
+	Sensors    []Sensor
+	Age        time.Duration
+	LowBattery uint16 // mV
+	LowPercent uint8  // %, for beacons that do not report their voltage
+}
+
+// Beacon is a Feed that polls the BLE controller for advertisements, and
+// expires measurements that are no longer recent.
+type Beacon struct {
+	scanner *ble.Device
+	config  Config
+	raw     [model.MaxRemotes]*history.Raw
+}
+
+// reading is a measurement decoded from an advertisement, some of whose values
+// may be missing, since MiBeacon advertises each value separately.
+type reading struct {
+	hasTemp bool
+	temp    float32 // °C
+	hum     uint8   // %, or model.NoHumidity
+	battery uint16  // mV, or 0
+	percent uint8   // %, or noPercent
+}
+
+// New returns a new Beacon receiving from the given BLE controller, which must
+// already be scanning.
+func New(scanner *ble.Device, config Config) *Beacon {
+
+	if config.Age == 0 {
+		config.Age = DefaultAge
+	}
+	if config.LowBattery == 0 {
+		config.LowBattery = DefaultLowBattery
+	}
+	if config.LowPercent == 0 {
+		config.LowPercent = DefaultLowPercent
+	}
+
+	b := &Beacon{scanner: scanner, config: config}
+	for i := range b.raw {
+		b.raw[i] = history.NewRaw("ble." + strconv.Itoa(i) + ".temperature")
+	}
+	return b
+}
+
+// Sync receives any advertisements waiting, and removes measurements from the
+// Model once they are no longer recent.
+func (b *Beacon) Sync() error {
+
+	for {
+		adv, ok := b.scanner.Receive()
+		if !ok {
+			break
+		}
+		b.receive(adv)
+	}
+
+	data := model.Peek()
+	for i, s := range data.Remote {
+		if s.Valid() && strings.HasPrefix(s.ID, source) &&
+			data.Time.Sub(s.Updated) > b.config.Age {
+			model.Set(func(m *model.Model) {
+				m.Remote[i] = model.Remote{}
+			})
+		}
+	}
+	return nil
+}
+
+// receive decodes the given advertisement, if it is of a known format, and
+// records its measurement in the slot of its beacon.
+func (b *Beacon) receive(adv ble.Advertisement) {
+
+	r := reading{hum: model.NoHumidity, percent: noPercent}
+	switch {
+	case decodeEnvironment(adv.ServiceData(uuidEnvironment), &r):
+	case decodeMiBeacon(adv.ServiceData(uuidMiBeacon), &r):
+	case decodeSwitchBot(adv.ServiceData(uuidSwitchBot), &r):
+	default:
+		return // not a known beacon
+	}
+
+	address := adv.Address.String()
+	name, ok := b.name(address)
+	if !ok {
+		return // not configured
+	}
+	key := source + address
+	data := model.Peek()
+	i := data.Remote.Slot(key)
+	if i < 0 {
+		return // no free slot
+	}
+	// merge with the previous measurement, if any
+	prev := data.Remote[i]
+	if key != prev.ID {
+		if !r.hasTemp {
+			return // nothing to show yet
+		}
+		prev = model.Remote{Humidity: model.NoHumidity}
+	}
+	if r.hasTemp {
+		prev.Temperature = r.temp
+		b.raw[i].Add(data.Time, r.temp)
+	}
+	if model.NoHumidity != r.hum {
+		prev.Humidity = r.hum
+	}
+	if 0 != r.battery {
+		prev.Battery = r.battery
+		prev.LowBattery = r.battery < b.config.LowBattery
+	} else if noPercent != r.percent {
+		prev.LowBattery = r.percent < b.config.LowPercent
+	}
+
+	model.Set(func(m *model.Model) {
+		prev.ID, prev.Name = key, name
+		prev.Updated = m.Time
+		prev.RSSI = int16(adv.RSSI)
+		m.Remote[i] = prev
+	})
+}
+
+// name returns the configured name of the beacon with the given address, or
+// its address if no sensors are configured. Returns ok false if sensors are
+// configured, but not the given one.
+func (b *Beacon) name(address string) (name string, ok bool) {
+	for _, s := range b.config.Sensors {
+		if strings.EqualFold(address, s.Address) {
+			return s.Name, true
+		}
+	}
+	// the last three bytes identify the device of its maker
+	return address[9:], 0 == len(b.config.Sensors)
+}
+
+// decodeEnvironment decodes the formats of the custom LYWSD03MMC firmware, and
+// returns true if d is either of them.
+func decodeEnvironment(d []byte, r *reading) bool {
+	switch len(d) {
+	case 13: // ATC1441: MAC, temperature 0.1 °C, humidity, battery %, mV, count
+		r.hasTemp = true
+		r.temp = float32(int16(uint16(d[6])<<8|uint16(d[7]))) / 10
+		r.hum = d[8]
+		r.percent = d[9]
+		r.battery = uint16(d[10])<<8 | uint16(d[11])
+		return true
+	case 15: // pvvx: MAC, temperature 0.01 °C, humidity 0.01 %, mV, battery %, …
+		r.hasTemp = true
+		r.temp = float32(int16(uint16(d[6])|uint16(d[7])<<8)) / 100
+		r.hum = uint8((uint16(d[8]) | uint16(d[9])<<8 + 50) / 100)
+		r.battery = uint16(d[10]) | uint16(d[11])<<8
+		r.percent = d[12]
+		return true
+	}
+	return false
+}
+
+// decodeMiBeacon decodes an unencrypted MiBeacon, and returns true if d is one
+// with a measurement.
+func decodeMiBeacon(d []byte, r *reading) bool {
+	if len(d) < 5 {
+		return false
+	}
+	// frame control, product ID, frame counter, then optional fields
+	fc := uint16(d[0]) | uint16(d[1])<<8
+	if 0 != fc&0x0008 || 0 == fc&0x0040 {
+		return false // encrypted, or no object
+	}
+	i := 5
+	if 0 != fc&0x0010 {
+		i += 6 // MAC
+	}
+	if 0 != fc&0x0020 {
+		i++ // capability
+	}
+	if len(d) < i+3 || len(d) < i+3+int(d[i+2]) {
+		return false
+	}
+	id, v := uint16(d[i])|uint16(d[i+1])<<8, d[i+3:i+3+int(d[i+2])]
+	switch {
+	case 0x1004 == id && len(v) >= 2: // temperature 0.1 °C
+		r.hasTemp = true
+		r.temp = float32(int16(uint16(v[0])|uint16(v[1])<<8)) / 10
+	case 0x1006 == id && len(v) >= 2: // humidity 0.1 %
+		r.hum = uint8((uint16(v[0]) | uint16(v[1])<<8 + 5) / 10)
+	case 0x100A == id && len(v) >= 1: // battery %
+		r.percent = v[0]
+	case 0x100D == id && len(v) >= 4: // temperature and humidity
+		r.hasTemp = true
+		r.temp = float32(int16(uint16(v[0])|uint16(v[1])<<8)) / 10
+		r.hum = uint8((uint16(v[2]) | uint16(v[3])<<8 + 5) / 10)
+	default:
+		return false
+	}
+	return true
+}
+
+// decodeSwitchBot decodes the advertisement of a SwitchBot meter, and returns
+// true if d is one.
+func decodeSwitchBot(d []byte, r *reading) bool {
+	if len(d) < 6 {
+		return false
+	}
+	switch d[0] & 0x7F { // device type
+	case 'T', 'i', 'w': // Meter, Meter Plus, Outdoor Meter
+	default:
+		return false
+	}
+	// temperature: tenths in low nibble of byte 3, integer and sign (set if
+	// positive) in byte 4
+	r.hasTemp = true
+	r.temp = float32(d[4]&0x7F) + float32(d[3]&0x0F)/10
+	if 0 == d[4]&0x80 {
+		r.temp = -r.temp
+	}
+	r.hum = d[5] & 0x7F
+	r.percent = d[2] & 0x7F
+	return true
+}
@@ -0,0 +1,120 @@
+// Package chime sounds the buzzer at the top of each hour, like a mantel clock.
+//
+// The chime is a melody, or a single beep if none is configured, followed by
+// one beep for each hour of the 12-hour clock if configured to count the hour.
+// It is silent during quiet hours, e.g., while the display sleeps overnight, and
+// on days it is not enabled.
+package chime
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/buzzer"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Config defines how the hour is chimed, and when the chime is silent.
+//
+// QuietFrom and QuietUntil are hours of the day, 0-23, and the chime is silent
+// from the beginning of QuietFrom until the beginning of QuietUntil, which may
+// span midnight. If both are equal, e.g., zero, the chime sounds at all hours.
+type Config struct {
+	Off        bool           // the chime never sounds
+	Melody     string         // RTTTL melody (see buzzer.Parse), or a beep if empty
+	Count      bool           // beep once for each hour after the melody
+	QuietFrom  int            // hour of the day quiet hours begin
+	QuietUntil int            // hour of the day quiet hours end
+	Days       []time.Weekday // days the chime sounds, or every day if empty
+}
+
+// Chime is a Feed that plays the chime on the buzzer once at the top of each
+// hour.
+type Chime struct {
+	buzzer *buzzer.Buzzer
+	config Config
+	melody []buzzer.Tone
+	hour   time.Time // hour most recently chimed
+}
+
+// New returns a new Chime using the given buzzer and configuration.
+// Returns buzzer.ErrInvalidMelody if the melody cannot be parsed.
+func New(b *buzzer.Buzzer, config Config) (*Chime, error) {
+	c := &Chime{buzzer: b}
+	if err := c.Set(config); nil != err {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Set changes the configuration of the Chime, e.g., when the Settings change.
+// Returns buzzer.ErrInvalidMelody if the melody cannot be parsed, in which case
+// the configuration is unchanged.
+func (c *Chime) Set(config Config) error {
+	melody := buzzer.Beep
+	if "" != config.Melody {
+		var err error
+		if melody, err = buzzer.Parse(config.Melody); nil != err {
+			return err
+		}
+	}
+	c.config, c.melody = config, melody
+	return nil
+}
+
+// Sync plays the chime if the hour has begun within the past minute and has
+// not yet been chimed, unless the chime is silent at this hour.
+func (c *Chime) Sync() error {
+
+	now := model.Peek().Time
+	if 0 != now.Minute() {
+		return nil
+	}
+	hour := now.Truncate(time.Hour)
+	if hour.Equal(c.hour) {
+		return nil
+	}
+	c.hour = hour
+
+	if c.config.Off || c.quiet(now.Hour()) || !c.enabled(now.Weekday()) {
+		return nil
+	}
+	if !c.config.Count {
+		c.buzzer.Play(c.melody)
+		return nil
+	}
+	n := now.Hour() % 12
+	if 0 == n {
+		n = 12
+	}
+	tone := append(make([]buzzer.Tone, 0, len(c.melody)+n), c.melody...)
+	for i := 0; i < n; i++ {
+		tone = append(tone, buzzer.Beep...)
+	}
+	c.buzzer.Play(tone)
+	return nil
+}
+
+// quiet returns true if the given hour of the day is within quiet hours.
+func (c *Chime) quiet(hour int) bool {
+	from, until := c.config.QuietFrom, c.config.QuietUntil
+	if from == until {
+		return false
+	}
+	if from < until {
+		return hour >= from && hour < until
+	}
+	return hour >= from || hour < until // spans midnight
+}
+
+// enabled returns true if the chime sounds on the given day of the week.
+func (c *Chime) enabled(day time.Weekday) bool {
+	if 0 == len(c.config.Days) {
+		return true
+	}
+	for _, d := range c.config.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Package control performs command lines published to an MQTT topic, e.g., by
+// Home Assistant buttons, so that the device is controlled with the same
+// commands accepted by the serial console and the HTTP API (see package
+// command).
+//
+// The message payload is either a plain command line, e.g., "page next", or a
+// JSON object containing one:
+//
+//	{"command": "set brightness 40", "secret": "hunter2"}
+//
+// If a secret is set in the Settings (see config.Authorized), messages must be
+// JSON objects with a field "secret" of the same value, and all others are
+// ignored.
+//
+// The output of each command, followed by the line "ok" or the error returned,
+// is published to the result topic.
+package control
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/command"
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for Control configuration.
+const (
+	DefaultTopic  = "~/command"
+	DefaultResult = "~/command/result"
+)
+
+// MaxLine defines the length of the longest command line accepted.
+const MaxLine = 80
+
+// Config defines the MQTT topics of command lines and their results.
+type Config struct {
+	Topic  string
+	Result string
+}
+
+// Control is a Feed that performs command lines received from an MQTT topic.
+//
+// Lines are performed as they are received, but their results are published by
+// Sync, outside of the MQTT client's dispatch of received messages.
+type Control struct {
+	client *mqtt.Client
+	config Config
+	result []byte // output of the most recent command, until published
+}
+
+// New returns a new Control subscribed to the configured topic using the given
+// MQTT client.
+func New(client *mqtt.Client, config Config) (*Control, error) {
+
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+	if config.Result == "" {
+		config.Result = DefaultResult
+	}
+
+	c := &Control{client: client, config: config}
+	if err := client.Subscribe(config.Topic, c.receive); nil != err {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Sync publishes the result of the most recent command, if not yet published.
+func (c *Control) Sync() error {
+	if nil == c.result {
+		return nil
+	}
+	result := c.result
+	c.result = nil
+	return c.client.Publish(c.config.Result, result, false)
+}
+
+func (c *Control) receive(topic string, payload []byte) {
+
+	line := string(bytes.TrimSpace(payload))
+
+	var secret string
+	if strings.HasPrefix(line, "{") {
+		line = ""
+		err := json.Extract(bytes.NewReader(payload), func(path []byte, kind json.Kind, value []byte) error {
+			switch string(path) {
+			case "command":
+				line = string(value)
+			case "secret":
+				secret = string(value)
+			}
+			return nil
+		})
+		if nil != err {
+			journal.Println("error: " + topic + ": " + err.Error())
+			return
+		}
+	}
+
+	if !config.Authorized(secret) {
+		journal.Println("error: " + topic + ": unauthorized command ignored")
+		return
+	}
+	if "" == line || len(line) > MaxLine {
+		return
+	}
+
+	var out bytes.Buffer
+	if err := command.Exec(&out, line); nil != err {
+		out.WriteString(err.Error() + "\n")
+	} else {
+		out.WriteString("ok\n")
+	}
+	c.result = out.Bytes()
+}
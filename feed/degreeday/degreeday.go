@@ -0,0 +1,172 @@
+// Package degreeday accumulates the heating, cooling, and growing degree days
+// of each month (see model.DegreeDays).
+//
+// The high and low temperature of each day are the highest and lowest of the
+// current conditions observed that day, as with package accuracy, and the
+// degree days of each day are accumulated once it has ended. Days that were not
+// observed from shortly after midnight are not accumulated, since their high or
+// low may have been missed. The totals of the current and previous month are
+// stored in the Model.
+package degreeday
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Default constants for DegreeDay configuration, °C.
+const (
+	DefaultHeating = 18 // about 65°F, conventional for buildings
+	DefaultCooling = 18
+	DefaultGrowing = 10 // about 50°F, typical of corn and most vegetables
+	DefaultCap     = 30 // about 86°F
+)
+
+// Config defines the base temperatures of each kind of degree day, °C. Nil
+// selects the default of each, so that any temperature, including 0 °C, may be
+// configured (see Celsius).
+type Config struct {
+	Heating *float32 // mean temperature below which heating is needed
+	Cooling *float32 // mean temperature above which cooling is needed
+	Growing *float32 // temperature below which plants do not develop
+	Cap     *float32 // temperature above which plants develop no faster
+}
+
+// lateStart defines how long after midnight observation of a day may begin for
+// the day to be accumulated.
+const lateStart = time.Hour
+
+// Celsius returns a base temperature of Config.
+func Celsius(t float32) *float32 {
+	return &t
+}
+
+// DegreeDay is a Feed that observes the current temperature each time the
+// weather is updated, and accumulates the high and low at the end of the day.
+type DegreeDay struct {
+	base      model.DegreeBase
+	updated   time.Time
+	day       time.Time // local midnight of the day observed
+	since     time.Time // when observation of the day began
+	high, low float32   // observed so far
+	observed  bool
+}
+
+// New returns a new DegreeDay using the given configuration.
+func New(config Config) *DegreeDay {
+
+	if nil == config.Heating {
+		config.Heating = Celsius(DefaultHeating)
+	}
+	if nil == config.Cooling {
+		config.Cooling = Celsius(DefaultCooling)
+	}
+	if nil == config.Growing {
+		config.Growing = Celsius(DefaultGrowing)
+	}
+	if nil == config.Cap {
+		config.Cap = Celsius(DefaultCap)
+	}
+
+	base := model.DegreeBase{
+		Heating: *config.Heating,
+		Cooling: *config.Cooling,
+		Growing: *config.Growing,
+		Cap:     *config.Cap,
+	}
+	model.Mod(func(m *model.Model) {
+		m.DegreeDays.Base = base
+	})
+	return &DegreeDay{base: base}
+}
+
+// Sync accumulates the day observed once it has ended, and observes the current
+// temperature if the weather has been updated since the previous call.
+func (d *DegreeDay) Sync() error {
+
+	data := model.Peek()
+	if !data.Weather.Valid() || !data.Weather.Updated.After(d.updated) {
+		return nil
+	}
+	d.updated = data.Weather.Updated
+
+	now := data.Time
+	if day := midnight(now); !day.Equal(d.day) {
+		if d.observed && d.since.Sub(d.day) <= lateStart {
+			d.accumulate()
+		}
+		d.day, d.since, d.observed = day, now, false
+	}
+
+	t := data.Weather.Current.Temperature
+	if !d.observed || t > d.high {
+		d.high = t
+	}
+	if !d.observed || t < d.low {
+		d.low = t
+	}
+	d.observed = true
+	return nil
+}
+
+// accumulate adds the degree days of the day observed to the totals of its month,
+// beginning a new month if needed.
+func (d *DegreeDay) accumulate() {
+	heat, cool, grow := d.degrees(d.high, d.low)
+	start := d.day.AddDate(0, 0, 1-d.day.Day())
+	model.Set(func(m *model.Model) {
+		dd := &m.DegreeDays
+		if !dd.Month.Start.Equal(start) {
+			if dd.Month.Start.AddDate(0, 1, 0).Equal(start) {
+				dd.Previous = dd.Month
+			} else {
+				dd.Previous = model.DegreeMonth{} // months were skipped
+			}
+			dd.Month = model.DegreeMonth{Start: start}
+		}
+		dd.Month.Days++
+		dd.Month.Heating += heat
+		dd.Month.Cooling += cool
+		dd.Month.Growing += grow
+	})
+}
+
+// degrees returns the heating, cooling, and growing degree days of a day with
+// the given high and low temperature.
+//
+// Growing degree days are computed by the modified average method, in which
+// the high and low are each limited to the range from the base to the cap.
+func (d *DegreeDay) degrees(high, low float32) (heat, cool, grow float32) {
+	mean := (high + low) / 2
+	heat = positive(d.base.Heating - mean)
+	cool = positive(mean - d.base.Cooling)
+	grow = (limit(high, d.base.Growing, d.base.Cap)+
+		limit(low, d.base.Growing, d.base.Cap))/2 - d.base.Growing
+	return heat, cool, grow
+}
+
+// positive returns v if it is positive, otherwise 0.
+func positive(v float32) float32 {
+	if v > 0 {
+		return v
+	}
+	return 0
+}
+
+// limit returns v limited to the range [lo, hi].
+func limit(v, lo, hi float32) float32 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// midnight returns local midnight of the day of time t.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
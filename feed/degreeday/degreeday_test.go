@@ -0,0 +1,52 @@
+package degreeday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+func TestNewBase(t *testing.T) {
+	d := New(Config{Heating: Celsius(0), Growing: Celsius(5)})
+	if 0 != d.base.Heating || DefaultCooling != d.base.Cooling ||
+		5 != d.base.Growing || DefaultCap != d.base.Cap {
+		t.Errorf("base = %+v", d.base)
+	}
+	// a day averaging -4 °C needs 4 degree days of heating above a 0 °C base
+	if heat, _, _ := d.degrees(2, -10); 4 != heat {
+		t.Errorf("heating degree days = %v, want 4", heat)
+	}
+}
+
+func TestSyncObserved(t *testing.T) {
+	d := New(Config{})
+	day := time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC)
+	observe := func(at time.Duration, temp float32) {
+		model.Set(func(m *model.Model) {
+			m.Time = day.Add(at)
+			m.Weather.Updated = m.Time
+			m.Weather.Current.Temperature = temp
+			m.Weather.Day[0] = model.Day{Date: day, High: 40, Low: 30} // ignored
+		})
+		d.Sync()
+	}
+	observe(10*time.Minute, 4)
+	observe(14*time.Hour, 12)
+	observe(23*time.Hour, 6)
+	observe(25*time.Hour, 5) // the next day
+	month := model.Peek().DegreeDays.Month
+	// the observed high and low average 8 °C, 10 below the heating base
+	if 1 != month.Days || 10 != month.Heating || 0 != month.Cooling {
+		t.Errorf("month = %+v, want 1 day of 10 heating degree days", month)
+	}
+	// a day observed from 02:00 is not accumulated
+	observe(50*time.Hour, 5)
+	if n := model.Peek().DegreeDays.Month.Days; 2 != n {
+		t.Errorf("days = %d, want 2", n)
+	}
+	observe(74*time.Hour, 5)
+	if n := model.Peek().DegreeDays.Month.Days; 2 != n {
+		t.Errorf("days = %d after a late start, want 2", n)
+	}
+}
@@ -0,0 +1,273 @@
+// Package esphome publishes the sensors and switches of the device to Home
+// Assistant using the MQTT flavor of the ESPHome API, so that they appear as
+// entities of one device without any YAML configuration.
+//
+// As ESPHome does, each entity is announced by a retained discovery message,
+// and its state is published to topics under the prefix of the device (see
+// mqtt.Config Prefix):
+//
+//	~/status               "online", or "offline" (last will)
+//	~/sensor/<id>/state    measurement, e.g., "21.5"
+//	~/switch/<id>/state    "ON" or "OFF"
+//	~/switch/<id>/command  "ON", "OFF", or "TOGGLE", from Home Assistant
+//
+// Commands from Home Assistant carry no secret, so while a Secret is set in the
+// Settings (see config.Authorized), they are ignored unless the broker is
+// trusted instead (see Config TrustBroker), i.e., only trusted clients may
+// publish to the command topics.
+//
+// The native ESPHome API, a protobuf protocol on a TCP port of its own, is not
+// implemented, so the MQTT integration of Home Assistant must be configured
+// with the same broker.
+package esphome
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for ESPHome configuration.
+const (
+	DefaultDiscovery = "homeassistant"
+	DefaultInterval  = time.Minute
+)
+
+var (
+	ErrNoBroker = errors.New("ESPHome entities require an MQTT broker")
+)
+
+// Config defines the discovery prefix of Home Assistant, how often the state of
+// every entity is published, and whether switch commands are accepted even if
+// a Secret is set.
+type Config struct {
+	Discovery   string
+	Interval    time.Duration
+	TrustBroker bool // the broker admits only trusted clients
+}
+
+// Switch is an entity that Home Assistant may turn on and off, e.g., the
+// display panel.
+type Switch struct {
+	ID   string // e.g., "display"
+	Name string // e.g., "Display"
+	Get  func() bool
+	Set  func(on bool)
+}
+
+// sensor is a measurement of the Model published as a sensor entity.
+type sensor struct {
+	id, name string
+	unit     string
+	class    string // device class of Home Assistant
+	prec     int    // digits after the decimal point
+	value    func(m model.Model) (v float32, ok bool)
+}
+
+// sensors lists every sensor entity, in order of discovery.
+var sensors = []sensor{
+	{"outdoor_temperature", "Outdoor temperature", "°C", "temperature", 1,
+		func(m model.Model) (float32, bool) {
+			return m.Weather.Current.Temperature, m.Weather.Valid()
+		}},
+	{"outdoor_humidity", "Outdoor humidity", "%", "humidity", 0,
+		func(m model.Model) (float32, bool) {
+			return float32(m.Weather.Current.Humidity), m.Weather.Valid()
+		}},
+	{"indoor_temperature", "Indoor temperature", "°C", "temperature", 1,
+		func(m model.Model) (float32, bool) {
+			return m.Indoor.Temperature, m.Indoor.Valid()
+		}},
+	{"indoor_humidity", "Indoor humidity", "%", "humidity", 0,
+		func(m model.Model) (float32, bool) {
+			return m.Indoor.Humidity, m.Indoor.Valid()
+		}},
+}
+
+func init() {
+	// each slot of the remote sensors, whichever sensor occupies it
+	for i := 0; i < model.MaxRemotes; i++ {
+		i, n := i, strconv.Itoa(i+1)
+		sensors = append(sensors,
+			sensor{"remote_" + n + "_temperature", "Remote " + n + " temperature",
+				"°C", "temperature", 1,
+				func(m model.Model) (float32, bool) {
+					return m.Remote[i].Temperature, m.Remote[i].Valid()
+				}},
+			sensor{"remote_" + n + "_humidity", "Remote " + n + " humidity",
+				"%", "humidity", 0,
+				func(m model.Model) (float32, bool) {
+					r := m.Remote[i]
+					return float32(r.Humidity), r.Valid() && model.NoHumidity != r.Humidity
+				}})
+	}
+}
+
+// ESPHome is a Feed that announces the entities of the device whenever the
+// broker is connected, and publishes their state periodically.
+type ESPHome struct {
+	broker    *mqtt.Client
+	config    Config
+	switches  []Switch
+	node      string    // node ID of discovery topics
+	announced bool      // discovery messages published since connecting
+	published time.Time // when states were last published
+	pending   bool      // a switch has changed since states were published
+}
+
+// New returns a new ESPHome announcing the given switches and the sensors of
+// the Model with the given MQTT client and configuration.
+func New(broker *mqtt.Client, config Config, switches ...Switch) (*ESPHome, error) {
+
+	if config.Discovery == "" {
+		config.Discovery = DefaultDiscovery
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+	if nil == broker {
+		return nil, ErrNoBroker
+	}
+
+	e := &ESPHome{
+		broker:   broker,
+		config:   config,
+		switches: switches,
+		node:     nodeID(broker.Prefix()),
+	}
+	broker.SetWill("~/status", []byte("offline"))
+	if err := broker.Subscribe("~/switch/+/command", e.command); nil != err {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Sync announces every entity once connected, and publishes the state of every
+// entity periodically, or immediately once a switch has changed.
+func (e *ESPHome) Sync() error {
+
+	if !e.broker.Connected() {
+		e.announced = false
+		return nil
+	}
+	if !e.announced {
+		if err := e.announce(); nil != err {
+			return err
+		}
+		e.announced, e.pending = true, true
+	}
+	if !e.pending && time.Since(e.published) < e.config.Interval {
+		return nil
+	}
+
+	data := model.Peek()
+	for _, s := range sensors {
+		v, ok := s.value(data)
+		if !ok {
+			continue
+		}
+		value := strconv.FormatFloat(float64(v), 'f', s.prec, 32)
+		if err := e.broker.Publish("~/sensor/"+s.id+"/state", []byte(value), false); nil != err {
+			return err
+		}
+	}
+	for _, s := range e.switches {
+		if err := e.broker.Publish("~/switch/"+s.ID+"/state", onOff(s.Get()), true); nil != err {
+			return err
+		}
+	}
+	e.published, e.pending = time.Now(), false
+	return nil
+}
+
+// announce publishes the availability of the device and the discovery message
+// of every entity.
+func (e *ESPHome) announce() error {
+
+	if err := e.broker.Publish("~/status", []byte("online"), true); nil != err {
+		return err
+	}
+	device := `, "~": "` + e.broker.Prefix() + `", "avty_t": "~/status"` +
+		`, "dev": {"ids": ["` + e.node + `"], "name": "` + e.node +
+		`", "mf": "weatherhub", "mdl": "Matrix Portal M4"}}`
+	for _, s := range sensors {
+		payload := `{"name": "` + s.name + `", "uniq_id": "` + e.node + "_" + s.id +
+			`", "stat_t": "~/sensor/` + s.id + `/state", "unit_of_meas": "` + s.unit +
+			`", "dev_cla": "` + s.class + `", "stat_cla": "measurement"` + device
+		if err := e.broker.Publish(e.discovery("sensor", s.id), []byte(payload), true); nil != err {
+			return err
+		}
+	}
+	for _, s := range e.switches {
+		payload := `{"name": "` + s.Name + `", "uniq_id": "` + e.node + "_" + s.ID +
+			`", "stat_t": "~/switch/` + s.ID + `/state", "cmd_t": "~/switch/` + s.ID +
+			`/command"` + device
+		if err := e.broker.Publish(e.discovery("switch", s.ID), []byte(payload), true); nil != err {
+			return err
+		}
+	}
+	journal.Println("esphome: announced " +
+		strconv.Itoa(len(sensors)+len(e.switches)) + " entities")
+	return nil
+}
+
+// discovery returns the topic of the discovery message of the entity with the
+// given component, e.g., "sensor", and ID.
+func (e *ESPHome) discovery(component, id string) string {
+	return e.config.Discovery + "/" + component + "/" + e.node + "/" + id + "/config"
+}
+
+// command turns the switch named by the given command topic on or off, if
+// authorized.
+func (e *ESPHome) command(topic string, payload []byte) {
+	if !e.config.TrustBroker && !config.Authorized("") {
+		journal.Println("error: " + topic + ": unauthorized command ignored")
+		return
+	}
+	// <prefix>/switch/<id>/command
+	topic = strings.TrimSuffix(topic, "/command")
+	id := topic[strings.LastIndexByte(topic, '/')+1:]
+	for _, s := range e.switches {
+		if id != s.ID {
+			continue
+		}
+		switch string(payload) {
+		case "ON":
+			s.Set(true)
+		case "OFF":
+			s.Set(false)
+		case "TOGGLE":
+			s.Set(!s.Get())
+		default:
+			return
+		}
+		e.pending = true
+	}
+}
+
+// onOff returns the state payload of a switch.
+func onOff(on bool) []byte {
+	if on {
+		return []byte("ON")
+	}
+	return []byte("OFF")
+}
+
+// nodeID returns the given name with every character not allowed in the node
+// ID of a discovery topic replaced by '_'.
+func nodeID(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+			'0' <= c && c <= '9' || '-' == c || '_' == c) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
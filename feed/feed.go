@@ -0,0 +1,100 @@
+// Package feed defines the interface common to all supplementary data sources,
+// such as transit departures, that are polled periodically by the run loop.
+package feed
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/profile"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Feed is a data source synchronized periodically with a remote service.
+type Feed interface {
+	// Sync polls the remote service if the Feed's update is due (see Due),
+	// storing the result in the Model.
+	// Sync is called on every iteration of the run loop while the system time is
+	// synchronized, so it must return immediately if no update is due.
+	Sync() error
+}
+
+// Offline is implemented by feeds that require no network, e.g., those reading
+// the buttons or serial port of the device, which the run loop also polls while
+// the system time is not synchronized, e.g., in demo mode (see package demo).
+type Offline interface {
+	Feed
+	// Offline has no effect. It only identifies the Feed as Offline.
+	Offline()
+}
+
+// Expired returns true if the given span of time has elapsed between times
+// since and at, or if either time is undefined (zero).
+func Expired(at, since time.Time, span time.Duration) bool {
+	return at.IsZero() || since.IsZero() || at.Sub(since) >= span
+}
+
+// InWindow returns true if the time of day of t is within the window beginning
+// at start and ending before end, both given as offsets from midnight.
+// The window may wrap around midnight. If start equals end, the window is empty.
+func InWindow(t time.Time, start, end time.Duration) bool {
+	h, m, s := t.Clock()
+	at := time.Duration(h)*time.Hour +
+		time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if start <= end {
+		return at >= start && at < end
+	}
+	return at >= start || at < end
+}
+
+// InSeason returns true if the month of t is within the season beginning with
+// month start and ending with month end, inclusive. The season may wrap around
+// the new year, e.g., December through March.
+func InSeason(t time.Time, start, end time.Month) bool {
+	m := t.Month()
+	if start <= end {
+		return m >= start && m <= end
+	}
+	return m >= start || m <= end
+}
+
+// FetchJSON requests the JSON document at the given URL, calling handle for
+// each of the scalar values it contains (see package codec/json).
+func FetchJSON(client *http.Client, url string, handle json.Handler) error {
+	res, err := client.Get(url)
+	if nil != err {
+		return err
+	}
+	defer res.Close()
+	if !res.OK() {
+		return http.ErrStatus
+	}
+	defer profile.Stop(profile.Parse, profile.Start())
+	return json.Extract(res.Body, handle)
+}
+
+// FetchJSONIfModified is like FetchJSON, but requests the document
+// conditionally, returning false without calling handle if the document has not
+// changed since it was last fetched.
+func FetchJSONIfModified(client *http.Client, url string, handle json.Handler) (bool, error) {
+	res, err := client.GetIfModified(url)
+	if nil != err {
+		return false, err
+	}
+	defer res.Close()
+	if res.NotModified() {
+		return false, nil
+	}
+	if !res.OK() {
+		return false, http.ErrStatus
+	}
+	start := profile.Start()
+	err = json.Extract(res.Body, handle)
+	profile.Stop(profile.Parse, start)
+	if nil != err {
+		// the document must be fetched again, since it was never used.
+		client.Forget(url)
+		return false, err
+	}
+	return true, nil
+}
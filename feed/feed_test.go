@@ -0,0 +1,31 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInWindow(t *testing.T) {
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name       string
+		at         time.Duration
+		start, end time.Duration
+		want       bool
+	}{
+		{"before", 6 * time.Hour, 7 * time.Hour, 9 * time.Hour, false},
+		{"start", 7 * time.Hour, 7 * time.Hour, 9 * time.Hour, true},
+		{"within", 8 * time.Hour, 7 * time.Hour, 9 * time.Hour, true},
+		{"end", 9 * time.Hour, 7 * time.Hour, 9 * time.Hour, false},
+		{"wrap late", 23 * time.Hour, 22 * time.Hour, 2 * time.Hour, true},
+		{"wrap early", time.Hour, 22 * time.Hour, 2 * time.Hour, true},
+		{"wrap outside", 12 * time.Hour, 22 * time.Hour, 2 * time.Hour, false},
+		{"empty", 7 * time.Hour, 7 * time.Hour, 7 * time.Hour, false},
+		{"empty midnight", 0, 0, 0, false},
+	} {
+		if got := InWindow(day.Add(tc.at), tc.start, tc.end); got != tc.want {
+			t.Errorf("%s: InWindow(%v, %v, %v) = %v, want %v",
+				tc.name, tc.at, tc.start, tc.end, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package fake answers the requests of the forecast and geo feeds made over the
+// emulated network of package wifi/fake with plausible, evolving data, so that
+// the display can be developed without any network at all.
+//
+// The location is always the same, and the temperature follows a daily curve,
+// coolest near dawn and warmest mid-afternoon, drifting from day to day. The
+// conditions change every few hours at random, but the same hours always have
+// the same conditions, so that the forecast agrees with itself between polls.
+//
+// The package registers its handlers when imported, which the main package does
+// only if built with the tag "fake".
+package fake
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/fake"
+)
+
+// Hosts answered by the package, those of forecast.DefaultURL and
+// geo.DefaultURL.
+const (
+	ForecastHost = "api.open-meteo.com"
+	GeoHost      = "ip-api.com"
+)
+
+// The fictional location of the device, in a time zone without daylight saving
+// time, so that its offset never changes.
+const (
+	city      = "Fakeville"
+	latitude  = 50.45
+	longitude = -104.61
+	zone      = "America/Regina"
+	offset    = -6 * 60 * 60 // seconds east of UTC
+)
+
+// Constants of the synthetic weather.
+const (
+	meanTemp  = 14.0          // °C, mean of the daily curve
+	swingTemp = 8.0           // °C, half the range of the daily curve
+	driftTemp = 6.0           // °C, greatest departure of a day from the mean
+	warmest   = 15            // local hour of the warmest temperature
+	block     = 3 * time.Hour // conditions persist at least this long
+)
+
+// conditions are the weather codes chosen among at random, weighted by the
+// number of times each is listed.
+var conditions = []model.Code{
+	model.CodeClear, model.CodeClear, model.CodeMainlyClear, model.CodeMainlyClear,
+	model.CodePartlyCloudy, model.CodePartlyCloudy, model.CodeOvercast,
+	model.CodeFog, model.CodeDrizzle, model.CodeRain, model.CodeShowers,
+	model.CodeThunderstorm,
+}
+
+func init() {
+	fake.Handle(ForecastHost, forecast)
+	fake.Handle(GeoHost, geo)
+}
+
+// geo replies with the fictional location, in the form of ip-api.com.
+func geo(path string) (int, []byte) {
+	b := []byte(`{"status":"success","city":"` + city + `"`)
+	b = appendField(b, "lat", latitude)
+	b = appendField(b, "lon", longitude)
+	b = append(b, `,"timezone":"`+zone+`","offset":`+strconv.Itoa(offset)+`}`...)
+	return 200, b
+}
+
+// forecast replies with the synthetic weather as of now, in the form of
+// Open-Meteo, regardless of the location requested.
+func forecast(path string) (int, []byte) {
+
+	now := time.Now()
+	hour := now.Truncate(time.Hour)
+	day := local(now).Truncate(24 * time.Hour).Add(-offset * time.Second)
+	quarter := now.Truncate(15 * time.Minute)
+
+	b := []byte(`{"current":{"time":` + strconv.FormatInt(now.Unix(), 10))
+	b = appendField(b, "temperature_2m", temperature(now))
+	b = appendField(b, "relative_humidity_2m", humidity(now))
+	b = appendField(b, "weather_code", float64(code(now)))
+	b = appendField(b, "wind_speed_10m", wind(now))
+	b = appendField(b, "wind_gusts_10m", 2*wind(now))
+
+	b = append(b, `},"minutely_15":{`...)
+	b = appendArray(b, "time", 7, func(i int) float64 {
+		return float64(quarter.Add(time.Duration(i) * 15 * time.Minute).Unix())
+	})
+	b = appendArray(b, "precipitation", 7, func(i int) float64 {
+		return rain(quarter.Add(time.Duration(i)*15*time.Minute)) / 4
+	})
+
+	at := func(i int) time.Time { return hour.Add(time.Duration(i) * time.Hour) }
+	b = append(b, `},"hourly":{`...)
+	b = appendArray(b, "time", 24, func(i int) float64 { return float64(at(i).Unix()) })
+	b = appendArray(b, "temperature_2m", 24, func(i int) float64 { return temperature(at(i)) })
+	b = appendArray(b, "relative_humidity_2m", 24, func(i int) float64 { return humidity(at(i)) })
+	b = appendArray(b, "precipitation_probability", 24, func(i int) float64 { return chance(at(i)) })
+	b = appendArray(b, "precipitation", 24, func(i int) float64 { return rain(at(i)) })
+	b = appendArray(b, "snowfall", 24, func(i int) float64 { return 0 })
+	b = appendArray(b, "weather_code", 24, func(i int) float64 { return float64(code(at(i))) })
+	b = appendArray(b, "wind_gusts_10m", 24, func(i int) float64 { return 2 * wind(at(i)) })
+
+	date := func(i int) time.Time { return day.AddDate(0, 0, i) }
+	b = append(b, `},"daily":{`...)
+	b = appendArray(b, "time", 3, func(i int) float64 { return float64(date(i).Unix()) })
+	b = appendArray(b, "weather_code", 3, func(i int) float64 {
+		return float64(code(date(i).Add(warmest * time.Hour)))
+	})
+	b = appendArray(b, "temperature_2m_max", 3, func(i int) float64 {
+		return temperature(date(i).Add(warmest * time.Hour))
+	})
+	b = appendArray(b, "temperature_2m_min", 3, func(i int) float64 {
+		return temperature(date(i).Add((warmest - 12) * time.Hour))
+	})
+	b = appendArray(b, "precipitation_probability_max", 3, func(i int) float64 {
+		return chance(date(i).Add(warmest * time.Hour))
+	})
+	b = appendArray(b, "precipitation_sum", 3, func(i int) float64 {
+		sum := 0.0
+		for h := 0; h < 24; h++ {
+			sum += rain(date(i).Add(time.Duration(h) * time.Hour))
+		}
+		return round(sum)
+	})
+
+	return 200, append(b, "}}"...)
+}
+
+// local returns t shifted by the offset of the fictional location, such that
+// its fields in UTC are those of the local time.
+func local(t time.Time) time.Time {
+	return t.UTC().Add(offset * time.Second)
+}
+
+// temperature returns the temperature (°C) at time t.
+func temperature(t time.Time) float64 {
+	l := local(t)
+	h := float64(l.Hour()) + float64(l.Minute())/60
+	curve := swingTemp * math.Cos(2*math.Pi*(h-warmest)/24)
+	drift := driftTemp * (2*random(l.Truncate(24*time.Hour), 0) - 1)
+	return round(meanTemp + drift + curve)
+}
+
+// humidity returns the relative humidity (%) at time t, which is highest when
+// the temperature is lowest, and high while precipitating.
+func humidity(t time.Time) float64 {
+	h := 60 - 2*(temperature(t)-meanTemp)
+	if rain(t) > 0 {
+		h += 25
+	}
+	return math.Round(math.Max(15, math.Min(100, h)))
+}
+
+// wind returns the wind speed (m/s) at time t.
+func wind(t time.Time) float64 {
+	return round(1 + 7*random(t.Truncate(block), 1))
+}
+
+// code returns the weather code at time t, which is the same throughout each
+// block.
+func code(t time.Time) model.Code {
+	return conditions[int(random(t.Truncate(block), 2)*float64(len(conditions)))]
+}
+
+// chance returns the probability of precipitation (%) at time t.
+func chance(t time.Time) float64 {
+	if rain(t) > 0 {
+		return math.Round(60 + 40*random(t.Truncate(block), 3))
+	}
+	return math.Round(20 * random(t.Truncate(block), 3))
+}
+
+// rain returns the precipitation (mm) in the hour beginning at time t.
+func rain(t time.Time) float64 {
+	switch code(t) {
+	case model.CodeDrizzle:
+		return 0.2
+	case model.CodeRain, model.CodeShowers:
+		return round(0.5 + 3*random(t.Truncate(time.Hour), 4))
+	case model.CodeThunderstorm:
+		return round(4 + 8*random(t.Truncate(time.Hour), 4))
+	}
+	return 0
+}
+
+// random returns a number in [0, 1) derived from time t and a seed, so that it
+// is the same for the same arguments.
+func random(t time.Time, seed byte) float64 {
+	h := fnv.New32a()
+	u := t.Unix()
+	h.Write([]byte{seed, byte(u >> 32), byte(u >> 24), byte(u >> 16), byte(u >> 8), byte(u)})
+	return float64(h.Sum32()) / (1 << 32)
+}
+
+// round returns x rounded to one decimal place.
+func round(x float64) float64 {
+	return math.Round(10*x) / 10
+}
+
+// appendField appends a JSON member with the given name and number value,
+// preceded by a comma.
+func appendField(b []byte, name string, value float64) []byte {
+	b = append(b, `,"`+name+`":`...)
+	return strconv.AppendFloat(b, value, 'f', -1, 64)
+}
+
+// appendArray appends a JSON member with the given name and an array of n
+// numbers, each returned by value, preceded by a comma unless it is the first
+// member of an object.
+func appendArray(b []byte, name string, n int, value func(i int) float64) []byte {
+	if '{' != b[len(b)-1] {
+		b = append(b, ',')
+	}
+	b = append(b, `"`+name+`":[`...)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendFloat(b, value(i), 'f', -1, 64)
+	}
+	return append(b, ']')
+}
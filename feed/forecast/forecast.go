@@ -0,0 +1,264 @@
+// Package forecast implements a Feed of the current weather conditions and
+// forecast at the device Location.
+//
+// The forecast is retrieved from Open-Meteo (open-meteo.com), which requires no
+// API key. Any service replying with the same JSON document may be used instead
+// by configuring its URL.
+package forecast
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/history"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Forecast configuration.
+const (
+	DefaultURL = "https://api.open-meteo.com/v1/forecast" +
+		"?latitude={lat}&longitude={lon}" +
+		"&current=temperature_2m,relative_humidity_2m,weather_code,wind_speed_10m,wind_gusts_10m" +
+		"&minutely_15=precipitation&forecast_minutely_15=7" +
+		"&hourly=temperature_2m,relative_humidity_2m,precipitation_probability,precipitation,snowfall,weather_code,wind_gusts_10m" +
+		"&daily=weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max,precipitation_sum" +
+		"&forecast_hours=24&forecast_days=3&timezone=auto&timeformat=unixtime&wind_speed_unit=ms"
+	DefaultInterval = 15 * time.Minute
+)
+
+// nowcastSlots defines the number of 15-minute intervals of precipitation read
+// from the reply, which must span MaxMinutes beginning with the current minute.
+const nowcastSlots = 7
+
+// slot is a 15-minute interval of precipitation.
+type slot struct {
+	time time.Time
+	rain float32 // millimeters
+}
+
+// Config defines the forecast service and how often it is polled.
+//
+// Smoothing is the window of the moving average of the temperature, humidity,
+// and wind speed of the current conditions, which otherwise jitter between
+// polls, or zero to use the conditions as reported. Gusts are never smoothed,
+// since they are peaks by nature.
+type Config struct {
+	URL       string // placeholders {lat} and {lon} are replaced with Location
+	Interval  time.Duration
+	Smoothing time.Duration
+}
+
+// Forecast polls a forecast service for the weather at the device Location.
+type Forecast struct {
+	client   *http.Client
+	config   Config
+	lastSync time.Time
+	smooth   [3]history.EMA  // temperature, humidity, wind speed
+	raw      [3]*history.Raw // as reported, for logging
+}
+
+// New returns a new Forecast using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Forecast {
+
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Forecast{client: client, config: config, raw: [3]*history.Raw{
+		history.NewRaw("current.temperature"),
+		history.NewRaw("current.humidity"),
+		history.NewRaw("current.wind"),
+	}}
+}
+
+// Sync polls the forecast service if the Location is known and the polling
+// interval has elapsed.
+func (f *Forecast) Sync() error {
+
+	data := model.Peek()
+	if !data.Location.Known() {
+		return nil // wait for geolocation
+	}
+	if !feed.Due(data.Time, f.lastSync, f.config.Interval) {
+		return nil
+	}
+	f.lastSync = data.Time
+
+	lat := strconv.FormatFloat(data.Location.Latitude, 'f', 4, 64)
+	lon := strconv.FormatFloat(data.Location.Longitude, 'f', 4, 64)
+	url := strings.Replace(f.config.URL, "{lat}", lat, 1)
+	url = strings.Replace(url, "{lon}", lon, 1)
+
+	loc := data.Time.Location()
+	w := model.Weather{Updated: data.Time}
+	var slots [nowcastSlots]slot
+	modified, err := feed.FetchJSONIfModified(f.client, url, func(path []byte, kind json.Kind, value []byte) error {
+		if json.Number != kind {
+			return nil // ignore units, null values, etc.
+		}
+		obj, field, idx := split(path)
+		num := parse(value)
+		switch string(obj) {
+		case "current":
+			current(&w.Current, field, num)
+		case "hourly":
+			if idx >= 0 && idx < len(w.Hour) {
+				hour(&w.Hour[idx], field, num, loc)
+			}
+		case "daily":
+			if idx >= 0 && idx < len(w.Day) {
+				day(&w.Day[idx], field, num, loc)
+			}
+		case "minutely_15":
+			if idx >= 0 && idx < len(slots) {
+				switch string(field) {
+				case "time":
+					slots[idx].time = time.Unix(int64(num), 0).In(loc)
+				case "precipitation":
+					slots[idx].rain = float32(num)
+				}
+			}
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+	if !modified {
+		model.Mod(func(m *model.Model) {
+			m.Weather.Updated = data.Time
+		})
+		return nil
+	}
+
+	w.Nowcast = nowcast(slots, data.Time)
+	f.smoothCurrent(&w.Current, data.Time)
+
+	model.Set(func(m *model.Model) {
+		m.Weather = w
+	})
+	return nil
+}
+
+// smoothCurrent replaces the jittery quantities of the current conditions c,
+// reported at time now, with their moving averages.
+func (f *Forecast) smoothCurrent(c *model.Conditions, now time.Time) {
+	value := [3]float32{c.Temperature, float32(c.Humidity), c.WindSpeed}
+	for i, v := range value {
+		f.raw[i].Add(now, v)
+		value[i] = f.smooth[i].Add(now, v, f.config.Smoothing)
+	}
+	c.Temperature = value[0]
+	c.Humidity = uint8(value[1] + 0.5)
+	c.WindSpeed = value[2]
+}
+
+// Expire causes the next call to Sync to poll the forecast service, regardless
+// of the configured Interval.
+func (f *Forecast) Expire() {
+	f.lastSync = time.Time{}
+}
+
+// nowcast expands the 15-minute intervals of precipitation into the intensity of
+// each minute, beginning with the minute of time now.
+func nowcast(slots [nowcastSlots]slot, now time.Time) model.Nowcast {
+	n := model.Nowcast{Start: now.Truncate(time.Minute)}
+	for m := range n.Rain {
+		t := n.Start.Add(time.Duration(m) * time.Minute)
+		for _, s := range slots {
+			if !s.time.IsZero() && !t.Before(s.time) &&
+				t.Before(s.time.Add(15*time.Minute)) {
+				n.Rain[m] = s.rain * 4 // millimeters per hour
+				break
+			}
+		}
+	}
+	return n
+}
+
+func current(c *model.Conditions, field []byte, num float64) {
+	switch string(field) {
+	case "temperature_2m":
+		c.Temperature = float32(num)
+	case "relative_humidity_2m":
+		c.Humidity = uint8(num)
+	case "wind_speed_10m":
+		c.WindSpeed = float32(num)
+	case "wind_gusts_10m":
+		c.WindGust = float32(num)
+	case "weather_code":
+		c.Code = model.Code(num)
+	}
+}
+
+func hour(h *model.Hour, field []byte, num float64, loc *time.Location) {
+	switch string(field) {
+	case "time":
+		h.Time = time.Unix(int64(num), 0).In(loc)
+	case "temperature_2m":
+		h.Temperature = float32(num)
+	case "relative_humidity_2m":
+		h.Humidity = uint8(num)
+	case "precipitation_probability":
+		h.Chance = uint8(num)
+	case "precipitation":
+		h.Rain = float32(num)
+	case "snowfall":
+		h.Snow = float32(num)
+	case "wind_gusts_10m":
+		h.WindGust = float32(num)
+	case "weather_code":
+		h.Code = model.Code(num)
+	}
+}
+
+func day(d *model.Day, field []byte, num float64, loc *time.Location) {
+	switch string(field) {
+	case "time":
+		d.Date = time.Unix(int64(num), 0).In(loc)
+	case "temperature_2m_max":
+		d.High = float32(num)
+	case "temperature_2m_min":
+		d.Low = float32(num)
+	case "precipitation_probability_max":
+		d.Chance = uint8(num)
+	case "precipitation_sum":
+		d.Rain = float32(num)
+	case "weather_code":
+		d.Code = model.Code(num)
+	}
+}
+
+// split separates a path such as "hourly.time.3" into its object ("hourly"),
+// field ("time"), and array index (3, or -1 if none).
+func split(path []byte) (obj, field []byte, index int) {
+	index = -1
+	i := bytes.IndexByte(path, '.')
+	if i < 0 {
+		return path, nil, index
+	}
+	obj, field = path[:i], path[i+1:]
+	if j := bytes.IndexByte(field, '.'); j >= 0 {
+		if n, err := strconv.Atoi(string(field[j+1:])); nil == err {
+			field, index = field[:j], n
+		}
+	}
+	return obj, field, index
+}
+
+// parse returns the value of a JSON number, or 0 if it is invalid.
+func parse(value []byte) float64 {
+	num, err := strconv.ParseFloat(string(value), 64)
+	if nil != err {
+		return 0
+	}
+	return num
+}
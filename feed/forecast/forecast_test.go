@@ -0,0 +1,107 @@
+//go:build fake
+// +build fake
+
+package forecast
+
+import (
+	"io/ioutil"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi"
+	"github.com/ardnew/weatherhub/wifi/fake"
+	"github.com/ardnew/weatherhub/wifi/http"
+	"github.com/ardnew/weatherhub/wifi/network"
+)
+
+// epoch is the time at which the fixtures were recorded.
+var epoch = time.Unix(1624280400, 0).UTC()
+
+// serve answers every request to host with the given file of testdata, and
+// returns the path of the last request.
+func serve(t *testing.T, host, file string) *string {
+	t.Helper()
+	body, err := ioutil.ReadFile("testdata/" + file)
+	if nil != err {
+		t.Fatal(err)
+	}
+	path := new(string)
+	fake.Handle(host, func(p string) (int, []byte) {
+		*path = p
+		return 200, body
+	})
+	return path
+}
+
+// client returns a Client of the emulated network, with the Model at epoch.
+func client(t *testing.T) *http.Client {
+	t.Helper()
+	w, err := wifi.New(wifi.Config{})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := w.Connect(network.AP{SSID: "fake"}); nil != err {
+		t.Fatal(err)
+	}
+	model.Set(func(m *model.Model) {
+		m.Time = epoch
+	})
+	return http.New(w, http.Config{})
+}
+
+func near(got, want float32) bool {
+	return math.Abs(float64(got-want)) < 1e-3
+}
+
+func TestSyncReplay(t *testing.T) {
+	path := serve(t, "api.open-meteo.com", "forecast.json")
+	c := client(t)
+	model.Set(func(m *model.Model) {
+		m.Location = model.Location{Latitude: 50.4452, Longitude: -104.6189, Zone: "America/Regina"}
+	})
+
+	if err := New(c, Config{}).Sync(); nil != err {
+		t.Fatal(err)
+	}
+	if !strings.Contains(*path, "latitude=50.4452&longitude=-104.6189") {
+		t.Errorf("requested %q", *path)
+	}
+	w := model.Peek().Weather
+
+	if !w.Updated.Equal(epoch) {
+		t.Errorf("Updated = %v, want %v", w.Updated, epoch)
+	}
+	now := w.Current
+	if !near(now.Temperature, 21.4) || 48 != now.Humidity || !near(now.WindSpeed, 5.3) ||
+		!near(now.WindGust, 11.2) || model.CodePartlyCloudy != now.Code {
+		t.Errorf("Current = %+v", now)
+	}
+
+	h := w.Hour[1]
+	if !h.Time.Equal(epoch.Add(time.Hour)) || !near(h.Temperature, 22.9) || 44 != h.Humidity ||
+		65 != h.Chance || !near(h.Rain, 0.9) || !near(h.WindGust, 14.8) || model.CodeShowers != h.Code {
+		t.Errorf("Hour[1] = %+v", h)
+	}
+	if !w.Hour[3].Time.IsZero() {
+		t.Errorf("Hour[3] = %+v, want none", w.Hour[3])
+	}
+
+	d := w.Day[1]
+	if 1624341600 != d.Date.Unix() || !near(d.High, 19.8) || !near(d.Low, 9.2) ||
+		20 != d.Chance || !near(d.Rain, 0) || model.CodeOvercast != d.Code {
+		t.Errorf("Day[1] = %+v", d)
+	}
+
+	// 0.3 mm from 30 to 45 minutes past, 0.5 mm from 45 to 60, as mm/h
+	for _, x := range []struct {
+		minute int
+		rain   float32
+	}{{0, 0}, {29, 0}, {30, 1.2}, {44, 1.2}, {45, 2.0}, {60, 0.4}, {75, 0}} {
+		if x.minute < len(w.Nowcast.Rain) && !near(w.Nowcast.Rain[x.minute], x.rain) {
+			t.Errorf("Nowcast.Rain[%d] = %v, want %v", x.minute, w.Nowcast.Rain[x.minute], x.rain)
+		}
+	}
+}
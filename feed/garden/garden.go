@@ -0,0 +1,168 @@
+// Package garden recommends whether to water the garden, based on a simple
+// balance of rainfall and evapotranspiration over the past several days.
+//
+// The daily high and low temperatures and total precipitation are logged from
+// the forecast of each day as it passes. Evapotranspiration is estimated from
+// the logged temperatures (see meteo.Evapotranspiration), and any shortfall of
+// rainfall, less the rain expected tomorrow, is the water deficit. Watering is
+// recommended when the deficit exceeds a threshold.
+//
+// The recommendation is stored in the Model, and it is optionally published as
+// a retained MQTT message for irrigation automation, e.g.:
+//
+//	{"water": true, "deficit": 12.4}
+package garden
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/meteo"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for Garden configuration.
+const (
+	DefaultDays        = 7
+	DefaultThreshold   = 10  // millimeters
+	DefaultCoefficient = 0.8 // typical of lawns and vegetable gardens
+	DefaultTopic       = "~/garden"
+)
+
+// minDays defines the number of days that must be logged before a
+// recommendation is made.
+const minDays = 3
+
+// Config defines the water balance parameters and MQTT topic.
+// The recommendation is not published to MQTT if Topic is "-".
+type Config struct {
+	Days        int     // number of days logged
+	Threshold   float32 // deficit at which watering is recommended, millimeters
+	Coefficient float32 // crop coefficient, relative to reference crop (grass)
+	Topic       string
+}
+
+// record contains the weather logged for a single day.
+type record struct {
+	date      time.Time
+	high, low float32
+	rain      float32
+}
+
+// Garden is a Feed that logs the daily weather each time the forecast is
+// updated, and updates its recommendation accordingly.
+type Garden struct {
+	broker    *mqtt.Client
+	config    Config
+	log       []record // most recent day last
+	updated   time.Time
+	published *model.Garden // most recent recommendation published to MQTT
+}
+
+// New returns a new Garden using the given MQTT client and configuration.
+func New(broker *mqtt.Client, config Config) *Garden {
+
+	if config.Days < minDays {
+		config.Days = DefaultDays
+	}
+	if config.Threshold == 0 {
+		config.Threshold = DefaultThreshold
+	}
+	if config.Coefficient == 0 {
+		config.Coefficient = DefaultCoefficient
+	}
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+
+	return &Garden{
+		broker: broker,
+		config: config,
+		log:    make([]record, 0, config.Days),
+	}
+}
+
+// Sync logs the weather of today and updates the recommendation if the forecast
+// has been updated since the previous call.
+func (g *Garden) Sync() error {
+
+	data := model.Peek()
+	if !data.Weather.Valid() || !data.Weather.Updated.After(g.updated) {
+		return g.publish(data.Garden)
+	}
+	g.updated = data.Weather.Updated
+
+	today := data.Weather.Day[0]
+	if today.Date.IsZero() {
+		return nil
+	}
+	g.record(today)
+
+	rec := g.recommend(data.Location.Latitude, data.Weather.Day[1])
+	model.Set(func(m *model.Model) {
+		m.Garden = rec
+	})
+	return g.publish(rec)
+}
+
+// record logs the weather of the given day, replacing any earlier record of the
+// same day.
+func (g *Garden) record(day model.Day) {
+	r := record{date: day.Date, high: day.High, low: day.Low, rain: day.Rain}
+	if n := len(g.log); n > 0 && g.log[n-1].date.Equal(day.Date) {
+		g.log[n-1] = r
+		return
+	}
+	if len(g.log) == cap(g.log) {
+		copy(g.log, g.log[1:])
+		g.log = g.log[:len(g.log)-1]
+	}
+	g.log = append(g.log, r)
+}
+
+// recommend computes the water balance of all logged days, crediting the rain
+// expected on the following day.
+func (g *Garden) recommend(latitude float64, tomorrow model.Day) model.Garden {
+	if len(g.log) < minDays {
+		return model.Garden{}
+	}
+	var deficit float32
+	for _, r := range g.log {
+		et := g.config.Coefficient *
+			meteo.Evapotranspiration(r.high, r.low, latitude, r.date.YearDay())
+		deficit += et - r.rain
+		if deficit < 0 {
+			deficit = 0 // excess rain runs off, it does not accumulate
+		}
+	}
+	deficit -= tomorrow.Rain * float32(tomorrow.Chance) / 100
+	if deficit < 0 {
+		deficit = 0
+	}
+	return model.Garden{
+		Known:   true,
+		Water:   deficit >= g.config.Threshold,
+		Deficit: deficit,
+	}
+}
+
+// publish sends the given recommendation to MQTT if it differs from the
+// recommendation most recently published.
+func (g *Garden) publish(rec model.Garden) error {
+	if nil == g.broker || "-" == g.config.Topic || !rec.Known ||
+		!g.broker.Connected() {
+		return nil
+	}
+	if nil != g.published && g.published.Water == rec.Water &&
+		int(g.published.Deficit) == int(rec.Deficit) {
+		return nil
+	}
+	payload := `{"water": ` + strconv.FormatBool(rec.Water) +
+		`, "deficit": ` + strconv.FormatFloat(float64(rec.Deficit), 'f', 1, 32) + `}`
+	if err := g.broker.Publish(g.config.Topic, []byte(payload), true); nil != err {
+		return err
+	}
+	g.published = &rec
+	return nil
+}
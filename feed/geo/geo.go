@@ -0,0 +1,227 @@
+// Package geo determines the location of the device, either from configuration
+// or by geolocation of its public IP address.
+//
+// Geolocation is only performed if the latitude and longitude are not
+// configured, and only until it succeeds once. The resulting location and time
+// zone are stored in the Model for use by other packages.
+//
+// If the coordinates are configured without a time zone, the zone is resolved
+// from the coarse regions of package tz, or by a one-time request to a time
+// zone service if the coordinates fall outside of those regions.
+package geo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/tz"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Geo configuration.
+const (
+	DefaultURL     = "http://ip-api.com/json/?fields=status,city,lat,lon,timezone,offset"
+	DefaultZoneURL = "https://timeapi.io/api/TimeZone/coordinate?latitude={lat}&longitude={lon}"
+	DefaultRetry   = 5 * time.Minute
+)
+
+var (
+	ErrLookupFailed = errors.New("IP geolocation failed")
+	ErrZoneFailed   = errors.New("time zone lookup failed")
+	ErrUnknownZone  = errors.New("time zone not in database")
+)
+
+// Config defines the location of the device, or the geolocation service used to
+// determine it. The service must reply with a JSON object with the same fields
+// as the default service, ip-api.com.
+//
+// ZoneURL is the time zone service used to resolve the time zone of configured
+// coordinates, with placeholders {lat} and {lon}. It must reply with a JSON
+// object with the same fields as the default service, timeapi.io.
+type Config struct {
+	Latitude  float64 // degrees north
+	Longitude float64 // degrees east
+	City      string
+	Zone      string        // IANA time zone name, resolved if empty
+	URL       string        // geolocation service
+	ZoneURL   string        // time zone service
+	Retry     time.Duration // how long to wait before retrying a lookup
+}
+
+// Geo is a Feed that performs IP geolocation once, if the location has not been
+// configured, or time zone lookup once, if only the time zone is unknown.
+type Geo struct {
+	client   *http.Client
+	config   Config
+	fixed    model.Location // location in effect, unless geolocated
+	done     bool
+	lastSync time.Time
+}
+
+// New returns a new Geo using the given HTTP client and configuration.
+// If the location is configured, it is stored in the Model immediately.
+// Returns ErrUnknownZone if the configured time zone is not in the database of
+// package tz, whose offset would otherwise be taken as UTC.
+func New(client *http.Client, config Config) (*Geo, error) {
+
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.ZoneURL == "" {
+		config.ZoneURL = DefaultZoneURL
+	}
+	if config.Retry == 0 {
+		config.Retry = DefaultRetry
+	}
+
+	if "" != config.Zone {
+		if _, ok := tz.Lookup(config.Zone); !ok {
+			return nil, ErrUnknownZone
+		}
+	}
+
+	g := &Geo{client: client, config: config}
+	g.locate(model.Location{
+		Latitude:  config.Latitude,
+		Longitude: config.Longitude,
+		City:      config.City,
+		Zone:      config.Zone,
+	})
+	return g, nil
+}
+
+// Locate replaces the location of the device with the given coordinates, whose
+// time zone is resolved as if configured. If both coordinates are zero, the
+// configured location is restored, or geolocation is performed again if none
+// is configured.
+func (g *Geo) Locate(latitude, longitude float64, city string) {
+	loc := model.Location{Latitude: latitude, Longitude: longitude, City: city}
+	if !loc.Known() {
+		loc = model.Location{
+			Latitude:  g.config.Latitude,
+			Longitude: g.config.Longitude,
+			City:      g.config.City,
+			Zone:      g.config.Zone,
+		}
+	}
+	g.done, g.lastSync = false, time.Time{}
+	g.locate(loc)
+}
+
+// locate stores the given location in the Model, resolving its time zone from
+// package tz if possible. If the location is unknown, nothing is stored, and
+// geolocation is performed by the next Sync.
+func (g *Geo) locate(loc model.Location) {
+	if !loc.Known() {
+		g.fixed = loc
+		return
+	}
+	if "" == loc.Zone {
+		if z, ok := tz.Locate(loc.Latitude, loc.Longitude); ok {
+			loc.Zone = z.Name
+		}
+	}
+	if z, ok := tz.Lookup(loc.Zone); ok {
+		loc.Offset = z.Offset
+	}
+	// only the time zone service remains to be queried if zone is unknown
+	g.done = "" != loc.Zone
+	g.fixed = loc
+	model.Set(func(m *model.Model) {
+		m.Location = loc
+	})
+}
+
+// Sync performs IP geolocation if the location is unknown, or time zone lookup
+// if only the time zone is unknown, and the retry interval has elapsed since
+// the previous attempt.
+func (g *Geo) Sync() error {
+
+	if g.done {
+		return nil
+	}
+
+	now := model.Peek().Time
+	if !feed.Expired(now, g.lastSync, g.config.Retry) {
+		return nil
+	}
+	g.lastSync = now
+
+	if g.fixed.Known() {
+		return g.zone()
+	}
+
+	loc := model.Location{Auto: true}
+	success := false
+	err := feed.FetchJSON(g.client, g.config.URL, func(path []byte, kind json.Kind, value []byte) error {
+		var err error
+		switch string(path) {
+		case "status":
+			success = "success" == string(value)
+		case "city":
+			loc.City = string(value)
+		case "lat":
+			loc.Latitude, err = strconv.ParseFloat(string(value), 64)
+		case "lon":
+			loc.Longitude, err = strconv.ParseFloat(string(value), 64)
+		case "timezone":
+			loc.Zone = string(value)
+		case "offset":
+			loc.Offset, err = strconv.Atoi(string(value))
+		}
+		return err
+	})
+	if nil != err {
+		return err
+	}
+	if !success || !loc.Known() {
+		return ErrLookupFailed
+	}
+
+	g.done = true
+	model.Set(func(m *model.Model) {
+		m.Location = loc
+	})
+	return nil
+}
+
+// zone queries the time zone service for the time zone of the configured
+// coordinates.
+func (g *Geo) zone() error {
+
+	lat := strconv.FormatFloat(g.fixed.Latitude, 'f', 4, 64)
+	lon := strconv.FormatFloat(g.fixed.Longitude, 'f', 4, 64)
+	url := strings.Replace(g.config.ZoneURL, "{lat}", lat, 1)
+	url = strings.Replace(url, "{lon}", lon, 1)
+
+	var zone string
+	var offset int
+	err := feed.FetchJSON(g.client, url, func(path []byte, kind json.Kind, value []byte) error {
+		var err error
+		switch string(path) {
+		case "timeZone":
+			zone = string(value)
+		case "standardUtcOffset.seconds":
+			offset, err = strconv.Atoi(string(value))
+		}
+		return err
+	})
+	if nil != err {
+		return err
+	}
+	if "" == zone {
+		return ErrZoneFailed
+	}
+
+	g.done = true
+	model.Set(func(m *model.Model) {
+		m.Location.Zone = zone
+		m.Location.Offset = offset
+	})
+	return nil
+}
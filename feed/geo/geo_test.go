@@ -0,0 +1,120 @@
+//go:build fake
+// +build fake
+
+package geo
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi"
+	"github.com/ardnew/weatherhub/wifi/fake"
+	"github.com/ardnew/weatherhub/wifi/http"
+	"github.com/ardnew/weatherhub/wifi/network"
+)
+
+// epoch is the time at which the fixtures were recorded.
+var epoch = time.Unix(1624280400, 0).UTC()
+
+// serve answers every request to host with the given file of testdata, and
+// returns the path of the last request.
+func serve(t *testing.T, host, file string) *string {
+	t.Helper()
+	body, err := ioutil.ReadFile("testdata/" + file)
+	if nil != err {
+		t.Fatal(err)
+	}
+	path := new(string)
+	fake.Handle(host, func(p string) (int, []byte) {
+		*path = p
+		return 200, body
+	})
+	return path
+}
+
+// client returns a Client of the emulated network, with the Model at epoch.
+func client(t *testing.T) *http.Client {
+	t.Helper()
+	w, err := wifi.New(wifi.Config{})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := w.Connect(network.AP{SSID: "fake"}); nil != err {
+		t.Fatal(err)
+	}
+	model.Set(func(m *model.Model) {
+		m.Time = epoch
+		m.Location = model.Location{}
+	})
+	return http.New(w, http.Config{})
+}
+
+func TestSyncGeolocate(t *testing.T) {
+	path := serve(t, "ip-api.com", "ip-api.json")
+	g, err := New(client(t), Config{})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := g.Sync(); nil != err {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(*path, "/json/") {
+		t.Errorf("requested %q", *path)
+	}
+	want := model.Location{
+		Latitude:  50.4452,
+		Longitude: -104.6189,
+		City:      "Regina",
+		Zone:      "America/Regina",
+		Offset:    -6 * 60 * 60,
+		Auto:      true,
+	}
+	if loc := model.Peek().Location; want != loc {
+		t.Errorf("Location = %+v, want %+v", loc, want)
+	}
+
+	// geolocation is performed only once
+	*path = ""
+	model.Set(func(m *model.Model) {
+		m.Time = epoch.Add(time.Hour)
+	})
+	if err := g.Sync(); nil != err || "" != *path {
+		t.Errorf("second Sync requested %q: %v", *path, err)
+	}
+}
+
+func TestSyncZone(t *testing.T) {
+	path := serve(t, "timeapi.io", "timeapi.json")
+	g, err := New(client(t), Config{Latitude: 50.4452, Longitude: -104.6189, City: "Regina"})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := g.Sync(); nil != err {
+		t.Fatal(err)
+	}
+	if !strings.Contains(*path, "latitude=50.4452&longitude=-104.6189") {
+		t.Errorf("requested %q", *path)
+	}
+	want := model.Location{
+		Latitude:  50.4452,
+		Longitude: -104.6189,
+		City:      "Regina",
+		Zone:      "America/Regina",
+		Offset:    -6 * 60 * 60,
+	}
+	if loc := model.Peek().Location; want != loc {
+		t.Errorf("Location = %+v, want %+v", loc, want)
+	}
+}
+
+func TestNewUnknownZone(t *testing.T) {
+	if _, err := New(client(t), Config{Latitude: 50.4452, Longitude: -104.6189, Zone: "America/Regina"}); ErrUnknownZone != err {
+		t.Errorf("New = %v, want ErrUnknownZone", err)
+	}
+	if _, err := New(client(t), Config{Latitude: 40.7128, Longitude: -74.006, Zone: "America/New_York"}); nil != err {
+		t.Errorf("New = %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+// Package indoor receives indoor climate measurements published to an MQTT
+// topic, e.g., by a room sensor, and classifies their comfort by dew point.
+//
+// Relative humidity alone is misleading, since it depends on temperature. The
+// dew point is instead compared with configurable thresholds to determine
+// whether the air is dry, comfortable, humid, or moist enough that mold may grow
+// on cool surfaces such as exterior walls and windows.
+//
+// Each measurement is a JSON object with temperature in degrees Celsius and
+// relative humidity in percent:
+//
+//	{"temperature": 21.5, "humidity": 48}
+//
+// Measurements may be smoothed by an exponential moving average before they
+// are shown, while the raw measurements are retained for logging (see package
+// history).
+package indoor
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/history"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/meteo"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for Indoor configuration.
+const (
+	DefaultTopic = "~/indoor"
+	DefaultDry   = 5  // dew point, °C
+	DefaultHumid = 13 // dew point, °C
+	DefaultMold  = 16 // dew point, °C
+	DefaultAge   = 15 * time.Minute
+)
+
+// Config defines the MQTT topic of measurements and the dew point thresholds
+// of each Comfort level.
+type Config struct {
+	Topic string
+	Dry   float32       // dew point below which the air is dry
+	Humid float32       // dew point at or above which the air is humid
+	Mold  float32       // dew point at or above which mold is likely
+	Age   time.Duration // how long a measurement remains valid
+	// Smoothing is the window of the moving average of measurements, or zero
+	// to show each measurement as received.
+	Smoothing time.Duration
+}
+
+// Indoor is a Feed that expires measurements that are no longer recent.
+type Indoor struct {
+	broker  *mqtt.Client
+	config  Config
+	temp    history.EMA
+	hum     history.EMA
+	rawTemp *history.Raw
+	rawHum  *history.Raw
+}
+
+// New returns a new Indoor subscribed to the configured topic using the given
+// MQTT client. The Indoor is disabled if the client is nil, e.g., when the
+// "mqtt" feature is not built.
+func New(broker *mqtt.Client, config Config) (*Indoor, error) {
+
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+	if config.Dry == 0 && config.Humid == 0 && config.Mold == 0 {
+		config.Dry, config.Humid, config.Mold = DefaultDry, DefaultHumid, DefaultMold
+	}
+	if config.Age == 0 {
+		config.Age = DefaultAge
+	}
+
+	i := &Indoor{
+		broker:  broker,
+		config:  config,
+		rawTemp: history.NewRaw("indoor.temperature"),
+		rawHum:  history.NewRaw("indoor.humidity"),
+	}
+	if nil == broker {
+		return i, nil
+	}
+	if err := broker.Subscribe(config.Topic, i.receive); nil != err {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Sync removes the measurement from the Model once it is no longer recent.
+func (i *Indoor) Sync() error {
+	if nil == i.broker {
+		return nil
+	}
+	data := model.Peek()
+	if data.Indoor.Valid() && data.Time.Sub(data.Indoor.Updated) > i.config.Age {
+		model.Set(func(m *model.Model) {
+			m.Indoor = model.Indoor{}
+		})
+	}
+	return nil
+}
+
+func (i *Indoor) receive(topic string, payload []byte) {
+
+	var temp, hum float64
+	var haveTemp, haveHum bool
+	err := json.Extract(bytes.NewReader(payload), func(path []byte, kind json.Kind, value []byte) error {
+		var err error
+		switch string(path) {
+		case "temperature":
+			temp, err = strconv.ParseFloat(string(value), 32)
+			haveTemp = nil == err
+		case "humidity":
+			hum, err = strconv.ParseFloat(string(value), 32)
+			haveHum = nil == err
+		}
+		return err
+	})
+	if nil != err {
+		journal.Println("error: " + topic + ": " + err.Error())
+		return
+	}
+	if !haveTemp || !haveHum {
+		return
+	}
+
+	now := model.Peek().Time
+	i.rawTemp.Add(now, float32(temp))
+	i.rawHum.Add(now, float32(hum))
+	t := i.temp.Add(now, float32(temp), i.config.Smoothing)
+	h := i.hum.Add(now, float32(hum), i.config.Smoothing)
+
+	dew := meteo.DewPoint(t, h)
+	model.Set(func(m *model.Model) {
+		m.Indoor = model.Indoor{
+			Updated:     m.Time,
+			Temperature: t,
+			Humidity:    h,
+			DewPoint:    dew,
+			Comfort:     i.comfort(dew),
+		}
+	})
+}
+
+func (i *Indoor) comfort(dew float32) model.Comfort {
+	switch {
+	case dew >= i.config.Mold:
+		return model.ComfortMold
+	case dew >= i.config.Humid:
+		return model.ComfortHumid
+	case dew < i.config.Dry:
+		return model.ComfortDry
+	}
+	return model.ComfortGood
+}
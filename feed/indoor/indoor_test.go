@@ -0,0 +1,26 @@
+package indoor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+func TestNoBroker(t *testing.T) {
+	i, err := New(nil, Config{})
+	if nil != err {
+		t.Fatalf("New(nil) = %v, want no error", err)
+	}
+	stale := model.Indoor{Updated: time.Unix(0, 0), Temperature: 20, Humidity: 50}
+	model.Set(func(m *model.Model) {
+		m.Time = time.Unix(0, 0).Add(time.Hour)
+		m.Indoor = stale
+	})
+	if err := i.Sync(); nil != err {
+		t.Fatalf("Sync() = %v, want no error", err)
+	}
+	if got := model.Peek().Indoor; got != stale {
+		t.Errorf("disabled Sync changed Indoor to %+v", got)
+	}
+}
@@ -0,0 +1,130 @@
+// Package message shows short text messages published to an MQTT topic, e.g.,
+// by Home Assistant automations or shell scripts.
+//
+// Each message is posted as a Notice (see package notify), replacing the
+// previous message. The message payload is either plain text, which is shown
+// using the configured duration and color, or a JSON object overriding them:
+//
+//	{"text": "Garage open", "duration": 30, "color": "#FF8000"}
+//
+// where duration is given in seconds. An empty payload dismisses the current
+// message.
+//
+// If a secret is set in the Settings (see config.Authorized), messages must be
+// JSON objects with a field "secret" of the same value, and all others are
+// ignored, including empty payloads.
+package message
+
+import (
+	"bytes"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for Message configuration.
+const (
+	DefaultTopic    = "~/message"
+	DefaultDuration = 15 * time.Second
+	DefaultPriority = model.PriorityNormal
+)
+
+// noticeID identifies the notices posted by Message.
+const noticeID = "message"
+
+// DefaultColor defines the color of messages that do not specify one.
+var DefaultColor = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+
+// Config defines the MQTT topic and default appearance of messages.
+type Config struct {
+	Topic    string
+	Duration time.Duration // how long each message is shown
+	Color    color.RGBA
+	Priority model.Priority
+}
+
+// Message receives text messages from an MQTT topic.
+type Message struct {
+	config Config
+}
+
+// New returns a new Message subscribed to the configured topic using the given
+// MQTT client.
+func New(client *mqtt.Client, config Config) (*Message, error) {
+
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+	if config.Duration == 0 {
+		config.Duration = DefaultDuration
+	}
+	if config.Color == (color.RGBA{}) {
+		config.Color = DefaultColor
+	}
+	if config.Priority == model.PriorityNone {
+		config.Priority = DefaultPriority
+	}
+
+	m := &Message{config: config}
+	if err := client.Subscribe(config.Topic, m.receive); nil != err {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Message) receive(topic string, payload []byte) {
+
+	msg := model.Notice{
+		ID:       noticeID,
+		Text:     string(bytes.TrimSpace(payload)),
+		Color:    m.config.Color,
+		Priority: m.config.Priority,
+		Dismiss:  model.DismissShown,
+		Duration: m.config.Duration,
+	}
+
+	var secret string
+	if strings.HasPrefix(msg.Text, "{") {
+		msg.Text = ""
+		err := json.Extract(bytes.NewReader(payload), func(path []byte, kind json.Kind, value []byte) error {
+			switch string(path) {
+			case "text":
+				msg.Text = string(value)
+			case "duration":
+				if sec, err := strconv.ParseFloat(string(value), 64); nil == err && sec > 0 {
+					msg.Duration = time.Duration(sec * float64(time.Second))
+				}
+			case "color":
+				if c, ok := notify.ParseColor(string(value)); ok {
+					msg.Color = c
+				}
+			case "secret":
+				secret = string(value)
+			}
+			return nil
+		})
+		if nil != err {
+			journal.Println("error: " + topic + ": " + err.Error())
+			return
+		}
+	}
+
+	if !config.Authorized(secret) {
+		journal.Println("error: " + topic + ": unauthorized message ignored")
+		return
+	}
+
+	if "" == msg.Text {
+		notify.Dismiss(noticeID)
+		return
+	}
+	notify.Post(msg)
+}
@@ -0,0 +1,72 @@
+// Package ping periodically measures the round-trip time to the gateway and to
+// a host on the internet, which are stored in the Model diagnostics.
+//
+// If the gateway replies but the internet host does not, the WiFi is working
+// and any problem is beyond the router, e.g., with the ISP or an API.
+package ping
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi"
+)
+
+// Default constants for Ping configuration.
+const (
+	DefaultHost     = "1.1.1.1"
+	DefaultInterval = 5 * time.Minute
+)
+
+// Config defines the internet host pinged, and how often.
+type Config struct {
+	Host     string // host name or IP address
+	Interval time.Duration
+}
+
+// Ping is a Feed that pings the gateway and internet host periodically.
+type Ping struct {
+	wifi     *wifi.WiFi
+	config   Config
+	lastSync time.Time
+}
+
+// New returns a new Ping using the given WiFi and configuration.
+func New(w *wifi.WiFi, config Config) *Ping {
+
+	if config.Host == "" {
+		config.Host = DefaultHost
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Ping{wifi: w, config: config}
+}
+
+// Sync pings the gateway and internet host if the interval has elapsed. Hosts
+// that do not reply are recorded as such, not reported as errors.
+func (p *Ping) Sync() error {
+
+	data := model.Peek()
+	if !feed.Due(data.Time, p.lastSync, p.config.Interval) {
+		return nil
+	}
+	p.lastSync = data.Time
+
+	gateway := model.Ping{}
+	if ip, err := p.wifi.Gateway(); nil == err {
+		gateway.Host = ip.String()
+		rtt, err := p.wifi.Ping(gateway.Host, 0)
+		gateway.RTT, gateway.OK = rtt, nil == err
+	}
+	internet := model.Ping{Host: p.config.Host}
+	rtt, err := p.wifi.Ping(internet.Host, 0)
+	internet.RTT, internet.OK = rtt, nil == err
+
+	model.Mod(func(m *model.Model) {
+		m.Diagnostics.Gateway, m.Diagnostics.Internet = gateway, internet
+	})
+	return nil
+}
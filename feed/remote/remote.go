@@ -0,0 +1,182 @@
+// Package remote receives measurements of remote battery-powered sensors, e.g.,
+// in a pool or greenhouse, transmitted by LoRa radio (see package lora).
+//
+// Each packet is 10 bytes, multi-byte fields big-endian:
+//
+//	offset  size  field
+//	0       1     magic 'W'
+//	1       1     sensor ID
+//	2       1     sequence number, incremented by each new measurement
+//	3       2     temperature, hundredths of a degree Celsius, signed
+//	5       1     relative humidity, percent, or 0xFF if not measured
+//	6       2     battery voltage, millivolts
+//	8       2     CRC-16/CCITT-FALSE of bytes 0-7
+//
+// Sensors may transmit each measurement more than once, to make up for packets
+// lost, so repeated sequence numbers are ignored.
+//
+// Each sensor heard is given a free slot of the Model, and its configured name,
+// or its ID if not configured.
+package remote
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/history"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/lora"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Default constants for Remote configuration.
+const (
+	DefaultAge        = 30 * time.Minute
+	DefaultLowBattery = 3300 // mV, of a single lithium cell
+)
+
+// packetSize defines the size of each packet, and magic its first byte. Sensor
+// IDs of the Model are prefixed by source.
+const (
+	packetSize = 10
+	magic      = 'W'
+	source     = "lora:"
+)
+
+// Sensor defines the ID and name of a remote sensor.
+type Sensor struct {
+	ID   uint8
+	Name string
+}
+
+// Config defines the names of remote sensors, how long a measurement remains
+// valid, and the battery voltage below which a sensor needs attention.
+type Config struct {
+	Sensors    []Sensor
+	Age        time.Duration
+	LowBattery uint16 // mV
+}
+
+// Remote is a Feed that polls the radio for packets, and expires measurements
+// that are no longer recent.
+type Remote struct {
+	radio  *lora.Device
+	config Config
+	seq    map[uint8]uint8 // sequence number of last packet of each sensor
+	raw    [model.MaxRemotes]*history.Raw
+	packet [packetSize + 1]byte // one more, to detect longer packets
+}
+
+// New returns a new Remote receiving from the given radio, which must already
+// be configured.
+func New(radio *lora.Device, config Config) *Remote {
+
+	if config.Age == 0 {
+		config.Age = DefaultAge
+	}
+	if config.LowBattery == 0 {
+		config.LowBattery = DefaultLowBattery
+	}
+
+	r := &Remote{radio: radio, config: config, seq: map[uint8]uint8{}}
+	for i := range r.raw {
+		r.raw[i] = history.NewRaw("remote." + strconv.Itoa(i) + ".temperature")
+	}
+	return r
+}
+
+// Sync receives any packets waiting, and removes measurements from the Model
+// once they are no longer recent.
+func (r *Remote) Sync() error {
+
+	for {
+		n, rssi, ok := r.radio.Receive(r.packet[:])
+		if !ok {
+			break
+		}
+		r.receive(r.packet[:n], rssi)
+	}
+
+	data := model.Peek()
+	for i, s := range data.Remote {
+		if s.Valid() && strings.HasPrefix(s.ID, source) &&
+			data.Time.Sub(s.Updated) > r.config.Age {
+			model.Set(func(m *model.Model) {
+				m.Remote[i] = model.Remote{}
+			})
+			id, _ := strconv.Atoi(s.ID[len(source):])
+			delete(r.seq, uint8(id))
+		}
+	}
+	return nil
+}
+
+// receive decodes the given packet, and records its measurement in the slot of
+// its sensor.
+func (r *Remote) receive(p []byte, rssi int16) {
+
+	if packetSize != len(p) || magic != p[0] {
+		return // not one of ours
+	}
+	if crc16(p[:8]) != uint16(p[8])<<8|uint16(p[9]) {
+		journal.Println("remote: CRC error")
+		return
+	}
+	id, seq := p[1], p[2]
+	if last, ok := r.seq[id]; ok && seq == last {
+		return // repeated
+	}
+	key := source + strconv.Itoa(int(id))
+	i := model.Peek().Remote.Slot(key)
+	if i < 0 {
+		return // no free slot
+	}
+	r.seq[id] = seq
+
+	temp := float32(int16(uint16(p[3])<<8|uint16(p[4]))) / 100
+	battery := uint16(p[6])<<8 | uint16(p[7])
+	now := model.Peek().Time
+	r.raw[i].Add(now, temp)
+
+	model.Set(func(m *model.Model) {
+		m.Remote[i] = model.Remote{
+			ID:          key,
+			Name:        r.name(id),
+			Updated:     m.Time,
+			Temperature: temp,
+			Humidity:    p[5],
+			Battery:     battery,
+			LowBattery:  battery < r.config.LowBattery,
+			RSSI:        rssi,
+		}
+	})
+}
+
+// name returns the configured name of the sensor with the given ID, or its ID
+// if not configured.
+func (r *Remote) name(id uint8) string {
+	for _, s := range r.config.Sensors {
+		if id == s.ID {
+			return s.Name
+		}
+	}
+	return "#" + strconv.Itoa(int(id))
+}
+
+// crc16 returns the CRC-16/CCITT-FALSE of b (polynomial 0x1021, initial value
+// 0xFFFF).
+func crc16(b []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, c := range b {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if 0 != crc&0x8000 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,138 @@
+// Package rss implements a Feed of news headlines from an RSS or Atom feed.
+//
+// Only the titles of the first few items are retained, and the remainder of the
+// document is never read, so feeds of any size can be used.
+package rss
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/xml"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for RSS configuration.
+const (
+	DefaultItems    = 3
+	DefaultInterval = 15 * time.Minute
+)
+
+// errDone stops extraction once the configured number of items has been read.
+var errDone = errors.New("done")
+
+// Config defines the feed URL and the schedule on which headlines are shown.
+//
+// The Feed is disabled if URL is empty.
+type Config struct {
+	URL      string
+	Items    int           // number of headlines shown, at most MaxHeadlines
+	Start    time.Duration // ticker schedule, as offsets from local midnight
+	End      time.Duration // (if Start equals End, the ticker is always shown)
+	Interval time.Duration // how often to poll the feed while scheduled
+}
+
+// RSS polls an RSS or Atom feed for headlines.
+type RSS struct {
+	client   *http.Client
+	config   Config
+	lastSync time.Time
+}
+
+// New returns a new RSS using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *RSS {
+
+	if config.Items <= 0 || config.Items > model.MaxHeadlines {
+		config.Items = DefaultItems
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &RSS{client: client, config: config}
+}
+
+// Sync polls the news feed if the current time is within the ticker schedule
+// and the polling interval has elapsed.
+func (r *RSS) Sync() error {
+
+	if "" == r.config.URL {
+		return nil // feed disabled
+	}
+
+	data := model.Peek()
+	if data.Time.IsZero() || (r.config.Start != r.config.End &&
+		!feed.InWindow(data.Time, r.config.Start, r.config.End)) {
+		// outside of schedule, hide the ticker and stop polling.
+		if data.News.Active {
+			model.Set(func(m *model.Model) {
+				m.News = model.News{}
+			})
+		}
+		// the headlines were discarded, so they must be fetched again even if the
+		// feed has not changed.
+		r.client.Forget(r.config.URL)
+		r.lastSync = time.Time{}
+		return nil
+	}
+
+	if !feed.Due(data.Time, r.lastSync, r.config.Interval) {
+		return nil
+	}
+	r.lastSync = data.Time
+
+	news, modified, err := r.fetch()
+	if nil != err || !modified {
+		return err
+	}
+	model.Set(func(m *model.Model) {
+		m.News = news
+	})
+	return nil
+}
+
+// fetch returns the headlines of the news feed, or false if the feed has not
+// changed since the headlines were last fetched.
+func (r *RSS) fetch() (news model.News, modified bool, err error) {
+
+	res, err := r.client.GetIfModified(r.config.URL)
+	if nil != err {
+		return news, false, err
+	}
+	defer res.Close()
+	if res.NotModified() {
+		return news, false, nil
+	}
+	if !res.OK() {
+		return news, false, http.ErrStatus
+	}
+
+	n := 0
+	err = xml.Extract(res.Body, func(path []byte, value []byte) error {
+		// RSS 1.0 and 2.0 items are <item>, Atom items are <entry>. This excludes
+		// the title of the feed itself.
+		if bytes.HasSuffix(path, []byte(".item.title")) ||
+			bytes.HasSuffix(path, []byte(".entry.title")) {
+			news.Headline[n] = string(value)
+			if n++; n == r.config.Items {
+				return errDone
+			}
+		}
+		return nil
+	})
+	// the document may be truncated by the HTTP client's body size limit, which
+	// is not an error as long as some headlines were found.
+	if err == errDone || (err == io.ErrUnexpectedEOF && n > 0) {
+		err = nil
+	}
+	if nil != err {
+		r.client.Forget(r.config.URL)
+		return news, false, err
+	}
+	news.Active = n > 0
+	return news, true, nil
+}
@@ -0,0 +1,213 @@
+// Package satellite implements an optional Feed of upcoming visible passes of a
+// satellite, such as the International Space Station, over the device Location.
+//
+// Passes are retrieved from N2YO (n2yo.com), which requires a free API key. The
+// Feed is disabled if no key is configured. A flashing Notice is posted (see
+// package notify) a few minutes before each pass begins, and it remains shown
+// until the pass ends.
+package satellite
+
+import (
+	"bytes"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Satellite configuration.
+const (
+	DefaultURL = "https://api.n2yo.com/rest/v1/satellite/visualpasses/" +
+		"{id}/{lat}/{lon}/0/{days}/{visible}/&apiKey={key}"
+	DefaultID       = 25544 // NORAD catalog number of the ISS
+	DefaultName     = "ISS"
+	DefaultDays     = 2
+	DefaultVisible  = 120 // seconds
+	DefaultLead     = 5 * time.Minute
+	DefaultInterval = 6 * time.Hour
+)
+
+// MaxPasses defines the number of upcoming passes retained.
+const MaxPasses = 4
+
+// noticeID identifies the notices posted by Satellite.
+const noticeID = "satellite"
+
+// DefaultColor defines the color of pass notices.
+var DefaultColor = color.RGBA{R: 0xC0, G: 0xC0, B: 0xFF, A: 0xFF}
+
+// Config defines the satellite, the pass prediction service, and when passes are
+// announced. URL placeholders {id}, {lat}, {lon}, {days}, {visible}, and {key}
+// are replaced with the corresponding configuration and Location.
+type Config struct {
+	URL      string
+	Key      string        // N2YO API key, unless given by the Settings
+	ID       int           // NORAD catalog number
+	Name     string        // name shown in notices
+	Days     int           // prediction horizon, 1-10 days
+	Visible  int           // minimum duration of optical visibility, seconds
+	Lead     time.Duration // how long before a pass its notice is shown
+	Interval time.Duration // how often predictions are retrieved
+	Color    color.RGBA
+}
+
+// pass describes a single visible pass.
+type pass struct {
+	start, end time.Time
+	compass    string // direction in which the satellite appears
+	elevation  int    // maximum elevation, degrees
+}
+
+// Satellite is a Feed of upcoming satellite passes.
+type Satellite struct {
+	client    *http.Client
+	config    Config
+	pass      [MaxPasses]pass
+	announced time.Time // start of the most recently announced pass
+	lastSync  time.Time
+}
+
+// New returns a new Satellite using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Satellite {
+
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.ID == 0 {
+		config.ID = DefaultID
+	}
+	if config.Name == "" {
+		config.Name = DefaultName
+	}
+	if config.Days == 0 {
+		config.Days = DefaultDays
+	}
+	if config.Visible == 0 {
+		config.Visible = DefaultVisible
+	}
+	if config.Lead == 0 {
+		config.Lead = DefaultLead
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Color == (color.RGBA{}) {
+		config.Color = DefaultColor
+	}
+
+	return &Satellite{client: client, config: config}
+}
+
+// Sync announces the next pass if it begins within the lead time, and retrieves
+// new predictions if the polling interval has elapsed.
+func (s *Satellite) Sync() error {
+
+	if "" == s.key() {
+		return nil // feed disabled
+	}
+
+	data := model.Peek()
+	if !data.Location.Known() {
+		return nil // wait for geolocation
+	}
+	s.announce(data.Time)
+
+	if !feed.Due(data.Time, s.lastSync, s.config.Interval) {
+		return nil
+	}
+	s.lastSync = data.Time
+
+	var pass [MaxPasses]pass
+	err := feed.FetchJSON(s.client, s.url(data.Location), func(path []byte, kind json.Kind, value []byte) error {
+		ok, i := json.Match(path, "passes.*.*")
+		if !ok || i < 0 || i >= len(pass) {
+			return nil
+		}
+		field := path[bytes.LastIndexByte(path, '.')+1:]
+		switch string(field) {
+		case "startUTC":
+			t, err := parseUnix(value, data.Time.Location())
+			pass[i].start = t
+			return err
+		case "endUTC":
+			t, err := parseUnix(value, data.Time.Location())
+			pass[i].end = t
+			return err
+		case "startAzCompass":
+			pass[i].compass = string(value)
+		case "maxEl":
+			el, err := strconv.ParseFloat(string(value), 64)
+			if nil != err {
+				return err
+			}
+			pass[i].elevation = int(el + 0.5)
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+	s.pass = pass
+	return nil
+}
+
+// announce posts a Notice for the next pass beginning within the lead time, if
+// it has not already been announced.
+func (s *Satellite) announce(now time.Time) {
+	for _, p := range s.pass {
+		if p.start.IsZero() || !p.end.After(now) {
+			continue
+		}
+		if p.start.Sub(now) > s.config.Lead || !p.start.After(s.announced) {
+			return
+		}
+		s.announced = p.start
+		notify.Post(model.Notice{
+			ID: noticeID,
+			Text: s.config.Name + " " + p.start.Format("15:04") + " " +
+				p.compass + " " + strconv.Itoa(p.elevation) + "deg",
+			Icon:     "satellite",
+			Color:    s.config.Color,
+			Flash:    model.FlashBlink,
+			Priority: model.PriorityNormal,
+			Dismiss:  model.DismissExpire,
+			Expires:  p.end,
+		})
+		return
+	}
+}
+
+func parseUnix(value []byte, loc *time.Location) (time.Time, error) {
+	sec, err := strconv.ParseInt(string(value), 10, 64)
+	if nil != err {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0).In(loc), nil
+}
+
+// key returns the N2YO API key of the Settings, if any, or else the configured
+// key.
+func (s *Satellite) key() string {
+	if key := config.Get().Keys.N2YO; "" != key {
+		return key
+	}
+	return s.config.Key
+}
+
+func (s *Satellite) url(loc model.Location) string {
+	return strings.NewReplacer(
+		"{id}", strconv.Itoa(s.config.ID),
+		"{lat}", strconv.FormatFloat(loc.Latitude, 'f', 4, 64),
+		"{lon}", strconv.FormatFloat(loc.Longitude, 'f', 4, 64),
+		"{days}", strconv.Itoa(s.config.Days),
+		"{visible}", strconv.Itoa(s.config.Visible),
+		"{key}", s.key(),
+	).Replace(s.config.URL)
+}
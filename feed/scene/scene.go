@@ -0,0 +1,81 @@
+// Package scene switches the scene of the display (see display.Scene) at
+// configured times of day, e.g., to the "movie" scene each evening and back to
+// "normal" each morning.
+//
+// The scene of the most recent time of day passed is applied once the time is
+// first known, and again each time another time of day passes. Scenes switched
+// otherwise in between, e.g., by the "scene" command, remain until then.
+package scene
+
+import (
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Entry defines the scene applied at a time of day.
+type Entry struct {
+	Hour   int // 0-23
+	Minute int // 0-59
+	Scene  string
+}
+
+// Config defines the scenes applied each day. The order of entries is
+// irrelevant.
+type Config struct {
+	Entries []Entry
+}
+
+// Schedule is a Feed that applies the scene of each Entry at its time of day.
+type Schedule struct {
+	apply  func(name string) error
+	config Config
+	last   int // index of the Entry most recently applied, or -1 if none
+}
+
+// New returns a new Schedule using the given configuration, which calls apply
+// with the name of each scene to apply, e.g., display.Display.SetScene.
+func New(apply func(name string) error, config Config) *Schedule {
+	return &Schedule{apply: apply, config: config, last: -1}
+}
+
+// Sync applies the scene of the Entry whose time of day most recently passed,
+// unless it was already applied.
+func (s *Schedule) Sync() error {
+
+	if 0 == len(s.config.Entries) {
+		return nil
+	}
+	data := model.Peek()
+	if model.StatusSynchronized != data.Status {
+		return nil // time of day unknown
+	}
+
+	// the Entry latest in the day at or before now, or the latest of all if none
+	// precede now, i.e., that of the previous day.
+	now := data.Time.Hour()*60 + data.Time.Minute()
+	k, kt, latest, lt := -1, -1, -1, -1
+	for i, e := range s.config.Entries {
+		t := e.Hour*60 + e.Minute
+		if t <= now && t > kt {
+			k, kt = i, t
+		}
+		if t > lt {
+			latest, lt = i, t
+		}
+	}
+	if k < 0 {
+		k = latest
+	}
+	if k == s.last {
+		return nil
+	}
+	s.last = k
+
+	name := s.config.Entries[k].Scene
+	if err := s.apply(name); nil != err {
+		journal.Println("scene: " + name + ": " + err.Error())
+		return nil
+	}
+	journal.Println("scene: " + name)
+	return nil
+}
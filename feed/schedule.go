@@ -0,0 +1,68 @@
+package feed
+
+import (
+	"time"
+)
+
+// DefaultSpread defines the longest delay of the first poll of each feed after
+// startup, over which a fleet of devices powered on together is spread.
+const DefaultSpread = 30 * time.Second
+
+// schedule holds the offset of the polls of this device (see Seed) and the time
+// the first poll was considered (see Due).
+var schedule = struct {
+	phase  uint32 // offset, as a fraction of each span (2^32 = 1 span)
+	origin time.Time
+}{}
+
+// Seed derives the offset of the polls of this device from a value unique to
+// the device, such as the MAC address of its network interface. Until Seed is
+// called, polls are aligned exactly to wall-clock boundaries.
+func Seed(seed uint64) {
+	// splitmix64 finalizer, so that similar seeds, e.g., consecutive MAC
+	// addresses, yield unrelated offsets.
+	seed += 0x9E3779B97F4A7C15
+	seed = (seed ^ seed>>30) * 0xBF58476D1CE4E5B9
+	seed = (seed ^ seed>>27) * 0x94D049BB133111EB
+	schedule.phase = uint32((seed ^ seed>>31) >> 32)
+}
+
+// Offset returns the offset of the polls of this device from each wall-clock
+// boundary of the given span, which is less than span.
+func Offset(span time.Duration) time.Duration {
+	return time.Duration(float64(span) * float64(schedule.phase) / (1 << 32))
+}
+
+// Due returns true if a periodic poll is due at time at, given the time since
+// of the previous poll.
+//
+// Polls are aligned to wall-clock boundaries rather than to the previous poll:
+// a poll is due once at reaches the next multiple of span (since the Unix
+// epoch) following since, shifted by the Offset of this device, e.g., minute 7
+// of each hour for an hourly span. This keeps a fleet of devices from polling
+// in lockstep after they are powered on together, e.g., after a power outage.
+//
+// If since is zero, the first poll is due at the Offset of this device within
+// DefaultSpread of the first call to Due, or immediately if that has elapsed,
+// e.g., following Expire. If at is zero, a poll is always due.
+func Due(at, since time.Time, span time.Duration) bool {
+	if at.IsZero() {
+		return true
+	}
+	if since.IsZero() {
+		if schedule.origin.IsZero() {
+			schedule.origin = at
+		}
+		return at.Sub(schedule.origin) >= Offset(DefaultSpread)
+	}
+	if span <= 0 {
+		return true
+	}
+	return slot(at, span) > slot(since, span)
+}
+
+// slot returns the index of the span containing t, counted from the Unix epoch
+// shifted by the Offset of this device.
+func slot(t time.Time, span time.Duration) int64 {
+	return (t.UnixNano() - int64(Offset(span))) / int64(span)
+}
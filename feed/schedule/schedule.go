@@ -0,0 +1,146 @@
+// Package schedule computes the daily times of events relative to the position
+// of the sun at the device Location, e.g., prayer times, or a reminder to walk
+// the dog before sunset, and optionally sounds the buzzer at each of them.
+//
+// For example, the times of the Islamic prayers with a Fajr angle of 18°, an
+// Isha angle of 17°, and Dhuhr shortly after solar noon:
+//
+//	schedule.Config{Events: []schedule.Event{
+//		{Name: "Fajr", Elevation: -18, Rising: true},
+//		{Name: "Sunrise", Elevation: astro.Horizon, Rising: true},
+//		{Name: "Dhuhr", Noon: true, Offset: 2 * time.Minute},
+//		{Name: "Maghrib", Elevation: astro.Horizon},
+//		{Name: "Isha", Elevation: -17},
+//	}}
+package schedule
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ardnew/weatherhub/astro"
+	"github.com/ardnew/weatherhub/buzzer"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Errors returned by Schedule operations.
+var (
+	ErrTooManyEvents = errors.New("too many scheduled events")
+)
+
+// Event defines a daily event at the time the sun crosses a given elevation,
+// or at solar noon, plus an offset.
+type Event struct {
+	Name      string
+	Elevation float64       // of the sun, in degrees, e.g., astro.Horizon
+	Rising    bool          // crossing the elevation rising, else setting
+	Noon      bool          // at solar noon, regardless of Elevation and Rising
+	Offset    time.Duration // added to the time of the crossing, may be negative
+	Chime     bool          // sound the buzzer at the time of the event
+}
+
+// Config defines the events of the schedule, in the order they are shown, and
+// the melody played at those that chime.
+//
+// The Feed is disabled if Events is empty.
+type Config struct {
+	Events []Event
+	Melody string // RTTTL melody (see buzzer.Parse), or a beep if empty
+}
+
+// Schedule is a Feed that computes the times of the configured events each day,
+// and plays the melody on the buzzer at the time of those that chime.
+type Schedule struct {
+	buzzer   *buzzer.Buzzer
+	config   Config
+	melody   []buzzer.Tone
+	day      time.Time      // date of the times computed, zero if none
+	location model.Location // at which the times were computed
+	event    [model.MaxEvents]model.Event
+	chimed   time.Time // time of the event most recently chimed
+}
+
+// New returns a new Schedule using the given buzzer, which may be nil if there
+// is none, and configuration.
+// Returns ErrTooManyEvents if more than model.MaxEvents are configured, or
+// buzzer.ErrInvalidMelody if the melody cannot be parsed.
+func New(b *buzzer.Buzzer, config Config) (*Schedule, error) {
+
+	if len(config.Events) > model.MaxEvents {
+		return nil, ErrTooManyEvents
+	}
+
+	melody := buzzer.Beep
+	if "" != config.Melody {
+		var err error
+		if melody, err = buzzer.Parse(config.Melody); nil != err {
+			return nil, err
+		}
+	}
+
+	return &Schedule{buzzer: b, config: config, melody: melody}, nil
+}
+
+// Sync computes the times of the events at the start of each day, or once the
+// device Location changes, and plays the melody if an event that chimes has
+// occurred within the past minute.
+func (s *Schedule) Sync() error {
+
+	if 0 == len(s.config.Events) {
+		return nil // feed disabled
+	}
+	data := model.Peek()
+	if !data.Location.Known() {
+		return nil
+	}
+
+	y, m, d := data.Time.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, data.Time.Location())
+	if !day.Equal(s.day) || data.Location != s.location {
+		s.day, s.location = day, data.Location
+		s.compute(data.Time)
+		model.Set(func(m *model.Model) {
+			m.Schedule = model.Schedule{Active: true, Event: s.event}
+		})
+	}
+
+	if nil == s.buzzer {
+		return nil
+	}
+	for i, e := range s.config.Events {
+		at := s.event[i].Time
+		if !e.Chime || at.IsZero() || !at.After(s.chimed) {
+			continue
+		}
+		if elapsed := data.Time.Sub(at); elapsed >= 0 && elapsed < time.Minute {
+			s.chimed = at
+			s.buzzer.Play(s.melody)
+		}
+	}
+	return nil
+}
+
+// compute determines the times of the events on the day of time t.
+func (s *Schedule) compute(t time.Time) {
+	loc := s.location
+	for i, e := range s.config.Events {
+		var (
+			at time.Time
+			ok bool
+		)
+		if e.Noon {
+			// solar noon is midway between sunrise and sunset.
+			var rise, set time.Time
+			if rise, ok = astro.SunTime(t, loc.Latitude, loc.Longitude, astro.Horizon, true); ok {
+				set, ok = astro.SunTime(t, loc.Latitude, loc.Longitude, astro.Horizon, false)
+				at = rise.Add(set.Sub(rise) / 2)
+			}
+		} else {
+			at, ok = astro.SunTime(t, loc.Latitude, loc.Longitude, e.Elevation, e.Rising)
+		}
+		s.event[i] = model.Event{Name: e.Name}
+		if ok {
+			s.event[i].Time = at.Add(e.Offset)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package scores implements a Feed of game scores from a JSON scoreboard.
+//
+// Any web API that replies with a JSON document can be used, since the paths of
+// the team and score fields within the reply are configurable.
+package scores
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Scores configuration.
+const (
+	DefaultInterval = 2 * time.Minute
+)
+
+// Config defines the scoreboard URL and the paths of the fields in its JSON
+// reply. Paths must contain "*" to match each game in an array of games, e.g.,
+// "events.*.home.abbr" (see package codec/json).
+//
+// The Feed is disabled if URL is empty.
+type Config struct {
+	URL       string
+	Home      string // path of each game's home team abbreviation
+	Away      string // path of each game's away team abbreviation
+	HomeScore string
+	AwayScore string
+	Status    string // optional path of each game's status, e.g., "Final"
+	Interval  time.Duration
+}
+
+// Scores polls a JSON scoreboard for game scores.
+type Scores struct {
+	client   *http.Client
+	config   Config
+	lastSync time.Time
+}
+
+// New returns a new Scores using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Scores {
+
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Scores{client: client, config: config}
+}
+
+// Sync polls the scoreboard if the polling interval has elapsed.
+func (s *Scores) Sync() error {
+
+	if "" == s.config.URL {
+		return nil // feed disabled
+	}
+
+	now := model.Peek().Time
+	if !feed.Due(now, s.lastSync, s.config.Interval) {
+		return nil
+	}
+	s.lastSync = now
+
+	var sc model.Scores
+	err := feed.FetchJSON(s.client, s.config.URL, func(path []byte, kind json.Kind, value []byte) error {
+		if i := game(path, s.config.Home); i >= 0 {
+			sc.Game[i].Home = string(value)
+		} else if i := game(path, s.config.Away); i >= 0 {
+			sc.Game[i].Away = string(value)
+		} else if i := game(path, s.config.HomeScore); i >= 0 {
+			sc.Game[i].HomeScore = string(value)
+		} else if i := game(path, s.config.AwayScore); i >= 0 {
+			sc.Game[i].AwayScore = string(value)
+		} else if i := game(path, s.config.Status); i >= 0 {
+			sc.Game[i].Status = string(value)
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+
+	model.Set(func(m *model.Model) {
+		m.Scores = sc
+	})
+	return nil
+}
+
+// game returns the index of the game whose field at the given path matches
+// pattern, or -1 if it does not match or exceeds the number of games held.
+func game(path []byte, pattern string) int {
+	if "" == pattern {
+		return -1
+	}
+	if ok, i := json.Match(path, pattern); ok && i < model.MaxGames {
+		return i
+	}
+	return -1
+}
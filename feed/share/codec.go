@@ -0,0 +1,221 @@
+package share
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// version identifies the encoding of messages, which is the first byte of each.
+// Messages of any other version are ignored, so that devices running different
+// versions of the program do not misinterpret each other.
+const version = 1
+
+var (
+	ErrVersion   = errors.New("shared weather message has unsupported version")
+	ErrTruncated = errors.New("shared weather message is truncated")
+)
+
+// Quantities are encoded as little-endian integers of fixed size. Times are
+// given in Unix seconds, and fractional quantities are scaled by 10 or 100 to
+// retain the precision reported by forecast services.
+
+func appendU16(b []byte, v uint16) []byte { return append(b, byte(v), byte(v>>8)) }
+
+func appendU32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// appendTime appends t in Unix seconds, or 0 if t is the zero time.
+func appendTime(b []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return appendU32(b, 0)
+	}
+	return appendU32(b, uint32(t.Unix()))
+}
+
+// appendScaled appends f multiplied by scale, rounded and saturated to int16.
+func appendScaled(b []byte, f float32, scale float32) []byte {
+	v := math.Round(float64(f * scale))
+	if v > math.MaxInt16 {
+		v = math.MaxInt16
+	} else if v < math.MinInt16 {
+		v = math.MinInt16
+	}
+	return appendU16(b, uint16(int16(v)))
+}
+
+func appendString(b []byte, s string) []byte {
+	if len(s) > 0xFF {
+		s = s[:0xFF]
+	}
+	return append(append(b, byte(len(s))), s...)
+}
+
+// appendLocation appends the encoded Location to b.
+func appendLocation(b []byte, loc model.Location) []byte {
+	b = append(b, version)
+	b = appendU32(b, uint32(math.Float64bits(loc.Latitude)))
+	b = appendU32(b, uint32(math.Float64bits(loc.Latitude)>>32))
+	b = appendU32(b, uint32(math.Float64bits(loc.Longitude)))
+	b = appendU32(b, uint32(math.Float64bits(loc.Longitude)>>32))
+	b = appendU32(b, uint32(int32(loc.Offset)))
+	b = appendString(b, loc.Zone)
+	return appendString(b, loc.City)
+}
+
+// appendWeather appends the encoded current conditions, daily forecast, and
+// nowcast of w to b.
+func appendWeather(b []byte, w model.Weather) []byte {
+	b = append(b, version)
+	b = appendTime(b, w.Updated)
+	c := w.Current
+	b = appendScaled(b, c.Temperature, 10)
+	b = append(b, c.Humidity)
+	b = appendScaled(b, c.WindSpeed, 10)
+	b = appendScaled(b, c.WindGust, 10)
+	b = append(b, byte(c.Code))
+	for _, d := range w.Day {
+		b = appendTime(b, d.Date)
+		b = appendScaled(b, d.High, 10)
+		b = appendScaled(b, d.Low, 10)
+		b = append(b, d.Chance)
+		b = appendScaled(b, d.Rain, 10)
+		b = append(b, byte(d.Code))
+	}
+	b = appendTime(b, w.Nowcast.Start)
+	for _, r := range w.Nowcast.Rain {
+		b = appendScaled(b, r, 100)
+	}
+	return b
+}
+
+// appendHourly appends the encoded hourly forecast of w to b.
+func appendHourly(b []byte, w model.Weather) []byte {
+	b = append(b, version)
+	b = appendTime(b, w.Updated)
+	for _, h := range w.Hour {
+		b = appendTime(b, h.Time)
+		b = appendScaled(b, h.Temperature, 10)
+		b = append(b, h.Humidity, h.Chance)
+		b = appendScaled(b, h.Rain, 100)
+		b = appendScaled(b, h.Snow, 10)
+		b = appendScaled(b, h.WindGust, 10)
+		b = append(b, byte(h.Code))
+	}
+	return b
+}
+
+// reader decodes the quantities of a message in order. Once the message is
+// exhausted, every read returns zero and err is set to ErrTruncated.
+type reader struct {
+	b   []byte
+	err error
+}
+
+// newReader returns a reader of the given message, following its version.
+func newReader(b []byte) *reader {
+	r := &reader{b: b}
+	if version != r.u8() && nil == r.err {
+		r.err = ErrVersion
+	}
+	return r
+}
+
+func (r *reader) next(n int) []byte {
+	if len(r.b) < n {
+		r.b, r.err = nil, ErrTruncated
+		return make([]byte, n)
+	}
+	p := r.b[:n]
+	r.b = r.b[n:]
+	return p
+}
+
+func (r *reader) u8() uint8   { return r.next(1)[0] }
+func (r *reader) u16() uint16 { return binary.LittleEndian.Uint16(r.next(2)) }
+func (r *reader) u32() uint32 { return binary.LittleEndian.Uint32(r.next(4)) }
+
+func (r *reader) time(loc *time.Location) time.Time {
+	if sec := int64(r.u32()); 0 != sec {
+		return time.Unix(sec, 0).In(loc)
+	}
+	return time.Time{} // zero time is encoded as 0 (1970), not year 1
+}
+
+func (r *reader) scaled(scale float32) float32 {
+	return float32(int16(r.u16())) / scale
+}
+
+func (r *reader) float64() float64 {
+	lo := uint64(r.u32())
+	return math.Float64frombits(lo | uint64(r.u32())<<32)
+}
+
+func (r *reader) string() string {
+	return string(r.next(int(r.u8())))
+}
+
+// decodeLocation returns the Location encoded in message b.
+func decodeLocation(b []byte) (model.Location, error) {
+	r := newReader(b)
+	loc := model.Location{
+		Latitude:  r.float64(),
+		Longitude: r.float64(),
+		Offset:    int(int32(r.u32())),
+	}
+	loc.Zone = r.string()
+	loc.City = r.string()
+	return loc, r.err
+}
+
+// decodeWeather decodes the current conditions, daily forecast, and nowcast
+// encoded in message b into w, with times in the given location.
+func decodeWeather(b []byte, w *model.Weather, loc *time.Location) error {
+	r := newReader(b)
+	w.Updated = r.time(loc)
+	c := &w.Current
+	c.Temperature = r.scaled(10)
+	c.Humidity = r.u8()
+	c.WindSpeed = r.scaled(10)
+	c.WindGust = r.scaled(10)
+	c.Code = model.Code(r.u8())
+	for i := range w.Day {
+		d := &w.Day[i]
+		d.Date = r.time(loc)
+		d.High = r.scaled(10)
+		d.Low = r.scaled(10)
+		d.Chance = r.u8()
+		d.Rain = r.scaled(10)
+		d.Code = model.Code(r.u8())
+	}
+	w.Nowcast.Start = r.time(loc)
+	for i := range w.Nowcast.Rain {
+		w.Nowcast.Rain[i] = r.scaled(100)
+	}
+	return r.err
+}
+
+// decodeHourly decodes the hourly forecast encoded in message b into w, with
+// times in the given location.
+func decodeHourly(b []byte, w *model.Weather, loc *time.Location) error {
+	r := newReader(b)
+	if updated := r.time(loc); updated.After(w.Updated) {
+		w.Updated = updated
+	}
+	for i := range w.Hour {
+		h := &w.Hour[i]
+		h.Time = r.time(loc)
+		h.Temperature = r.scaled(10)
+		h.Humidity = r.u8()
+		h.Chance = r.u8()
+		h.Rain = r.scaled(100)
+		h.Snow = r.scaled(10)
+		h.WindGust = r.scaled(10)
+		h.Code = model.Code(r.u8())
+	}
+	return r.err
+}
@@ -0,0 +1,156 @@
+// Package share coordinates several devices in one house, so that only one of
+// them polls the forecast service while all of them show the same weather.
+//
+// The leader publishes its Location and Weather to the broker as retained MQTT
+// messages whenever they change. Followers do not poll the geolocation and
+// forecast services at all, and instead subscribe to the messages of the leader,
+// so that a follower started later receives the latest weather immediately.
+//
+// Messages are encoded in a compact binary form (see codec.go), since the
+// hourly forecast is too large for an MQTT packet when encoded as JSON.
+package share
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for Share configuration.
+const (
+	DefaultTopic = "weatherhub/share"
+)
+
+var (
+	ErrNoBroker = errors.New("shared weather requires an MQTT broker")
+)
+
+// Role selects whether a device fetches or consumes the shared weather.
+type Role uint8
+
+// Constants defining each possible Role.
+const (
+	RoleNone     Role = iota // fetch weather independently, sharing nothing
+	RoleLeader               // fetch weather and publish it to followers
+	RoleFollower             // receive weather from the leader
+)
+
+// Suffixes of the topics of each message, appended to Config Topic.
+const (
+	topicLocation = "/location"
+	topicWeather  = "/weather" // current conditions, daily forecast, nowcast
+	topicHourly   = "/hourly"
+)
+
+// Config defines the Role of the device and the topic prefix shared by all
+// devices. Topic should not begin with "~", which is replaced by the name of
+// each device (see mqtt.Config Prefix).
+type Config struct {
+	Role  Role
+	Topic string
+}
+
+// Share is a Feed that publishes the weather of the leader, or receives the
+// weather of the leader on a follower.
+type Share struct {
+	broker   *mqtt.Client
+	config   Config
+	location model.Location // most recently published, on the leader
+	updated  int64          // Weather.Updated most recently published (Unix)
+	buf      []byte         // encoded message, reused by each publish
+}
+
+// New returns a new Share using the given MQTT client and configuration. The
+// client is required unless the Role is RoleNone.
+func New(broker *mqtt.Client, config Config) (*Share, error) {
+
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+
+	s := &Share{broker: broker, config: config}
+	if RoleNone == config.Role {
+		return s, nil
+	}
+	if nil == broker {
+		return nil, ErrNoBroker
+	}
+	if RoleFollower == config.Role {
+		if err := broker.Subscribe(config.Topic+"/+", s.receive); nil != err {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Following returns true if the weather is received from the leader, in which
+// case the geolocation and forecast feeds should not be used.
+func (s *Share) Following() bool {
+	return RoleFollower == s.config.Role
+}
+
+// Sync publishes the Location and Weather on the leader whenever they change.
+// If the broker is not connected, publishing is retried by the next Sync.
+func (s *Share) Sync() error {
+
+	if RoleLeader != s.config.Role || !s.broker.Connected() {
+		return nil
+	}
+
+	data := model.Peek()
+	if data.Location.Known() && data.Location != s.location {
+		s.buf = appendLocation(s.buf[:0], data.Location)
+		if err := s.broker.Publish(s.config.Topic+topicLocation, s.buf, true); nil != err {
+			return err
+		}
+		s.location = data.Location
+	}
+	if data.Weather.Valid() && data.Weather.Updated.Unix() != s.updated {
+		s.buf = appendWeather(s.buf[:0], data.Weather)
+		if err := s.broker.Publish(s.config.Topic+topicWeather, s.buf, true); nil != err {
+			return err
+		}
+		s.buf = appendHourly(s.buf[:0], data.Weather)
+		if err := s.broker.Publish(s.config.Topic+topicHourly, s.buf, true); nil != err {
+			return err
+		}
+		s.updated = data.Weather.Updated.Unix()
+	}
+	return nil
+}
+
+// receive decodes a message published by the leader into the Model.
+func (s *Share) receive(topic string, payload []byte) {
+
+	var err error
+	switch {
+	case strings.HasSuffix(topic, topicLocation):
+		var loc model.Location
+		if loc, err = decodeLocation(payload); nil == err {
+			loc.Auto = false // never replaced by geolocation on a follower
+			model.Set(func(m *model.Model) {
+				m.Location = loc
+			})
+		}
+	case strings.HasSuffix(topic, topicWeather), strings.HasSuffix(topic, topicHourly):
+		// decode into a copy, so that the Model is unchanged if malformed.
+		data := model.Peek()
+		w := data.Weather
+		if strings.HasSuffix(topic, topicWeather) {
+			err = decodeWeather(payload, &w, data.Time.Location())
+		} else {
+			err = decodeHourly(payload, &w, data.Time.Location())
+		}
+		if nil == err {
+			model.Set(func(m *model.Model) {
+				m.Weather = w
+			})
+		}
+	}
+	if nil != err {
+		journal.Println("error: " + topic + ": " + err.Error())
+	}
+}
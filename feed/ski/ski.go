@@ -0,0 +1,124 @@
+// Package ski implements a Feed of the snow conditions at a ski resort during
+// ski season.
+//
+// Snow depth and snowfall are retrieved from Open-Meteo (open-meteo.com) for the
+// coordinates of the resort, which should be those of its slopes rather than its
+// base village, since both vary greatly with elevation.
+package ski
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Ski configuration.
+const (
+	DefaultURL = "https://api.open-meteo.com/v1/forecast" +
+		"?latitude={lat}&longitude={lon}" +
+		"&current=snow_depth,temperature_2m&daily=snowfall_sum&forecast_days=1&timezone=auto"
+	DefaultStart    = time.December
+	DefaultEnd      = time.March
+	DefaultInterval = time.Hour
+)
+
+// Config defines the ski resort and its season. The Feed is disabled if the
+// coordinates of the resort are not configured.
+type Config struct {
+	Name      string
+	Latitude  float64    // degrees north
+	Longitude float64    // degrees east
+	Start     time.Month // first month of ski season
+	End       time.Month // last month of ski season
+	URL       string     // placeholders {lat} and {lon} are replaced
+	Interval  time.Duration
+}
+
+// Ski polls the snow conditions at a ski resort.
+type Ski struct {
+	client   *http.Client
+	config   Config
+	url      string
+	lastSync time.Time
+}
+
+// New returns a new Ski using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Ski {
+
+	if config.Start == 0 && config.End == 0 {
+		config.Start, config.End = DefaultStart, DefaultEnd
+	}
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Ski{
+		client: client,
+		config: config,
+		url: strings.NewReplacer(
+			"{lat}", strconv.FormatFloat(config.Latitude, 'f', 4, 64),
+			"{lon}", strconv.FormatFloat(config.Longitude, 'f', 4, 64),
+		).Replace(config.URL),
+	}
+}
+
+// Sync polls the snow conditions if in season and the polling interval has
+// elapsed. Out of season, the conditions are removed from the Model.
+func (s *Ski) Sync() error {
+
+	if 0 == s.config.Latitude && 0 == s.config.Longitude {
+		return nil // feed disabled
+	}
+
+	data := model.Peek()
+	if !feed.InSeason(data.Time, s.config.Start, s.config.End) {
+		if data.Ski.Active {
+			model.Set(func(m *model.Model) {
+				m.Ski = model.Ski{}
+			})
+		}
+		s.lastSync = time.Time{}
+		return nil
+	}
+
+	if !feed.Due(data.Time, s.lastSync, s.config.Interval) {
+		return nil
+	}
+	s.lastSync = data.Time
+
+	ski := model.Ski{Active: true, Name: s.config.Name}
+	err := feed.FetchJSON(s.client, s.url, func(path []byte, kind json.Kind, value []byte) error {
+		if json.Number != kind {
+			return nil
+		}
+		v, err := strconv.ParseFloat(string(value), 32)
+		if nil != err {
+			return err
+		}
+		switch string(path) {
+		case "current.snow_depth":
+			ski.Depth = float32(v * 100) // reported in meters, held in centimeters
+		case "current.temperature_2m":
+			ski.Temperature = float32(v)
+		case "daily.snowfall_sum.0":
+			ski.Snowfall = float32(v)
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+
+	model.Set(func(m *model.Model) {
+		m.Ski = ski
+	})
+	return nil
+}
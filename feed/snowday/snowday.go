@@ -0,0 +1,131 @@
+// Package snowday implements a playful "snow day-o-meter", estimating the chance
+// that school is canceled on the next school morning.
+//
+// The estimate combines the snowfall forecast before the morning, relative to a
+// district's threshold of accumulation, with the overnight low (snow stays on
+// cold roads) and any freezing rain (ice cancels school on its own). It is not
+// a prediction anyone should rely upon.
+package snowday
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Default constants for SnowDay configuration.
+const (
+	DefaultThreshold = 10            // centimeters (4 in)
+	DefaultMorning   = 7 * time.Hour // 07:00
+)
+
+// Constants defining the contribution of each factor to the chance.
+const (
+	iceChance = 0.3 // added if freezing rain or drizzle is expected
+	coldLow   = -5  // °C at or below which snow is not expected to melt
+	warmLow   = 2   // °C at or above which snow is expected to melt
+	warmScale = 0.4 // scale of snowfall contribution at warmLow
+	maxChance = 99  // it is never certain
+)
+
+// Config defines the accumulation considered sufficient to cancel school, and
+// when the school day begins. School days are Monday through Friday.
+type Config struct {
+	Threshold float32       // snowfall, centimeters
+	Morning   time.Duration // start of the school day, as offset from midnight
+}
+
+// SnowDay is a Feed that updates the estimate each time the forecast is
+// updated.
+type SnowDay struct {
+	config  Config
+	updated time.Time
+}
+
+// New returns a new SnowDay with the given configuration.
+func New(config Config) *SnowDay {
+
+	if config.Threshold == 0 {
+		config.Threshold = DefaultThreshold
+	}
+	if config.Morning == 0 {
+		config.Morning = DefaultMorning
+	}
+
+	return &SnowDay{config: config}
+}
+
+// Sync updates the estimate if the forecast has been updated since the
+// previous call.
+func (s *SnowDay) Sync() error {
+
+	data := model.Peek()
+	if !data.Weather.Valid() || !data.Weather.Updated.After(s.updated) {
+		return nil
+	}
+	s.updated = data.Weather.Updated
+
+	est := s.estimate(data.Weather, data.Time)
+	if est != data.SnowDay {
+		model.Set(func(m *model.Model) {
+			m.SnowDay = est
+		})
+	}
+	return nil
+}
+
+// estimate returns the chance of a snow day on the next school morning after
+// time now.
+func (s *SnowDay) estimate(w model.Weather, now time.Time) model.SnowDay {
+
+	morning := s.morning(now)
+	if morning.Sub(now) > model.MaxHours*time.Hour {
+		return model.SnowDay{} // beyond the hourly forecast, e.g., on weekends
+	}
+	est := model.SnowDay{Morning: morning}
+	found := false
+	for _, h := range w.Hour {
+		if h.Time.IsZero() || !h.Time.Before(morning) {
+			break
+		}
+		if !found || h.Temperature < est.Low {
+			est.Low, found = h.Temperature, true
+		}
+		est.Snow += h.Snow
+		est.Ice = est.Ice || h.Code.Freezing()
+	}
+	if !found || (0 == est.Snow && !est.Ice) {
+		return model.SnowDay{}
+	}
+
+	// snowfall contributes in proportion to the threshold, reduced if the low is
+	// warm enough that the snow is likely to melt.
+	chance := est.Snow / s.config.Threshold
+	switch {
+	case est.Low >= warmLow:
+		chance *= warmScale
+	case est.Low > coldLow:
+		chance *= warmScale + (1-warmScale)*(warmLow-est.Low)/(warmLow-coldLow)
+	}
+	if est.Ice {
+		chance += iceChance
+	}
+	if chance *= 100; chance > maxChance {
+		chance = maxChance
+	}
+	est.Chance = uint8(chance)
+	return est
+}
+
+// morning returns the start of the next school day after time now.
+func (s *SnowDay) morning(now time.Time) time.Time {
+	y, m, d := now.Date()
+	t := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).Add(s.config.Morning)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	for time.Saturday == t.Weekday() || time.Sunday == t.Weekday() {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
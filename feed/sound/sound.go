@@ -0,0 +1,120 @@
+// Package sound plays a melody on the buzzer when certain notices are posted,
+// e.g., alarms and weather alerts, so that they are heard as well as seen.
+//
+// Each melody is selected by the ID of the notice (see model.Notice), so that
+// different kinds of notices have distinct sounds.
+package sound
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/buzzer"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Default melodies of the notices posted by the feeds of this program.
+const (
+	DefaultAlarm = "alarm:d=16,o=6,b=140:a,p,a,p,a,p,a,p,a,p,a"
+	DefaultAlert = "alert:d=8,o=5,b=120:e6,c6,4g"
+)
+
+// Melody is a melody played when a notice is posted with an ID beginning with
+// Prefix, e.g., "advisory:" for every weather advisory.
+type Melody struct {
+	Prefix string
+	Song   string // RTTTL melody (see buzzer.Parse)
+}
+
+// DefaultMelodies defines the melodies played, unless configured otherwise: an
+// alarm for wind gusts, and an alert for weather advisories.
+var DefaultMelodies = []Melody{
+	{Prefix: "wind", Song: DefaultAlarm},
+	{Prefix: "advisory:", Song: DefaultAlert},
+}
+
+// Config defines the melodies played for notices. The first melody whose
+// prefix matches the ID of a notice is played.
+type Config struct {
+	Melodies []Melody
+}
+
+// song is a parsed Melody.
+type song struct {
+	prefix string
+	tone   []buzzer.Tone
+}
+
+// Sound is a Feed that plays the melody of each notice when it is posted.
+type Sound struct {
+	buzzer *buzzer.Buzzer
+	song   []song
+	played [model.MaxNotices]time.Time // when each notice played was posted
+}
+
+// New returns a new Sound using the given buzzer and configuration.
+// Returns buzzer.ErrInvalidMelody if any melody cannot be parsed.
+func New(b *buzzer.Buzzer, config Config) (*Sound, error) {
+	s := &Sound{buzzer: b}
+	if err := s.Set(config); nil != err {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Set changes the melodies played, e.g., when the Settings change. Returns
+// buzzer.ErrInvalidMelody if any melody cannot be parsed, in which case the
+// melodies are unchanged.
+func (s *Sound) Set(config Config) error {
+
+	if 0 == len(config.Melodies) {
+		config.Melodies = DefaultMelodies
+	}
+
+	var parsed []song
+	for _, m := range config.Melodies {
+		tone, err := buzzer.Parse(m.Song)
+		if nil != err {
+			return err
+		}
+		parsed = append(parsed, song{prefix: m.Prefix, tone: tone})
+	}
+	s.song = parsed
+	return nil
+}
+
+// Sync plays the melody of the notice of highest priority posted since the
+// previous call, if any has a melody.
+func (s *Sound) Sync() error {
+
+	data := model.Peek()
+	var (
+		play     []buzzer.Tone
+		priority model.Priority
+	)
+	for i, n := range data.Notice {
+		if !n.Active(data.Time) || n.Posted.Equal(s.played[i]) {
+			continue
+		}
+		s.played[i] = n.Posted
+		if tone := s.melody(n.ID); nil != tone &&
+			(nil == play || n.Priority > priority) {
+			play, priority = tone, n.Priority
+		}
+	}
+	if nil != play {
+		s.buzzer.Play(play)
+	}
+	return nil
+}
+
+// melody returns the tones of the first melody whose prefix matches the given
+// notice ID, or nil if none match.
+func (s *Sound) melody(id string) []buzzer.Tone {
+	for _, m := range s.song {
+		if strings.HasPrefix(id, m.prefix) {
+			return m.tone
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package summary publishes a one-line summary of the day's forecast each
+// morning, e.g., "High 72, rain after 3pm".
+//
+// The summary is shown on the display as a Notice (see package notify), and it
+// is also published to an MQTT topic and/or posted to an ntfy endpoint (ntfy.sh)
+// so that it arrives on a phone.
+package summary
+
+import (
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/lang"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+	"github.com/ardnew/weatherhub/wifi/http"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for Summary configuration.
+const (
+	DefaultAt       = 7 * time.Hour // 07:00
+	DefaultTopic    = "~/summary"
+	DefaultDuration = 30 * time.Second
+	DefaultChance   = 50 // percent
+)
+
+// window defines how long after the configured time the summary may still be
+// published, e.g., if the forecast was not yet available.
+const window = time.Hour
+
+// noticeID identifies the notices posted by Summary.
+const noticeID = "summary"
+
+// DefaultColor defines the color of the summary shown on the display.
+var DefaultColor = color.RGBA{R: 0x80, G: 0xC0, B: 0xFF, A: 0xFF}
+
+// Config defines when and where the summary is published.
+//
+// The summary is not published to MQTT if Topic is "-", and it is not posted to
+// ntfy if URL is empty.
+type Config struct {
+	At       time.Duration // time of day, as offset from local midnight
+	Units    model.Units
+	Language lang.Language
+	Chance   uint8         // probability of precipitation considered likely, percent
+	Topic    string        // MQTT topic
+	URL      string        // ntfy endpoint, e.g., "https://ntfy.sh/mytopic"
+	Duration time.Duration // how long the summary is shown on the display
+	Color    color.RGBA
+}
+
+// Summary is a Feed that publishes the daily forecast summary.
+type Summary struct {
+	client *http.Client
+	broker *mqtt.Client
+	config Config
+	last   time.Time // date of the most recent summary
+}
+
+// New returns a new Summary using the given HTTP and MQTT clients and
+// configuration.
+func New(client *http.Client, broker *mqtt.Client, config Config) *Summary {
+
+	if config.At == 0 {
+		config.At = DefaultAt
+	}
+	if config.Chance == 0 {
+		config.Chance = DefaultChance
+	}
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+	if config.Duration == 0 {
+		config.Duration = DefaultDuration
+	}
+	if config.Color == (color.RGBA{}) {
+		config.Color = DefaultColor
+	}
+
+	return &Summary{client: client, broker: broker, config: config}
+}
+
+// SetLocale changes the units and language of the summary, e.g., when the
+// Settings change, taking effect when the summary is next published.
+func (s *Summary) SetLocale(units model.Units, language lang.Language) {
+	s.config.Units, s.config.Language = units, language
+}
+
+// Sync publishes the summary if the configured time of day has passed, the
+// forecast has been retrieved today, and the summary has not yet been published
+// today.
+func (s *Summary) Sync() error {
+
+	data := model.Peek()
+	if data.Time.IsZero() || !data.Weather.Valid() {
+		return nil
+	}
+	today := midnight(data.Time)
+	if !today.After(s.last) ||
+		data.Weather.Updated.Before(today) ||
+		data.Time.Before(today.Add(s.config.At)) ||
+		!data.Time.Before(today.Add(s.config.At+window)) {
+		return nil
+	}
+	s.last = today
+
+	text := Compose(data.Weather, data.Time, s.config.Units, s.config.Language,
+		s.config.Chance)
+
+	notify.Post(model.Notice{
+		ID:       noticeID,
+		Text:     text,
+		Color:    s.config.Color,
+		Dismiss:  model.DismissShown,
+		Duration: s.config.Duration,
+	})
+	if nil != s.broker && "-" != s.config.Topic {
+		if err := s.broker.Publish(s.config.Topic, []byte(text), false); nil != err {
+			return err
+		}
+	}
+	if "" != s.config.URL {
+		res, err := s.client.Post(s.config.URL, "text/plain", []byte(text))
+		if nil != err {
+			return err
+		}
+		res.Close()
+		if !res.OK() {
+			return http.ErrStatus
+		}
+	}
+	return nil
+}
+
+// Compose returns the summary of the forecast for the day of time now, in the
+// given Language, e.g.:
+//
+//	"High 72, rain after 3pm"
+//	"High 18, partly cloudy"
+//	"Max 18, Regen ab 15 Uhr"
+//
+// Precipitation is reported from the first remaining hour of the day with
+// precipitation forecast, or with at least the given chance of it.
+func Compose(w model.Weather, now time.Time, units model.Units,
+	language lang.Language, chance uint8) string {
+
+	day := w.Day[0]
+	for _, d := range w.Day {
+		if sameDay(d.Date, now) {
+			day = d
+			break
+		}
+	}
+
+	high, _ := units.Temp(day.High)
+	text := language.High() + " " + strconv.Itoa(round(high))
+
+	for _, h := range w.Hour {
+		if !sameDay(h.Time, now) {
+			continue
+		}
+		if h.Code.Precipitating() || h.Chance >= chance {
+			kind := language.Condition(model.ConditionRain)
+			if h.Code.Precipitating() {
+				kind = language.Code(h.Code)
+			}
+			return text + ", " + kind + " " + language.After(h.Time)
+		}
+	}
+	return text + ", " + language.Code(day.Code)
+}
+
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func round(f float32) int {
+	if f < 0 {
+		return int(f - 0.5)
+	}
+	return int(f + 0.5)
+}
@@ -0,0 +1,124 @@
+// Package surf implements a Feed of the wave conditions at a surf break during
+// surf season.
+//
+// Wave height, period, and direction are retrieved from the Open-Meteo marine
+// API (open-meteo.com) for the coordinates of the break, which should be just
+// offshore, since the marine model has no data over land.
+package surf
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Surf configuration.
+const (
+	DefaultURL = "https://marine-api.open-meteo.com/v1/marine" +
+		"?latitude={lat}&longitude={lon}" +
+		"&current=wave_height,wave_period,wave_direction"
+	DefaultStart    = time.May
+	DefaultEnd      = time.October
+	DefaultInterval = time.Hour
+)
+
+// Config defines the surf break and its season. The Feed is disabled if the
+// coordinates of the break are not configured.
+type Config struct {
+	Name      string
+	Latitude  float64    // degrees north
+	Longitude float64    // degrees east
+	Start     time.Month // first month of surf season
+	End       time.Month // last month of surf season
+	URL       string     // placeholders {lat} and {lon} are replaced
+	Interval  time.Duration
+}
+
+// Surf polls the wave conditions at a surf break.
+type Surf struct {
+	client   *http.Client
+	config   Config
+	url      string
+	lastSync time.Time
+}
+
+// New returns a new Surf using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Surf {
+
+	if config.Start == 0 && config.End == 0 {
+		config.Start, config.End = DefaultStart, DefaultEnd
+	}
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Surf{
+		client: client,
+		config: config,
+		url: strings.NewReplacer(
+			"{lat}", strconv.FormatFloat(config.Latitude, 'f', 4, 64),
+			"{lon}", strconv.FormatFloat(config.Longitude, 'f', 4, 64),
+		).Replace(config.URL),
+	}
+}
+
+// Sync polls the wave conditions if in season and the polling interval has
+// elapsed. Out of season, the conditions are removed from the Model.
+func (s *Surf) Sync() error {
+
+	if 0 == s.config.Latitude && 0 == s.config.Longitude {
+		return nil // feed disabled
+	}
+
+	data := model.Peek()
+	if !feed.InSeason(data.Time, s.config.Start, s.config.End) {
+		if data.Surf.Active {
+			model.Set(func(m *model.Model) {
+				m.Surf = model.Surf{}
+			})
+		}
+		s.lastSync = time.Time{}
+		return nil
+	}
+
+	if !feed.Due(data.Time, s.lastSync, s.config.Interval) {
+		return nil
+	}
+	s.lastSync = data.Time
+
+	surf := model.Surf{Active: true, Name: s.config.Name}
+	err := feed.FetchJSON(s.client, s.url, func(path []byte, kind json.Kind, value []byte) error {
+		if json.Number != kind {
+			return nil
+		}
+		v, err := strconv.ParseFloat(string(value), 32)
+		if nil != err {
+			return err
+		}
+		switch string(path) {
+		case "current.wave_height":
+			surf.Height = float32(v)
+		case "current.wave_period":
+			surf.Period = float32(v)
+		case "current.wave_direction":
+			surf.Direction = float32(v)
+		}
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+
+	model.Set(func(m *model.Model) {
+		m.Surf = surf
+	})
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package transit implements a Feed of upcoming departures from a transit stop.
+//
+// Departures are retrieved from any web API that replies with a JSON document,
+// such as a local GTFS-realtime proxy or an agency's REST endpoint. The paths of
+// the route and time fields within the reply are configurable, so no code is
+// specific to a particular agency.
+package transit
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/codec/json"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Transit configuration.
+const (
+	DefaultStart    = 6*time.Hour + 30*time.Minute // 06:30
+	DefaultEnd      = 9 * time.Hour                // 09:00
+	DefaultInterval = time.Minute
+)
+
+// maxCandidates defines the number of departures read from the API reply, from
+// which the next MaxDepartures departures are selected.
+const maxCandidates = 8
+
+var (
+	ErrInvalidTime = errors.New("invalid transit departure time")
+)
+
+// Config defines the transit API endpoint and the paths of the fields in its
+// JSON reply. Paths may contain "*" to match each element of an array, e.g.,
+// "departures.*.route" (see package codec/json).
+//
+// The Feed is disabled if URL is empty.
+type Config struct {
+	URL      string        // the substring "{stop}" is replaced with Stop
+	Stop     string        // identifier of the transit stop
+	Route    string        // path of each departure's route name
+	Time     string        // path of each departure's time
+	Relative bool          // Time is minutes until departure, not a timestamp
+	Start    time.Duration // commute hours, as offsets from local midnight
+	End      time.Duration // (if Start equals End, departures are never shown)
+	Interval time.Duration // how often to poll the API during commute hours
+}
+
+// Transit polls a transit API for upcoming departures during commute hours.
+type Transit struct {
+	client   *http.Client
+	config   Config
+	url      string
+	lastSync time.Time
+}
+
+// New returns a new Transit using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Transit {
+
+	if config.Start == 0 && config.End == 0 {
+		config.Start, config.End = DefaultStart, DefaultEnd
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultInterval
+	}
+
+	return &Transit{
+		client: client,
+		config: config,
+		url:    strings.ReplaceAll(config.URL, "{stop}", config.Stop),
+	}
+}
+
+// Sync polls the transit API if the current time is within commute hours and
+// the polling interval has elapsed.
+func (t *Transit) Sync() error {
+
+	if "" == t.url {
+		return nil // feed disabled
+	}
+
+	data := model.Peek()
+	if data.Time.IsZero() ||
+		!feed.InWindow(data.Time, t.config.Start, t.config.End) {
+		// outside of commute hours, hide the transit page and stop polling.
+		if data.Transit.Active {
+			model.Set(func(m *model.Model) {
+				m.Transit = model.Transit{}
+			})
+		}
+		t.lastSync = time.Time{}
+		return nil
+	}
+
+	if !feed.Due(data.Time, t.lastSync, t.config.Interval) {
+		return nil
+	}
+	t.lastSync = data.Time
+
+	dep, err := t.fetch(data.Time)
+	if nil != err {
+		return err
+	}
+	model.Set(func(m *model.Model) {
+		m.Transit = model.Transit{Active: true, Departure: dep}
+	})
+	return nil
+}
+
+func (t *Transit) fetch(now time.Time) (dep [model.MaxDepartures]model.Departure, err error) {
+
+	// collect the first few departures listed in the reply, in any order.
+	var cand [maxCandidates]model.Departure
+	err = feed.FetchJSON(t.client, t.url, func(path []byte, kind json.Kind, value []byte) error {
+		if ok, i := json.Match(path, t.config.Route); ok && i >= 0 && i < len(cand) {
+			cand[i].Route = string(value)
+		} else if ok, i := json.Match(path, t.config.Time); ok && i >= 0 && i < len(cand) {
+			if cand[i].Time, ok = t.parseTime(kind, value, now); !ok {
+				return ErrInvalidTime
+			}
+		}
+		return nil
+	})
+	if nil != err {
+		return dep, err
+	}
+
+	// select the earliest departures that have not already left.
+	for i := range dep {
+		next := -1
+		for k := range cand {
+			if cand[k].Time.IsZero() || cand[k].Time.Before(now) {
+				continue
+			}
+			if next < 0 || cand[k].Time.Before(cand[next].Time) {
+				next = k
+			}
+		}
+		if next < 0 {
+			break
+		}
+		dep[i], cand[next] = cand[next], model.Departure{}
+	}
+	return dep, nil
+}
+
+// parseTime interprets a departure time field as minutes until departure (if
+// configured as Relative), Unix time in seconds or milliseconds, or an RFC 3339
+// formatted timestamp.
+func (t *Transit) parseTime(kind json.Kind, value []byte, now time.Time) (time.Time, bool) {
+	if json.Number != kind && json.String != kind {
+		return time.Time{}, false
+	}
+	num, err := strconv.ParseFloat(string(value), 64)
+	if nil != err {
+		if json.String != kind || t.config.Relative {
+			return time.Time{}, false
+		}
+		at, err := time.Parse(time.RFC3339, string(value))
+		return at, nil == err
+	}
+	if t.config.Relative {
+		return now.Add(time.Duration(num * float64(time.Minute))), true
+	}
+	sec := int64(num)
+	if sec > 1e11 {
+		sec /= 1000 // milliseconds
+	}
+	return time.Unix(sec, 0), true
+}
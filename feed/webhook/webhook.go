@@ -0,0 +1,307 @@
+// Package webhook requests configured URLs when certain events occur, e.g., to
+// push a message with ntfy, trigger an IFTTT applet, or post to Slack.
+//
+// The URL and body of each Hook are templates, whose placeholders are replaced
+// by the details of the event:
+//
+//	{event}  name of the Event, e.g., "alert"
+//	{text}   description, e.g., the text of the alert
+//	{value}  temperature, of EventTemperature, e.g., "31.5"
+//	{time}   time of the event, e.g., "2021-07-04T15:04:05-05:00"
+//
+// Values replacing placeholders are percent-encoded in URLs, and escaped as
+// JSON strings in bodies whose ContentType is JSON. Bodies of any other type,
+// e.g., text/plain for ntfy, receive the values verbatim.
+//
+// Requests are made once, without retrying, so an event is lost if the server
+// cannot be reached at the time.
+package webhook
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/http"
+)
+
+// Default constants for Webhook configuration.
+const (
+	DefaultContentType = "application/json"
+	DefaultHysteresis  = 1 // °C
+)
+
+// Event identifies the condition that fires a Hook.
+type Event uint8
+
+// Constants defining each possible Event.
+const (
+	EventAlert       Event = iota // a notice of high priority or above is posted
+	EventTemperature              // the outdoor temperature crosses Threshold
+	EventReconnect                // a network is joined again after it was lost
+)
+
+// String returns the lowercase name of the Event.
+func (e Event) String() string {
+	switch e {
+	case EventTemperature:
+		return "temperature"
+	case EventReconnect:
+		return "reconnect"
+	}
+	return "alert"
+}
+
+// ParseEvent returns the Event with the given name, as returned by String.
+func ParseEvent(name string) (Event, bool) {
+	for e := EventAlert; e <= EventReconnect; e++ {
+		if name == e.String() {
+			return e, true
+		}
+	}
+	return EventAlert, false
+}
+
+// Hook defines the request made when its Event occurs. If Body is empty, the
+// request is a GET, and a POST of Body otherwise.
+type Hook struct {
+	Event       Event
+	URL         string
+	Body        string
+	ContentType string  // of Body, default DefaultContentType
+	Threshold   float32 // °C, of EventTemperature, crossed either way
+}
+
+// Config defines the hooks requested, and how far the temperature must return
+// past the Threshold before crossing it again fires its Hook again.
+type Config struct {
+	Hooks      []Hook
+	Hysteresis float32 // °C
+}
+
+// Webhook is a Feed that watches the Model for the events of each Hook.
+type Webhook struct {
+	client   *http.Client
+	config   Config
+	notices  map[string]time.Time // Posted of each alert already fired
+	above    []int8               // side of each Threshold: 1 above, -1 below, 0 unknown
+	connects uint                 // Diagnostics.Connects when last seen
+}
+
+// New returns a new Webhook using the given HTTP client and configuration.
+func New(client *http.Client, config Config) *Webhook {
+	w := &Webhook{
+		client:  client,
+		notices: map[string]time.Time{},
+	}
+	w.Set(config)
+	return w
+}
+
+// Set changes the hooks requested, e.g., when the Settings change. The side of
+// each Threshold is unknown again until the temperature is next observed.
+func (w *Webhook) Set(config Config) {
+
+	if config.Hysteresis == 0 {
+		config.Hysteresis = DefaultHysteresis
+	}
+	hooks := make([]Hook, len(config.Hooks)) // never modify those of the caller
+	copy(hooks, config.Hooks)
+	for i := range hooks {
+		if hooks[i].ContentType == "" {
+			hooks[i].ContentType = DefaultContentType
+		}
+	}
+	config.Hooks = hooks
+
+	w.config = config
+	w.above = make([]int8, len(hooks))
+}
+
+// Sync fires the hooks of any events that occurred since the previous call.
+func (w *Webhook) Sync() error {
+
+	if 0 == len(w.config.Hooks) {
+		return nil
+	}
+	data := model.Peek()
+
+	// a network was joined again
+	if n := data.Diagnostics.Connects; n != w.connects {
+		if 0 != w.connects {
+			w.fire(EventReconnect, "Reconnected to "+data.AP.SSID, "", data.Time)
+		}
+		w.connects = n
+	}
+
+	// alerts posted, each fired once
+	for _, n := range data.Notice {
+		if "" == n.Text || n.Priority < model.PriorityHigh {
+			continue
+		}
+		if posted, ok := w.notices[n.ID]; ok && posted.Equal(n.Posted) {
+			continue
+		}
+		w.notices[n.ID] = n.Posted
+		w.fire(EventAlert, n.Text, "", data.Time)
+	}
+	for id := range w.notices {
+		if !queued(data.Notice, id) {
+			delete(w.notices, id)
+		}
+	}
+
+	// outdoor temperature crossing each threshold
+	if !data.Weather.Valid() {
+		return nil
+	}
+	temp := data.Weather.Current.Temperature
+	for i, h := range w.config.Hooks {
+		if EventTemperature != h.Event {
+			continue
+		}
+		var side int8
+		switch {
+		case temp >= h.Threshold+w.config.Hysteresis/2:
+			side = 1
+		case temp <= h.Threshold-w.config.Hysteresis/2:
+			side = -1
+		default:
+			continue // too close to tell
+		}
+		if side != w.above[i] {
+			if 0 != w.above[i] {
+				text := "Temperature below "
+				if side > 0 {
+					text = "Temperature above "
+				}
+				text += strconv.FormatFloat(float64(h.Threshold), 'f', -1, 32) + " °C"
+				w.request(h, text, strconv.FormatFloat(float64(temp), 'f', 1, 32), data.Time)
+			}
+			w.above[i] = side
+		}
+	}
+	return nil
+}
+
+// fire requests every Hook of the given Event.
+func (w *Webhook) fire(event Event, text, value string, at time.Time) {
+	for _, h := range w.config.Hooks {
+		if event == h.Event {
+			w.request(h, text, value, at)
+		}
+	}
+}
+
+// request makes the request of the given Hook, with its placeholders replaced
+// by the given details. Failures are logged, but otherwise ignored.
+func (w *Webhook) request(h Hook, text, value string, at time.Time) {
+
+	fields := [...]string{
+		"{event}", h.Event.String(),
+		"{text}", text,
+		"{value}", value,
+		"{time}", at.Format(time.RFC3339),
+	}
+	url := expand(h.URL, fields[:], queryEscape)
+
+	var (
+		res *http.Response
+		err error
+	)
+	if "" == h.Body {
+		res, err = w.client.Get(url)
+	} else {
+		body := expand(h.Body, fields[:], bodyEscape(h.ContentType))
+		res, err = w.client.Post(url, h.ContentType, []byte(body))
+	}
+	if nil != err {
+		journal.Println("webhook: " + err.Error())
+		return
+	}
+	if !res.OK() {
+		journal.Println("webhook: status " + strconv.Itoa(res.StatusCode))
+	}
+	res.Close()
+}
+
+// queued returns true if a Notice with the given ID is queued.
+func queued(notice model.Notices, id string) bool {
+	for _, n := range notice {
+		if "" != n.Text && id == n.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// expand returns the given template with each placeholder of fields, given as
+// pairs of placeholder and value, replaced by its value, escaped.
+func expand(template string, fields []string, escape func(string) string) string {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if strings.Contains(template, fields[i]) {
+			template = strings.ReplaceAll(template, fields[i], escape(fields[i+1]))
+		}
+	}
+	return template
+}
+
+// bodyEscape returns the function escaping values for a body of the given
+// content type: jsonEscape if it is JSON, e.g., "application/json" or
+// "application/ld+json; charset=utf-8", or else none.
+func bodyEscape(contentType string) func(string) string {
+	media := contentType
+	if i := strings.IndexByte(media, ';'); i >= 0 {
+		media = media[:i]
+	}
+	media = strings.ToLower(strings.TrimSpace(media))
+	if "application/json" == media || strings.HasSuffix(media, "+json") {
+		return jsonEscape
+	}
+	return verbatim
+}
+
+// verbatim returns s unchanged.
+func verbatim(s string) string {
+	return s
+}
+
+// queryEscape percent-encodes every byte of s other than unreserved characters.
+func queryEscape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			'-' == c || '_' == c || '.' == c || '~' == c {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xF])
+		}
+	}
+	return b.String()
+}
+
+// jsonEscape escapes s for use within a JSON string.
+func jsonEscape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case '"' == c || '\\' == c:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20:
+			b.WriteString(`\u00`)
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xF])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,19 @@
+package webhook
+
+import "testing"
+
+func TestExpandBody(t *testing.T) {
+	fields := []string{"{text}", `Tornado "warning"` + "\n" + `\ now`}
+	for _, x := range []struct {
+		contentType, body, want string
+	}{
+		{"application/json", `{"m":"{text}"}`, `{"m":"Tornado \"warning\"\u000A\\ now"}`},
+		{"application/json; charset=utf-8", `"{text}"`, `"Tornado \"warning\"\u000A\\ now"`},
+		{"application/ld+json", `"{text}"`, `"Tornado \"warning\"\u000A\\ now"`},
+		{"text/plain", "{text}", "Tornado \"warning\"\n\\ now"},
+	} {
+		if got := expand(x.body, fields, bodyEscape(x.contentType)); x.want != got {
+			t.Errorf("%s: expand = %q, want %q", x.contentType, got, x.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package wind raises an alarm when wind gusts exceed a configured threshold,
+// e.g., to retract an awning or cancel a drone flight.
+//
+// Gusts are taken from the current conditions and the next few hours of the
+// forecast, and from a local weather station if it publishes its measurements
+// to an MQTT topic. Station measurements are plain-text numbers in meters per
+// second.
+//
+// When the alarm is raised, a flashing Notice is posted (see package notify)
+// and an event is published to an MQTT topic, e.g.:
+//
+//	{"alarm": true, "gust": 15.2, "source": "station"}
+//
+// The alarm is cleared, with another event, once gusts have fallen below a
+// fraction of the threshold, so that it does not repeatedly toggle while gusts
+// hover around the threshold.
+package wind
+
+import (
+	"bytes"
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Default constants for Wind configuration.
+const (
+	DefaultThreshold = 12 // meters per second (27 mph)
+	DefaultHours     = 3
+	DefaultTopic     = "~/wind"
+	DefaultStation   = "~/station/gust"
+	DefaultDuration  = 30 * time.Second
+	DefaultFlash     = model.FlashStrobe
+)
+
+// Constants defining behavior not exposed by Config.
+const (
+	hysteresis = 0.8              // fraction of threshold that clears the alarm
+	stationAge = 10 * time.Minute // how long a station measurement is valid
+)
+
+// noticeID identifies the notices posted by Wind.
+const noticeID = "wind"
+
+// DefaultColor defines the color of the wind warning.
+var DefaultColor = color.RGBA{R: 0xFF, G: 0xC0, B: 0x00, A: 0xFF}
+
+// Config defines the gust threshold, the sources of gust measurements, and the
+// appearance of the warning. The alarm is not published to MQTT if Topic is
+// "-", and station measurements are not received if Station is "-".
+type Config struct {
+	Threshold float32 // meters per second
+	Hours     int     // forecast horizon, in hours from now
+	Topic     string  // MQTT topic of alarm events
+	Station   string  // MQTT topic of station gust measurements
+	Duration  time.Duration
+	Flash     model.Flash
+	Color     color.RGBA
+}
+
+// Wind is a Feed that monitors wind gusts.
+type Wind struct {
+	broker  *mqtt.Client
+	config  Config
+	alarm   bool
+	station float32   // most recent station measurement
+	stamp   time.Time // time of station measurement
+	pending bool      // alarm event has not been published
+}
+
+// New returns a new Wind using the given MQTT client and configuration.
+func New(broker *mqtt.Client, config Config) (*Wind, error) {
+
+	if config.Threshold == 0 {
+		config.Threshold = DefaultThreshold
+	}
+	if config.Hours <= 0 || config.Hours > model.MaxHours {
+		config.Hours = DefaultHours
+	}
+	if config.Topic == "" {
+		config.Topic = DefaultTopic
+	}
+	if config.Station == "" {
+		config.Station = DefaultStation
+	}
+	if config.Duration == 0 {
+		config.Duration = DefaultDuration
+	}
+	if config.Flash == model.FlashNone {
+		config.Flash = DefaultFlash
+	}
+	if config.Color == (color.RGBA{}) {
+		config.Color = DefaultColor
+	}
+
+	w := &Wind{broker: broker, config: config}
+	if nil != broker && "-" != config.Station {
+		if err := broker.Subscribe(config.Station, w.receive); nil != err {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (w *Wind) receive(topic string, payload []byte) {
+	gust, err := strconv.ParseFloat(string(bytes.TrimSpace(payload)), 32)
+	if nil != err {
+		journal.Println("error: " + topic + ": " + err.Error())
+		return
+	}
+	w.station, w.stamp = float32(gust), model.Peek().Time
+}
+
+// Sync raises or clears the alarm according to the strongest gust from all
+// sources, and publishes any pending alarm event.
+func (w *Wind) Sync() error {
+
+	data := model.Peek()
+	gust, source := w.gust(data)
+
+	switch {
+	case !w.alarm && gust > w.config.Threshold:
+		w.alarm, w.pending = true, true
+		notify.Post(model.Notice{
+			ID:       noticeID,
+			Text:     "Wind gusts " + strconv.Itoa(int(gust+0.5)) + " m/s",
+			Icon:     "wind",
+			Color:    w.config.Color,
+			Flash:    w.config.Flash,
+			Priority: model.PriorityHigh,
+			Dismiss:  model.DismissShown,
+			Duration: w.config.Duration,
+		})
+	case w.alarm && gust < hysteresis*w.config.Threshold:
+		w.alarm, w.pending = false, true
+		notify.Dismiss(noticeID)
+	}
+
+	if !w.pending || nil == w.broker || "-" == w.config.Topic ||
+		!w.broker.Connected() {
+		return nil
+	}
+	payload := `{"alarm": ` + strconv.FormatBool(w.alarm) +
+		`, "gust": ` + strconv.FormatFloat(float64(gust), 'f', 1, 32) +
+		`, "source": "` + source + `"}`
+	if err := w.broker.Publish(w.config.Topic, []byte(payload), false); nil != err {
+		return err
+	}
+	w.pending = false
+	return nil
+}
+
+// gust returns the strongest gust reported by any source, and the name of that
+// source.
+func (w *Wind) gust(data model.Model) (gust float32, source string) {
+	if !w.stamp.IsZero() && data.Time.Sub(w.stamp) < stationAge {
+		gust, source = w.station, "station"
+	}
+	if !data.Weather.Valid() {
+		return
+	}
+	if data.Weather.Current.WindGust > gust {
+		gust, source = data.Weather.Current.WindGust, "current"
+	}
+	end := data.Time.Add(time.Duration(w.config.Hours) * time.Hour)
+	for _, h := range data.Weather.Hour {
+		if h.Time.IsZero() || h.Time.Add(time.Hour).Before(data.Time) {
+			continue
+		}
+		if h.Time.After(end) {
+			break
+		}
+		if h.WindGust > gust {
+			gust, source = h.WindGust, "forecast"
+		}
+	}
+	return
+}
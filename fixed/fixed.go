@@ -0,0 +1,121 @@
+// Package fixed implements Q16.16 fixed-point arithmetic.
+//
+// The Cortex-M4F has no hardware support for double-precision floating point,
+// so the float64 operations of package math are emulated in software at great
+// cost. The functions of this package use only integer operations, and are
+// accurate to within a few units in the last place (about 1e-4) over the ranges
+// of values encountered in weather and solar calculations.
+//
+// Angles are given in degrees.
+package fixed
+
+// Q16 is a signed fixed-point number with 16 integer bits and 16 fraction bits,
+// representing values in the range [-32768, 32768) with a resolution of 1/65536.
+type Q16 int32
+
+// Constants defining common values.
+const (
+	fracBits     = 16
+	One      Q16 = 1 << fracBits
+	Half     Q16 = One / 2
+	Max      Q16 = 1<<31 - 1
+	Min      Q16 = -1 << 31
+)
+
+// From returns the fixed-point value nearest f. Values out of range saturate.
+// Single-precision conversions are performed by the FPU of the Cortex-M4F.
+func From(f float32) Q16 {
+	f *= float32(One)
+	switch {
+	case f >= float32(Max):
+		return Max
+	case f <= float32(Min):
+		return Min
+	case f < 0:
+		return Q16(f - 0.5)
+	}
+	return Q16(f + 0.5)
+}
+
+// Int returns the fixed-point value of integer i.
+func Int(i int) Q16 {
+	return Q16(i << fracBits)
+}
+
+// Float returns the floating-point value of q.
+func (q Q16) Float() float32 {
+	return float32(q) / float32(One)
+}
+
+// Int returns the integer part of q, rounded toward negative infinity.
+func (q Q16) Int() int {
+	return int(q >> fracBits)
+}
+
+// Round returns q rounded to the nearest integer.
+func (q Q16) Round() int {
+	return int((q + Half) >> fracBits)
+}
+
+// Abs returns the absolute value of q.
+func (q Q16) Abs() Q16 {
+	if q < 0 {
+		return -q
+	}
+	return q
+}
+
+// Mul returns the product q×r, rounded to nearest.
+func (q Q16) Mul(r Q16) Q16 {
+	return Q16((int64(q)*int64(r) + int64(Half)) >> fracBits)
+}
+
+// Div returns the quotient q÷r, which saturates if r is zero.
+func (q Q16) Div(r Q16) Q16 {
+	if 0 == r {
+		if q < 0 {
+			return Min
+		}
+		return Max
+	}
+	n := int64(q) << fracBits
+	if (n < 0) == (r < 0) {
+		n += int64(r.Abs()) / 2
+	} else {
+		n -= int64(r.Abs()) / 2
+	}
+	return saturate(n / int64(r))
+}
+
+// Sqrt returns the square root of q, or 0 if q is negative.
+func Sqrt(q Q16) Q16 {
+	if q <= 0 {
+		return 0
+	}
+	// the square root of a Q32.32 number is a Q16.16 number
+	n := uint64(q) << fracBits
+	var r, bit uint64 = 0, 1 << 62
+	for bit > n {
+		bit >>= 2
+	}
+	for ; 0 != bit; bit >>= 2 {
+		if n >= r+bit {
+			n -= r + bit
+			r = r>>1 + bit
+		} else {
+			r >>= 1
+		}
+	}
+	return Q16(r)
+}
+
+// saturate returns the Q16 nearest n.
+func saturate(n int64) Q16 {
+	switch {
+	case n > int64(Max):
+		return Max
+	case n < int64(Min):
+		return Min
+	}
+	return Q16(n)
+}
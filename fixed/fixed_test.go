@@ -0,0 +1,68 @@
+package fixed
+
+import (
+	"math"
+	"testing"
+)
+
+// Error limits of the functions compared with package math, given as absolute
+// errors of the result.
+const (
+	sinLimit  = 5e-5        // sine
+	acosLimit = 0.0057      // arccosine, degrees (1e-4 radians)
+	logLimit  = 1e-4        // natural logarithm
+	sqrtLimit = 1.0 / 65536 // square root, one unit in the last place
+)
+
+func TestSin(t *testing.T) {
+	for a := -720.0; a <= 720; a += 1.0 / 64 {
+		q := From(float32(a))
+		want := math.Sin(float64(q.Float()) * math.Pi / 180)
+		if got := float64(Sin(q).Float()); math.Abs(got-want) > sinLimit {
+			t.Fatalf("Sin(%v) = %v, want %v", q.Float(), got, want)
+		}
+	}
+}
+
+func TestAcos(t *testing.T) {
+	for c := -1.0; c <= 1; c += 1.0 / 4096 {
+		q := From(float32(c))
+		want := math.Acos(float64(q.Float())) * 180 / math.Pi
+		if got := float64(Acos(q).Float()); math.Abs(got-want) > acosLimit {
+			t.Fatalf("Acos(%v) = %v, want %v", q.Float(), got, want)
+		}
+	}
+	// out of range values are clamped
+	if got := Acos(Int(2)); 0 != got {
+		t.Errorf("Acos(2) = %v, want 0", got.Float())
+	}
+	if got := Acos(Int(-2)).Float(); math.Abs(float64(got)-180) > acosLimit {
+		t.Errorf("Acos(-2) = %v, want 180", got)
+	}
+}
+
+func TestLog(t *testing.T) {
+	for x := 1.0 / 1024; x < 32000; x *= 1.01 {
+		q := From(float32(x))
+		want := math.Log(float64(q.Float()))
+		if got := float64(Log(q).Float()); math.Abs(got-want) > logLimit {
+			t.Fatalf("Log(%v) = %v, want %v", q.Float(), got, want)
+		}
+	}
+	if got := Log(0); Min != got {
+		t.Errorf("Log(0) = %v, want Min", got.Float())
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	for x := 0.0; x < 32000; x = x*1.01 + sqrtLimit {
+		q := From(float32(x))
+		want := math.Sqrt(float64(q.Float()))
+		if got := float64(Sqrt(q).Float()); math.Abs(got-want) > sqrtLimit {
+			t.Fatalf("Sqrt(%v) = %v, want %v", q.Float(), got, want)
+		}
+	}
+	if got := Sqrt(-One); 0 != got {
+		t.Errorf("Sqrt(-1) = %v, want 0", got.Float())
+	}
+}
@@ -0,0 +1,154 @@
+package fixed
+
+// Constants used by the elementary functions.
+const (
+	ln2    Q16 = 45426 // natural logarithm of 2
+	deg90  Q16 = 90 << fracBits
+	deg180 Q16 = 180 << fracBits
+	deg360 Q16 = 360 << fracBits
+
+	// conversion factors between degrees and radians, with 30 and 16 fraction
+	// bits, respectively, so that the conversions do not magnify rounding error.
+	radPerDeg = 18740330 // π/180 × 2^30
+	degPerRad = 3754936  // 180/π × 2^16
+)
+
+// Coefficients of the arctangent polynomial of Abramowitz and Stegun (4.4.49).
+const (
+	atan1 Q16 = 65527  // 0.9998660
+	atan3 Q16 = -21647 // -0.3302995
+	atan5 Q16 = 11806  // 0.1801410
+	atan7 Q16 = -5579  // -0.0851330
+	atan9 Q16 = 1365   // 0.0208351
+)
+
+// toRad converts angle a from degrees to radians.
+func toRad(a Q16) Q16 {
+	return saturate((int64(a)*radPerDeg + 1<<29) >> 30)
+}
+
+// toDeg converts angle r from radians to degrees.
+func toDeg(r Q16) Q16 {
+	return saturate((int64(r)*degPerRad + 1<<15) >> 16)
+}
+
+// Norm returns angle a reduced to the range [0, 360) degrees.
+func Norm(a Q16) Q16 {
+	if a %= deg360; a < 0 {
+		a += deg360
+	}
+	return a
+}
+
+// Sin returns the sine of angle a, in degrees.
+func Sin(a Q16) Q16 {
+	// reduce to the first quadrant, noting the sign
+	a = Norm(a)
+	neg := a >= deg180
+	if neg {
+		a -= deg180
+	}
+	if a > deg90 {
+		a = deg180 - a
+	}
+	// Taylor series through the term in x⁹, accurate to 4e-6 in [0, π/2]
+	x := toRad(a)
+	x2 := x.Mul(x)
+	s := One - x2/72
+	s = One - x2.Mul(s)/42
+	s = One - x2.Mul(s)/20
+	s = One - x2.Mul(s)/6
+	if s = x.Mul(s); neg {
+		return -s
+	}
+	return s
+}
+
+// Cos returns the cosine of angle a, in degrees.
+func Cos(a Q16) Q16 {
+	return Sin(a + deg90)
+}
+
+// Atan2 returns the angle, in degrees in the range [-180, 180], of the vector
+// (x, y) from the positive x axis.
+func Atan2(y, x Q16) Q16 {
+	if 0 == x && 0 == y {
+		return 0
+	}
+	// reduce to the first octant, where the ratio is in [0, 1]
+	ax, ay := x.Abs(), y.Abs()
+	var a Q16
+	if ay <= ax {
+		a = atan(ay.Div(ax))
+	} else {
+		a = deg90 - atan(ax.Div(ay))
+	}
+	if x < 0 {
+		a = deg180 - a
+	}
+	if y < 0 {
+		a = -a
+	}
+	return a
+}
+
+// atan returns the arctangent of z in [0, 1], in degrees, accurate to 1e-5
+// radians.
+func atan(z Q16) Q16 {
+	z2 := z.Mul(z)
+	p := atan7 + z2.Mul(atan9)
+	p = atan5 + z2.Mul(p)
+	p = atan3 + z2.Mul(p)
+	p = atan1 + z2.Mul(p)
+	return toDeg(z.Mul(p))
+}
+
+// Asin returns the arcsine of s, in degrees in the range [-90, 90].
+// The value of s is clamped to [-1, 1].
+func Asin(s Q16) Q16 {
+	s = clampUnit(s)
+	return Atan2(s, Sqrt(One-s.Mul(s)))
+}
+
+// Acos returns the arccosine of c, in degrees in the range [0, 180].
+// The value of c is clamped to [-1, 1].
+func Acos(c Q16) Q16 {
+	c = clampUnit(c)
+	return Atan2(Sqrt(One-c.Mul(c)), c)
+}
+
+// Log returns the natural logarithm of q, or Min if q is not positive.
+func Log(q Q16) Q16 {
+	if q <= 0 {
+		return Min
+	}
+	// q = m × 2^k, with m in [1, 2)
+	k := Q16(0)
+	for q >= 2*One {
+		q >>= 1
+		k++
+	}
+	for q < One {
+		q <<= 1
+		k--
+	}
+	// ln(m) = 2 artanh(t), with t = (m-1)/(m+1) in [0, 1/3]
+	t := (q - One).Div(q + One)
+	t2 := t.Mul(t)
+	s := One/7 + t2/9
+	s = One/5 + t2.Mul(s)
+	s = One/3 + t2.Mul(s)
+	s = One + t2.Mul(s)
+	return 2*t.Mul(s) + k*ln2
+}
+
+// clampUnit returns q clamped to [-1, 1].
+func clampUnit(q Q16) Q16 {
+	switch {
+	case q > One:
+		return One
+	case q < -One:
+		return -One
+	}
+	return q
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"machine"
+	"time"
+)
+
+// fault is the class of an error that prevents the program from starting, which
+// is the number of times the onboard LED blinks between pauses while halted.
+type fault int
+
+// Constants defining each fault, in order of initialization.
+const (
+	faultDisplay fault = 1 + iota // display driver
+	faultNetwork                  // WiFi coprocessor
+	faultFeature                  // services and feeds of optional features
+)
+
+// Timing of the blinks of the onboard LED while halted.
+const (
+	blinkOn    = 200 * time.Millisecond
+	blinkOff   = 300 * time.Millisecond
+	blinkPause = 1500 * time.Millisecond
+)
+
+// halt prints the given error to the serial port and blinks the onboard LED
+// forever, the number of times of the given fault between pauses, so that the
+// fault may be diagnosed without a serial connection even if the display itself
+// failed to initialize.
+func halt(f fault, err error) {
+	led := machine.LED
+	led.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	for {
+		println("error: " + err.Error())
+		for i := fault(0); i < f; i++ {
+			led.High()
+			time.Sleep(blinkOn)
+			led.Low()
+			time.Sleep(blinkOff)
+		}
+		time.Sleep(blinkPause)
+	}
+}
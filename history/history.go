@@ -0,0 +1,115 @@
+// Package history implements ring buffers retaining a summary of each of the
+// past several days, such as the maximum AQI of each day, so that recent trends
+// can be shown at a glance.
+//
+// It also implements the moving averages used to smooth values that jitter,
+// such as sensor measurements, along with buffers retaining the most recent raw
+// values for logging (see smooth.go).
+//
+// Buffers are held in RAM only, so history begins anew at each reset.
+package history
+
+import "time"
+
+// Days defines the number of days retained by each buffer, including today.
+const Days = 7
+
+// Daily is a ring buffer of one value per day. Days on which no value was
+// recorded are invalid.
+//
+// The zero value is an empty buffer ready to use.
+type Daily struct {
+	value [Days]float32
+	valid [Days]bool
+	head  int       // index of the most recent day
+	date  time.Time // local midnight of the most recent day
+}
+
+// Max records v for the day of time t, retaining the greatest value recorded
+// that day.
+func (d *Daily) Max(t time.Time, v float32) {
+	if d.advance(t) {
+		if !d.valid[d.head] || v > d.value[d.head] {
+			d.value[d.head], d.valid[d.head] = v, true
+		}
+	}
+}
+
+// Min records v for the day of time t, retaining the least value recorded that
+// day.
+func (d *Daily) Min(t time.Time, v float32) {
+	if d.advance(t) {
+		if !d.valid[d.head] || v < d.value[d.head] {
+			d.value[d.head], d.valid[d.head] = v, true
+		}
+	}
+}
+
+// Sum records v for the day of time t, accumulating all values recorded that
+// day.
+func (d *Daily) Sum(t time.Time, v float32) {
+	if d.advance(t) {
+		d.value[d.head] += v
+		d.valid[d.head] = true
+	}
+}
+
+// Get returns the value of the day ago days before the most recent day, and
+// false if no value was recorded that day.
+func (d *Daily) Get(ago int) (float32, bool) {
+	if ago < 0 || ago >= Days {
+		return 0, false
+	}
+	i := (d.head - ago + Days) % Days
+	return d.value[i], d.valid[i]
+}
+
+// Mean returns the mean of the values of the days recorded, and the number of
+// days included, which is zero if no value was recorded on any day.
+func (d *Daily) Mean() (float32, int) {
+	var sum float32
+	n := 0
+	for i := range d.value {
+		if d.valid[i] {
+			sum += d.value[i]
+			n++
+		}
+	}
+	if 0 == n {
+		return 0, 0
+	}
+	return sum / float32(n), n
+}
+
+// Date returns local midnight of the most recent day recorded.
+func (d *Daily) Date() time.Time {
+	return d.date
+}
+
+// advance rotates the buffer so that the most recent day is the day of time t,
+// invalidating the days skipped. Returns false if t precedes the most recent
+// day, which cannot be recorded.
+func (d *Daily) advance(t time.Time) bool {
+	y, m, dd := t.Date()
+	day := time.Date(y, m, dd, 0, 0, 0, 0, t.Location())
+	if d.date.IsZero() {
+		d.date = day
+		return true
+	}
+	if day.Before(d.date) {
+		return false
+	}
+	// round, since a day may be 23 or 25 hours long across DST transitions
+	n := int((day.Sub(d.date) + 12*time.Hour) / (24 * time.Hour))
+	if n > Days {
+		n = Days
+	}
+	for i := 0; i < n; i++ {
+		d.head = (d.head + 1) % Days
+		d.value[d.head], d.valid[d.head] = 0, false
+	}
+	if n > 0 {
+		d.date = day
+	}
+	return true
+}
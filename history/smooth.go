@@ -0,0 +1,112 @@
+package history
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// EMA is an exponential moving average of samples taken at irregular intervals,
+// used to smooth values that jitter between samples before they are shown.
+//
+// The weight of each sample depends on the time elapsed since the previous
+// sample, relative to the window (time constant) of the average, so that a
+// sensor publishing twice as often does not smooth twice as much.
+//
+// The zero value is an empty average ready to use.
+type EMA struct {
+	value float32
+	stamp time.Time // time of the most recent sample
+}
+
+// Add includes sample v taken at time t in the average, returning the average.
+// The first sample, or every sample if window is not positive, is returned
+// unchanged.
+func (e *EMA) Add(t time.Time, v float32, window time.Duration) float32 {
+	if window <= 0 || e.stamp.IsZero() {
+		e.value, e.stamp = v, t
+		return v
+	}
+	if dt := t.Sub(e.stamp); dt > 0 {
+		alpha := 1 - math.Exp(-float64(dt)/float64(window))
+		e.value += float32(alpha) * (v - e.value)
+		e.stamp = t
+	}
+	return e.value
+}
+
+// MaxSamples defines the number of most recent raw samples retained by each
+// Raw buffer.
+const MaxSamples = 16
+
+// Sample is a raw value and the time it was taken.
+type Sample struct {
+	Time  time.Time
+	Value float32
+}
+
+// Raw is a ring buffer of the most recent raw samples of a quantity, retained
+// for logging when the quantity is smoothed before it is shown. Each Raw is
+// registered by name when created, so that all may be listed (see Each).
+type Raw struct {
+	name   string
+	sample [MaxSamples]Sample
+	next   int // index of the oldest Sample, replaced by the next Add
+	count  int // number of samples retained
+}
+
+var raw = struct {
+	lock sync.Mutex
+	list []*Raw
+}{}
+
+// NewRaw returns a new empty Raw with the given name, e.g.,
+// "indoor.temperature".
+func NewRaw(name string) *Raw {
+	r := &Raw{name: name}
+	raw.lock.Lock()
+	raw.list = append(raw.list, r)
+	raw.lock.Unlock()
+	return r
+}
+
+// Each calls visit with each Raw created, in order of creation.
+func Each(visit func(*Raw)) {
+	raw.lock.Lock()
+	list := raw.list
+	raw.lock.Unlock()
+	for _, r := range list {
+		visit(r)
+	}
+}
+
+// Name returns the name of the quantity sampled.
+func (r *Raw) Name() string { return r.name }
+
+// Add records sample v taken at time t, replacing the oldest sample retained.
+func (r *Raw) Add(t time.Time, v float32) {
+	raw.lock.Lock()
+	r.sample[r.next] = Sample{Time: t, Value: v}
+	r.next = (r.next + 1) % MaxSamples
+	if r.count < MaxSamples {
+		r.count++
+	}
+	raw.lock.Unlock()
+}
+
+// Tail calls visit with each of the n most recent samples retained, oldest
+// first.
+func (r *Raw) Tail(n int, visit func(Sample)) {
+	raw.lock.Lock()
+	if n > r.count {
+		n = r.count
+	}
+	var tail [MaxSamples]Sample
+	for i := 0; i < n; i++ {
+		tail[i] = r.sample[(r.next-n+i+MaxSamples)%MaxSamples]
+	}
+	raw.lock.Unlock()
+	for _, s := range tail[:n] {
+		visit(s)
+	}
+}
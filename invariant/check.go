@@ -0,0 +1,90 @@
+//go:build debug
+// +build debug
+
+package invariant
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+)
+
+// violation identifies each invariant, by bit.
+type violation uint8
+
+const (
+	lagDrawn violation = 1 << iota
+	lagModel
+	staleWeather
+)
+
+// state holds the time last drawn and the violations in progress.
+var state struct {
+	drawn  time.Time // time of the Model last drawn
+	synced time.Time // when the Model was first seen synchronized, zero if not
+	active violation
+}
+
+// Drawn records the time of the Model data drawn by the display.
+func Drawn(data model.Model) {
+	state.drawn = data.Time
+}
+
+// Check verifies each invariant as of the system time now, which should be
+// called once each iteration of the run loop, before the Model is updated.
+func Check(now time.Time) {
+
+	data := model.Peek()
+	if model.StatusSynchronized != data.Status {
+		state.synced, state.active = time.Time{}, 0
+		return
+	}
+	if state.synced.IsZero() {
+		state.synced = now
+	}
+
+	check(lagDrawn, data.Time.Sub(state.drawn) > MaxLag,
+		"display lags model by "+seconds(data.Time.Sub(state.drawn)))
+	check(lagModel, now.Sub(data.Time) > MaxLag,
+		"model lags system time by "+seconds(now.Sub(data.Time)))
+
+	updated := data.Weather.Updated
+	if updated.IsZero() {
+		updated = state.synced // never refreshed since synchronized
+	}
+	check(staleWeather, now.Sub(updated) > MaxAge,
+		"weather not refreshed for "+seconds(now.Sub(updated)))
+}
+
+// check logs the given invariant v when it is first violated, with detail, and
+// again once it holds.
+func check(v violation, violated bool, detail string) {
+	switch {
+	case violated && 0 == state.active&v:
+		state.active |= v
+		journal.Println("invariant: " + detail)
+	case !violated && 0 != state.active&v:
+		state.active &^= v
+		journal.Println("invariant: restored: " + name(v))
+	}
+}
+
+// name returns a brief description of the invariant v.
+func name(v violation) string {
+	switch v {
+	case lagDrawn:
+		return "display time"
+	case lagModel:
+		return "model time"
+	case staleWeather:
+		return "weather"
+	}
+	return "unknown"
+}
+
+// seconds formats d in whole seconds, e.g., "12s".
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+}
@@ -0,0 +1,27 @@
+// Package invariant checks, in builds with the tag "debug", that the content
+// shown stays fresh while synchronized, logging any violation to the journal.
+// This catches bugs of the run loop early, e.g., a feed blocking the loop, or
+// the Model changed without marking it changed, before they are noticed as a
+// frozen clock or stale forecast.
+//
+// Three invariants are checked:
+//
+//	the time drawn never lags the time of the Model by more than MaxLag;
+//	the time of the Model never lags the system time by more than MaxLag;
+//	the weather is refreshed at least every MaxAge.
+//
+// Each violation is logged once when it begins and once when it ends. In other
+// builds, every function of the package does nothing.
+package invariant
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/feed/forecast"
+)
+
+// Limits of the invariants checked.
+const (
+	MaxLag = 5 * time.Second
+	MaxAge = 2 * forecast.DefaultInterval // one failed poll is tolerated
+)
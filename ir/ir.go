@@ -0,0 +1,130 @@
+// Package ir implements a Feed receiving the codes of an infrared remote using
+// the NEC protocol, which is used by most inexpensive remotes, from a 38 kHz
+// demodulating receiver, e.g., a TSOP38238, whose output is low while a burst
+// is received.
+//
+// Each frame begins with a leading burst, followed by 32 bits: the address, its
+// complement, the command, and its complement, each least significant bit
+// first. Bits are distinguished by the time between the bursts that begin them,
+// so only falling edges of the pin are timed, by its interrupt handler. Frames
+// are decoded as the edges arrive, and reported by Sync.
+//
+// Repeat frames, sent while a button is held, are ignored, so that each press
+// is reported once.
+package ir
+
+import (
+	"machine"
+	"runtime/volatile"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time between the falling edges of each part of a frame.
+const (
+	periodLead = 13500 * time.Microsecond
+	periodZero = 1125 * time.Microsecond
+	periodOne  = 2250 * time.Microsecond
+)
+
+// Code identifies a button of a remote. Address is 8 bits, unless the remote
+// uses the extended NEC protocol, in which the complement of the address is
+// replaced by 8 more bits of address.
+type Code struct {
+	Address uint16
+	Command uint8
+}
+
+// String returns the Code in hexadecimal, e.g., "0x00/0x45", as logged for
+// buttons without a command.
+func (c Code) String() string {
+	return "0x" + hex(int64(c.Address), 2) + "/0x" + hex(int64(c.Command), 2)
+}
+
+// Receiver is a Feed that reports the code of each frame received on a pin.
+type Receiver struct {
+	pin   machine.Pin
+	press func(Code)
+
+	// decoder state, owned by the interrupt handler
+	last time.Time // time of the previous falling edge
+	bits uint32
+	n    int // bits received of the current frame, or -1 if none expected
+
+	frame volatile.Register32 // most recent complete frame
+	ready volatile.Register8  // frame has not yet been reported
+}
+
+// New returns a new Receiver reading the given pin, which calls press with the
+// Code of each button pressed.
+func New(pin machine.Pin, press func(Code)) (*Receiver, error) {
+	r := &Receiver{pin: pin, press: press, n: -1}
+	pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	if err := pin.SetInterrupt(machine.PinFalling, r.edge); nil != err {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Offline identifies the Receiver as a feed.Offline, which needs no network.
+func (r *Receiver) Offline() {}
+
+// Sync calls the press function with the Code of any frame received since the
+// previous call. Frames whose command does not match its complement are
+// discarded.
+func (r *Receiver) Sync() error {
+	if 0 == r.ready.Get() {
+		return nil
+	}
+	f := r.frame.Get()
+	r.ready.Set(0)
+	if uint8(f>>16) != ^uint8(f>>24) {
+		return nil // corrupt
+	}
+	c := Code{Address: uint16(f), Command: uint8(f >> 16)}
+	if uint8(f) == ^uint8(f>>8) {
+		c.Address &= 0xFF // standard, not extended, address
+	}
+	r.press(c)
+	return nil
+}
+
+// edge is the interrupt handler called at each falling edge of the pin.
+func (r *Receiver) edge(machine.Pin) {
+	now := time.Now()
+	d := now.Sub(r.last)
+	r.last = now
+	switch {
+	case near(d, periodLead):
+		r.bits, r.n = 0, 0
+	case r.n < 0:
+		// not within a frame
+	case near(d, periodZero):
+		r.n++
+	case near(d, periodOne):
+		r.bits |= 1 << r.n
+		r.n++
+	default:
+		r.n = -1 // noise, or a gap between frames
+	}
+	if 32 == r.n {
+		r.frame.Set(r.bits)
+		r.ready.Set(1)
+		r.n = -1
+	}
+}
+
+// near returns true if d is within 25% of period.
+func near(d, period time.Duration) bool {
+	return d > period*3/4 && d < period*5/4
+}
+
+// hex returns v in uppercase hexadecimal, padded with zeros to width digits.
+func hex(v int64, width int) string {
+	s := strconv.FormatInt(v, 16)
+	for len(s) < width {
+		s = "0" + s
+	}
+	return strings.ToUpper(s)
+}
@@ -0,0 +1,55 @@
+// Package journal records the most recent messages printed by the program, so
+// that they may be reviewed later, e.g., with the "log tail" command, without a
+// serial monitor attached when they were printed.
+package journal
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxEntries defines the number of most recent messages retained.
+const MaxEntries = 16
+
+// Entry is a message and the time it was printed.
+type Entry struct {
+	Time time.Time
+	Text string
+}
+
+var state = struct {
+	lock  sync.Mutex
+	entry [MaxEntries]Entry
+	next  int // index of the oldest Entry, replaced by the next Println
+	count int // number of entries retained
+}{}
+
+// Println prints the given message to the serial console and retains it,
+// replacing the oldest message retained.
+func Println(text string) {
+	println(text)
+	state.lock.Lock()
+	state.entry[state.next] = Entry{Time: time.Now(), Text: text}
+	state.next = (state.next + 1) % MaxEntries
+	if state.count < MaxEntries {
+		state.count++
+	}
+	state.lock.Unlock()
+}
+
+// Tail calls visit with each of the n most recent messages retained, oldest
+// first.
+func Tail(n int, visit func(Entry)) {
+	state.lock.Lock()
+	if n > state.count {
+		n = state.count
+	}
+	var tail [MaxEntries]Entry
+	for i := 0; i < n; i++ {
+		tail[i] = state.entry[(state.next-n+i+MaxEntries)%MaxEntries]
+	}
+	state.lock.Unlock()
+	for _, e := range tail[:n] {
+		visit(e)
+	}
+}
@@ -0,0 +1,165 @@
+// Package lang implements the translation of the short phrases describing the
+// weather, e.g., in the daily forecast summary, so that conditions are readable
+// in households not speaking English.
+//
+// Only words describing provider condition codes and the few words joining
+// them are translated. The phrases are written with their accents, all within
+// Latin-1, e.g., "dégagé", which the font of the display contains, though it
+// draws accented letters as the letters they are based on (see package
+// display). Phrases sent elsewhere, e.g., to a phone, keep their accents.
+//
+// The Language is chosen by the Settings (see package config).
+package lang
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Language selects the language of the phrases returned by this package.
+type Language uint8
+
+// Constants defining each supported Language.
+const (
+	English Language = iota
+	Spanish
+	German
+	French
+	languageCount
+)
+
+// table contains the phrases of a single Language.
+type table struct {
+	code      string                       // ISO 639-1
+	condition [model.ConditionCount]string // empty if given by model
+	high      string                       // label preceding the day's high temperature
+	after     string                       // preposition preceding the hour precipitation begins
+	hour      string                       // suffix of the hour in 24-hour format, or "" for 12-hour
+}
+
+// tables contains the table of each Language.
+var tables = [languageCount]table{
+	English: {
+		code:  "en", // conditions described by model.Condition.String
+		high:  "High",
+		after: "after",
+	},
+	Spanish: {
+		code: "es",
+		condition: [model.ConditionCount]string{
+			model.ConditionClear:        "despejado",
+			model.ConditionPartlyCloudy: "poco nuboso",
+			model.ConditionCloudy:       "nublado",
+			model.ConditionFog:          "niebla",
+			model.ConditionDrizzle:      "llovizna",
+			model.ConditionRain:         "lluvia",
+			model.ConditionIce:          "hielo",
+			model.ConditionSnow:         "nieve",
+			model.ConditionShowers:      "chubascos",
+			model.ConditionStorms:       "tormentas",
+		},
+		high:  "Máx",
+		after: "desde las",
+		hour:  "h",
+	},
+	German: {
+		code: "de",
+		condition: [model.ConditionCount]string{
+			model.ConditionClear:        "klar",
+			model.ConditionPartlyCloudy: "teils bewölkt",
+			model.ConditionCloudy:       "bewölkt",
+			model.ConditionFog:          "Nebel",
+			model.ConditionDrizzle:      "Niesel",
+			model.ConditionRain:         "Regen",
+			model.ConditionIce:          "Glatteis",
+			model.ConditionSnow:         "Schnee",
+			model.ConditionShowers:      "Schauer",
+			model.ConditionStorms:       "Gewitter",
+		},
+		high:  "Max",
+		after: "ab",
+		hour:  " Uhr",
+	},
+	French: {
+		code: "fr",
+		condition: [model.ConditionCount]string{
+			model.ConditionClear:        "dégagé",
+			model.ConditionPartlyCloudy: "peu nuageux",
+			model.ConditionCloudy:       "nuageux",
+			model.ConditionFog:          "brouillard",
+			model.ConditionDrizzle:      "bruine",
+			model.ConditionRain:         "pluie",
+			model.ConditionIce:          "verglas",
+			model.ConditionSnow:         "neige",
+			model.ConditionShowers:      "averses",
+			model.ConditionStorms:       "orages",
+		},
+		high:  "Max",
+		after: "après",
+		hour:  "h",
+	},
+}
+
+// Parse parses an ISO 639-1 language code, e.g., "es".
+func Parse(s string) (Language, bool) {
+	for l, t := range tables {
+		if s == t.code {
+			return Language(l), true
+		}
+	}
+	return English, false
+}
+
+// Codes returns the ISO 639-1 code of each supported Language, in order.
+func Codes() []string {
+	codes := make([]string, languageCount)
+	for l := range tables {
+		codes[l] = tables[l].code
+	}
+	return codes
+}
+
+// table returns the table of the Language, or of English if unsupported.
+func (l Language) table() *table {
+	if l < languageCount {
+		return &tables[l]
+	}
+	return &tables[English]
+}
+
+// String returns the ISO 639-1 code of the Language.
+func (l Language) String() string {
+	return l.table().code
+}
+
+// Condition returns a short description of the given weather Condition.
+func (l Language) Condition(c model.Condition) string {
+	if c < model.ConditionCount {
+		if s := l.table().condition[c]; "" != s {
+			return s
+		}
+	}
+	return c.String()
+}
+
+// Code returns a short description of the weather described by the given code.
+func (l Language) Code(c model.Code) string {
+	return l.Condition(c.Condition())
+}
+
+// High returns the label preceding the day's high temperature, e.g., "High".
+func (l Language) High() string {
+	return l.table().high
+}
+
+// After returns the phrase stating that precipitation begins at the hour of
+// time t, e.g., "after 3pm".
+func (l Language) After(t time.Time) string {
+	tab := l.table()
+	if "" == tab.hour {
+		return tab.after + " " + t.Format("3pm")
+	}
+	return tab.after + " " + strconv.Itoa(t.Hour()) + tab.hour
+}
@@ -0,0 +1,178 @@
+// Package lora implements a receive-only driver for Semtech SX1276/77/78/79
+// (SX127x) LoRa radio transceivers, e.g., the RFM95W module, over SPI.
+//
+// The radio receives continuously, and packets are polled from its FIFO (see
+// Receive), so that no interrupt pin is required.
+package lora
+
+import (
+	"errors"
+	"machine"
+
+	"tinygo.org/x/drivers"
+)
+
+// Default constants for Device configuration. The sync word 0x12 is that of
+// private networks, which LoRaWAN gateways ignore.
+const (
+	DefaultFrequency       = 915000000 // Hz
+	DefaultSpreadingFactor = 7
+	DefaultBandwidth       = Bandwidth125k
+	DefaultSyncWord        = 0x12
+)
+
+var (
+	ErrNoDevice     = errors.New("SX127x radio not found")
+	ErrInvalidRadio = errors.New("invalid radio configuration")
+)
+
+// Bandwidth is the signal bandwidth, as encoded by the radio.
+type Bandwidth uint8
+
+// Constants defining the supported bandwidths.
+const (
+	Bandwidth62k5 Bandwidth = 6
+	Bandwidth125k Bandwidth = 7
+	Bandwidth250k Bandwidth = 8
+	Bandwidth500k Bandwidth = 9
+)
+
+// Registers of LoRa mode.
+const (
+	regFifo          = 0x00
+	regOpMode        = 0x01
+	regFrfMsb        = 0x06
+	regLna           = 0x0C
+	regFifoAddrPtr   = 0x0D
+	regFifoRxBase    = 0x0F
+	regFifoRxCurrent = 0x10
+	regIrqFlags      = 0x12
+	regRxNbBytes     = 0x13
+	regPktRssiValue  = 0x1A
+	regModemConfig1  = 0x1D
+	regModemConfig2  = 0x1E
+	regModemConfig3  = 0x26
+	regSyncWord      = 0x39
+	regVersion       = 0x42
+
+	modeLoRa    = 0x80
+	modeSleep   = 0x00
+	modeStandby = 0x01
+	modeRxCont  = 0x05
+
+	irqRxDone   = 0x40
+	irqCrcError = 0x20
+
+	chipVersion = 0x12
+)
+
+// Config defines the carrier frequency and modulation, which must match those
+// of the transmitters.
+type Config struct {
+	Frequency       uint32 // Hz
+	SpreadingFactor uint8  // 6-12
+	Bandwidth       Bandwidth
+	SyncWord        uint8
+}
+
+// Device wraps the SPI interface of the SX127x.
+type Device struct {
+	SPI drivers.SPI
+	CS  machine.Pin
+
+	lowBand bool    // frequency below 525 MHz, which changes the RSSI offset
+	buf     [2]byte // address and value of registers read or written
+}
+
+// Configure puts the radio in LoRa mode with the given configuration, and
+// begins receiving continuously. Returns ErrNoDevice if no SX127x replies.
+func (d *Device) Configure(config Config) error {
+
+	if 0 == config.Frequency {
+		config.Frequency = DefaultFrequency
+	}
+	if 0 == config.SpreadingFactor {
+		config.SpreadingFactor = DefaultSpreadingFactor
+	}
+	if 0 == config.Bandwidth {
+		config.Bandwidth = DefaultBandwidth
+	}
+	if 0 == config.SyncWord {
+		config.SyncWord = DefaultSyncWord
+	}
+	if config.SpreadingFactor < 6 || config.SpreadingFactor > 12 ||
+		config.Bandwidth < Bandwidth62k5 || config.Bandwidth > Bandwidth500k {
+		return ErrInvalidRadio
+	}
+
+	d.CS.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	d.CS.High()
+	if chipVersion != d.read(regVersion) {
+		return ErrNoDevice
+	}
+
+	// the mode may only be changed to LoRa while asleep
+	d.write(regOpMode, modeLoRa|modeSleep)
+	frf := uint64(config.Frequency) << 19 / 32000000
+	d.write(regFrfMsb, uint8(frf>>16))
+	d.write(regFrfMsb+1, uint8(frf>>8))
+	d.write(regFrfMsb+2, uint8(frf))
+	d.lowBand = config.Frequency < 525000000
+
+	d.write(regLna, 0x23) // maximum gain, boosted
+	d.write(regFifoRxBase, 0)
+	d.write(regModemConfig1, uint8(config.Bandwidth)<<4|1<<1) // coding rate 4/5, explicit header
+	d.write(regModemConfig2, config.SpreadingFactor<<4|0x04)  // payload CRC on
+	cfg3 := uint8(0x04)                                       // automatic gain control
+	if config.SpreadingFactor >= 11 && config.Bandwidth <= Bandwidth125k {
+		cfg3 |= 0x08 // low data rate optimization, required for long symbols
+	}
+	d.write(regModemConfig3, cfg3)
+	d.write(regSyncWord, config.SyncWord)
+
+	d.write(regOpMode, modeLoRa|modeStandby)
+	d.write(regOpMode, modeLoRa|modeRxCont)
+	return nil
+}
+
+// Receive copies the payload of the next packet received into b, and returns
+// its length and signal strength (dBm). Returns ok false if no packet has been
+// received, or if it failed its CRC. Receive never blocks.
+func (d *Device) Receive(b []byte) (n int, rssi int16, ok bool) {
+	flags := d.read(regIrqFlags)
+	if 0 == flags&irqRxDone {
+		return 0, 0, false
+	}
+	d.write(regIrqFlags, 0xFF) // clear
+	if 0 != flags&irqCrcError {
+		return 0, 0, false
+	}
+	n = int(d.read(regRxNbBytes))
+	d.write(regFifoAddrPtr, d.read(regFifoRxCurrent))
+	if n > len(b) {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		b[i] = d.read(regFifo)
+	}
+	rssi = int16(d.read(regPktRssiValue)) - 157
+	if d.lowBand {
+		rssi -= 7
+	}
+	return n, rssi, true
+}
+
+func (d *Device) read(addr uint8) uint8 {
+	d.buf[0], d.buf[1] = addr&0x7F, 0
+	d.CS.Low()
+	d.SPI.Tx(d.buf[:], d.buf[:])
+	d.CS.High()
+	return d.buf[1]
+}
+
+func (d *Device) write(addr, value uint8) {
+	d.buf[0], d.buf[1] = addr|0x80, value
+	d.CS.Low()
+	d.SPI.Tx(d.buf[:], nil)
+	d.CS.High()
+}
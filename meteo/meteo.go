@@ -0,0 +1,115 @@
+// Package meteo implements formulas for quantities derived from basic weather
+// observations, such as the heat index.
+//
+// All temperatures are given in degrees Celsius and relative humidity in
+// percent, matching the units held in the Model.
+//
+// Formulas evaluated frequently (e.g., for every reading of a sensor) use the
+// fixed-point arithmetic of package fixed.
+package meteo
+
+import (
+	"math"
+
+	"github.com/ardnew/weatherhub/fixed"
+)
+
+// Coefficients of the heat index regression, scaled for temperature and
+// humidity given in hundreds of degrees Fahrenheit and percent, respectively,
+// so that every term remains within the range of fixed.Q16.
+var (
+	hiC0    = fixed.From(-42.379)
+	hiCf    = fixed.From(2.04901523 * 100)
+	hiCh    = fixed.From(10.14333127 * 100)
+	hiCfh   = fixed.From(-0.22475541 * 1e4)
+	hiCff   = fixed.From(-0.00683783 * 1e4)
+	hiChh   = fixed.From(-0.05481717 * 1e4)
+	hiCffh  = fixed.From(0.00122874 * 1e6)
+	hiCfhh  = fixed.From(0.00085282 * 1e6)
+	hiCffhh = fixed.From(-0.00000199 * 1e8)
+)
+
+// HeatIndex returns the apparent temperature resulting from the combined effect
+// of temperature t and relative humidity rh, using the regression of the US
+// National Weather Service.
+func HeatIndex(t, rh float32) float32 {
+	f := fixed.From(t*9/5 + 32)
+	h := fixed.From(rh)
+	// simple formula, adequate when the heat index is below 80 °F
+	hi := (f + fixed.Int(61) + (f - fixed.Int(68)).Mul(fixed.From(1.2)) +
+		h.Mul(fixed.From(0.094))) / 2
+	if (hi+f)/2 >= fixed.Int(80) {
+		fs, hs := f/100, h/100
+		hi = hiC0 + hiCf.Mul(fs) + hiCh.Mul(hs) +
+			fs.Mul(hs).Mul(hiCfh+hiCffh.Mul(fs)+hiCfhh.Mul(hs)+hiCffhh.Mul(fs).Mul(hs)) +
+			fs.Mul(fs).Mul(hiCff) + hs.Mul(hs).Mul(hiChh)
+		switch {
+		case h < fixed.Int(13) && f >= fixed.Int(80) && f <= fixed.Int(112):
+			hi -= (fixed.Int(13) - h).Mul(fixed.Sqrt(
+				(fixed.Int(17)-(f-fixed.Int(95)).Abs())/17)) / 4
+		case h > fixed.Int(85) && f >= fixed.Int(80) && f <= fixed.Int(87):
+			hi += (h - fixed.Int(85)).Mul(fixed.Int(87)-f) / 50
+		}
+	}
+	return ((hi - fixed.Int(32)) * 5 / 9).Float()
+}
+
+// Evapotranspiration returns the reference evapotranspiration, in millimeters
+// per day, estimated by the Hargreaves equation from the daily high and low
+// temperatures at the given latitude (degrees north) and day of the year.
+func Evapotranspiration(high, low float32, latitude float64, yday int) float32 {
+	mean := float64(high+low) / 2
+	span := float64(high - low)
+	if span < 0 {
+		span = 0
+	}
+	// 0.408 converts radiation from MJ/m²/day to equivalent millimeters of water.
+	et := 0.0023 * 0.408 * radiation(latitude, yday) * (mean + 17.8) * math.Sqrt(span)
+	if et < 0 {
+		return 0
+	}
+	return float32(et)
+}
+
+// radiation returns the extraterrestrial solar radiation, in MJ/m²/day, at the
+// given latitude (degrees north) and day of the year (FAO-56, eq. 21).
+func radiation(latitude float64, yday int) float64 {
+	const solarConstant = 0.0820 // MJ/m²/min
+	phi := latitude * math.Pi / 180
+	angle := 2 * math.Pi * float64(yday) / 365
+	dr := 1 + 0.033*math.Cos(angle)       // inverse relative distance Earth-Sun
+	delta := 0.409 * math.Sin(angle-1.39) // solar declination
+	x := -math.Tan(phi) * math.Tan(delta)
+	if x < -1 {
+		x = -1 // midnight sun
+	} else if x > 1 {
+		x = 1 // polar night
+	}
+	ws := math.Acos(x) // sunset hour angle
+	return 24 * 60 / math.Pi * solarConstant * dr *
+		(ws*math.Sin(phi)*math.Sin(delta) + math.Cos(phi)*math.Cos(delta)*math.Sin(ws))
+}
+
+// DewPoint returns the temperature to which air at temperature t and relative
+// humidity rh must be cooled to become saturated, using the Magnus formula.
+func DewPoint(t, rh float32) float32 {
+	b, c := fixed.From(17.62), fixed.From(243.12) // °C
+	if rh <= 0.1 {
+		rh = 0.1 // avoid log(0)
+	}
+	tq := fixed.From(t)
+	g := fixed.Log(fixed.From(rh/100)) + b.Mul(tq).Div(c+tq)
+	return c.Mul(g).Div(b - g).Float()
+}
+
+// Geomagnetic returns the geomagnetic latitude, in degrees, of the position with
+// given geographic latitude and longitude (degrees north and east), using a
+// dipole model of Earth's magnetic field.
+func Geomagnetic(latitude, longitude float64) float64 {
+	const poleLat, poleLon = 80.65, -72.68 // north geomagnetic pole (IGRF, 2020)
+	phi, lambda := latitude*math.Pi/180, longitude*math.Pi/180
+	phiP, lambdaP := poleLat*math.Pi/180, poleLon*math.Pi/180
+	s := math.Sin(phi)*math.Sin(phiP) +
+		math.Cos(phi)*math.Cos(phiP)*math.Cos(lambda-lambdaP)
+	return math.Asin(s) * 180 / math.Pi
+}
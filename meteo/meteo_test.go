@@ -0,0 +1,60 @@
+package meteo
+
+import (
+	"math"
+	"testing"
+)
+
+// Error limits of the fixed-point formulas compared with their evaluation in
+// double precision, in °C.
+const (
+	heatIndexLimit = 0.05
+	dewPointLimit  = 0.02
+)
+
+// heatIndex is the regression of the US National Weather Service, including its
+// adjustments for low and high humidity, in double precision.
+func heatIndex(t, rh float64) float64 {
+	f := t*9/5 + 32
+	hi := (f + 61 + (f-68)*1.2 + rh*0.094) / 2
+	if (hi+f)/2 >= 80 {
+		hi = -42.379 + 2.04901523*f + 10.14333127*rh - 0.22475541*f*rh -
+			0.00683783*f*f - 0.05481717*rh*rh + 0.00122874*f*f*rh +
+			0.00085282*f*rh*rh - 0.00000199*f*f*rh*rh
+		switch {
+		case rh < 13 && f >= 80 && f <= 112:
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(f-95))/17)
+		case rh > 85 && f >= 80 && f <= 87:
+			hi += (rh - 85) / 10 * (87 - f) / 5
+		}
+	}
+	return (hi - 32) * 5 / 9
+}
+
+// dewPoint is the Magnus formula in double precision.
+func dewPoint(t, rh float64) float64 {
+	g := math.Log(rh/100) + 17.62*t/(243.12+t)
+	return 243.12 * g / (17.62 - g)
+}
+
+func TestHeatIndex(t *testing.T) {
+	for temp := -10.0; temp <= 50; temp += 0.25 {
+		for rh := 1.0; rh <= 100; rh++ {
+			got := float64(HeatIndex(float32(temp), float32(rh)))
+			if want := heatIndex(temp, rh); math.Abs(got-want) > heatIndexLimit {
+				t.Fatalf("HeatIndex(%v, %v) = %v, want %v", temp, rh, got, want)
+			}
+		}
+	}
+}
+
+func TestDewPoint(t *testing.T) {
+	for temp := -10.0; temp <= 50; temp += 0.25 {
+		for rh := 1.0; rh <= 100; rh++ {
+			got := float64(DewPoint(float32(temp), float32(rh)))
+			if want := dewPoint(temp, rh); math.Abs(got-want) > dewPointLimit {
+				t.Fatalf("DewPoint(%v, %v) = %v, want %v", temp, rh, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/history"
+)
+
+// Accuracy compares the high and low temperature forecast the day before with
+// those observed on the most recent complete day, and tracks the bias of the
+// forecast over the past several days.
+//
+// Errors and bias are forecast minus observed, so a positive bias means the
+// forecast runs warm.
+type Accuracy struct {
+	Date         time.Time // local midnight of the day compared; zero if none
+	ForecastHigh float32   // °C
+	ForecastLow  float32   // °C
+	ObservedHigh float32   // °C
+	ObservedLow  float32   // °C
+	HighBias     float32   // mean error of forecast highs, °C
+	LowBias      float32   // mean error of forecast lows, °C
+	Days         int       // number of days included in the bias
+	Corrected    bool      // forecast temperatures are corrected by the bias
+	HighError    history.Daily
+	LowError     history.Daily
+}
+
+// Valid returns true if a day has been compared.
+func (a Accuracy) Valid() bool {
+	return !a.Date.IsZero()
+}
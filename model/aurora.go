@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Visibility estimates how likely the aurora is to be seen overhead or on the
+// horizon.
+type Visibility uint8
+
+// Constants defining each possible Visibility, in increasing order.
+const (
+	VisibilityNone Visibility = iota
+	VisibilityLow
+	VisibilityPossible
+	VisibilityLikely
+)
+
+// String returns a short description of the Visibility.
+func (v Visibility) String() string {
+	switch v {
+	case VisibilityLow:
+		return "Low"
+	case VisibilityPossible:
+		return "Possible"
+	case VisibilityLikely:
+		return "Likely"
+	}
+	return "None"
+}
+
+// Aurora contains the most recent planetary K-index and the aurora visibility
+// it implies at the device Location.
+type Aurora struct {
+	Updated    time.Time // zero if never retrieved
+	Kp         float32   // planetary K-index, 0-9
+	Required   float32   // K-index at which aurora reaches the Location
+	Visibility Visibility
+	Show       bool // Location is far enough poleward to show the aurora page
+}
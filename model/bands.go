@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// MaxBands defines the number of groups of HF bands of Bands.
+const MaxBands = 4
+
+// Propagation is the condition of radio propagation on a group of bands.
+type Propagation uint8
+
+// Constants defining each possible Propagation.
+const (
+	PropagationUnknown Propagation = iota
+	PropagationPoor
+	PropagationFair
+	PropagationGood
+)
+
+// String returns the name of the Propagation.
+func (p Propagation) String() string {
+	switch p {
+	case PropagationPoor:
+		return "Poor"
+	case PropagationFair:
+		return "Fair"
+	case PropagationGood:
+		return "Good"
+	}
+	return "?"
+}
+
+// Band contains the propagation on a group of HF bands, e.g., "80m-40m", by day
+// and by night.
+type Band struct {
+	Name       string
+	Day, Night Propagation
+}
+
+// Bands contains the solar indices and HF band conditions, for amateur radio
+// operators.
+type Bands struct {
+	Active  bool      // retrieved
+	Updated time.Time // zero if never retrieved
+	Flux    int       // solar flux index (SFI), 10.7 cm
+	A, K    int       // planetary A- and K-indices of geomagnetic activity
+	Band    [MaxBands]Band
+}
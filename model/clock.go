@@ -0,0 +1,7 @@
+package model
+
+// WorldClock is a time zone shown by the world clock page.
+type WorldClock struct {
+	Label string // e.g., a city in the zone
+	Zone  string // IANA time zone name, which must be defined in package tz
+}
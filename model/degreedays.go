@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// DegreeDays accumulates the heating, cooling, and growing degree days of the
+// current and previous month, from the high and low temperature of each day.
+//
+// Heating and cooling degree days are the number of degrees by which the mean
+// temperature of each day fell below or rose above their base temperatures,
+// which estimate the energy used to heat or cool a building. Growing degree days
+// estimate the development of plants and insects, and are accumulated from the
+// high and low temperature limited to their base temperature and cap.
+type DegreeDays struct {
+	Base     DegreeBase
+	Month    DegreeMonth // current month, through the most recent complete day
+	Previous DegreeMonth // previous month, if accumulated
+}
+
+// DegreeBase defines the base temperatures of each kind of degree day, °C.
+type DegreeBase struct {
+	Heating float32
+	Cooling float32
+	Growing float32
+	Cap     float32 // temperature above which plants grow no faster
+}
+
+// DegreeMonth contains the degree days accumulated over a month, °C-days.
+type DegreeMonth struct {
+	Start   time.Time // local midnight of the first day of the month; zero if none
+	Days    int       // number of days accumulated
+	Heating float32
+	Cooling float32
+	Growing float32
+}
+
+// Valid returns true if any day of the month has been accumulated.
+func (m DegreeMonth) Valid() bool {
+	return !m.Start.IsZero() && m.Days > 0
+}
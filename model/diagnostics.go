@@ -0,0 +1,107 @@
+package model
+
+import "time"
+
+// MaxQuotas defines the number of API quotas whose usage is held in the Model.
+const MaxQuotas = 3
+
+// Quota describes the number of requests made to a web API today, and the
+// number allowed each day.
+type Quota struct {
+	Host  string // empty if unused
+	Used  int
+	Limit int
+}
+
+// Ping describes the round-trip time of the most recent ping of a host.
+type Ping struct {
+	Host string // empty if not yet pinged
+	OK   bool   // a reply was received
+	RTT  time.Duration
+}
+
+// Diagnostics contains information about the operation of the device itself.
+//
+// The gateway and a host on the internet are pinged, so that problems with the
+// WiFi may be distinguished from problems beyond the router, e.g., with APIs.
+type Diagnostics struct {
+	Quota    [MaxQuotas]Quota
+	Timing   string // name of the display timing preset in effect
+	Gateway  Ping
+	Internet Ping
+	Connects uint // number of times a network was joined since power on
+	TimeSync TimeSync
+	Boot     time.Time // when the device started, see Uptime
+	Boots    uint32    // number of times the device has started, 0 if unknown
+	Reset    ResetCause
+}
+
+// Uptime returns the time elapsed since the device started. The monotonic clock
+// is used, so that the uptime is unaffected by synchronizing the system time.
+func (d Diagnostics) Uptime() time.Duration {
+	return time.Since(d.Boot)
+}
+
+// ResetCause identifies what reset the microcontroller before it last started.
+type ResetCause uint8
+
+// Constants defining each possible ResetCause.
+const (
+	ResetUnknown  ResetCause = iota // not reported by the microcontroller
+	ResetPowerOn                    // power was applied
+	ResetBrownout                   // supply voltage dropped too low
+	ResetExternal                   // reset pin, e.g., the reset button
+	ResetWatchdog                   // watchdog timer expired
+	ResetSoftware                   // requested by the program, e.g., to update
+)
+
+// String returns the lowercase name of the ResetCause.
+func (r ResetCause) String() string {
+	switch r {
+	case ResetPowerOn:
+		return "power-on"
+	case ResetBrownout:
+		return "brownout"
+	case ResetExternal:
+		return "external"
+	case ResetWatchdog:
+		return "watchdog"
+	case ResetSoftware:
+		return "software"
+	}
+	return "unknown"
+}
+
+// TimeSync describes the most recent synchronization of the system time with an
+// NTP server.
+type TimeSync struct {
+	Server  string        // host name, empty if never synchronized
+	Stratum uint8         // of the server, 1 for a primary reference clock
+	Offset  time.Duration // correction applied to the system time
+	Delay   time.Duration // round-trip delay of the request
+	Synced  time.Time     // when synchronized, zero if never
+}
+
+// SyncHealth summarizes the accuracy of the system time.
+type SyncHealth uint8
+
+// Constants defining each possible SyncHealth.
+const (
+	SyncUnknown SyncHealth = iota // never synchronized
+	SyncGood
+	SyncFair // distant server or slow network, accurate to within a second
+	SyncPoor // not synchronized for a day or more
+)
+
+// Health returns the SyncHealth of the system time at time now.
+func (s TimeSync) Health(now time.Time) SyncHealth {
+	switch {
+	case s.Synced.IsZero():
+		return SyncUnknown
+	case now.Sub(s.Synced) >= 24*time.Hour:
+		return SyncPoor
+	case s.Delay >= 500*time.Millisecond || s.Stratum > 4:
+		return SyncFair
+	}
+	return SyncGood
+}
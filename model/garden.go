@@ -0,0 +1,9 @@
+package model
+
+// Garden describes whether the garden should be watered, based on the balance
+// of recent rainfall and evapotranspiration.
+type Garden struct {
+	Known   bool    // enough weather has been logged to make a recommendation
+	Water   bool    // watering is recommended
+	Deficit float32 // evapotranspiration not replaced by rainfall, millimeters
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/history"
+)
+
+// Health contains the current air quality and pollen at the device Location,
+// and the maximum of each over the past several days.
+type Health struct {
+	Updated       time.Time // zero if never retrieved
+	AQI           float32   // US air quality index, 0-500
+	Pollen        float32   // greatest concentration of any pollen, grains/m³
+	HasPollen     bool      // pollen is reported at the Location
+	AQIHistory    history.Daily
+	PollenHistory history.Daily
+}
+
+// Valid returns true if the air quality has been retrieved.
+func (h Health) Valid() bool {
+	return !h.Updated.IsZero()
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Comfort classifies the indoor climate by its dew point.
+type Comfort uint8
+
+// Constants defining each possible Comfort level, in increasing order of
+// moisture.
+const (
+	ComfortUnknown Comfort = iota
+	ComfortDry
+	ComfortGood
+	ComfortHumid
+	ComfortMold // condensation on cool surfaces is likely
+)
+
+// String returns a short description of the Comfort level.
+func (c Comfort) String() string {
+	switch c {
+	case ComfortDry:
+		return "Dry"
+	case ComfortGood:
+		return "Good"
+	case ComfortHumid:
+		return "Humid"
+	case ComfortMold:
+		return "Mold risk"
+	}
+	return "Unknown"
+}
+
+// Indoor contains the most recent indoor climate measurement.
+// Temperatures are given in degrees Celsius.
+type Indoor struct {
+	Updated     time.Time // zero if no recent measurement
+	Temperature float32
+	Humidity    float32 // relative humidity, percent
+	DewPoint    float32
+	Comfort     Comfort
+}
+
+// Valid returns true if a recent measurement is available.
+func (i Indoor) Valid() bool {
+	return !i.Updated.IsZero()
+}
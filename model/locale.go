@@ -0,0 +1,172 @@
+package model
+
+import "time"
+
+// DateOrder is the order of the day and month of dates presented.
+type DateOrder uint8
+
+// Constants defining each possible DateOrder.
+const (
+	OrderDefault  DateOrder = iota // that of the base Locale (see Locale.Or)
+	OrderMonthDay                  // January 2
+	OrderDayMonth                  // 2 January
+)
+
+// WeekStart is the first day of the week, which determines the week number of
+// dates.
+type WeekStart uint8
+
+// Constants defining each possible WeekStart.
+const (
+	StartDefault  WeekStart = iota // that of the base Locale (see Locale.Or)
+	StartSunday                    // week 1 contains January 1
+	StartMonday                    // ISO 8601, week 1 contains January 4
+	StartSaturday                  // week 1 contains January 1
+)
+
+// WeekNumber selects whether the week number is presented with dates.
+type WeekNumber uint8
+
+// Constants defining each possible WeekNumber.
+const (
+	NumberDefault WeekNumber = iota // that of the base Locale (see Locale.Or)
+	NumberHidden
+	NumberShown // numbered by the WeekStart
+	NumberISO   // numbered as in ISO 8601, regardless of the WeekStart
+)
+
+// String returns the name of the DateOrder.
+func (o DateOrder) String() string {
+	switch o {
+	case OrderMonthDay:
+		return "month-day"
+	case OrderDayMonth:
+		return "day-month"
+	}
+	return "default"
+}
+
+// String returns the name of the WeekStart.
+func (s WeekStart) String() string {
+	switch s {
+	case StartSunday:
+		return "sunday"
+	case StartMonday:
+		return "monday"
+	case StartSaturday:
+		return "saturday"
+	}
+	return "default"
+}
+
+// String returns the name of the WeekNumber.
+func (n WeekNumber) String() string {
+	switch n {
+	case NumberHidden:
+		return "hidden"
+	case NumberShown:
+		return "shown"
+	case NumberISO:
+		return "iso"
+	}
+	return "default"
+}
+
+// ParseDateOrder returns the DateOrder with the given name, as returned by
+// String.
+func ParseDateOrder(name string) (DateOrder, bool) {
+	for o := OrderDefault; o <= OrderDayMonth; o++ {
+		if name == o.String() {
+			return o, true
+		}
+	}
+	return OrderDefault, false
+}
+
+// ParseWeekStart returns the WeekStart with the given name, as returned by
+// String.
+func ParseWeekStart(name string) (WeekStart, bool) {
+	for s := StartDefault; s <= StartSaturday; s++ {
+		if name == s.String() {
+			return s, true
+		}
+	}
+	return StartDefault, false
+}
+
+// ParseWeekNumber returns the WeekNumber with the given name, as returned by
+// String.
+func ParseWeekNumber(name string) (WeekNumber, bool) {
+	for n := NumberDefault; n <= NumberISO; n++ {
+		if name == n.String() {
+			return n, true
+		}
+	}
+	return NumberDefault, false
+}
+
+// Locale selects the conventions of dates presented, e.g., for "2 Jan W01":
+//
+//	Locale{Order: OrderDayMonth, Start: StartMonday, Number: NumberShown}
+//
+// Each field left default is that of a base Locale (see Or). The zero value
+// presents dates as in the United States: month first, weeks beginning Sunday,
+// and no week number.
+type Locale struct {
+	Order  DateOrder
+	Start  WeekStart
+	Number WeekNumber
+}
+
+// Or returns l with each default field replaced by that of base, e.g., the
+// configured Locale overridden by the settings.
+func (l Locale) Or(base Locale) Locale {
+	if OrderDefault == l.Order {
+		l.Order = base.Order
+	}
+	if StartDefault == l.Start {
+		l.Start = base.Start
+	}
+	if NumberDefault == l.Number {
+		l.Number = base.Number
+	}
+	return l
+}
+
+// DayFirst returns true if the day of dates precedes the month.
+func (l Locale) DayFirst() bool {
+	return OrderDayMonth == l.Order
+}
+
+// ShowWeek returns true if the week number is presented with dates.
+func (l Locale) ShowWeek() bool {
+	return NumberShown == l.Number || NumberISO == l.Number
+}
+
+// FirstDay returns the first day of the week.
+func (l Locale) FirstDay() time.Weekday {
+	switch l.Start {
+	case StartMonday:
+		return time.Monday
+	case StartSaturday:
+		return time.Saturday
+	}
+	return time.Sunday
+}
+
+// Week returns the week number of the date of t. Weeks are numbered 1-53 as in
+// ISO 8601 if they begin Monday or Number is NumberISO, so the first days of
+// January may belong to the last week of the previous year. Otherwise, week 1
+// is the week containing January 1, and the last week is numbered 54 if the
+// year has days in 54 weeks, e.g., December 31 of a leap year beginning on the
+// last day of the week, such as 2028 with weeks beginning Sunday.
+func (l Locale) Week(t time.Time) int {
+	first := l.FirstDay()
+	if time.Monday == first || NumberISO == l.Number {
+		_, week := t.ISOWeek()
+		return week
+	}
+	jan1 := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	lead := (int(jan1.Weekday()) - int(first) + 7) % 7 // days of week 1 before January 1
+	return (t.YearDay()-1+lead)/7 + 1
+}
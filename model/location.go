@@ -0,0 +1,17 @@
+package model
+
+// Location describes where the device is installed.
+// The zero value indicates the location is unknown.
+type Location struct {
+	Latitude  float64 // degrees north
+	Longitude float64 // degrees east
+	City      string
+	Zone      string // IANA time zone name, e.g., "America/Chicago"
+	Offset    int    // seconds east of UTC, used if Zone is not in package tz
+	Auto      bool   // determined by geolocation rather than configuration
+}
+
+// Known returns true if the coordinates of the Location have been determined.
+func (l Location) Known() bool {
+	return 0 != l.Latitude || 0 != l.Longitude
+}
@@ -22,6 +22,31 @@ type Model struct {
 	Time   time.Time
 	Retry  uint
 	Status Status
+
+	Diagnostics Diagnostics
+
+	Location   Location
+	Weather    Weather
+	Accuracy   Accuracy
+	DegreeDays DegreeDays
+	Garden     Garden
+	Indoor     Indoor
+	Remote     Remotes
+	SnowDay    SnowDay
+	Aurora     Aurora
+	Health     Health
+	Ski        Ski
+	Surf       Surf
+	Bands      Bands
+	Schedule   Schedule
+
+	Stopwatch Stopwatch
+	QR        QR
+
+	Transit Transit
+	Scores  Scores
+	News    News
+	Notice  Notices
 }
 
 // Status represents the current position of the program state machine.
@@ -32,10 +57,31 @@ const (
 	StatusIdle Status = iota
 	StatusDisconnected
 	StatusConnecting
+	StatusCaptive // connected, but the network requires sign-in (captive portal)
 	StatusUnsynchronized
 	StatusSynchronized
+	StatusDemo // showing synthetic data, without the network (see package demo)
 )
 
+// String returns the lowercase name of the Status.
+func (s Status) String() string {
+	switch s {
+	case StatusDisconnected:
+		return "disconnected"
+	case StatusConnecting:
+		return "connecting"
+	case StatusCaptive:
+		return "captive"
+	case StatusUnsynchronized:
+		return "unsynchronized"
+	case StatusSynchronized:
+		return "synchronized"
+	case StatusDemo:
+		return "demo"
+	}
+	return "idle"
+}
+
 // state holds the instance variable of singleton type Model and other fields
 // used for access control.
 // See godoc on type Model for details.
@@ -58,6 +104,16 @@ func Get() (changed bool, data Model) {
 	return
 }
 
+// Peek safely returns a copy of the Model data (as it was defined when Peek was
+// called).
+// The changed flag is unaffected by this method.
+func Peek() (data Model) {
+	state.lock.Lock()
+	data = state.data
+	state.lock.Unlock()
+	return
+}
+
 // Set provides synchronized read+write access to the Model data via argument to
 // the given closure.
 // The changed flag is automatically set true after the closure has been called.
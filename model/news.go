@@ -0,0 +1,11 @@
+package model
+
+// MaxHeadlines defines the number of news headlines held in the Model.
+const MaxHeadlines = 5
+
+// News holds the headlines of the most recent items of the configured news
+// feed. Active is only true during the configured ticker schedule.
+type News struct {
+	Active   bool
+	Headline [MaxHeadlines]string
+}
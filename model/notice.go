@@ -0,0 +1,94 @@
+package model
+
+import (
+	"image/color"
+	"time"
+)
+
+// MaxNotices defines the number of notices held in the Model.
+const MaxNotices = 8
+
+// Priority determines which of the queued notices is shown.
+type Priority uint8
+
+// Constants defining each possible notice Priority, in increasing order.
+// A Notice posted with PriorityNone is given PriorityNormal.
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+)
+
+// Dismiss determines when a notice is removed from the queue.
+type Dismiss uint8
+
+// Constants defining each possible Dismiss rule.
+const (
+	DismissExpire Dismiss = iota // removed when it expires
+	DismissShown                 // removed after shown for its duration, or expires
+	DismissManual                // removed only when dismissed by ID
+)
+
+// Flash determines how the display flashes while a notice is shown.
+type Flash uint8
+
+// Constants defining each possible Flash pattern.
+const (
+	FlashNone   Flash = iota
+	FlashBlink        // entire display alternates slowly between normal and inverted
+	FlashStrobe       // entire display briefly inverted in rapid succession
+	FlashBorder       // border around the display blinks
+)
+
+// Notice is a short line of text shown over the current page.
+//
+// Only the active Notice with highest priority is shown. The others remain
+// queued until it is dismissed, unless they expire or are dismissed first.
+type Notice struct {
+	ID       string // posting a Notice replaces any queued Notice with same ID
+	Text     string
+	Icon     string // name of an icon shown beside the text, if any
+	Color    color.RGBA
+	Flash    Flash
+	Priority Priority
+	Dismiss  Dismiss
+	Expires  time.Time     // zero if the Notice never expires
+	Duration time.Duration // total time shown, if dismissed by DismissShown
+	Shown    time.Duration // time shown so far
+	Posted   time.Time
+}
+
+// Notices is the queue of all notices, ordered arbitrarily.
+// Unused elements have empty Text.
+type Notices [MaxNotices]Notice
+
+// Active returns true if the Notice has text and has not been dismissed or
+// expired at time t.
+func (n Notice) Active(t time.Time) bool {
+	if "" == n.Text {
+		return false
+	}
+	if DismissManual != n.Dismiss && !n.Expires.IsZero() && !t.Before(n.Expires) {
+		return false
+	}
+	return DismissShown != n.Dismiss || n.Shown < n.Duration
+}
+
+// Top returns the index of the active Notice that should be shown at time t,
+// or -1 if there are no active notices. Of those with highest priority, the
+// most recently posted is shown.
+func (q *Notices) Top(t time.Time) int {
+	top := -1
+	for i := range q {
+		if !q[i].Active(t) {
+			continue
+		}
+		if top < 0 || q[i].Priority > q[top].Priority ||
+			(q[i].Priority == q[top].Priority && q[i].Posted.After(q[top].Posted)) {
+			top = i
+		}
+	}
+	return top
+}
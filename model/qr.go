@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// QR is text shown as a QR code in place of the carousel until a given time,
+// e.g., the URL of the device, so that it may be opened by scanning the panel
+// with a phone rather than typing its address.
+type QR struct {
+	Text    string // encoded, empty if none
+	Caption string // shown beside the code, e.g., "Scan to open"
+	Until   time.Time
+}
+
+// Shown returns true if the QR code is shown at time now.
+func (q QR) Shown(now time.Time) bool {
+	return "" != q.Text && now.Before(q.Until)
+}
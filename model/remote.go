@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// MaxRemotes defines the number of remote sensors shown.
+const MaxRemotes = 4
+
+// NoHumidity is the Humidity of a remote sensor that does not measure it.
+const NoHumidity = 0xFF
+
+// Remote contains the most recent measurement of a remote battery-powered
+// sensor, e.g., in a pool or greenhouse, received by radio.
+// Temperatures are given in degrees Celsius.
+//
+// The ID of each sensor is prefixed by its source, e.g., "lora:7", so that
+// sensors of all sources may share the slots of Remotes.
+type Remote struct {
+	ID          string
+	Name        string
+	Updated     time.Time // zero if no recent measurement
+	Temperature float32
+	Humidity    uint8  // relative humidity, percent, or NoHumidity
+	Battery     uint16 // millivolts, or 0 if unknown
+	LowBattery  bool
+	RSSI        int16 // signal strength of the last packet, dBm
+}
+
+// Valid returns true if a recent measurement is available.
+func (r Remote) Valid() bool {
+	return !r.Updated.IsZero()
+}
+
+// Remotes contains a slot for each remote sensor.
+type Remotes [MaxRemotes]Remote
+
+// Any returns true if any slot contains a recent measurement.
+func (r Remotes) Any() bool {
+	for _, s := range r {
+		if s.Valid() {
+			return true
+		}
+	}
+	return false
+}
+
+// Slot returns the index of the slot of the sensor with the given ID, or of the
+// first free slot if it has none. Returns -1 if all slots are taken.
+func (r Remotes) Slot(id string) int {
+	free := -1
+	for i, s := range r {
+		if s.Valid() && id == s.ID {
+			return i
+		}
+		if free < 0 && !s.Valid() {
+			free = i
+		}
+	}
+	return free
+}
@@ -0,0 +1,19 @@
+package model
+
+// Ski contains the snow conditions at the configured ski resort.
+type Ski struct {
+	Active      bool // in season and retrieved
+	Name        string
+	Depth       float32 // snow depth, centimeters
+	Snowfall    float32 // snowfall expected today, centimeters
+	Temperature float32 // °C
+}
+
+// Surf contains the wave conditions at the configured surf break.
+type Surf struct {
+	Active    bool // in season and retrieved
+	Name      string
+	Height    float32 // significant wave height, meters
+	Period    float32 // wave period, seconds
+	Direction float32 // direction waves come from, degrees clockwise from north
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// MaxEvents defines the number of events of a Schedule.
+const MaxEvents = 5
+
+// Event is a daily event whose time is relative to the position of the sun,
+// e.g., "Maghrib" at sunset.
+type Event struct {
+	Name string
+	Time time.Time // zero if the event does not occur today
+}
+
+// Schedule contains the times of today's sun-relative events, in the order
+// they are configured.
+type Schedule struct {
+	Active bool // any events are configured
+	Event  [MaxEvents]Event
+}
+
+// Next returns the index of the first event of the Schedule that has not yet
+// occurred as of time now, or -1 if all have.
+func (s Schedule) Next(now time.Time) int {
+	next := -1
+	for i, e := range s.Event {
+		if e.Time.IsZero() || !e.Time.After(now) {
+			continue
+		}
+		if next < 0 || e.Time.Before(s.Event[next].Time) {
+			next = i
+		}
+	}
+	return next
+}
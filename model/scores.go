@@ -0,0 +1,23 @@
+package model
+
+// MaxGames defines the number of games held in the Model.
+const MaxGames = 3
+
+// Game describes the current score of a single game.
+// Scores are kept as reported by the scoreboard, since some sports (cricket,
+// tennis) do not score with plain integers.
+type Game struct {
+	Home, Away           string // team abbreviations
+	HomeScore, AwayScore string
+	Status               string // e.g., "Q3", "Final"
+}
+
+// Scores holds the games reported by the configured scoreboard.
+type Scores struct {
+	Game [MaxGames]Game
+}
+
+// Active returns true if the scoreboard reported any games.
+func (s Scores) Active() bool {
+	return "" != s.Game[0].Home || "" != s.Game[0].Away
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// SnowDay estimates the chance that school is canceled due to snow or ice.
+type SnowDay struct {
+	Chance  uint8     // percent, zero if no snow or ice is forecast
+	Snow    float32   // snowfall expected before Morning, centimeters
+	Low     float32   // lowest temperature expected before Morning, °C
+	Ice     bool      // freezing rain or drizzle expected before Morning
+	Morning time.Time // start of the school day in question
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// MaxLaps defines the number of the most recent lap times retained by the
+// Stopwatch.
+const MaxLaps = 8
+
+// Stopwatch measures elapsed time, with lap times, and is controlled by package
+// stopwatch.
+//
+// Times are measured with the monotonic clock, not the Model Time, which is
+// only as precise as the NTP client configures it.
+type Stopwatch struct {
+	Running bool
+	Start   time.Time              // when most recently started, if Running
+	Elapsed time.Duration          // accumulated before Start
+	Laps    int                    // number of laps recorded since reset
+	Split   [MaxLaps]time.Duration // elapsed time of lap k at index k%MaxLaps
+}
+
+// At returns the elapsed time as of time now.
+func (s Stopwatch) At(now time.Time) time.Duration {
+	if s.Running {
+		return s.Elapsed + now.Sub(s.Start)
+	}
+	return s.Elapsed
+}
+
+// Active returns true if the Stopwatch is running or has been stopped without
+// being reset.
+func (s Stopwatch) Active() bool {
+	return s.Running || s.Elapsed > 0
+}
+
+// Lap returns the duration of the lap with the given number, counting from 1,
+// and false if that lap is no longer retained.
+func (s Stopwatch) Lap(n int) (time.Duration, bool) {
+	if n < 1 || n > s.Laps || n <= s.Laps-MaxLaps {
+		return 0, false
+	}
+	split := s.Split[(n-1)%MaxLaps]
+	if 1 == n {
+		return split, true
+	}
+	if n-1 <= s.Laps-MaxLaps {
+		return 0, false // previous split is no longer retained
+	}
+	return split - s.Split[(n-2)%MaxLaps], true
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// MaxDepartures defines the number of upcoming departures held in the Model.
+const MaxDepartures = 2
+
+// Departure describes the next scheduled or predicted departure of a single
+// transit route from the configured stop.
+type Departure struct {
+	Route string
+	Time  time.Time
+}
+
+// Transit holds the upcoming departures from the configured transit stop.
+// Active is only true during the configured commute hours.
+type Transit struct {
+	Active    bool
+	Departure [MaxDepartures]Departure
+}
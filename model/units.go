@@ -0,0 +1,131 @@
+package model
+
+// System is a system of units.
+type System uint8
+
+// Constants defining each possible System.
+const (
+	SystemDefault  System = iota // the System of Units, if given as an override
+	SystemMetric                 // °C, km/h, hPa, mm, cm, m
+	SystemImperial               // °F, mph, inHg, in, ft
+)
+
+// String returns the name of the System.
+func (s System) String() string {
+	switch s {
+	case SystemMetric:
+		return "metric"
+	case SystemImperial:
+		return "imperial"
+	}
+	return "default"
+}
+
+// ParseSystem returns the System with the given name, as returned by String.
+func ParseSystem(name string) (System, bool) {
+	for s := SystemDefault; s <= SystemImperial; s++ {
+		if name == s.String() {
+			return s, true
+		}
+	}
+	return SystemDefault, false
+}
+
+// Units selects the units in which weather is presented. The Model always holds
+// metric units, which are converted only when presented.
+//
+// Each quantity is presented in the System of Units, unless overridden by the
+// field of that quantity, e.g., for °F but hPa:
+//
+//	Units{System: SystemImperial, Pressure: SystemMetric}
+//
+// The zero value presents every quantity in metric units.
+type Units struct {
+	System        System
+	Temperature   System // held in °C
+	Speed         System // held in m/s
+	Pressure      System // held in hPa
+	Precipitation System // held in mm (rain) or cm (snow)
+	Height        System // held in m
+}
+
+// imperial returns true if a quantity with the given override is presented in
+// imperial units.
+func (u Units) imperial(override System) bool {
+	if SystemDefault != override {
+		return SystemImperial == override
+	}
+	return SystemImperial == u.System
+}
+
+// Temp converts the given temperature, in degrees Celsius, returning it with
+// its symbol.
+func (u Units) Temp(c float32) (float32, string) {
+	if u.imperial(u.Temperature) {
+		return c*9/5 + 32, "°F"
+	}
+	return c, "°C"
+}
+
+// TempLetter returns the letter of the temperature scale, 'C' or 'F', for text
+// too narrow for the degree sign.
+func (u Units) TempLetter() byte {
+	if u.imperial(u.Temperature) {
+		return 'F'
+	}
+	return 'C'
+}
+
+// TempDelta converts the given difference of temperature, in degrees Celsius,
+// e.g., a degree day, returning it with its symbol.
+func (u Units) TempDelta(c float32) (float32, string) {
+	if u.imperial(u.Temperature) {
+		return c * 9 / 5, "°F"
+	}
+	return c, "°C"
+}
+
+// Wind converts the given speed, in meters per second, returning it with its
+// symbol.
+func (u Units) Wind(ms float32) (float32, string) {
+	if u.imperial(u.Speed) {
+		return ms * 2.236936, "mph"
+	}
+	return ms * 3.6, "km/h"
+}
+
+// Barometer converts the given pressure, in hectopascals, returning it with its
+// symbol.
+func (u Units) Barometer(hpa float32) (float32, string) {
+	if u.imperial(u.Pressure) {
+		return hpa * 0.02953, "inHg"
+	}
+	return hpa, "hPa"
+}
+
+// Rain converts the given depth of rainfall, in millimeters, returning it with
+// its symbol.
+func (u Units) Rain(mm float32) (float32, string) {
+	if u.imperial(u.Precipitation) {
+		return mm / 25.4, "in"
+	}
+	return mm, "mm"
+}
+
+// Snow converts the given depth of snow, in centimeters, returning it with its
+// symbol.
+func (u Units) Snow(cm float32) (float32, string) {
+	if u.imperial(u.Precipitation) {
+		return cm / 2.54, "in"
+	}
+	return cm, "cm"
+}
+
+// Elevation converts the given height, in meters, e.g., of waves, returning it
+// with its symbol.
+func (u Units) Elevation(m float32) (float32, string) {
+	if u.imperial(u.Height) {
+		return m * 3.28084, "ft"
+	}
+	return m, "m"
+}
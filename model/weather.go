@@ -0,0 +1,189 @@
+package model
+
+import "time"
+
+// Constants defining the length of the forecasts held in the Model.
+const (
+	MaxHours   = 24 // hourly forecast, beginning with the current hour
+	MaxDays    = 3  // daily forecast, beginning with today
+	MaxMinutes = 90 // precipitation nowcast, beginning with the current minute
+)
+
+// Code is a WMO weather interpretation code, as reported by most forecast
+// services (WMO 4677, reduced to the subset used by Open-Meteo).
+type Code uint8
+
+// Constants defining the weather codes with special meaning.
+const (
+	CodeClear           Code = 0
+	CodeMainlyClear     Code = 1
+	CodePartlyCloudy    Code = 2
+	CodeOvercast        Code = 3
+	CodeFog             Code = 45
+	CodeDrizzle         Code = 51
+	CodeFreezingDrizzle Code = 56
+	CodeRain            Code = 61
+	CodeFreezingRain    Code = 66
+	CodeSnow            Code = 71
+	CodeShowers         Code = 80
+	CodeSnowShowers     Code = 85
+	CodeThunderstorm    Code = 95
+)
+
+// Precipitating returns true if the weather code describes any precipitation.
+func (c Code) Precipitating() bool {
+	return c >= CodeDrizzle
+}
+
+// Freezing returns true if the weather code describes freezing drizzle or
+// freezing rain, which glaze surfaces with ice.
+func (c Code) Freezing() bool {
+	return c == CodeFreezingDrizzle || c == CodeFreezingDrizzle+1 ||
+		c == CodeFreezingRain || c == CodeFreezingRain+1
+}
+
+// Snowing returns true if the weather code describes snow.
+func (c Code) Snowing() bool {
+	return (c >= CodeSnow && c < CodeShowers) ||
+		(c >= CodeSnowShowers && c < CodeThunderstorm)
+}
+
+// Condition is the general class of weather described by a Code, e.g., to
+// select the words describing it (see package lang).
+type Condition uint8
+
+// Constants defining each Condition.
+const (
+	ConditionClear Condition = iota
+	ConditionPartlyCloudy
+	ConditionCloudy
+	ConditionFog
+	ConditionDrizzle
+	ConditionRain
+	ConditionIce
+	ConditionSnow
+	ConditionShowers
+	ConditionStorms
+	ConditionCount // number of conditions, not a Condition
+)
+
+// Condition returns the general class of weather described by the weather code.
+func (c Code) Condition() Condition {
+	switch {
+	case c.Freezing():
+		return ConditionIce
+	case c >= CodeThunderstorm:
+		return ConditionStorms
+	case c >= CodeSnowShowers:
+		return ConditionSnow
+	case c >= CodeShowers:
+		return ConditionShowers
+	case c >= CodeSnow:
+		return ConditionSnow
+	case c >= CodeRain:
+		return ConditionRain
+	case c >= CodeDrizzle:
+		return ConditionDrizzle
+	case c >= CodeFog:
+		return ConditionFog
+	case c >= CodeOvercast:
+		return ConditionCloudy
+	case c >= CodePartlyCloudy:
+		return ConditionPartlyCloudy
+	}
+	return ConditionClear
+}
+
+// conditionNames defines the English description of each Condition.
+var conditionNames = [ConditionCount]string{
+	ConditionClear:        "clear",
+	ConditionPartlyCloudy: "partly cloudy",
+	ConditionCloudy:       "cloudy",
+	ConditionFog:          "fog",
+	ConditionDrizzle:      "drizzle",
+	ConditionRain:         "rain",
+	ConditionIce:          "ice",
+	ConditionSnow:         "snow",
+	ConditionShowers:      "showers",
+	ConditionStorms:       "storms",
+}
+
+// String returns a short, lowercase English description of the Condition.
+func (c Condition) String() string {
+	if c < ConditionCount {
+		return conditionNames[c]
+	}
+	return conditionNames[ConditionClear]
+}
+
+// String returns a short, lowercase English description of the weather code.
+func (c Code) String() string {
+	return c.Condition().String()
+}
+
+// Conditions describes the current weather.
+// Temperatures are given in degrees Celsius and speeds in meters per second.
+type Conditions struct {
+	Temperature float32
+	Humidity    uint8 // relative humidity, percent
+	WindSpeed   float32
+	WindGust    float32
+	Code        Code
+}
+
+// Hour describes the forecast weather of a single hour.
+type Hour struct {
+	Time        time.Time
+	Temperature float32
+	Humidity    uint8   // relative humidity, percent
+	Chance      uint8   // probability of precipitation, percent
+	Rain        float32 // precipitation, millimeters
+	Snow        float32 // snowfall, centimeters
+	WindGust    float32
+	Code        Code
+}
+
+// Day describes the forecast weather of a single day.
+type Day struct {
+	Date   time.Time
+	High   float32
+	Low    float32
+	Chance uint8   // maximum probability of precipitation, percent
+	Rain   float32 // total precipitation, millimeters
+	Code   Code
+}
+
+// Nowcast describes the precipitation expected each minute in the near term.
+// It is held longer than it is shown, so that it remains complete between
+// updates of the forecast.
+type Nowcast struct {
+	Start time.Time           // time of the first minute
+	Rain  [MaxMinutes]float32 // precipitation intensity, millimeters per hour
+}
+
+// At returns the precipitation intensity expected at time t, and false if t is
+// not within the Nowcast.
+func (n Nowcast) At(t time.Time) (float32, bool) {
+	if n.Start.IsZero() || t.Before(n.Start) {
+		return 0, false
+	}
+	i := int(t.Sub(n.Start) / time.Minute)
+	if i >= len(n.Rain) {
+		return 0, false
+	}
+	return n.Rain[i], true
+}
+
+// Weather contains the current conditions and forecast at the device Location.
+type Weather struct {
+	Updated time.Time // zero if the forecast has never been retrieved
+	Current Conditions
+	Nowcast Nowcast
+	Hour    [MaxHours]Hour
+	Day     [MaxDays]Day
+}
+
+// Valid returns true if the Weather has been retrieved.
+func (w Weather) Valid() bool {
+	return !w.Updated.IsZero()
+}
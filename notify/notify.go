@@ -0,0 +1,157 @@
+// Package notify implements the queue of notices shown over the current page.
+//
+// Notices may be posted from any source, such as weather alerts, MQTT messages,
+// or system errors. The queue itself is held in the Model, and the display
+// always shows the active notice of highest priority (see Notices.Top).
+package notify
+
+import (
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Default constants for notices describing errors.
+const (
+	ErrorID       = "error"
+	ErrorDuration = 5 * time.Second
+)
+
+// ErrorColor defines the color of notices describing errors.
+var ErrorColor = color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF}
+
+// lastUpdate holds the time of the most recent call to Update.
+var lastUpdate time.Time
+
+// Post adds the given Notice to the queue, replacing any queued Notice with
+// the same ID. If the queue is full, the Notice replaces the oldest of those
+// with lowest priority, unless they all have greater priority than the Notice.
+// Returns true if and only if the Notice was queued.
+func Post(n model.Notice) (ok bool) {
+	if model.PriorityNone == n.Priority {
+		n.Priority = model.PriorityNormal
+	}
+	model.Set(func(m *model.Model) {
+		n.Posted, n.Shown = m.Time, 0
+		slot := -1
+		// replace a queued Notice with the same ID
+		if "" != n.ID {
+			for i := range m.Notice {
+				if n.ID == m.Notice[i].ID {
+					slot = i
+					break
+				}
+			}
+		}
+		// otherwise, use an unused or inactive slot
+		if slot < 0 {
+			for i := range m.Notice {
+				if !m.Notice[i].Active(m.Time) {
+					slot = i
+					break
+				}
+			}
+		}
+		// otherwise, evict the oldest Notice with lowest priority
+		if slot < 0 {
+			for i, q := range m.Notice {
+				if slot < 0 || q.Priority < m.Notice[slot].Priority ||
+					(q.Priority == m.Notice[slot].Priority &&
+						q.Posted.Before(m.Notice[slot].Posted)) {
+					slot = i
+				}
+			}
+			if m.Notice[slot].Priority > n.Priority {
+				return
+			}
+		}
+		m.Notice[slot], ok = n, true
+	})
+	return
+}
+
+// Dismiss removes all notices with the given ID from the queue.
+func Dismiss(id string) {
+	model.Set(func(m *model.Model) {
+		for i := range m.Notice {
+			if id == m.Notice[i].ID {
+				m.Notice[i] = model.Notice{}
+			}
+		}
+	})
+}
+
+// Error posts a low-priority Notice describing the given error, which replaces
+// any other Notice posted by Error.
+func Error(source string, err error) {
+	Post(model.Notice{
+		ID:       ErrorID,
+		Text:     source + ": " + err.Error(),
+		Color:    ErrorColor,
+		Priority: model.PriorityLow,
+		Dismiss:  model.DismissShown,
+		Duration: ErrorDuration,
+	})
+}
+
+// Update accrues the time shown by the Notice currently shown and removes any
+// notices that have been dismissed or expired. It should be called frequently
+// while notices are being shown.
+func Update(now time.Time) {
+	elapsed := time.Duration(0)
+	if !lastUpdate.IsZero() {
+		elapsed = now.Sub(lastUpdate)
+	}
+	lastUpdate = now
+
+	// accruing time shown does not change what is drawn, so do not mark the Model
+	// as changed unless a Notice must be removed.
+	stale := false
+	model.Mod(func(m *model.Model) {
+		if i := m.Notice.Top(m.Time); i >= 0 {
+			m.Notice[i].Shown += elapsed
+		}
+		for _, n := range m.Notice {
+			stale = stale || ("" != n.Text && !n.Active(m.Time))
+		}
+	})
+	if stale {
+		model.Set(func(m *model.Model) {
+			for i, n := range m.Notice {
+				if !n.Active(m.Time) {
+					m.Notice[i] = model.Notice{}
+				}
+			}
+		})
+	}
+}
+
+// ParseColor parses a color given in hexadecimal notation "#RRGGBB".
+func ParseColor(s string) (color.RGBA, bool) {
+	if len(s) != 7 || '#' != s[0] {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if nil != err {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, true
+}
+
+// ParseFlash parses the name of a Flash pattern: "none", "blink", "strobe", or
+// "border".
+func ParseFlash(s string) (model.Flash, bool) {
+	switch s {
+	case "none":
+		return model.FlashNone, true
+	case "blink":
+		return model.FlashBlink, true
+	case "strobe":
+		return model.FlashStrobe, true
+	case "border":
+		return model.FlashBorder, true
+	}
+	return model.FlashNone, false
+}
@@ -0,0 +1,110 @@
+// Package profile measures the time spent in each subsystem of the main run
+// loop, e.g., rendering the display or waiting on the WiFi coprocessor, so that
+// the frame budget may be spent where it matters most.
+//
+// Each measured Section is enclosed by Start and Stop:
+//
+//	start := profile.Start()
+//	disp.Update(data)
+//	profile.Stop(profile.Render, start)
+//
+// Sections may be nested, e.g., Parse includes the NINA transactions reading
+// the document parsed, so the time of each is inclusive of any others within.
+package profile
+
+import (
+	"sync"
+	"time"
+)
+
+// Section identifies a subsystem whose time is measured.
+type Section uint8
+
+// Constants defining each possible Section.
+const (
+	Render  Section = iota // updating the display with a changed Model
+	Animate                // advancing the animations of the current page
+	Feeds                  // polling every data feed
+	NINA                   // transactions with the WiFi coprocessor
+	Parse                  // extracting values from JSON documents
+	sections
+)
+
+// String returns the lowercase name of the Section.
+func (s Section) String() string {
+	switch s {
+	case Render:
+		return "render"
+	case Animate:
+		return "animate"
+	case Feeds:
+		return "feeds"
+	case NINA:
+		return "nina"
+	case Parse:
+		return "parse"
+	}
+	return "unknown"
+}
+
+// Stats describes the time spent in a Section since the profile was reset.
+type Stats struct {
+	Section Section
+	Calls   uint32
+	Total   time.Duration
+	Max     time.Duration // of a single call
+}
+
+var state = struct {
+	lock  sync.Mutex
+	stats [sections]Stats
+	loops uint32    // iterations of the run loop
+	reset time.Time // when the profile was last reset
+}{reset: time.Now()}
+
+// Start returns the time a Section begins, to be given to Stop when it ends.
+func Start() time.Time { return time.Now() }
+
+// Stop adds the time elapsed since start to the given Section.
+func Stop(s Section, start time.Time) {
+	d := time.Since(start)
+	state.lock.Lock()
+	st := &state.stats[s]
+	st.Calls++
+	st.Total += d
+	if d > st.Max {
+		st.Max = d
+	}
+	state.lock.Unlock()
+}
+
+// Loop counts an iteration of the run loop, by which the time of each Section
+// is averaged.
+func Loop() {
+	state.lock.Lock()
+	state.loops++
+	state.lock.Unlock()
+}
+
+// Report calls visit with the Stats of each Section, and returns the number of
+// iterations of the run loop and the time elapsed since the profile was reset.
+func Report(visit func(Stats)) (loops uint32, elapsed time.Duration) {
+	state.lock.Lock()
+	stats := state.stats
+	loops, elapsed = state.loops, time.Since(state.reset)
+	state.lock.Unlock()
+	for i := range stats {
+		stats[i].Section = Section(i)
+		visit(stats[i])
+	}
+	return loops, elapsed
+}
+
+// Reset clears the Stats of every Section.
+func Reset() {
+	state.lock.Lock()
+	state.stats = [sections]Stats{}
+	state.loops = 0
+	state.reset = time.Now()
+	state.lock.Unlock()
+}
@@ -0,0 +1,10 @@
+// Package reset reports why the microcontroller was last reset, so that a device
+// restarting unattended, e.g., overnight, may be diagnosed after the fact.
+package reset
+
+import "github.com/ardnew/weatherhub/model"
+
+// Cause returns the ResetCause reported by the microcontroller, which is only
+// valid until the next reset, i.e., it describes the reset preceding the
+// current run of the program.
+func Cause() model.ResetCause { return cause() }
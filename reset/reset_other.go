@@ -0,0 +1,10 @@
+//go:build !atsamd51
+// +build !atsamd51
+
+package reset
+
+import "github.com/ardnew/weatherhub/model"
+
+// cause returns ResetUnknown, since the reset controller of other
+// microcontrollers is not supported.
+func cause() model.ResetCause { return model.ResetUnknown }
@@ -0,0 +1,28 @@
+//go:build atsamd51
+// +build atsamd51
+
+package reset
+
+import (
+	"device/sam"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// cause returns the ResetCause of the RCAUSE register of the reset controller,
+// in which exactly one bit is set at startup.
+func cause() model.ResetCause {
+	switch r := sam.RSTC.RCAUSE.Get(); {
+	case 0 != r&sam.RSTC_RCAUSE_POR:
+		return model.ResetPowerOn
+	case 0 != r&(sam.RSTC_RCAUSE_BODCORE|sam.RSTC_RCAUSE_BODVDD):
+		return model.ResetBrownout
+	case 0 != r&sam.RSTC_RCAUSE_EXT:
+		return model.ResetExternal
+	case 0 != r&sam.RSTC_RCAUSE_WDT:
+		return model.ResetWatchdog
+	case 0 != r&sam.RSTC_RCAUSE_SYST:
+		return model.ResetSoftware
+	}
+	return model.ResetUnknown // NVM or backup
+}
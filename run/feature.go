@@ -0,0 +1,76 @@
+package run
+
+import (
+	"github.com/ardnew/weatherhub/buzzer"
+	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/wifi"
+	"github.com/ardnew/weatherhub/wifi/http"
+	"github.com/ardnew/weatherhub/wifi/mqtt"
+)
+
+// Stage orders the initialization of features, so that a feature may use the
+// services (e.g., the MQTT client) provided by the features of earlier stages.
+type Stage uint8
+
+// Constants defining each Stage, in order of initialization.
+const (
+	StageService Stage = iota // provides a service in Env
+	StageFeed                 // consumes the services in Env
+)
+
+// Env contains the resources shared by all features. Services provided by
+// features are nil unless the feature providing them was compiled in.
+type Env struct {
+	Display *display.Display
+	WiFi    *wifi.WiFi
+	Client  *http.Client
+	Broker  *mqtt.Client   // provided by the "mqtt" feature
+	Server  *http.Server   // provided by the "server" feature
+	Buzzer  *buzzer.Buzzer // provided by the "buzzer" feature
+}
+
+// Feature is an optional subsystem, compiled in unless excluded by build tag.
+// Each Feature registers itself from an init function of the file containing
+// it, so that the program only refers to the features compiled in.
+type Feature struct {
+	Name  string
+	Stage Stage
+	// Init initializes the Feature using the given Env, returning any feeds that
+	// must be synchronized by the run loop.
+	Init func(env *Env) ([]feed.Feed, error)
+}
+
+// features contains every registered Feature, in order of registration.
+var features []Feature
+
+// Register adds the given Feature to those initialized by Init.
+func Register(f Feature) {
+	features = append(features, f)
+}
+
+// Features returns the names of all registered features.
+func Features() []string {
+	name := make([]string, len(features))
+	for i, f := range features {
+		name[i] = f.Name
+	}
+	return name
+}
+
+// Init initializes every registered Feature of the given Stage, in order of
+// registration, returning all of their feeds.
+func Init(env *Env, stage Stage) ([]feed.Feed, error) {
+	var feeds []feed.Feed
+	for _, f := range features {
+		if stage != f.Stage {
+			continue
+		}
+		add, err := f.Init(env)
+		if nil != err {
+			return nil, err
+		}
+		feeds = append(feeds, add...)
+	}
+	return feeds, nil
+}
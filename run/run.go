@@ -3,14 +3,37 @@ package run
 import (
 	"time"
 
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/demo"
 	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/invariant"
+	"github.com/ardnew/weatherhub/journal"
 	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/notify"
+	"github.com/ardnew/weatherhub/profile"
 	"github.com/ardnew/weatherhub/wifi"
+	"github.com/ardnew/weatherhub/wifi/http"
 	"github.com/ardnew/weatherhub/wifi/network"
 	"github.com/ardnew/weatherhub/wifi/ntp"
 )
 
-func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP) {
+// probeInterval defines how often the network is probed for a captive portal
+// while waiting for the user to sign in.
+const probeInterval = 30 * time.Second
+
+func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP, client *http.Client, feeds ...feed.Feed) {
+
+	var probed time.Time // last time the network was probed for a captive portal
+
+	// feeds requiring no network, e.g., the buttons, are also polled while not
+	// synchronized, so that the device may be controlled while offline.
+	var offline []feed.Feed
+	for _, f := range feeds {
+		if o, ok := f.(feed.Offline); ok {
+			offline = append(offline, o)
+		}
+	}
 
 	// initial state
 	model.Set(func(m *model.Model) {
@@ -19,12 +42,17 @@ func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP) {
 
 	// main run loop
 	for {
+		profile.Loop()
+		invariant.Check(time.Now()) // only in debug builds
 		if changed, data := model.Get(); changed {
 
 			// something in the Model has changed. update the display with current
 			// Model data, and then perform any transition logic.
 
+			start := profile.Start()
 			disp.Update(data)
+			invariant.Drawn(data)
+			profile.Stop(profile.Render, start)
 			switch data.Status {
 			case model.StatusIdle, model.StatusDisconnected:
 				// transition to initiate connection
@@ -33,15 +61,27 @@ func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP) {
 				})
 
 			case model.StatusConnecting:
-				// try to connect to each known AP, in order
-				for _, ap := range network.Network {
+				// try to connect to each known AP in range, by priority, until one
+				// is joined
+				for _, ap := range net.InRange(networks()) {
 					if err := net.Connect(ap); nil != err {
-						println(ap.SSID + ": " + err.Error())
+						journal.Println(ap.SSID + ": " + err.Error())
 					} else {
-						// no error, we successfully connected
+						// no error, we successfully connected. make sure the network
+						// does not require sign-in before trying to synchronize.
+						probed = time.Now()
+						if ap.Unsecured() {
+							journal.Println(ap.SSID + ": unsecured network")
+						}
+						status := model.StatusUnsynchronized
+						if captive(client) {
+							status = model.StatusCaptive
+						}
 						model.Set(func(m *model.Model) {
-							m.Status = model.StatusUnsynchronized
+							m.Status = status
+							m.Diagnostics.Connects++
 						})
+						break
 					}
 				}
 
@@ -49,7 +89,7 @@ func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP) {
 				// try to synchronize system time with NTP server
 				model.Mod(func(m *model.Model) { m.Retry = 0 })
 				if err := host.Sync(); nil != err {
-					println("error: " + err.Error())
+					journal.Println("error: " + err.Error())
 				} else {
 					// no error, transition to synchronized state
 					model.Set(func(m *model.Model) {
@@ -60,11 +100,21 @@ func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP) {
 			case model.StatusSynchronized:
 				// synchronize Model time with current system time.
 				if err := host.Sync(); nil != err {
-					println("error: " + err.Error())
+					journal.Println("error: " + err.Error())
 					// caught an error, transition back to unsynchronized state
 					model.Set(func(m *model.Model) {
 						m.Status = model.StatusUnsynchronized
 					})
+				} else {
+					// time is valid, poll any supplementary data feeds.
+					syncFeeds(feeds)
+					notify.Update(time.Now())
+				}
+
+			case model.StatusDemo:
+				// show synthetic data, advancing through the pages (see package demo).
+				if demo.Step(time.Now()) {
+					disp.NextPage()
 				}
 			}
 
@@ -74,12 +124,37 @@ func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP) {
 			// previous iteration. perform any idle or maintenance logic.
 			// do NOT update the display.
 
+			// the connection is established again if the network link has been
+			// replaced by a fallback which has since been lost, or is due to give
+			// way to an access point (see wifi.FallbackRetry).
 			switch data.Status {
+			case model.StatusCaptive, model.StatusUnsynchronized, model.StatusSynchronized:
+				if net.Reconnect() {
+					journal.Println("reconnecting")
+					model.Set(func(m *model.Model) {
+						m.Status = model.StatusDisconnected
+					})
+					data.Status = model.StatusDisconnected
+				}
+			}
+
+			switch data.Status {
+			case model.StatusCaptive:
+				// wait for the user to sign in to the network from another device.
+				if time.Since(probed) >= probeInterval {
+					probed = time.Now()
+					if !captive(client) {
+						model.Set(func(m *model.Model) {
+							m.Status = model.StatusUnsynchronized
+						})
+					}
+				}
+
 			case model.StatusUnsynchronized:
 				// retry to synchronize system time with NTP server
 				model.Mod(func(m *model.Model) { m.Retry++ })
 				if err := host.Sync(); nil != err {
-					println("error: " + err.Error())
+					journal.Println("error: " + err.Error())
 				} else {
 					// no error, transition to synchronized state
 					model.Set(func(m *model.Model) {
@@ -90,15 +165,74 @@ func Run(disp *display.Display, net *wifi.WiFi, host *ntp.NTP) {
 			case model.StatusSynchronized:
 				// synchronize Model time with current system time.
 				if err := host.Sync(); nil != err {
-					println("error: " + err.Error())
+					journal.Println("error: " + err.Error())
 					// caught an error, transition back to unsynchronized state
 					model.Set(func(m *model.Model) {
 						m.Status = model.StatusUnsynchronized
 					})
+				} else {
+					// time is valid, poll any supplementary data feeds.
+					syncFeeds(feeds)
+					notify.Update(time.Now())
+				}
+
+			case model.StatusDemo:
+				// show synthetic data, advancing through the pages (see package demo).
+				if demo.Step(time.Now()) {
+					disp.NextPage()
 				}
 			}
 		}
 
+		if model.StatusSynchronized != model.Peek().Status {
+			syncFeeds(offline)
+		}
+
+		// advance any animations on the current page
+		start := profile.Start()
+		disp.Animate(start)
+		profile.Stop(profile.Animate, start)
+
 		time.Sleep(10 * time.Millisecond)
 	}
 }
+
+// syncFeeds polls each of the given feeds. Errors are reported briefly on the
+// display but otherwise ignored, since a failing feed should not affect the
+// others. Requests deferred to conserve an API quota are expected and are not
+// reported.
+func syncFeeds(feeds []feed.Feed) {
+	defer profile.Stop(profile.Feeds, profile.Start())
+	for _, f := range feeds {
+		if err := f.Sync(); nil != err && http.ErrThrottled != err {
+			journal.Println("error: " + err.Error())
+			notify.Error("error", err)
+		}
+	}
+}
+
+// captive returns true if the network connected is behind a captive portal.
+// If the probe fails for any other reason, the network is assumed to be open, so
+// that the usual synchronization retries report the underlying problem.
+func captive(client *http.Client) bool {
+	ok, err := client.Captive()
+	if nil != err {
+		journal.Println("probe: " + err.Error())
+		return false
+	}
+	if ok {
+		journal.Println("network requires sign-in")
+	}
+	return ok
+}
+
+// networks returns the access points to try, by descending priority. Among
+// those of equal priority, the access point of the Settings, if any, is tried
+// before those of package network.
+func networks() []network.AP {
+	var ap []network.AP
+	if s := config.Get().WiFi; "" != s.SSID {
+		ap = append(ap, s)
+	}
+	return network.Order(append(ap, network.Network...))
+}
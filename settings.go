@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/ardnew/weatherhub/config"
+	"github.com/ardnew/weatherhub/feed/forecast"
+	"github.com/ardnew/weatherhub/feed/geo"
+	"github.com/ardnew/weatherhub/feed/webhook"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/run"
+)
+
+// watchSettings applies the settings adjustable at runtime to the subsystems
+// created by main whenever they change. The geolocation feed and forecast are
+// nil if weather is received from a leader device, in which case the location
+// of the leader is used instead.
+//
+// The access point of the settings is not applied here. It is tried first by
+// the run loop whenever connecting.
+func watchSettings(env *run.Env, loc *geo.Geo, fc *forecast.Forecast) {
+
+	apply := func(prev, next config.Settings) {
+		if next.Name != prev.Name {
+			env.WiFi.SetName(next.Name)
+		}
+		if next.Location != prev.Location && nil != loc {
+			l := next.Location
+			loc.Locate(l.Latitude, l.Longitude, l.City)
+			fc.Expire() // weather of the previous location is no longer relevant
+		}
+		if next.Units != prev.Units {
+			env.Display.SetUnits(next.Units)
+		}
+		if next.Locale != prev.Locale {
+			env.Display.SetLocale(next.Locale)
+		}
+		if strings.Join(next.Hidden, ",") != strings.Join(prev.Hidden, ",") {
+			if err := env.Display.SetHidden(next.Hidden); nil != err {
+				journal.Println("error: " + err.Error())
+			}
+		}
+		if next.Theme != prev.Theme {
+			if err := env.Display.SetTheme(next.Theme); nil != err {
+				journal.Println("error: " + err.Error())
+			}
+		}
+		if next.Sleep != prev.Sleep {
+			env.Display.SetSleep(next.Sleep.From, next.Sleep.Until)
+		}
+		if !sameClocks(next.Clocks, prev.Clocks) {
+			if err := env.Display.SetClocks(next.Clocks); nil != err {
+				journal.Println("error: " + err.Error())
+			}
+		}
+	}
+	config.Watch(apply)
+	// apply any settings restored from flash before watching began.
+	apply(config.Settings{}, config.Get())
+}
+
+// sameClocks returns true if the given lists of world clocks are equal.
+func sameClocks(a, b []model.WorldClock) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookConfig returns the hooks given by the Settings, omitting any of an
+// unknown event.
+func webhookConfig(s config.Settings) webhook.Config {
+	var c webhook.Config
+	for _, h := range s.Webhooks {
+		event, ok := webhook.ParseEvent(h.Event)
+		if !ok {
+			journal.Println("error: webhook: unknown event: " + h.Event)
+			continue
+		}
+		c.Hooks = append(c.Hooks, webhook.Hook{
+			Event:     event,
+			URL:       h.URL,
+			Body:      h.Body,
+			Threshold: h.Threshold,
+		})
+	}
+	return c
+}
+
+// sameWebhooks returns true if the given lists of webhooks are equal.
+func sameWebhooks(a, b []config.Webhook) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
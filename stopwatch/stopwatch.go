@@ -0,0 +1,69 @@
+// Package stopwatch controls the Stopwatch of the Model, which may be operated
+// by the buttons of the device, console commands, or the HTTP API alike.
+package stopwatch
+
+import (
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// Start starts the stopwatch, if it is not already running.
+func Start() {
+	now := time.Now()
+	model.Set(func(m *model.Model) {
+		if !m.Stopwatch.Running {
+			m.Stopwatch.Running, m.Stopwatch.Start = true, now
+		}
+	})
+}
+
+// Stop stops the stopwatch, if it is running, retaining the elapsed time.
+func Stop() {
+	now := time.Now()
+	model.Set(func(m *model.Model) {
+		if m.Stopwatch.Running {
+			m.Stopwatch.Elapsed = m.Stopwatch.At(now)
+			m.Stopwatch.Running = false
+		}
+	})
+}
+
+// Toggle starts the stopwatch if it is stopped, or stops it if it is running.
+func Toggle() {
+	if model.Peek().Stopwatch.Running {
+		Stop()
+	} else {
+		Start()
+	}
+}
+
+// Lap records the elapsed time as the end of the current lap, if the stopwatch
+// is running.
+func Lap() {
+	now := time.Now()
+	model.Set(func(m *model.Model) {
+		s := &m.Stopwatch
+		if s.Running {
+			s.Split[s.Laps%model.MaxLaps] = s.At(now)
+			s.Laps++
+		}
+	})
+}
+
+// Reset stops the stopwatch and clears the elapsed time and laps.
+func Reset() {
+	model.Set(func(m *model.Model) {
+		m.Stopwatch = model.Stopwatch{}
+	})
+}
+
+// LapOrReset records a lap if the stopwatch is running, otherwise it resets the
+// stopwatch, as the second button of a stopwatch does.
+func LapOrReset() {
+	if model.Peek().Stopwatch.Running {
+		Lap()
+	} else {
+		Reset()
+	}
+}
@@ -0,0 +1,195 @@
+// Package theme defines the color palettes and decorations used to draw pages,
+// and selects them automatically for holidays and other special dates.
+package theme
+
+import (
+	"image/color"
+	"time"
+)
+
+// Decoration identifies a set of small sprites drawn in the empty space of the
+// clock page.
+type Decoration uint8
+
+// Constants defining each possible Decoration.
+const (
+	DecorationNone Decoration = iota
+	DecorationHearts
+	DecorationSnow
+	DecorationStars
+	DecorationPumpkins
+)
+
+// Theme defines the colors used to draw each element of the pages.
+type Theme struct {
+	Name       string
+	Time       color.RGBA // time of day
+	Weekday    color.RGBA // day of week
+	Date       color.RGBA // month and day
+	Title      color.RGBA // page titles
+	Text       color.RGBA // labels
+	Value      color.RGBA // quantities, such as scores or countdowns
+	Ticker     color.RGBA // news ticker
+	Decoration Decoration
+}
+
+// Default defines the Theme used on ordinary days.
+var Default = Theme{
+	Name:    "default",
+	Time:    color.RGBA{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF},
+	Weekday: color.RGBA{R: 0x00, G: 0xFF, B: 0xFF, A: 0xFF},
+	Date:    color.RGBA{R: 0x00, G: 0x00, B: 0xFF, A: 0xFF},
+	Title:   color.RGBA{R: 0x00, G: 0xFF, B: 0xFF, A: 0xFF},
+	Text:    color.RGBA{R: 0xFF, G: 0xFF, B: 0x00, A: 0xFF},
+	Value:   color.RGBA{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF},
+	Ticker:  color.RGBA{R: 0xFF, G: 0xA0, B: 0x00, A: 0xFF},
+}
+
+// Night defines a Theme entirely in dim red, which preserves night vision and
+// disturbs sleep less than other colors, e.g., for a bedroom or theater.
+var Night = Theme{
+	Name:    "night",
+	Time:    color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+	Weekday: color.RGBA{R: 0xA0, G: 0x00, B: 0x00, A: 0xFF},
+	Date:    color.RGBA{R: 0xA0, G: 0x00, B: 0x00, A: 0xFF},
+	Title:   color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+	Text:    color.RGBA{R: 0xA0, G: 0x00, B: 0x00, A: 0xFF},
+	Value:   color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+	Ticker:  color.RGBA{R: 0xA0, G: 0x00, B: 0x00, A: 0xFF},
+}
+
+// Date identifies a day of the year that uses a special Theme.
+type Date struct {
+	Month time.Month
+	Day   int
+	Theme Theme
+}
+
+// Holidays defines the built-in special dates.
+var Holidays = []Date{
+	{Month: time.January, Day: 1, Theme: Theme{
+		Name:       "new-year",
+		Time:       color.RGBA{R: 0xFF, G: 0xD0, B: 0x00, A: 0xFF},
+		Weekday:    color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Date:       color.RGBA{R: 0xFF, G: 0xD0, B: 0x00, A: 0xFF},
+		Title:      color.RGBA{R: 0xFF, G: 0xD0, B: 0x00, A: 0xFF},
+		Text:       color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Value:      color.RGBA{R: 0xFF, G: 0xD0, B: 0x00, A: 0xFF},
+		Ticker:     color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Decoration: DecorationStars,
+	}},
+	{Month: time.February, Day: 14, Theme: Theme{
+		Name:       "valentine",
+		Time:       color.RGBA{R: 0xFF, G: 0x00, B: 0x40, A: 0xFF},
+		Weekday:    color.RGBA{R: 0xFF, G: 0x60, B: 0xC0, A: 0xFF},
+		Date:       color.RGBA{R: 0xFF, G: 0x00, B: 0x40, A: 0xFF},
+		Title:      color.RGBA{R: 0xFF, G: 0x60, B: 0xC0, A: 0xFF},
+		Text:       color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Value:      color.RGBA{R: 0xFF, G: 0x00, B: 0x40, A: 0xFF},
+		Ticker:     color.RGBA{R: 0xFF, G: 0x60, B: 0xC0, A: 0xFF},
+		Decoration: DecorationHearts,
+	}},
+	{Month: time.July, Day: 4, Theme: Theme{
+		Name:       "independence",
+		Time:       color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+		Weekday:    color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Date:       color.RGBA{R: 0x00, G: 0x40, B: 0xFF, A: 0xFF},
+		Title:      color.RGBA{R: 0x00, G: 0x40, B: 0xFF, A: 0xFF},
+		Text:       color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Value:      color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+		Ticker:     color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Decoration: DecorationStars,
+	}},
+	{Month: time.October, Day: 31, Theme: Theme{
+		Name:       "halloween",
+		Time:       color.RGBA{R: 0xFF, G: 0x60, B: 0x00, A: 0xFF},
+		Weekday:    color.RGBA{R: 0x90, G: 0x00, B: 0xFF, A: 0xFF},
+		Date:       color.RGBA{R: 0xFF, G: 0x60, B: 0x00, A: 0xFF},
+		Title:      color.RGBA{R: 0x90, G: 0x00, B: 0xFF, A: 0xFF},
+		Text:       color.RGBA{R: 0xFF, G: 0x60, B: 0x00, A: 0xFF},
+		Value:      color.RGBA{R: 0x40, G: 0xFF, B: 0x00, A: 0xFF},
+		Ticker:     color.RGBA{R: 0xFF, G: 0x60, B: 0x00, A: 0xFF},
+		Decoration: DecorationPumpkins,
+	}},
+	{Month: time.December, Day: 25, Theme: Theme{
+		Name:       "christmas",
+		Time:       color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+		Weekday:    color.RGBA{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF},
+		Date:       color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+		Title:      color.RGBA{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF},
+		Text:       color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Value:      color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF},
+		Ticker:     color.RGBA{R: 0x00, G: 0xFF, B: 0x00, A: 0xFF},
+		Decoration: DecorationSnow,
+	}},
+}
+
+// Config defines the Theme used on ordinary days and any additional special
+// dates. Dates configured here take precedence over the built-in Holidays.
+type Config struct {
+	Base       Theme // Default if Name is empty
+	Dates      []Date
+	NoHolidays bool // do not use the built-in Holidays
+}
+
+// Selector chooses the Theme in effect on a given day.
+type Selector struct {
+	config Config
+}
+
+// New returns a new Selector using the given configuration.
+func New(config Config) *Selector {
+
+	if config.Base.Name == "" {
+		config.Base = Default
+	}
+
+	return &Selector{config: config}
+}
+
+// For returns the Theme in effect on the date of the given time.
+// The returned Theme must not be modified.
+func (s *Selector) For(t time.Time) *Theme {
+	_, month, day := t.Date()
+	for i := range s.config.Dates {
+		if d := &s.config.Dates[i]; d.Month == month && d.Day == day {
+			return &d.Theme
+		}
+	}
+	if !s.config.NoHolidays {
+		for i := range Holidays {
+			if d := &Holidays[i]; d.Month == month && d.Day == day {
+				return &d.Theme
+			}
+		}
+	}
+	return &s.config.Base
+}
+
+// Lookup returns the built-in Theme with the given name, which is either that of
+// Default, Night, or one of the Holidays.
+func Lookup(name string) (Theme, bool) {
+	if name == Default.Name {
+		return Default, true
+	}
+	if name == Night.Name {
+		return Night, true
+	}
+	for _, d := range Holidays {
+		if name == d.Theme.Name {
+			return d.Theme, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Names returns the names of the built-in themes, in the order of Default and
+// Night followed by each of the Holidays.
+func Names() []string {
+	name := make([]string, 0, 2+len(Holidays))
+	name = append(name, Default.Name, Night.Name)
+	for _, d := range Holidays {
+		name = append(name, d.Theme.Name)
+	}
+	return name
+}
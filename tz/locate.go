@@ -0,0 +1,96 @@
+package tz
+
+// region is a coarse bounding box mapped to a time zone.
+type region struct {
+	south, north float64 // latitude bounds, degrees
+	west, east   float64 // longitude bounds, degrees
+	zone         string
+}
+
+// regions approximates the shapes of the zones in the database with bounding
+// boxes. Boxes are tested in order, from most to least specific, so that
+// smaller exceptions (e.g., Arizona, Ireland, or Korea) precede the larger
+// regions overlapping them, and a zone may need several boxes to follow an
+// irregular border. Positions near a border may still resolve to a
+// neighboring zone.
+var regions = []region{
+	// North America
+	{51, 72, -170, -141, "America/Anchorage"},
+	{54.5, 60, -141, -130, "America/Anchorage"}, // panhandle
+	{18, 23, -161, -154, "Pacific/Honolulu"},
+	{31, 37, -115, -109, "America/Phoenix"},
+	{42, 45.5, -117, -111, "America/Denver"}, // southern Idaho
+	{49, 60, -139, -120, "America/Vancouver"},
+	{49, 60, -120, -110, "America/Edmonton"},
+	{49, 60, -102, -89, "America/Winnipeg"},
+	{41.7, 46, -83.5, -74, "America/Toronto"}, // southern Ontario
+	{46, 57, -90, -74, "America/Toronto"},
+	{43, 60, -67, -59.5, "America/Halifax"},
+	{30, 37, -87.5, -85.5, "America/Chicago"}, // Alabama, Middle Tennessee
+	{32, 49, -125, -114.5, "America/Los_Angeles"},
+	{31, 49, -114.5, -101.5, "America/Denver"},
+	{25.8, 49, -101.5, -87.5, "America/Chicago"},
+	{24, 48, -87.5, -66, "America/New_York"},
+	{14, 32, -118, -86, "America/Mexico_City"},
+	// South America
+	{-5, 13, -80, -66, "America/Bogota"},
+	{-56, -22, -74, -53, "America/Argentina/Buenos_Aires"},
+	{-34, 5, -74, -34, "America/Sao_Paulo"},
+	// Europe
+	{54.05, 55.3, -7.6, -5.4, "Europe/London"}, // Northern Ireland
+	{51.4, 55.4, -10.7, -6, "Europe/Dublin"},
+	{49.9, 61, -8, 1.8, "Europe/London"},
+	{36.5, 42.5, -10, -6.2, "Europe/Lisbon"},
+	{36, 43.8, -6.2, -1.8, "Europe/Madrid"},
+	{36, 42.8, -1.8, 4.5, "Europe/Madrid"}, // south of the Pyrenees
+	{50.5, 54, 3, 7.5, "Europe/Amsterdam"},
+	{42, 51, -5, 7.5, "Europe/Paris"},
+	{36, 47, 7, 19, "Europe/Rome"},
+	{59.5, 70, 21, 31.5, "Europe/Helsinki"},
+	{55, 69, 10.5, 24, "Europe/Stockholm"},
+	{49, 55, 14, 24, "Europe/Warsaw"},
+	{45, 55, 5.5, 17, "Europe/Berlin"},
+	{34.5, 42, 19.5, 26.7, "Europe/Athens"},
+	{35.8, 37.5, 26.7, 28.3, "Europe/Athens"}, // Dodecanese
+	{44, 52.5, 22, 40.5, "Europe/Kiev"},
+	{36, 42.5, 26, 45, "Europe/Istanbul"},
+	{41, 70, 27, 60, "Europe/Moscow"},
+	// Africa
+	{-35, -22, 16, 33, "Africa/Johannesburg"},
+	{4, 14, 2.5, 15, "Africa/Lagos"},
+	// Asia
+	{22, 27, 51, 57, "Asia/Dubai"},
+	{1, 2, 103.5, 104.5, "Asia/Singapore"},
+	{6, 24, 68, 92, "Asia/Kolkata"},
+	{24, 31, 68, 89, "Asia/Kolkata"},   // north of the Tropic, south of Tibet
+	{31, 35.5, 73, 80, "Asia/Kolkata"}, // Punjab, Kashmir
+	{5, 21, 97.5, 110, "Asia/Bangkok"},
+	{21, 23.4, 102, 106.8, "Asia/Bangkok"}, // northern Vietnam, Laos
+	{33, 38.7, 124.5, 129.6, "Asia/Seoul"},
+	{37.7, 40, 124.5, 128.5, "Asia/Seoul"}, // Pyongyang
+	{30, 41.6, 129.6, 142.1, "Asia/Tokyo"}, // Kyushu, Shikoku, Honshu
+	{41.3, 45.6, 139.3, 146, "Asia/Tokyo"}, // Hokkaido
+	{24, 30, 122.9, 131.5, "Asia/Tokyo"},   // Ryukyu Islands
+	{18, 53.5, 73.5, 131.3, "Asia/Shanghai"},
+	{45, 48.5, 131.3, 134.8, "Asia/Shanghai"}, // east of Harbin
+	// Oceania
+	{-35, -13, 112, 129, "Australia/Perth"},
+	{-26, -10, 129, 138, "Australia/Darwin"},
+	{-38, -26, 129, 141, "Australia/Adelaide"},
+	{-29, -10, 138, 154, "Australia/Brisbane"},
+	{-39.2, -34, 140.9, 150, "Australia/Melbourne"},
+	{-44, -28, 141, 154, "Australia/Sydney"},
+	{-48, -34, 166, 179, "Pacific/Auckland"},
+}
+
+// Locate returns the Zone containing the given position, in degrees, if it
+// falls within one of the coarse regions known to the database.
+func Locate(latitude, longitude float64) (*Zone, bool) {
+	for _, r := range regions {
+		if latitude >= r.south && latitude <= r.north &&
+			longitude >= r.west && longitude <= r.east {
+			return Lookup(r.zone)
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,111 @@
+package tz
+
+import "testing"
+
+func TestLocate(t *testing.T) {
+	for _, x := range []struct {
+		city     string
+		lat, lon float64
+		zone     string // empty if outside every region
+	}{
+		{"Anchorage", 61.22, -149.90, "America/Anchorage"},
+		{"Juneau", 58.30, -134.42, "America/Anchorage"},
+		{"Phoenix", 33.45, -112.07, "America/Phoenix"},
+		{"Boise", 43.62, -116.21, "America/Denver"},
+		{"Seattle", 47.61, -122.33, "America/Los_Angeles"},
+		{"Vancouver", 49.28, -123.12, "America/Vancouver"},
+		{"Calgary", 51.05, -114.07, "America/Edmonton"},
+		{"Regina", 50.45, -104.61, ""},
+		{"Winnipeg", 49.90, -97.14, "America/Winnipeg"},
+		{"Milwaukee", 43.04, -87.91, "America/Chicago"},
+		{"Chicago", 41.88, -87.63, "America/Chicago"},
+		{"Nashville", 36.16, -86.78, "America/Chicago"},
+		{"Birmingham", 33.52, -86.80, "America/Chicago"},
+		{"Chattanooga", 35.05, -85.31, "America/New_York"},
+		{"Atlanta", 33.75, -84.39, "America/New_York"},
+		{"Detroit", 42.33, -83.05, "America/Toronto"},
+		{"Toronto", 43.65, -79.38, "America/Toronto"},
+		{"Thunder Bay", 48.38, -89.25, "America/Toronto"},
+		{"New York", 40.71, -74.01, "America/New_York"},
+		{"Halifax", 44.65, -63.58, "America/Halifax"},
+		{"St. John's", 47.56, -52.71, ""},
+		{"Denver", 39.74, -104.99, "America/Denver"},
+		{"Houston", 29.76, -95.37, "America/Chicago"},
+		{"Monterrey", 25.67, -100.31, "America/Mexico_City"},
+		{"Mexico City", 19.43, -99.13, "America/Mexico_City"},
+		{"London", 51.51, -0.13, "Europe/London"},
+		{"Belfast", 54.60, -5.93, "Europe/London"},
+		{"Edinburgh", 55.95, -3.19, "Europe/London"},
+		{"Dublin", 53.35, -6.26, "Europe/Dublin"},
+		{"Cork", 51.90, -8.47, "Europe/Dublin"},
+		{"Calais", 50.95, 1.86, "Europe/Paris"},
+		{"Paris", 48.86, 2.35, "Europe/Paris"},
+		{"Toulouse", 43.60, 1.44, "Europe/Paris"},
+		{"Madrid", 40.42, -3.70, "Europe/Madrid"},
+		{"Barcelona", 41.39, 2.17, "Europe/Madrid"},
+		{"San Sebastián", 43.32, -1.98, "Europe/Madrid"},
+		{"Lisbon", 38.72, -9.14, "Europe/Lisbon"},
+		{"Amsterdam", 52.37, 4.90, "Europe/Amsterdam"},
+		{"Berlin", 52.52, 13.40, "Europe/Berlin"},
+		{"Rome", 41.90, 12.50, "Europe/Rome"},
+		{"Stockholm", 59.33, 18.07, "Europe/Stockholm"},
+		{"Vaasa", 63.10, 21.62, "Europe/Helsinki"},
+		{"Helsinki", 60.17, 24.94, "Europe/Helsinki"},
+		{"Warsaw", 52.23, 21.01, "Europe/Warsaw"},
+		{"Athens", 37.98, 23.73, "Europe/Athens"},
+		{"Izmir", 38.42, 27.14, "Europe/Istanbul"},
+		{"Istanbul", 41.01, 28.98, "Europe/Istanbul"},
+		{"Kyiv", 50.45, 30.52, "Europe/Kiev"},
+		{"Moscow", 55.76, 37.62, "Europe/Moscow"},
+		{"Johannesburg", -26.20, 28.05, "Africa/Johannesburg"},
+		{"Lagos", 6.52, 3.38, "Africa/Lagos"},
+		{"Dubai", 25.20, 55.27, "Asia/Dubai"},
+		{"Mumbai", 19.08, 72.88, "Asia/Kolkata"},
+		{"Delhi", 28.61, 77.21, "Asia/Kolkata"},
+		{"Lhasa", 29.65, 91.12, "Asia/Shanghai"},
+		{"Singapore", 1.35, 103.82, "Asia/Singapore"},
+		{"Bangkok", 13.76, 100.50, "Asia/Bangkok"},
+		{"Hanoi", 21.03, 105.85, "Asia/Bangkok"},
+		{"Shanghai", 31.23, 121.47, "Asia/Shanghai"},
+		{"Beijing", 39.90, 116.41, "Asia/Shanghai"},
+		{"Seoul", 37.57, 126.98, "Asia/Seoul"},
+		{"Busan", 35.18, 129.08, "Asia/Seoul"},
+		{"Pyongyang", 39.04, 125.76, "Asia/Seoul"},
+		{"Harbin", 45.80, 126.53, "Asia/Shanghai"},
+		{"Fukuoka", 33.59, 130.40, "Asia/Tokyo"},
+		{"Hiroshima", 34.39, 132.46, "Asia/Tokyo"},
+		{"Tokyo", 35.68, 139.69, "Asia/Tokyo"},
+		{"Sapporo", 43.06, 141.35, "Asia/Tokyo"},
+		{"Naha", 26.21, 127.68, "Asia/Tokyo"},
+		{"Vladivostok", 43.12, 131.89, ""},
+		{"Perth", -31.95, 115.86, "Australia/Perth"},
+		{"Darwin", -12.46, 130.84, "Australia/Darwin"},
+		{"Adelaide", -34.93, 138.60, "Australia/Adelaide"},
+		{"Brisbane", -27.47, 153.03, "Australia/Brisbane"},
+		{"Melbourne", -37.81, 144.96, "Australia/Melbourne"},
+		{"Sydney", -33.87, 151.21, "Australia/Sydney"},
+		{"Auckland", -36.85, 174.76, "Pacific/Auckland"},
+	} {
+		z, ok := Locate(x.lat, x.lon)
+		switch {
+		case "" == x.zone && ok:
+			t.Errorf("%s: Locate = %s, want none", x.city, z.Name)
+		case "" != x.zone && !ok:
+			t.Errorf("%s: Locate = none, want %s", x.city, x.zone)
+		case ok && x.zone != z.Name:
+			t.Errorf("%s: Locate = %s, want %s", x.city, z.Name, x.zone)
+		}
+	}
+}
+
+// TestRegions checks that every region maps to a zone of the database.
+func TestRegions(t *testing.T) {
+	for _, r := range regions {
+		if _, ok := Lookup(r.zone); !ok {
+			t.Errorf("region %v: unknown zone", r)
+		}
+		if r.south >= r.north || r.west >= r.east {
+			t.Errorf("region %v: empty", r)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package tz implements a small database of time zones and their daylight
+// saving time rules.
+//
+// TinyGo does not include the IANA time zone database, and it would not fit in
+// flash anyway. Instead, this package covers the zones of the most populous
+// regions with the few rules they have in common, which is enough to compute
+// the local time offset at any instant without network access.
+package tz
+
+import "time"
+
+// Rule identifies the daylight saving time rule observed by a Zone.
+type Rule uint8
+
+// Constants defining each possible Rule.
+const (
+	RuleNone Rule = iota // no daylight saving time
+	RuleUS               // 2nd Sunday March 02:00 to 1st Sunday November 02:00 local
+	RuleEU               // last Sunday March to last Sunday October, 01:00 UTC
+	RuleAU               // 1st Sunday October 02:00 to 1st Sunday April 03:00 local
+	RuleNZ               // last Sunday September 02:00 to 1st Sunday April 03:00 local
+)
+
+// Zone describes a time zone.
+type Zone struct {
+	Name    string // IANA time zone name
+	Abbr    string // abbreviation of standard time
+	DSTAbbr string // abbreviation of daylight saving time
+	Offset  int    // standard time offset, in seconds east of UTC
+	Rule    Rule
+}
+
+const hour = 60 * 60
+
+// Zones defines all zones in the database.
+var Zones = []Zone{
+	{Name: "UTC", Abbr: "UTC", Offset: 0},
+	{Name: "America/New_York", Abbr: "EST", DSTAbbr: "EDT", Offset: -5 * hour, Rule: RuleUS},
+	{Name: "America/Toronto", Abbr: "EST", DSTAbbr: "EDT", Offset: -5 * hour, Rule: RuleUS},
+	{Name: "America/Chicago", Abbr: "CST", DSTAbbr: "CDT", Offset: -6 * hour, Rule: RuleUS},
+	{Name: "America/Winnipeg", Abbr: "CST", DSTAbbr: "CDT", Offset: -6 * hour, Rule: RuleUS},
+	{Name: "America/Denver", Abbr: "MST", DSTAbbr: "MDT", Offset: -7 * hour, Rule: RuleUS},
+	{Name: "America/Edmonton", Abbr: "MST", DSTAbbr: "MDT", Offset: -7 * hour, Rule: RuleUS},
+	{Name: "America/Phoenix", Abbr: "MST", Offset: -7 * hour},
+	{Name: "America/Los_Angeles", Abbr: "PST", DSTAbbr: "PDT", Offset: -8 * hour, Rule: RuleUS},
+	{Name: "America/Vancouver", Abbr: "PST", DSTAbbr: "PDT", Offset: -8 * hour, Rule: RuleUS},
+	{Name: "America/Anchorage", Abbr: "AKST", DSTAbbr: "AKDT", Offset: -9 * hour, Rule: RuleUS},
+	{Name: "America/Halifax", Abbr: "AST", DSTAbbr: "ADT", Offset: -4 * hour, Rule: RuleUS},
+	{Name: "Pacific/Honolulu", Abbr: "HST", Offset: -10 * hour},
+	{Name: "America/Mexico_City", Abbr: "CST", Offset: -6 * hour},
+	{Name: "America/Bogota", Abbr: "-05", Offset: -5 * hour},
+	{Name: "America/Sao_Paulo", Abbr: "-03", Offset: -3 * hour},
+	{Name: "America/Argentina/Buenos_Aires", Abbr: "-03", Offset: -3 * hour},
+	{Name: "Europe/London", Abbr: "GMT", DSTAbbr: "BST", Offset: 0, Rule: RuleEU},
+	{Name: "Europe/Dublin", Abbr: "GMT", DSTAbbr: "IST", Offset: 0, Rule: RuleEU},
+	{Name: "Europe/Lisbon", Abbr: "WET", DSTAbbr: "WEST", Offset: 0, Rule: RuleEU},
+	{Name: "Europe/Paris", Abbr: "CET", DSTAbbr: "CEST", Offset: 1 * hour, Rule: RuleEU},
+	{Name: "Europe/Berlin", Abbr: "CET", DSTAbbr: "CEST", Offset: 1 * hour, Rule: RuleEU},
+	{Name: "Europe/Madrid", Abbr: "CET", DSTAbbr: "CEST", Offset: 1 * hour, Rule: RuleEU},
+	{Name: "Europe/Rome", Abbr: "CET", DSTAbbr: "CEST", Offset: 1 * hour, Rule: RuleEU},
+	{Name: "Europe/Amsterdam", Abbr: "CET", DSTAbbr: "CEST", Offset: 1 * hour, Rule: RuleEU},
+	{Name: "Europe/Stockholm", Abbr: "CET", DSTAbbr: "CEST", Offset: 1 * hour, Rule: RuleEU},
+	{Name: "Europe/Warsaw", Abbr: "CET", DSTAbbr: "CEST", Offset: 1 * hour, Rule: RuleEU},
+	{Name: "Europe/Helsinki", Abbr: "EET", DSTAbbr: "EEST", Offset: 2 * hour, Rule: RuleEU},
+	{Name: "Europe/Athens", Abbr: "EET", DSTAbbr: "EEST", Offset: 2 * hour, Rule: RuleEU},
+	{Name: "Europe/Kiev", Abbr: "EET", DSTAbbr: "EEST", Offset: 2 * hour, Rule: RuleEU},
+	{Name: "Europe/Istanbul", Abbr: "+03", Offset: 3 * hour},
+	{Name: "Europe/Moscow", Abbr: "MSK", Offset: 3 * hour},
+	{Name: "Africa/Johannesburg", Abbr: "SAST", Offset: 2 * hour},
+	{Name: "Africa/Lagos", Abbr: "WAT", Offset: 1 * hour},
+	{Name: "Asia/Dubai", Abbr: "+04", Offset: 4 * hour},
+	{Name: "Asia/Kolkata", Abbr: "IST", Offset: 5*hour + 30*60},
+	{Name: "Asia/Bangkok", Abbr: "+07", Offset: 7 * hour},
+	{Name: "Asia/Shanghai", Abbr: "CST", Offset: 8 * hour},
+	{Name: "Asia/Singapore", Abbr: "+08", Offset: 8 * hour},
+	{Name: "Australia/Perth", Abbr: "AWST", Offset: 8 * hour},
+	{Name: "Asia/Tokyo", Abbr: "JST", Offset: 9 * hour},
+	{Name: "Asia/Seoul", Abbr: "KST", Offset: 9 * hour},
+	{Name: "Australia/Adelaide", Abbr: "ACST", DSTAbbr: "ACDT", Offset: 9*hour + 30*60, Rule: RuleAU},
+	{Name: "Australia/Darwin", Abbr: "ACST", Offset: 9*hour + 30*60},
+	{Name: "Australia/Brisbane", Abbr: "AEST", Offset: 10 * hour},
+	{Name: "Australia/Sydney", Abbr: "AEST", DSTAbbr: "AEDT", Offset: 10 * hour, Rule: RuleAU},
+	{Name: "Australia/Melbourne", Abbr: "AEST", DSTAbbr: "AEDT", Offset: 10 * hour, Rule: RuleAU},
+	{Name: "Pacific/Auckland", Abbr: "NZST", DSTAbbr: "NZDT", Offset: 12 * hour, Rule: RuleNZ},
+}
+
+// Lookup returns the Zone with the given IANA name.
+func Lookup(name string) (*Zone, bool) {
+	for i := range Zones {
+		if name == Zones[i].Name {
+			return &Zones[i], true
+		}
+	}
+	return nil, false
+}
+
+// At returns the abbreviation and offset (in seconds east of UTC) of the local
+// time in effect at instant t.
+func (z *Zone) At(t time.Time) (abbr string, offset int) {
+	if z.isDST(t.UTC()) {
+		return z.DSTAbbr, z.Offset + hour
+	}
+	return z.Abbr, z.Offset
+}
+
+// Location returns a fixed time.Location with the offset in effect at instant t.
+// Since the offset changes at DST transitions, a new Location must be obtained
+// periodically.
+func (z *Zone) Location(t time.Time) *time.Location {
+	abbr, offset := z.At(t)
+	return time.FixedZone(abbr, offset)
+}
+
+// isDST returns true if daylight saving time is in effect at UTC time t.
+func (z *Zone) isDST(t time.Time) bool {
+	year := t.Year()
+	// transitions given in local standard time are converted to UTC by
+	// subtracting the standard offset.
+	std := time.Duration(z.Offset) * time.Second
+	switch z.Rule {
+	case RuleUS:
+		start := nthSunday(year, time.March, 2).Add(2*time.Hour - std)
+		end := nthSunday(year, time.November, 1).Add(1*time.Hour - std)
+		return !t.Before(start) && t.Before(end)
+	case RuleEU:
+		start := lastSunday(year, time.March).Add(1 * time.Hour)
+		end := lastSunday(year, time.October).Add(1 * time.Hour)
+		return !t.Before(start) && t.Before(end)
+	case RuleAU:
+		// southern hemisphere: DST spans the new year
+		end := nthSunday(year, time.April, 1).Add(2*time.Hour - std)
+		start := nthSunday(year, time.October, 1).Add(2*time.Hour - std)
+		return t.Before(end) || !t.Before(start)
+	case RuleNZ:
+		end := nthSunday(year, time.April, 1).Add(2*time.Hour - std)
+		start := lastSunday(year, time.September).Add(2*time.Hour - std)
+		return t.Before(end) || !t.Before(start)
+	}
+	return false
+}
+
+// nthSunday returns midnight (as if UTC) of the n'th Sunday of the given month.
+func nthSunday(year int, month time.Month, n int) time.Time {
+	t := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	days := (7 - int(t.Weekday())) % 7
+	return t.AddDate(0, 0, days+7*(n-1))
+}
+
+// lastSunday returns midnight (as if UTC) of the last Sunday of the given month.
+func lastSunday(year int, month time.Month) time.Time {
+	t := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC) // last day of month
+	return t.AddDate(0, 0, -int(t.Weekday()))
+}
@@ -4,11 +4,38 @@ import (
 	"errors"
 	"time"
 
-	"tinygo.org/x/drivers/rgb75"
-
+	"github.com/ardnew/weatherhub/config"
 	"github.com/ardnew/weatherhub/display"
+	"github.com/ardnew/weatherhub/feed"
+	"github.com/ardnew/weatherhub/feed/accuracy"
+	"github.com/ardnew/weatherhub/feed/advisory"
+	"github.com/ardnew/weatherhub/feed/air"
+	"github.com/ardnew/weatherhub/feed/announce"
+	"github.com/ardnew/weatherhub/feed/aurora"
+	"github.com/ardnew/weatherhub/feed/bands"
+	"github.com/ardnew/weatherhub/feed/degreeday"
+	"github.com/ardnew/weatherhub/feed/forecast"
+	"github.com/ardnew/weatherhub/feed/garden"
+	"github.com/ardnew/weatherhub/feed/geo"
+	"github.com/ardnew/weatherhub/feed/ping"
+	"github.com/ardnew/weatherhub/feed/rss"
+	"github.com/ardnew/weatherhub/feed/satellite"
+	"github.com/ardnew/weatherhub/feed/scene"
+	"github.com/ardnew/weatherhub/feed/schedule"
+	"github.com/ardnew/weatherhub/feed/scores"
+	"github.com/ardnew/weatherhub/feed/share"
+	"github.com/ardnew/weatherhub/feed/ski"
+	"github.com/ardnew/weatherhub/feed/snowday"
+	"github.com/ardnew/weatherhub/feed/summary"
+	"github.com/ardnew/weatherhub/feed/surf"
+	"github.com/ardnew/weatherhub/feed/transit"
+	"github.com/ardnew/weatherhub/feed/webhook"
+	"github.com/ardnew/weatherhub/journal"
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/reset"
 	"github.com/ardnew/weatherhub/run"
 	"github.com/ardnew/weatherhub/wifi"
+	"github.com/ardnew/weatherhub/wifi/http"
 	"github.com/ardnew/weatherhub/wifi/ntp"
 )
 
@@ -16,26 +43,115 @@ var (
 	ErrNotConnected = errors.New("could not connect to any preferred access point")
 )
 
+// version identifies the firmware, and may be set when building, e.g., with
+// -ldflags "-X main.version=1.4.0".
+var version = "dev"
+
 func main() {
+	// record when the device started, from which its uptime is measured, and why
+	// it was reset, e.g., by the watchdog.
+	cause := reset.Cause()
+	model.Mod(func(m *model.Model) {
+		m.Diagnostics.Boot = time.Now()
+		m.Diagnostics.Reset = cause
+	})
+	journal.Println("reset: " + cause.String())
 	// initialize the HUB75 display
-	disp, err := display.New(rgb75.Config{})
+	disp, err := display.New(display.Config{})
 	if nil != err {
-		halt(err)
+		halt(faultDisplay, err)
 	}
 	// initialize the network interface
-	net, err := wifi.New()
+	net, err := wifi.New(wifi.Config{})
 	if nil != err {
-		halt(err)
+		halt(faultNetwork, err)
+	}
+	// offset the periodic polls of this device from those of any others, which
+	// might otherwise poll in lockstep after being powered on together.
+	if mac, err := net.MAC(); nil == err {
+		feed.Seed(mac)
 	}
 	// initialize the NTP client
 	host := ntp.New(net, ntp.Config{})
-	// enter state machine
-	run.Run(disp, net, host)
-}
-
-func halt(err error) {
-	for {
-		println("error: " + err.Error())
-		time.Sleep(time.Second)
+	// initialize the HTTP client used by all data feeds
+	client := http.New(net, http.Config{})
+	// initialize the services of optional features, which are selected by build
+	// tags (see README.md)
+	env := &run.Env{Display: disp, WiFi: net, Client: client}
+	feeds, err := run.Init(env, run.StageService)
+	if nil != err {
+		halt(faultFeature, err)
 	}
+	// weather may be shared among several devices in one house, in which case
+	// only the leader polls the geolocation and forecast services.
+	shared, err := share.New(env.Broker, share.Config{})
+	if nil != err {
+		halt(faultFeature, err)
+	}
+	feeds = append(feeds, shared)
+	var (
+		loc *geo.Geo
+		fc  *forecast.Forecast
+	)
+	if !shared.Following() {
+		if loc, err = geo.New(client, geo.Config{}); nil != err {
+			halt(faultFeature, err)
+		}
+		fc = forecast.New(client, forecast.Config{})
+		// accuracy follows forecast, so that other feeds use any correction.
+		feeds = append(feeds, loc, fc, accuracy.New(accuracy.Config{}))
+	}
+	// sun-relative events chime on the buzzer, if the "buzzer" feature provides it.
+	events, err := schedule.New(env.Buzzer, schedule.Config{})
+	if nil != err {
+		halt(faultFeature, err)
+	}
+	// the summary is composed in the units and language of the settings.
+	settings := config.Get()
+	daily := summary.New(client, env.Broker, summary.Config{Units: settings.Units, Language: settings.Language})
+	config.Watch(func(prev, next config.Settings) {
+		if next.Units != prev.Units || next.Language != prev.Language {
+			daily.SetLocale(next.Units, next.Language)
+		}
+	})
+	// webhooks are requested as configured by the settings.
+	hooks := webhook.New(client, webhookConfig(settings))
+	config.Watch(func(prev, next config.Settings) {
+		if !sameWebhooks(next.Webhooks, prev.Webhooks) {
+			hooks.Set(webhookConfig(next))
+		}
+	})
+	feeds = append(feeds,
+		daily,
+		advisory.New(advisory.Config{}),
+		garden.New(env.Broker, garden.Config{}),
+		degreeday.New(degreeday.Config{}),
+		snowday.New(snowday.Config{}),
+		satellite.New(client, satellite.Config{}),
+		aurora.New(client, aurora.Config{}),
+		air.New(client, air.Config{}),
+		ski.New(client, ski.Config{}),
+		surf.New(client, surf.Config{}),
+		bands.New(client, bands.Config{}),
+		events,
+		transit.New(client, transit.Config{}),
+		scores.New(client, scores.Config{}),
+		rss.New(client, rss.Config{}),
+		ping.New(net, ping.Config{}),
+		hooks,
+		announce.New(net, announce.Config{Version: version}),
+		scene.New(disp.SetScene, scene.Config{}),
+	)
+	// initialize the feeds of optional features
+	more, err := run.Init(env, run.StageFeed)
+	if nil != err {
+		halt(faultFeature, err)
+	}
+	feeds = append(feeds, more...)
+	// register the commands of the serial console and HTTP API
+	registerCommands(env, host, fc)
+	// apply the settings changed at runtime, e.g., by the configuration UI
+	watchSettings(env, loc, fc)
+	// enter state machine
+	run.Run(disp, net, host, client, feeds...)
 }
@@ -0,0 +1,83 @@
+package wifi
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/ardnew/weatherhub/profile"
+)
+
+// BusTimeout defines how long a transaction waits for the coprocessor to finish
+// any other transaction before failing with ErrBusy.
+const BusTimeout = 2 * time.Second
+
+var (
+	ErrBusy = errors.New("timeout waiting for WiFi coprocessor")
+)
+
+// bus serializes transactions with the coprocessor, each a sequence of SPI
+// commands that must not be interleaved with the commands of another.
+type bus chan struct{}
+
+func newBus() bus {
+	return make(bus, 1)
+}
+
+// do calls f once all other transactions have finished, or returns ErrBusy if
+// they do not finish within BusTimeout. The transaction performed by f must not
+// call do itself.
+func (b bus) do(f func() error) error {
+	start := time.Now()
+	for {
+		select {
+		case b <- struct{}{}:
+			defer func() { <-b }()
+			defer profile.Stop(profile.NINA, profile.Start())
+			return f()
+		default:
+		}
+		if time.Since(start) > BusTimeout {
+			return ErrBusy
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Do calls f as a single transaction with the coprocessor. It must be used by
+// other packages for any driver calls made outside of package wifi (e.g., to
+// dial sockets), so that they are not interleaved with concurrent calls.
+func (w *WiFi) Do(f func() error) error {
+	return w.bus.do(f)
+}
+
+// Serialize returns a connection that performs each Read, Write, and Close of
+// the given connection as a single transaction with the coprocessor.
+func (w *WiFi) Serialize(conn io.ReadWriteCloser) io.ReadWriteCloser {
+	return &serialConn{conn: conn, bus: w.bus}
+}
+
+type serialConn struct {
+	conn io.ReadWriteCloser
+	bus  bus
+}
+
+func (s *serialConn) Read(b []byte) (n int, err error) {
+	err = s.bus.do(func() error {
+		n, err = s.conn.Read(b)
+		return err
+	})
+	return n, err
+}
+
+func (s *serialConn) Write(b []byte) (n int, err error) {
+	err = s.bus.do(func() error {
+		n, err = s.conn.Write(b)
+		return err
+	})
+	return n, err
+}
+
+func (s *serialConn) Close() error {
+	return s.bus.do(s.conn.Close)
+}
@@ -0,0 +1,285 @@
+// Package cellular implements a driver for a cellular modem controlled with AT
+// commands over UART, used as a fallback network link (see wifi.Fallback).
+//
+// The SIMCom TCP/IP command set is used (e.g., SIM7000, SIM800), with a single
+// connection at a time, which is all that package net requires. Modems of other
+// makers, e.g., the u-blox SARA-R4, use a different command set and are not
+// supported. Secure sockets are not supported either, since the certificates
+// would have to be provisioned on the modem.
+package cellular
+
+import (
+	"bytes"
+	"errors"
+	"machine"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+)
+
+// Default constants for Modem configuration.
+const (
+	DefaultBaudRate = 115200
+)
+
+// Constants defining how long to wait for replies of the modem.
+const (
+	CommandTimeout  = 2 * time.Second
+	AttachTimeout   = 60 * time.Second // to register with the cellular network
+	BearerTimeout   = 85 * time.Second // to activate the data connection
+	ConnectTimeout  = 30 * time.Second // to open a socket
+	ResponseTimeout = 10 * time.Second // for DNS replies and data sent
+)
+
+var (
+	ErrNoModem    = errors.New("no reply from cellular modem")
+	ErrNoSIM      = errors.New("SIM card missing or locked")
+	ErrNoService  = errors.New("not registered with cellular network")
+	ErrCommand    = errors.New("cellular modem command failed")
+	ErrTimeout    = errors.New("timeout waiting for cellular modem")
+	ErrConnect    = errors.New("connection refused or timed out")
+	ErrNoTLS      = errors.New("secure sockets not supported by cellular modem")
+	ErrBadAddress = errors.New("invalid address")
+)
+
+// Config defines the UART pins and baud rate of the modem, and the access point
+// name (APN) of the data connection, or empty to use the carrier's default.
+type Config struct {
+	TX, RX   machine.Pin
+	BaudRate uint32
+	APN      string
+}
+
+// Modem wraps the UART connected to the cellular modem.
+type Modem struct {
+	uart   *machine.UART
+	config Config
+	line   [128]byte // line of a reply
+	n      int       // length of line
+}
+
+// New returns a new Modem using the given UART and configuration.
+// The UART is also initialized and configured for use.
+func New(uart *machine.UART, config Config) *Modem {
+
+	if config.BaudRate == 0 {
+		config.BaudRate = DefaultBaudRate
+	}
+
+	uart.Configure(machine.UARTConfig{
+		BaudRate: config.BaudRate,
+		TX:       config.TX,
+		RX:       config.RX,
+	})
+
+	return &Modem{uart: uart, config: config}
+}
+
+// Connect registers with the cellular network and activates the data
+// connection, returning the IP address assigned by the carrier.
+func (m *Modem) Connect() (net.IP, error) {
+
+	// the modem may be asleep or autobauding, so try to wake it a few times.
+	awake := false
+	for i := 0; i < 5 && !awake; i++ {
+		awake = nil == m.command("AT", CommandTimeout)
+	}
+	if !awake {
+		return nil, ErrNoModem
+	}
+	m.command("ATE0", CommandTimeout) // no echo
+	if nil != m.expect("AT+CPIN?", "+CPIN: READY", CommandTimeout) {
+		return nil, ErrNoSIM
+	}
+	attached := false
+	for start := time.Now(); !attached && time.Since(start) < AttachTimeout; {
+		if attached = nil == m.expect("AT+CGATT?", "+CGATT: 1", CommandTimeout); !attached {
+			time.Sleep(time.Second)
+		}
+	}
+	if !attached {
+		return nil, ErrNoService
+	}
+
+	// single connection, received data held by the modem until requested
+	m.expect("AT+CIPSHUT", "SHUT OK", CommandTimeout)
+	for _, cmd := range []string{"AT+CIPMUX=0", "AT+CIPRXGET=1",
+		"AT+CSTT=" + strconv.Quote(m.config.APN)} {
+		if err := m.command(cmd, CommandTimeout); nil != err {
+			return nil, err
+		}
+	}
+	if err := m.command("AT+CIICR", BearerTimeout); nil != err {
+		return nil, err
+	}
+	// the reply to CIFSR is the address alone, without OK
+	m.write("AT+CIFSR")
+	line, err := m.readLine(CommandTimeout)
+	if nil != err {
+		return nil, err
+	}
+	ip := net.ParseIP(string(line))
+	if nil == ip {
+		return nil, ErrCommand
+	}
+	return ip, nil
+}
+
+// Connected returns true if the modem is still registered with the cellular
+// network and its data connection still holds an IP address.
+func (m *Modem) Connected() bool {
+	if nil != m.expect("AT+CGATT?", "+CGATT: 1", CommandTimeout) {
+		return false
+	}
+	// the reply to CIFSR is the address alone, or ERROR if the bearer is down
+	m.write("AT+CIFSR")
+	line, err := m.readLine(CommandTimeout)
+	return nil == err && nil != net.ParseIP(string(line))
+}
+
+// GetHostByName returns the IP address of the given host name, resolved by the
+// carrier's DNS server.
+func (m *Modem) GetHostByName(name string) (net.IP, error) {
+	if ip := net.ParseIP(name); nil != ip {
+		return ip, nil
+	}
+	if err := m.command("AT+CDNSGIP="+strconv.Quote(name), CommandTimeout); nil != err {
+		return nil, err
+	}
+	// +CDNSGIP: 1,"name","address"
+	line, err := m.await("+CDNSGIP:", ResponseTimeout)
+	if nil != err {
+		return nil, err
+	}
+	if !bytes.HasPrefix(line, []byte("+CDNSGIP: 1,")) {
+		return nil, ErrBadAddress
+	}
+	field := bytes.Split(line, []byte{','})
+	if len(field) < 3 {
+		return nil, ErrBadAddress
+	}
+	ip := net.ParseIP(string(bytes.Trim(field[2], `"`)))
+	if nil == ip {
+		return nil, ErrBadAddress
+	}
+	return ip, nil
+}
+
+// write sends the given command, terminated by a carriage return.
+func (m *Modem) write(cmd string) {
+	m.uart.Write([]byte(cmd))
+	m.uart.WriteByte('\r')
+}
+
+// command sends the given command and waits for it to succeed (OK) or fail
+// (ERROR), ignoring any other lines of the reply.
+func (m *Modem) command(cmd string, timeout time.Duration) error {
+	m.write(cmd)
+	_, err := m.await("", timeout)
+	return err
+}
+
+// expect sends the given command, and waits for a reply beginning with want and
+// for the command to succeed.
+func (m *Modem) expect(cmd, want string, timeout time.Duration) error {
+	m.write(cmd)
+	if _, err := m.await(want, timeout); nil != err {
+		return err
+	}
+	if "SHUT OK" == want {
+		return nil // the reply was the final result
+	}
+	_, err := m.await("", timeout)
+	return err
+}
+
+// await waits for a line of reply beginning with prefix, or for the final
+// result OK if prefix is empty, and returns the line. Returns ErrCommand if the
+// command fails instead.
+func (m *Modem) await(prefix string, timeout time.Duration) ([]byte, error) {
+	start := time.Now()
+	for time.Since(start) < timeout {
+		line, err := m.readLine(timeout - time.Since(start))
+		if nil != err {
+			return nil, err
+		}
+		switch {
+		case "" != prefix && bytes.HasPrefix(line, []byte(prefix)):
+			return line, nil
+		case "" == prefix && bytes.Equal(line, []byte("OK")):
+			return line, nil
+		case bytes.Equal(line, []byte("ERROR")),
+			bytes.HasPrefix(line, []byte("+CME ERROR")):
+			return nil, ErrCommand
+		}
+	}
+	return nil, ErrTimeout
+}
+
+// readLine returns the next non-empty line received, without its terminator.
+// The line returned is only valid until the next call.
+func (m *Modem) readLine(timeout time.Duration) ([]byte, error) {
+	m.n = 0
+	start := time.Now()
+	for time.Since(start) < timeout {
+		if 0 == m.uart.Buffered() {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		c, err := m.uart.ReadByte()
+		if nil != err {
+			return nil, err
+		}
+		switch {
+		case '\n' == c && m.n > 0:
+			return m.line[:m.n], nil
+		case '\r' == c || '\n' == c:
+		case m.n < len(m.line):
+			m.line[m.n] = c
+			m.n++
+		}
+	}
+	return nil, ErrTimeout
+}
+
+// readFull reads exactly len(b) bytes of data received.
+func (m *Modem) readFull(b []byte, timeout time.Duration) error {
+	start := time.Now()
+	for n := 0; n < len(b); {
+		if time.Since(start) > timeout {
+			return ErrTimeout
+		}
+		if 0 == m.uart.Buffered() {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		c, err := m.uart.ReadByte()
+		if nil != err {
+			return err
+		}
+		b[n] = c
+		n++
+	}
+	return nil
+}
+
+// waitPrompt waits for the prompt "> " asking for the data of CIPSEND, which is
+// not terminated by a new line.
+func (m *Modem) waitPrompt(timeout time.Duration) error {
+	start := time.Now()
+	for time.Since(start) < timeout {
+		if 0 == m.uart.Buffered() {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		c, err := m.uart.ReadByte()
+		if nil != err {
+			return err
+		}
+		if '>' == c {
+			return nil
+		}
+	}
+	return ErrTimeout
+}
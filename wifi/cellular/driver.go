@@ -0,0 +1,152 @@
+package cellular
+
+import (
+	"strconv"
+	"time"
+)
+
+// The methods in this file implement the DeviceDriver interface of package net,
+// whose sockets are used one at a time (e.g., net.DialTCP disconnects the socket
+// previously dialed), matching the single connection of the modem.
+
+// maxChunk defines the most data requested from the modem at once.
+const maxChunk = 512
+
+// GetDNS returns the IP address of the given domain name.
+func (m *Modem) GetDNS(domain string) (string, error) {
+	ip, err := m.GetHostByName(domain)
+	if nil != err {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// ConnectTCPSocket connects to the given address and port.
+func (m *Modem) ConnectTCPSocket(addr, port string) error {
+	return m.connect("TCP", addr, port)
+}
+
+// ConnectSSLSocket returns ErrNoTLS, since secure sockets are not supported.
+func (m *Modem) ConnectSSLSocket(addr, port string) error {
+	return ErrNoTLS
+}
+
+// ConnectUDPSocket opens a UDP socket on local port listenport sending
+// datagrams to the given address and port sendport.
+func (m *Modem) ConnectUDPSocket(addr, sendport, listenport string) error {
+	if err := m.command(`AT+CLPORT="UDP",`+listenport, CommandTimeout); nil != err {
+		return err
+	}
+	return m.connect("UDP", addr, sendport)
+}
+
+// connect opens the connection of the given protocol.
+func (m *Modem) connect(proto, addr, port string) error {
+	if _, err := strconv.ParseUint(port, 10, 16); nil != err {
+		return ErrBadAddress
+	}
+	cmd := "AT+CIPSTART=" + strconv.Quote(proto) + "," +
+		strconv.Quote(addr) + "," + strconv.Quote(port)
+	if err := m.command(cmd, CommandTimeout); nil != err {
+		return err
+	}
+	for start := time.Now(); time.Since(start) < ConnectTimeout; {
+		line, err := m.readLine(ConnectTimeout - time.Since(start))
+		if nil != err {
+			break
+		}
+		switch string(line) {
+		case "CONNECT OK", "ALREADY CONNECT":
+			return nil
+		case "CONNECT FAIL", "ERROR":
+			return ErrConnect
+		}
+	}
+	return ErrConnect
+}
+
+// DisconnectSocket closes the connection, if any.
+func (m *Modem) DisconnectSocket() error {
+	m.expect("AT+CIPCLOSE", "CLOSE OK", CommandTimeout)
+	return nil
+}
+
+// StartSocketSend asks the modem to send the given number of bytes, which must
+// then be written with Write.
+func (m *Modem) StartSocketSend(size int) error {
+	m.write("AT+CIPSEND=" + strconv.Itoa(size))
+	return m.waitPrompt(CommandTimeout)
+}
+
+// Write writes the data announced by StartSocketSend, and waits for it to be
+// sent.
+func (m *Modem) Write(b []byte) (int, error) {
+	n, err := m.uart.Write(b)
+	if nil != err {
+		return n, err
+	}
+	if _, err := m.await("SEND OK", ResponseTimeout); nil != err {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ReadSocket reads any data received into b, without waiting for more.
+func (m *Modem) ReadSocket(b []byte) (int, error) {
+	if len(b) > maxChunk {
+		b = b[:maxChunk]
+	}
+	m.write("AT+CIPRXGET=2," + strconv.Itoa(len(b)))
+	// +CIPRXGET: 2,<read>,<remaining>, followed by the data read
+	line, err := m.await("+CIPRXGET: 2,", CommandTimeout)
+	if nil != err {
+		return 0, err
+	}
+	n, _ := field(line, 1)
+	if n > len(b) {
+		n = len(b)
+	}
+	if err := m.readFull(b[:n], CommandTimeout); nil != err {
+		return 0, err
+	}
+	_, err = m.await("", CommandTimeout)
+	return n, err
+}
+
+// IsSocketDataAvailable returns true if data has been received.
+func (m *Modem) IsSocketDataAvailable() bool {
+	m.write("AT+CIPRXGET=4")
+	// +CIPRXGET: 4,<remaining>
+	line, err := m.await("+CIPRXGET: 4,", CommandTimeout)
+	if nil != err {
+		return false
+	}
+	n, _ := field(line, 1)
+	m.await("", CommandTimeout)
+	return n > 0
+}
+
+// Response returns nothing, since Write already waits for data to be sent.
+func (m *Modem) Response(timeout int) ([]byte, error) {
+	return nil, nil
+}
+
+// field returns the number in the comma-separated field at the given index of
+// the given line of reply.
+func field(line []byte, index int) (int, error) {
+	start := 0
+	for i := 0; i < index; i++ {
+		for start < len(line) && ',' != line[start] {
+			start++
+		}
+		start++
+	}
+	end := start
+	for end < len(line) && ',' != line[end] {
+		end++
+	}
+	if start > len(line) {
+		return 0, ErrCommand
+	}
+	return strconv.Atoi(string(line[start:end]))
+}
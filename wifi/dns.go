@@ -0,0 +1,81 @@
+package wifi
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers/net"
+)
+
+// Constants defining the behavior of the DNS cache.
+//
+// The coprocessor does not report the TTL of the records it resolves, so every
+// entry is considered fresh for the same period of time. Once expired, an entry
+// is resolved again when next used, but is still returned if the resolver
+// cannot be reached, until it becomes too old to trust.
+const (
+	DNSCacheSize = 8
+	DNSTTL       = 5 * time.Minute // how long an entry is used without resolving
+	DNSStale     = 1 * time.Hour   // how long an entry is used if resolving fails
+)
+
+// host is an entry in the DNS cache.
+type host struct {
+	name string
+	ip   net.IP
+	at   time.Time // when the name was resolved
+}
+
+// dnsCache holds the most recently resolved host names, least recently resolved
+// first.
+type dnsCache struct {
+	host []host
+}
+
+// lookup returns the index of the entry for the given name, or -1 if none.
+func (c *dnsCache) lookup(name string) int {
+	for i := range c.host {
+		if name == c.host[i].name {
+			return i
+		}
+	}
+	return -1
+}
+
+// age returns how long ago the entry at index i was resolved. An entry resolved
+// in the future (i.e., the system time was set backward) is considered stale.
+func (c *dnsCache) age(i int, now time.Time) time.Duration {
+	if now.Before(c.host[i].at) {
+		return DNSStale
+	}
+	return now.Sub(c.host[i].at)
+}
+
+// store adds or replaces the entry for the given name, evicting the least
+// recently resolved entry if the cache is full.
+func (c *dnsCache) store(name string, ip net.IP, now time.Time) {
+	if i := c.lookup(name); i >= 0 {
+		c.host = append(c.host[:i], c.host[i+1:]...)
+	} else if len(c.host) >= DNSCacheSize {
+		c.host = c.host[:copy(c.host, c.host[1:])]
+	}
+	c.host = append(c.host, host{name: name, ip: ip, at: now})
+}
+
+// resolve returns the IP address of the given host name, using the cache if
+// possible and the resolve function otherwise.
+func (c *dnsCache) resolve(name string, resolve func(string) (net.IP, error)) (net.IP, error) {
+	now := time.Now()
+	i := c.lookup(name)
+	if i >= 0 && c.age(i, now) < DNSTTL {
+		return c.host[i].ip, nil
+	}
+	ip, err := resolve(name)
+	if nil != err {
+		if i >= 0 && c.age(i, now) < DNSStale {
+			return c.host[i].ip, nil
+		}
+		return nil, err
+	}
+	c.store(name, ip, now)
+	return ip, nil
+}
@@ -0,0 +1,302 @@
+//go:build ethernet && !fake
+// +build ethernet,!fake
+
+package wifi
+
+import (
+	"hash/fnv"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+	"tinygo.org/x/drivers/wifinina"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/network"
+	"github.com/ardnew/weatherhub/wifi/w5500"
+)
+
+// Peripherals and GPIO pins connected to the W5500 module, wired to the analog
+// header of the board.
+var (
+	ethSPI = machine.SPI0
+	ethSCK = machine.A2
+	ethSDO = machine.A3
+	ethSDI = machine.A4
+	ethCS  = machine.A0
+)
+
+// wired is the only "access point" of an Ethernet link (see InRange).
+var wired = network.AP{SSID: "ethernet", Wired: true}
+
+// WiFi wraps the W5500 device driver. Despite its name, it is an Ethernet link,
+// so that it may replace the WiFi coprocessor without changing its clients.
+//
+// All calls to the driver are serialized (see Do), so that the WiFi may be used
+// concurrently.
+type WiFi struct {
+	eth    *w5500.Device
+	config Config
+	bus    bus
+	dns    dnsCache
+	renew  time.Time // when the lease is next renewed
+}
+
+// New returns a new WiFi using the W5500 module and the given configuration.
+// The SPI interface connected to the module is also initialized and configured
+// for use, and the module is made the driver of the sockets of package net.
+// This method will always return a nil WiFi or a nil error. It will never
+// return nil or non-nil for both WiFi and error.
+func New(config Config) (*WiFi, error) {
+
+	if config.Name == "" {
+		config.Name = DefaultName
+	}
+
+	ethSPI.Configure(machine.SPIConfig{
+		Frequency: 16 * 1e6,
+		SDO:       ethSDO,
+		SDI:       ethSDI,
+		SCK:       ethSCK,
+	})
+	eth := &w5500.Device{SPI: ethSPI, CS: ethCS}
+	if err := eth.Configure(w5500.Config{MAC: hardwareAddr()}); nil != err {
+		return nil, err
+	}
+	net.UseDriver(eth)
+
+	return &WiFi{eth: eth, config: config, bus: newBus()}, nil
+}
+
+// hardwareAddr returns the hardware address of the module, which has none of its
+// own. A locally administered address is derived from the serial number of the
+// microcontroller, which is unique to each board, unlike the name of the device,
+// which is the same on every device unless configured otherwise.
+func hardwareAddr() [6]byte {
+	h := fnv.New32a()
+	h.Write(machine.DeviceID())
+	s := h.Sum32()
+	return [6]byte{0x02, 'W', byte(s >> 24), byte(s >> 16), byte(s >> 8), byte(s)}
+}
+
+// Connect waits for the Ethernet link to come up and leases an IP address from
+// the DHCP server. The given access point is only recorded, since the link has
+// none but wired (see InRange).
+func (w *WiFi) Connect(ap network.AP) error {
+
+	if !w.waitWithTimeout(w.isConnected) {
+		return ErrConnectToAP
+	}
+	var lease w5500.Lease
+	if err := w.bus.do(func() (err error) {
+		lease, err = w.eth.DHCP(w.config.Name)
+		return err
+	}); nil != err {
+		return ErrNoIPAddress
+	}
+	w.renew = lease.Renew()
+
+	// update model with our connection details
+	ip, _ := wifinina.ParseIPv4(lease.IP.String())
+	model.Set(func(m *model.Model) {
+		m.AP, m.IP = ap, ip
+	})
+
+	return nil
+}
+
+// MAC returns the hardware address of the network interface.
+func (w *WiFi) MAC() (uint64, error) {
+	var mac uint64
+	for _, b := range hardwareAddr() {
+		mac = mac<<8 | uint64(b)
+	}
+	return mac, nil
+}
+
+// Scan visits nothing, since an Ethernet link has no access points.
+func (w *WiFi) Scan(visit func(ssid string, rssi int32)) error {
+	return nil
+}
+
+// InRange returns only wired, regardless of the given access points, so that
+// connecting to it brings up the Ethernet link.
+func (w *WiFi) InRange(ap []network.AP) []network.AP {
+	return []network.AP{wired}
+}
+
+func (w *WiFi) getHostByName(name string) (ip net.IP, err error) {
+	err = w.bus.do(func() error {
+		ip, err = w.eth.GetHostByName(name)
+		return err
+	})
+	return ip, err
+}
+
+// ping sends an ICMP echo request to the given IP address with the given time
+// to live, and returns the round-trip time.
+func (w *WiFi) ping(ip net.IP, ttl uint8) (rtt time.Duration, err error) {
+	err = w.bus.do(func() error {
+		rtt, err = w.eth.Ping(ip, ttl)
+		return err
+	})
+	if w5500.ErrTimeout == err {
+		return 0, ErrPingFailed
+	}
+	return rtt, err
+}
+
+// Reconnect returns true if the connection should be established again, which
+// is never, since the Ethernet link is never replaced by a fallback.
+func (w *WiFi) Reconnect() bool {
+	return false
+}
+
+// Gateway returns the IP address of the default gateway, i.e., the router of
+// the lease.
+func (w *WiFi) Gateway() (net.IP, error) {
+	var lease w5500.Lease
+	w.bus.do(func() error {
+		lease = w.eth.Lease()
+		return nil
+	})
+	if !lease.Valid() {
+		return nil, ErrNotConnected
+	}
+	return lease.Gateway, nil
+}
+
+// Listener accepts TCP connections on a local port.
+//
+// The socket listening becomes the socket of the connection accepted, so each
+// connection accepted requires another socket to continue listening. While all
+// sockets are in use, connections are refused.
+type Listener struct {
+	eth       *w5500.Device
+	bus       bus
+	port      uint16
+	sock      w5500.Socket
+	listening bool
+}
+
+// Conn is a TCP connection accepted by a Listener.
+type Conn struct {
+	eth  *w5500.Device
+	bus  bus
+	sock w5500.Socket
+}
+
+// Listen starts a TCP server on the given local port.
+func (w *WiFi) Listen(port uint16) (*Listener, error) {
+	if !w.isConnected() {
+		return nil, ErrNotConnected
+	}
+	var sock w5500.Socket
+	err := w.bus.do(func() (err error) {
+		sock, err = w.eth.Listen(port)
+		return err
+	})
+	if nil != err {
+		return nil, err
+	}
+	return &Listener{eth: w.eth, bus: w.bus, port: port, sock: sock, listening: true}, nil
+}
+
+// Accept returns a connection from the next waiting client, or nil if no client
+// is waiting. Accept never blocks.
+func (l *Listener) Accept() (*Conn, error) {
+	var conn *Conn
+	err := l.bus.do(func() error {
+		if !l.listening {
+			// listen again once a connection has been closed, freeing a socket.
+			sock, err := l.eth.Listen(l.port)
+			if nil != err {
+				return nil
+			}
+			l.sock, l.listening = sock, true
+		}
+		if !l.eth.Accepted(l.sock) {
+			return nil
+		}
+		conn = &Conn{eth: l.eth, bus: l.bus, sock: l.sock}
+		sock, err := l.eth.Listen(l.port)
+		l.sock, l.listening = sock, nil == err
+		return nil
+	})
+	return conn, err
+}
+
+// Close stops the TCP server.
+func (l *Listener) Close() error {
+	return l.bus.do(func() error {
+		if !l.listening {
+			return nil
+		}
+		l.listening = false
+		return l.eth.Close(l.sock)
+	})
+}
+
+// Read reads any data received from the client into b. Read never blocks, and
+// returns 0 with a nil error if no data has been received.
+func (c *Conn) Read(b []byte) (n int, err error) {
+	err = c.bus.do(func() error {
+		n, err = c.eth.Receive(c.sock, b)
+		return err
+	})
+	return n, err
+}
+
+// Write sends b to the client.
+func (c *Conn) Write(b []byte) (n int, err error) {
+	err = c.bus.do(func() error {
+		n, err = c.eth.Send(c.sock, b)
+		return err
+	})
+	return n, err
+}
+
+// Close closes the connection to the client.
+func (c *Conn) Close() error {
+	return c.bus.do(func() error {
+		return c.eth.Close(c.sock)
+	})
+}
+
+func (w *WiFi) isConnected() bool {
+	var up bool
+	w.bus.do(func() error {
+		up = w.eth.LinkUp()
+		return nil
+	})
+	return up
+}
+
+// renewRetry defines how long to wait before retrying to renew a lease.
+const renewRetry = time.Minute
+
+// hasIP returns true if an IP address is leased, renewing the lease once it is
+// halfway through. The address is kept if the lease cannot be renewed, since
+// the DHCP server may be only briefly unavailable.
+func (w *WiFi) hasIP() bool {
+	var lease w5500.Lease
+	renewed := false
+	w.bus.do(func() error {
+		lease = w.eth.Lease()
+		if lease.Valid() && time.Now().After(w.renew) {
+			w.renew = time.Now().Add(renewRetry)
+			if l, err := w.eth.DHCP(w.config.Name); nil == err {
+				lease, renewed, w.renew = l, true, l.Renew()
+			}
+		}
+		return nil
+	})
+	if renewed {
+		// the server may have leased a different address
+		ip, _ := wifinina.ParseIPv4(lease.IP.String())
+		model.Set(func(m *model.Model) {
+			m.IP = ip
+		})
+	}
+	return lease.Valid()
+}
@@ -0,0 +1,168 @@
+//go:build fake
+// +build fake
+
+package wifi
+
+import (
+	"hash/fnv"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+	"tinygo.org/x/drivers/wifinina"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/fake"
+	"github.com/ardnew/weatherhub/wifi/network"
+)
+
+// emulated is the only "access point" of the emulated network (see InRange).
+var emulated = network.AP{SSID: "fake", Wired: true}
+
+// WiFi wraps the emulated network device of package fake, so that the program
+// runs without any network hardware at all, e.g., while working on the display.
+//
+// All calls to the device are serialized (see Do), so that the WiFi may be used
+// concurrently.
+type WiFi struct {
+	dev       *fake.Device
+	config    Config
+	bus       bus
+	dns       dnsCache
+	connected bool
+}
+
+// New returns a new WiFi using the emulated network device and the given
+// configuration, making the device the driver of the sockets of package net.
+// This method will always return a nil WiFi or a nil error. It will never
+// return nil or non-nil for both WiFi and error.
+func New(config Config) (*WiFi, error) {
+
+	if config.Name == "" {
+		config.Name = DefaultName
+	}
+
+	dev := fake.New(fake.Config{})
+	net.UseDriver(dev)
+
+	return &WiFi{dev: dev, config: config, bus: newBus()}, nil
+}
+
+// Connect joins the emulated network at once, regardless of the given access
+// point, which is only recorded.
+func (w *WiFi) Connect(ap network.AP) error {
+
+	w.connected = true
+
+	// update model with our connection details
+	ip, _ := wifinina.ParseIPv4(fake.IP.String())
+	model.Set(func(m *model.Model) {
+		m.AP, m.IP = ap, ip
+	})
+
+	return nil
+}
+
+// MAC returns a locally administered hardware address derived from the name of
+// the device.
+func (w *WiFi) MAC() (uint64, error) {
+	h := fnv.New32a()
+	h.Write([]byte(w.config.Name))
+	s := h.Sum32()
+	mac := uint64(0x02)<<40 | uint64('F')<<32 | uint64(s)
+	return mac, nil
+}
+
+// Scan visits only the emulated network.
+func (w *WiFi) Scan(visit func(ssid string, rssi int32)) error {
+	visit(emulated.SSID, -40)
+	return nil
+}
+
+// InRange returns only emulated, regardless of the given access points, so that
+// the device connects even if none are provisioned.
+func (w *WiFi) InRange(ap []network.AP) []network.AP {
+	return []network.AP{emulated}
+}
+
+func (w *WiFi) getHostByName(name string) (ip net.IP, err error) {
+	err = w.bus.do(func() error {
+		ip, err = w.dev.GetHostByName(name)
+		return err
+	})
+	return ip, err
+}
+
+// ping returns the round-trip time to the given IP address, as emulated.
+func (w *WiFi) ping(ip net.IP, ttl uint8) (rtt time.Duration, err error) {
+	err = w.bus.do(func() error {
+		rtt, err = w.dev.Ping(ip, ttl)
+		return err
+	})
+	if fake.ErrTimeout == err {
+		return 0, ErrPingFailed
+	}
+	return rtt, err
+}
+
+// Reconnect returns true if the connection should be established again, which
+// is never, since the emulated network is never lost.
+func (w *WiFi) Reconnect() bool {
+	return false
+}
+
+// Gateway returns the IP address of the emulated default gateway.
+func (w *WiFi) Gateway() (net.IP, error) {
+	if !w.connected {
+		return nil, ErrNotConnected
+	}
+	return fake.Gateway, nil
+}
+
+// Listener accepts TCP connections on a local port. No client can reach the
+// emulated network, so no connection is ever accepted.
+type Listener struct{}
+
+// Conn is a TCP connection accepted by a Listener.
+type Conn struct{}
+
+// Listen starts a TCP server on the given local port.
+func (w *WiFi) Listen(port uint16) (*Listener, error) {
+	if !w.isConnected() {
+		return nil, ErrNotConnected
+	}
+	return &Listener{}, nil
+}
+
+// Accept always returns nil, since no client is ever waiting. Accept never
+// blocks.
+func (l *Listener) Accept() (*Conn, error) {
+	return nil, nil
+}
+
+// Close stops the TCP server.
+func (l *Listener) Close() error {
+	return nil
+}
+
+// Read returns 0 with a nil error, since no data is ever received.
+func (c *Conn) Read(b []byte) (n int, err error) {
+	return 0, nil
+}
+
+// Write discards b.
+func (c *Conn) Write(b []byte) (n int, err error) {
+	return len(b), nil
+}
+
+// Close closes the connection to the client.
+func (c *Conn) Close() error {
+	return nil
+}
+
+func (w *WiFi) isConnected() bool {
+	return w.connected
+}
+
+func (w *WiFi) hasIP() bool {
+	return w.connected
+}
@@ -0,0 +1,293 @@
+// Package fake implements an emulated network device, which drives the sockets
+// of package net without any hardware, so that the program may be developed
+// without a network, e.g., while working on the display.
+//
+// The device emulates only the services the program requires to run: DNS
+// resolves every name to a stable private address, NTP replies with the time of
+// a simulated clock, and HTTP requests are answered by the Handler registered
+// for the host requested (see Handle), e.g., by package feed/forecast/fake, or
+// with 404 Not Found if none is registered. Requests for the captive portal
+// probe, ending in "/generate_204", are always answered with 204 No Content.
+//
+// TLS connections are accepted as if plain, since nothing is encrypted.
+package fake
+
+import (
+	"bytes"
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+)
+
+// DefaultEpoch defines the time reported by the simulated clock when the Device
+// is created, unless configured otherwise or the system time is already later.
+var DefaultEpoch = time.Date(2021, time.June, 21, 13, 0, 0, 0, time.UTC)
+
+var (
+	ErrBadAddress = errors.New("invalid address")
+	ErrTimeout    = errors.New("no reply from emulated host")
+)
+
+// Addresses of the emulated network.
+var (
+	IP      = net.IP{10, 0, 0, 2} // of the Device
+	Gateway = net.IP{10, 0, 0, 1}
+)
+
+// maxHops defines the greatest number of hops to an emulated host.
+const maxHops = 8
+
+// Handler returns the status code and body of the reply to a request of the
+// given path, e.g., "/v1/forecast?latitude=...".
+type Handler func(path string) (status int, body []byte)
+
+// handlers holds the Handler of each host (see Handle).
+var handlers = struct {
+	lock sync.Mutex
+	host map[string]Handler
+}{host: map[string]Handler{}}
+
+// Handle registers the Handler answering HTTP requests to the given host, e.g.,
+// "api.open-meteo.com". It is typically called from an init function.
+func Handle(host string, h Handler) {
+	handlers.lock.Lock()
+	handlers.host[host] = h
+	handlers.lock.Unlock()
+}
+
+// Config defines the time of the simulated clock when the Device is created.
+type Config struct {
+	Epoch time.Time
+}
+
+// Device is an emulated network device. Like the coprocessor it replaces, it has
+// a single socket, used by one connection at a time.
+type Device struct {
+	config Config
+	start  time.Time // system time when created, from which the clock advances
+	udp    bool      // socket is UDP, otherwise TCP
+	port   string    // remote port of the socket
+	req    []byte    // request received, until complete
+	res    []byte    // reply not yet read
+}
+
+// New returns a new Device using the given configuration.
+func New(config Config) *Device {
+	if config.Epoch.IsZero() {
+		config.Epoch = DefaultEpoch
+	}
+	return &Device{config: config, start: time.Now()}
+}
+
+// Now returns the time of the simulated clock, which is the system time once
+// it has been set to or past Epoch, e.g., by NTP.
+func (d *Device) Now() time.Time {
+	if now := time.Now(); !now.Before(d.config.Epoch) {
+		return now
+	}
+	return d.config.Epoch.Add(time.Since(d.start))
+}
+
+// GetHostByName returns the address of the given host name, which is derived
+// from the name, so that it is the same every time.
+func (d *Device) GetHostByName(name string) (net.IP, error) {
+	if ip := net.ParseIP(name); nil != ip {
+		return ip, nil
+	}
+	if "" == name {
+		return nil, ErrBadAddress
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	s := h.Sum32()
+	return net.IP{10, byte(s >> 16), byte(s >> 8), byte(s | 1)}, nil
+}
+
+// Ping returns the round-trip time to the given address, which is a few
+// milliseconds per hop, or ErrTimeout if it is more than ttl hops away.
+func (d *Device) Ping(ip net.IP, ttl uint8) (time.Duration, error) {
+	hops := uint8(1)
+	if 4 == len(ip) && !bytes.Equal(ip, Gateway) {
+		hops = 2 + ip[3]%(maxHops-1)
+	}
+	if hops > ttl {
+		return 0, ErrTimeout
+	}
+	return time.Duration(hops)*3*time.Millisecond + time.Duration(time.Now().UnixNano()%1e6), nil
+}
+
+// The methods below implement the DeviceDriver interface of package net.
+
+// GetDNS returns the address of the given domain name.
+func (d *Device) GetDNS(domain string) (string, error) {
+	ip, err := d.GetHostByName(domain)
+	if nil != err {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// ConnectTCPSocket connects to the given address and port.
+func (d *Device) ConnectTCPSocket(addr, port string) error {
+	d.udp, d.port, d.req, d.res = false, port, d.req[:0], nil
+	return nil
+}
+
+// ConnectSSLSocket connects to the given host and port, as if plain TCP.
+func (d *Device) ConnectSSLSocket(addr, port string) error {
+	return d.ConnectTCPSocket(addr, port)
+}
+
+// ConnectUDPSocket opens a UDP socket sending datagrams to the given address
+// and port sendport.
+func (d *Device) ConnectUDPSocket(addr, sendport, listenport string) error {
+	d.udp, d.port, d.req, d.res = true, sendport, d.req[:0], nil
+	return nil
+}
+
+// DisconnectSocket closes the socket.
+func (d *Device) DisconnectSocket() error {
+	d.req, d.res = d.req[:0], nil
+	return nil
+}
+
+// StartSocketSend does nothing, since data written is handled as is.
+func (d *Device) StartSocketSend(size int) error {
+	return nil
+}
+
+// Write sends b to the emulated peer of the socket, which replies once the
+// request is complete.
+func (d *Device) Write(b []byte) (int, error) {
+	if d.udp {
+		if "123" == d.port {
+			d.res = d.ntp(b)
+		}
+		return len(b), nil
+	}
+	d.req = append(d.req, b...)
+	if res, ok := d.http(d.req); ok {
+		d.req, d.res = d.req[:0], append(d.res, res...)
+	}
+	return len(b), nil
+}
+
+// ReadSocket reads any reply not yet read into b, without waiting. If UDP, the
+// entire datagram is read, and any part of it beyond len(b) discarded.
+func (d *Device) ReadSocket(b []byte) (int, error) {
+	n := copy(b, d.res)
+	if d.udp {
+		d.res = nil
+	} else {
+		d.res = d.res[n:]
+	}
+	return n, nil
+}
+
+// IsSocketDataAvailable returns true if any reply has not yet been read.
+func (d *Device) IsSocketDataAvailable() bool {
+	return len(d.res) > 0
+}
+
+// Response is used only by AT command drivers, and returns nothing.
+func (d *Device) Response(timeout int) ([]byte, error) {
+	return nil, nil
+}
+
+// ntp returns the reply to the given NTP request, with the time of the
+// simulated clock, or nil if the request is invalid.
+func (d *Device) ntp(req []byte) []byte {
+	const seventyYears = 2208988800
+	if len(req) < 48 {
+		return nil
+	}
+	now := d.Now()
+	sec := uint32(now.Unix() + seventyYears)
+	frac := uint32((uint64(now.Nanosecond()) << 32) / uint64(time.Second))
+	res := make([]byte, 48)
+	res[0] = 0b00100100 // no leap second, version 4, server mode
+	res[1] = 1          // stratum, primary reference
+	copy(res[24:32], req[40:48])
+	for _, off := range []int{32, 40} { // received, transmitted
+		res[off+0], res[off+1], res[off+2], res[off+3] =
+			byte(sec>>24), byte(sec>>16), byte(sec>>8), byte(sec)
+		res[off+4], res[off+5], res[off+6], res[off+7] =
+			byte(frac>>24), byte(frac>>16), byte(frac>>8), byte(frac)
+	}
+	return res
+}
+
+// http returns the reply to the given HTTP request, and true if the request
+// is complete, i.e., its headers and any body have been received.
+func (d *Device) http(req []byte) ([]byte, bool) {
+
+	end := bytes.Index(req, []byte("\r\n\r\n"))
+	if end < 0 {
+		return nil, false
+	}
+	head := req[:end]
+	if size, ok := header(head, "Content-Length"); ok {
+		n, err := strconv.Atoi(size)
+		if nil == err && len(req) < end+4+n {
+			return nil, false
+		}
+	}
+
+	// request line, e.g.: "GET /path HTTP/1.1"
+	line := head
+	if i := bytes.Index(line, []byte("\r\n")); i >= 0 {
+		line = line[:i]
+	}
+	var path string
+	if f := bytes.Fields(line); len(f) >= 2 {
+		path = string(f[1])
+	}
+	host, _ := header(head, "Host")
+
+	status, body := 404, []byte(nil)
+	handlers.lock.Lock()
+	h, ok := handlers.host[host]
+	handlers.lock.Unlock()
+	switch {
+	case strings.HasSuffix(path, "/generate_204"):
+		status = 204
+	case ok:
+		status, body = h(path)
+	}
+
+	res := "HTTP/1.1 " + strconv.Itoa(status) + " " + reason(status) + "\r\n" +
+		"Content-Type: application/json\r\n" +
+		"Content-Length: " + strconv.Itoa(len(body)) + "\r\n" +
+		"Connection: close\r\n\r\n"
+	return append([]byte(res), body...), true
+}
+
+// header returns the value of the header with the given name, and true if
+// the header is present in head.
+func header(head []byte, name string) (string, bool) {
+	for _, line := range bytes.Split(head, []byte("\r\n"))[1:] {
+		i := bytes.IndexByte(line, ':')
+		if i > 0 && bytes.EqualFold(line[:i], []byte(name)) {
+			return string(bytes.TrimSpace(line[i+1:])), true
+		}
+	}
+	return "", false
+}
+
+// reason returns the reason phrase of the given status code.
+func reason(status int) string {
+	switch status {
+	case 200:
+		return "OK"
+	case 204:
+		return "No Content"
+	case 404:
+		return "Not Found"
+	}
+	return "Status"
+}
@@ -0,0 +1,67 @@
+package http
+
+// validator holds the cache validators of the document most recently retrieved
+// from a URL, which allow the server to reply that it has not changed.
+type validator struct {
+	url      string
+	etag     string
+	modified string
+}
+
+// GetIfModified issues a conditional GET request for the given URL, which the
+// server may answer with status 304 Not Modified (see Response.NotModified) and
+// no body if the document has not changed since the previous successful
+// GetIfModified of the same URL. Errors are reported as for Get.
+//
+// Only the validators of the most recent URLs are remembered, as configured by
+// Config.Validators. If the body of a successful reply cannot be used, Forget
+// should be called so that the next request retrieves the document again.
+func (c *Client) GetIfModified(url string) (*Response, error) {
+	extra := ""
+	if i := c.validator(url); i >= 0 {
+		v := c.valid[i]
+		if "" != v.etag {
+			extra += "If-None-Match: " + v.etag + "\r\n"
+		}
+		if "" != v.modified {
+			extra += "If-Modified-Since: " + v.modified + "\r\n"
+		}
+	}
+	res, err := c.do("GET", url, "", nil, extra)
+	if nil != err {
+		return nil, err
+	}
+	if res.OK() {
+		c.Forget(url)
+		if "" != res.ETag || "" != res.LastModified {
+			if len(c.valid) == cap(c.valid) {
+				c.valid = c.valid[:copy(c.valid, c.valid[1:])]
+			}
+			c.valid = append(c.valid, validator{
+				url:      url,
+				etag:     res.ETag,
+				modified: res.LastModified,
+			})
+		}
+	}
+	return res, nil
+}
+
+// Forget discards the cache validators of the given URL, so that the next
+// GetIfModified of the URL is unconditional.
+func (c *Client) Forget(url string) {
+	if i := c.validator(url); i >= 0 {
+		c.valid = append(c.valid[:i], c.valid[i+1:]...)
+	}
+}
+
+// validator returns the index of the validators of the given URL, or -1 if none
+// are remembered.
+func (c *Client) validator(url string) int {
+	for i := range c.valid {
+		if url == c.valid[i].url {
+			return i
+		}
+	}
+	return -1
+}
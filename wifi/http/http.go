@@ -0,0 +1,524 @@
+// Package http implements a minimal HTTP/1.1 client using the TCP sockets of
+// the WiFi coprocessor.
+//
+// Only the small subset of HTTP required to fetch documents from (and post
+// short messages to) web APIs is supported. Response bodies are streamed
+// directly from the socket so that they never need to be held in memory in
+// their entirety.
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+	"tinygo.org/x/drivers/net/tls"
+
+	"github.com/ardnew/weatherhub/wifi"
+)
+
+// Default constants for Client configuration.
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultIdle       = 1 * time.Second
+	DefaultMaxBody    = 16 * 1024 // bytes
+	DefaultUserAgent  = "weatherhub"
+	DefaultValidators = 8
+	DefaultMaxIdle    = 2
+	DefaultKeepAlive  = 10 * time.Second
+	DefaultProbeURL   = "http://connectivitycheck.gstatic.com/generate_204"
+)
+
+var (
+	ErrInvalidURL        = errors.New("invalid URL")
+	ErrUnsupportedScheme = errors.New("unsupported URL scheme")
+	ErrMalformedResponse = errors.New("malformed HTTP response")
+	ErrStatus            = errors.New("unexpected HTTP response status")
+	ErrTimeout           = errors.New("timeout waiting for HTTP response")
+)
+
+// Config defines the behavior of a Client.
+type Config struct {
+	Timeout    time.Duration // how long to wait for the server to begin replying
+	Idle       time.Duration // how long a reply may stall before it is complete
+	MaxBody    int           // maximum number of response body bytes read
+	UserAgent  string
+	Validators int           // number of URLs whose cache validators are remembered
+	MaxIdle    int           // number of idle connections kept, or -1 for none
+	KeepAlive  time.Duration // how long an idle connection is kept
+	Quotas     []Quota       // daily request limits, or empty to use DefaultQuotas
+	Throttle   int           // percentage of a Quota after which requests are spaced
+	ProbeURL   string        // replies 204 No Content unless behind a captive portal
+}
+
+// Client performs HTTP requests over the WiFi coprocessor.
+type Client struct {
+	device *wifi.WiFi
+	config Config
+	usage  []usage
+	valid  []validator // ring buffer of validators, most recent last
+	pool   pool
+}
+
+// Response contains the status and body of a reply received from the server.
+// The Body must be read before calling Close, which releases the socket, or
+// keeps it open for reuse if the entire Body was read.
+type Response struct {
+	StatusCode    int
+	ContentLength int    // -1 if unknown
+	ETag          string // empty if not provided
+	LastModified  string // empty if not provided
+	Body          io.Reader
+	conn          io.ReadWriteCloser
+	pool          *pool
+	key           string
+	keep          bool        // server permits reuse of the connection
+	done          func() bool // returns true if the Body was read entirely
+}
+
+// New returns a new Client using the given WiFi device and configuration.
+func New(device *wifi.WiFi, config Config) *Client {
+
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Idle == 0 {
+		config.Idle = DefaultIdle
+	}
+	if config.MaxBody == 0 {
+		config.MaxBody = DefaultMaxBody
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
+	}
+	if 0 == len(config.Quotas) {
+		config.Quotas = DefaultQuotas
+	}
+	if config.Throttle == 0 {
+		config.Throttle = DefaultThrottle
+	}
+	if config.Validators == 0 {
+		config.Validators = DefaultValidators
+	}
+	if config.MaxIdle == 0 {
+		config.MaxIdle = DefaultMaxIdle
+	}
+	if config.KeepAlive == 0 {
+		config.KeepAlive = DefaultKeepAlive
+	}
+	if config.ProbeURL == "" {
+		config.ProbeURL = DefaultProbeURL
+	}
+
+	usage := make([]usage, len(config.Quotas))
+	for i, q := range config.Quotas {
+		usage[i].quota = q
+	}
+
+	return &Client{
+		device: device,
+		config: config,
+		usage:  usage,
+		valid:  make([]validator, 0, config.Validators),
+		pool:   pool{max: config.MaxIdle, keepAlive: config.KeepAlive},
+	}
+}
+
+// Get issues a GET request for the given URL and returns the server's reply.
+// An error is returned if the server could not be reached, if its reply was not
+// understood, or if the request would exceed the daily Quota of the server.
+// A non-2xx status code is not considered an error.
+func (c *Client) Get(url string) (*Response, error) {
+	return c.do("GET", url, "", nil, "")
+}
+
+// Post issues a POST request for the given URL with the given body and content
+// type, and returns the server's reply. Errors are reported as for Get.
+func (c *Client) Post(url, contentType string, body []byte) (*Response, error) {
+	return c.do("POST", url, contentType, body, "")
+}
+
+func (c *Client) do(method, url, contentType string, body []byte, extra string) (*Response, error) {
+
+	u, err := parseURL(url)
+	if nil != err {
+		return nil, err
+	}
+	if err := c.admit(u.host); nil != err {
+		return nil, err
+	}
+
+	req := method + " " + u.path + " HTTP/1.1\r\n" +
+		"Host: " + u.host + "\r\n" +
+		"User-Agent: " + c.config.UserAgent + "\r\n" +
+		"Accept: */*\r\n" + extra
+	if nil != body {
+		req += "Content-Type: " + contentType + "\r\n" +
+			"Content-Length: " + strconv.Itoa(len(body)) + "\r\n"
+	}
+	if c.config.MaxIdle < 0 {
+		req += "Connection: close\r\n"
+	}
+	req += "\r\n"
+
+	// an idle connection may have been closed by the server, which is not known
+	// until the request fails. a GET is then retried once on a new connection,
+	// but only if the server cannot have received it, i.e., the request could
+	// not be sent or the connection was closed before any reply, since any other
+	// request may have taken effect already.
+	key := u.key()
+	conn := c.pool.get(key)
+	reused := nil != conn
+	if !reused {
+		if conn, err = c.dial(u); nil != err {
+			return nil, err
+		}
+	}
+	res, stale, err := c.exchange(conn, req, body)
+	if nil != err && reused && stale && "GET" == method {
+		if conn, err = c.dial(u); nil != err {
+			return nil, err
+		}
+		res, _, err = c.exchange(conn, req, body)
+	}
+	if nil != err {
+		return nil, err
+	}
+	res.conn, res.pool, res.key = conn, &c.pool, key
+	return res, nil
+}
+
+// exchange sends a request on the given connection and reads the status line
+// and headers of the reply. The connection is closed if an error occurs, and
+// stale is true if the connection was found closed, i.e., the request could not
+// be written, or the connection reached EOF before any byte of a reply.
+func (c *Client) exchange(conn io.ReadWriteCloser, req string, body []byte) (res *Response, stale bool, err error) {
+	if _, err := conn.Write([]byte(req)); nil != err {
+		conn.Close()
+		return nil, true, err
+	}
+	if nil != body {
+		if _, err := conn.Write(body); nil != err {
+			conn.Close()
+			return nil, true, err
+		}
+	}
+	poll := &pollReader{
+		conn:    conn,
+		timeout: c.config.Timeout,
+		idle:    c.config.Idle,
+	}
+	if res, err = readResponse(bufio.NewReaderSize(poll, 256), c.config.MaxBody); nil != err {
+		conn.Close()
+		return nil, !poll.recv && (io.EOF == err || io.ErrUnexpectedEOF == err), err
+	}
+	return res, false, nil
+}
+
+// Captive returns true if the network is behind a captive portal, i.e., one
+// that requires signing in from a browser before any other traffic is allowed.
+// The portal is detected by requesting the ProbeURL, which a portal intercepts
+// to reply with its sign-in page or a redirect, instead of 204 No Content.
+// Returns an error if the ProbeURL could not be reached at all.
+func (c *Client) Captive() (bool, error) {
+	res, err := c.Get(c.config.ProbeURL)
+	if nil != err {
+		return false, err
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Close()
+	return 204 != res.StatusCode, nil
+}
+
+// Close releases the socket used to receive the Response. If the entire Body
+// was read and the server permits, the socket is kept open for reuse instead.
+func (r *Response) Close() error {
+	if nil == r.conn {
+		return nil
+	}
+	var err error
+	if r.keep && nil != r.done && r.done() && nil != r.pool {
+		r.pool.put(r.key, r.conn)
+	} else {
+		err = r.conn.Close()
+	}
+	r.conn = nil
+	return err
+}
+
+// OK returns true if and only if the Response has a 2xx status code.
+func (r *Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// NotModified returns true if the Response is a reply to a conditional request
+// indicating that the document has not changed since it was last retrieved.
+func (r *Response) NotModified() bool {
+	return 304 == r.StatusCode
+}
+
+func (c *Client) dial(u url) (io.ReadWriteCloser, error) {
+	var conn io.ReadWriteCloser
+	if u.tls {
+		// the coprocessor performs its own DNS lookup for TLS connections, since
+		// it needs the host name for certificate verification.
+		err := c.device.Do(func() error {
+			tc, err := tls.Dial("tcp", u.host+":"+strconv.Itoa(u.port), nil)
+			conn = tc
+			return err
+		})
+		if nil != err {
+			return nil, err
+		}
+		return c.device.Serialize(conn), nil
+	}
+	host, err := c.device.GetHostByName(u.host)
+	if nil != err {
+		return nil, err
+	}
+	err = c.device.Do(func() error {
+		tc, err := net.DialTCP("tcp", nil, &net.TCPAddr{IP: host, Port: u.port})
+		conn = tc
+		return err
+	})
+	if nil != err {
+		return nil, err
+	}
+	return c.device.Serialize(conn), nil
+}
+
+func readResponse(rd *bufio.Reader, maxBody int) (*Response, error) {
+
+	// status line, e.g.: "HTTP/1.1 200 OK"
+	line, err := readLine(rd)
+	if nil != err {
+		return nil, err
+	}
+	if !bytes.HasPrefix(line, []byte("HTTP/")) {
+		return nil, ErrMalformedResponse
+	}
+	sp := bytes.IndexByte(line, ' ')
+	if sp < 0 || len(line) < sp+4 {
+		return nil, ErrMalformedResponse
+	}
+	code, ok := atoi(line[sp+1 : sp+4])
+	if !ok {
+		return nil, ErrMalformedResponse
+	}
+
+	res := &Response{StatusCode: code, ContentLength: -1}
+	res.keep = bytes.HasPrefix(line, []byte("HTTP/1.1 "))
+	chunked := false
+
+	// headers, terminated by an empty line
+	for {
+		if line, err = readLine(rd); nil != err {
+			return nil, err
+		}
+		if 0 == len(line) {
+			break
+		}
+		if val, ok := header(line, "Content-Length"); ok {
+			if res.ContentLength, ok = atoi(val); !ok {
+				return nil, ErrMalformedResponse
+			}
+		} else if val, ok := header(line, "Transfer-Encoding"); ok {
+			chunked = bytes.EqualFold(val, []byte("chunked"))
+		} else if val, ok := header(line, "ETag"); ok {
+			res.ETag = string(val)
+		} else if val, ok := header(line, "Last-Modified"); ok {
+			res.LastModified = string(val)
+		} else if val, ok := header(line, "Connection"); ok {
+			res.keep = res.keep && !bytes.EqualFold(val, []byte("close"))
+		}
+	}
+	if (code >= 100 && code < 200) || 204 == code || 304 == code {
+		res.ContentLength, chunked = 0, false // never has a body
+	}
+
+	// the connection may only be reused once the end of the body has been read,
+	// which is only known if its length is given or it is chunked.
+	var body io.Reader = rd
+	if chunked {
+		cr := &chunkedReader{rd: rd}
+		body = cr
+		res.done = func() bool { return cr.done && 0 == rd.Buffered() }
+	} else if res.ContentLength >= 0 && res.ContentLength <= maxBody {
+		cr := &countReader{rd: rd}
+		body, maxBody = cr, res.ContentLength
+		res.done = func() bool { return cr.n == res.ContentLength && 0 == rd.Buffered() }
+	}
+	res.Body = io.LimitReader(body, int64(maxBody))
+
+	return res, nil
+}
+
+// readLine returns the next line read from rd without its line terminator.
+// Lines longer than the buffer of rd are truncated. The returned slice is only
+// valid until the next read from rd.
+func readLine(rd *bufio.Reader) ([]byte, error) {
+	line, err := rd.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		// discard the remainder of the line, returning only what was buffered.
+		line = append([]byte{}, line...)
+		for err == bufio.ErrBufferFull {
+			_, err = rd.ReadSlice('\n')
+		}
+	}
+	if nil != err {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// header returns the value of the header line if its field name equals the
+// given name (case-insensitive).
+func header(line []byte, name string) ([]byte, bool) {
+	if len(line) <= len(name) || ':' != line[len(name)] ||
+		!bytes.EqualFold(line[:len(name)], []byte(name)) {
+		return nil, false
+	}
+	return bytes.TrimSpace(line[len(name)+1:]), true
+}
+
+// atoi parses a non-negative decimal integer without allocating.
+func atoi(b []byte) (n int, ok bool) {
+	if 0 == len(b) {
+		return 0, false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = 10*n + int(c-'0')
+	}
+	return n, true
+}
+
+// url contains the components of a URL required to issue a request.
+type url struct {
+	tls  bool
+	host string
+	port int
+	path string
+}
+
+func parseURL(s string) (u url, err error) {
+	switch {
+	case strings.HasPrefix(s, "http://"):
+		s, u.port = s[len("http://"):], 80
+	case strings.HasPrefix(s, "https://"):
+		s, u.port, u.tls = s[len("https://"):], 443, true
+	default:
+		return u, ErrUnsupportedScheme
+	}
+	u.host, u.path = s, "/"
+	if i := strings.IndexAny(s, "/?"); i >= 0 {
+		u.host, u.path = s[:i], s[i:]
+		if '?' == u.path[0] {
+			u.path = "/" + u.path
+		}
+	}
+	if i := strings.LastIndexByte(u.host, ':'); i >= 0 {
+		port, ok := atoi([]byte(u.host[i+1:]))
+		if !ok || port <= 0 || port > 0xFFFF {
+			return u, ErrInvalidURL
+		}
+		u.host, u.port = u.host[:i], port
+	}
+	if "" == u.host {
+		return u, ErrInvalidURL
+	}
+	return u, nil
+}
+
+// pollReader adapts the non-blocking socket reads of the WiFi coprocessor to
+// the blocking semantics expected by io.Reader.
+//
+// The coprocessor does not reliably report when the server has closed the
+// connection, so once any data has been received, a stall longer than the idle
+// period is treated as the end of the stream.
+type pollReader struct {
+	conn    io.Reader
+	timeout time.Duration
+	idle    time.Duration
+	recv    bool
+}
+
+func (p *pollReader) Read(b []byte) (int, error) {
+	wait := p.timeout
+	if p.recv {
+		wait = p.idle
+	}
+	start := time.Now()
+	for {
+		n, err := p.conn.Read(b)
+		if n > 0 {
+			p.recv = true
+			return n, err
+		}
+		if nil != err {
+			return 0, err
+		}
+		if time.Since(start) > wait {
+			if p.recv {
+				return 0, io.EOF
+			}
+			return 0, ErrTimeout
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// chunkedReader decodes a response body using chunked transfer encoding.
+type chunkedReader struct {
+	rd   *bufio.Reader
+	size int // bytes remaining in the current chunk
+	done bool
+}
+
+func (c *chunkedReader) Read(b []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+	if 0 == c.size {
+		line, err := readLine(c.rd)
+		if nil != err {
+			return 0, err
+		}
+		if i := bytes.IndexByte(line, ';'); i >= 0 {
+			line = line[:i] // ignore chunk extensions
+		}
+		size, err := strconv.ParseUint(string(bytes.TrimSpace(line)), 16, 31)
+		if nil != err {
+			return 0, ErrMalformedResponse
+		}
+		if 0 == size {
+			// consume any trailers and the empty line ending the body
+			for {
+				if line, err = readLine(c.rd); nil != err {
+					return 0, err
+				}
+				if 0 == len(line) {
+					break
+				}
+			}
+			c.done = true
+			return 0, io.EOF
+		}
+		c.size = int(size)
+	}
+	if len(b) > c.size {
+		b = b[:c.size]
+	}
+	n, err := c.rd.Read(b)
+	if c.size -= n; 0 == c.size && nil == err {
+		_, err = readLine(c.rd) // consume the CRLF following each chunk
+	}
+	return n, err
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// idleConn is a connection kept open after receiving a complete Response, so
+// that it may be reused by the next request to the same server.
+type idleConn struct {
+	key   string
+	conn  io.ReadWriteCloser
+	since time.Time // when the connection became idle
+}
+
+// pool holds the idle connections of a Client, least recently used first.
+//
+// The coprocessor supports only a few sockets at once, so only a small number
+// of connections are kept, and only briefly, since servers close idle
+// connections without notice.
+type pool struct {
+	idle      []idleConn
+	max       int
+	keepAlive time.Duration
+}
+
+// key returns the string identifying connections to the server of u.
+func (u url) key() string {
+	s := "http://"
+	if u.tls {
+		s = "https://"
+	}
+	return s + u.host + ":" + strconv.Itoa(u.port)
+}
+
+// get removes and returns an idle connection with the given key, or nil if
+// none. Any connections idle longer than the keep-alive period are closed.
+func (p *pool) get(key string) io.ReadWriteCloser {
+	now := time.Now()
+	for i := 0; i < len(p.idle); {
+		if d := now.Sub(p.idle[i].since); d < 0 || d > p.keepAlive {
+			p.idle[i].conn.Close()
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			continue
+		}
+		i++
+	}
+	for i := range p.idle {
+		if key == p.idle[i].key {
+			conn := p.idle[i].conn
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			return conn
+		}
+	}
+	return nil
+}
+
+// put adds an idle connection with the given key, closing the least recently
+// used connection if the pool is full.
+func (p *pool) put(key string, conn io.ReadWriteCloser) {
+	if p.max <= 0 {
+		conn.Close()
+		return
+	}
+	if len(p.idle) >= p.max {
+		p.idle[0].conn.Close()
+		p.idle = p.idle[:copy(p.idle, p.idle[1:])]
+	}
+	p.idle = append(p.idle, idleConn{key: key, conn: conn, since: time.Now()})
+}
+
+// countReader counts the bytes read from an io.Reader.
+type countReader struct {
+	rd io.Reader
+	n  int
+}
+
+func (c *countReader) Read(b []byte) (int, error) {
+	n, err := c.rd.Read(b)
+	c.n += n
+	return n, err
+}
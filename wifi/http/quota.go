@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/model"
+)
+
+// DefaultThrottle defines the percentage of a daily Quota after which requests
+// are spaced evenly over the remainder of the day.
+const DefaultThrottle = 80
+
+// DefaultQuotas defines the daily request limits of the free tiers of the web
+// APIs used by the data feeds.
+var DefaultQuotas = []Quota{
+	{Host: "open-meteo.com", Daily: 10000},
+	{Host: "openweathermap.org", Daily: 1000},
+}
+
+var (
+	ErrQuota     = errors.New("daily API quota exhausted")
+	ErrThrottled = errors.New("API requests throttled near daily quota")
+)
+
+// Quota defines the number of requests allowed each day to a web API.
+// Quotas reset at midnight UTC.
+type Quota struct {
+	Host  string // matches this host and all of its subdomains
+	Daily int
+}
+
+// usage tracks the requests made against a Quota.
+type usage struct {
+	quota Quota
+	count int
+	day   int       // day of the count, as days since the Unix epoch
+	last  time.Time // time of the most recent request
+}
+
+// match returns true if the given host is subject to the Quota.
+func (q Quota) match(host string) bool {
+	return host == q.Host ||
+		(strings.HasSuffix(host, q.Host) && '.' == host[len(host)-len(q.Host)-1])
+}
+
+// admit counts a request to the given host against its Quota, if any. Returns
+// ErrQuota if the Quota is exhausted, or ErrThrottled if the Quota is nearly
+// exhausted and the request would exceed an even spacing of the remaining
+// requests over the remainder of the day.
+func (c *Client) admit(host string) error {
+	for i := range c.usage {
+		u := &c.usage[i]
+		if !u.quota.match(host) {
+			continue
+		}
+		now := time.Now().UTC()
+		if day := int(now.Unix() / 86400); day != u.day {
+			u.day, u.count = day, 0
+		}
+		if u.count >= u.quota.Daily {
+			return ErrQuota
+		}
+		if 100*u.count >= c.config.Throttle*u.quota.Daily {
+			left := time.Duration(86400-now.Unix()%86400) * time.Second
+			if now.Sub(u.last) < left/time.Duration(u.quota.Daily-u.count) {
+				return ErrThrottled
+			}
+		}
+		u.count++
+		u.last = now
+		if i < model.MaxQuotas {
+			model.Mod(func(m *model.Model) {
+				m.Diagnostics.Quota[i] = model.Quota{
+					Host:  u.quota.Host,
+					Used:  u.count,
+					Limit: u.quota.Daily,
+				}
+			})
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardnew/weatherhub/wifi"
+)
+
+// Default constants for Server configuration.
+const (
+	DefaultPort       = 80
+	DefaultMaxRequest = 2 * 1024 // bytes
+)
+
+// listenRetry defines how long the Server waits before listening again after
+// failing, e.g., while the network is reached by the cellular fallback, which
+// accepts no connections.
+const listenRetry = 10 * time.Second
+
+var (
+	ErrMalformedRequest = errors.New("malformed HTTP request")
+	ErrRequestSize      = errors.New("HTTP request body exceeds maximum size")
+)
+
+// ServerConfig defines the behavior of a Server.
+type ServerConfig struct {
+	Port       int
+	Timeout    time.Duration // how long to wait for a client to send its request
+	MaxRequest int           // maximum number of request body bytes read
+}
+
+// Request contains the method, target, and body of a request received by the
+// Server. The Body is only valid until the Handler returns.
+type Request struct {
+	Method        string
+	Path          string
+	Query         string // the part of the request target following '?'
+	ContentLength int    // -1 if unknown
+	Authorization string // credentials, e.g., "Bearer <token>"
+	WebSocketKey  string // Sec-WebSocket-Key of a WebSocket handshake (see Upgrade)
+	Body          io.Reader
+}
+
+// ResponseWriter sends the reply to a Request.
+type ResponseWriter struct {
+	conn     *wifi.Conn
+	status   int
+	header   string // additional header lines (see Header)
+	upgraded bool   // the connection is kept open by a WebSocket
+}
+
+// Handler replies to a Request received by the Server.
+type Handler func(w *ResponseWriter, r *Request)
+
+type route struct {
+	method, path string
+	handle       Handler
+}
+
+// Server replies to HTTP requests received from clients on the local network.
+//
+// Clients are served one at a time, synchronously, by calling Sync from the run
+// loop. Each connection is closed after replying to a single request, unless it
+// is upgraded to a WebSocket (see Upgrade).
+type Server struct {
+	device   *wifi.WiFi
+	config   ServerConfig
+	listener *wifi.Listener
+	retry    time.Time // when to listen again after failing, or zero
+	route    []route
+}
+
+// NewServer returns a new Server using the given WiFi device and configuration.
+// The Server does not begin listening until the first call to Sync.
+func NewServer(device *wifi.WiFi, config ServerConfig) *Server {
+
+	if config.Port == 0 {
+		config.Port = DefaultPort
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxRequest == 0 {
+		config.MaxRequest = DefaultMaxRequest
+	}
+
+	return &Server{device: device, config: config}
+}
+
+// Handle registers a Handler for requests with the given method and path.
+func (s *Server) Handle(method, path string, handle Handler) {
+	s.route = append(s.route, route{method: method, path: path, handle: handle})
+}
+
+// Sync begins listening if not already listening, and then serves the next
+// waiting client, if any. If listening fails, it is tried again by the first
+// Sync after listenRetry, and no error is returned while not connected.
+func (s *Server) Sync() error {
+
+	if nil == s.listener {
+		now := time.Now()
+		if now.Before(s.retry) {
+			return nil
+		}
+		l, err := s.device.Listen(uint16(s.config.Port))
+		if nil != err {
+			s.retry = now.Add(listenRetry)
+			if wifi.ErrNotConnected == err {
+				return nil // nothing to serve until the link is restored
+			}
+			return err
+		}
+		s.listener, s.retry = l, time.Time{}
+	}
+
+	conn, err := s.listener.Accept()
+	if nil != err || nil == conn {
+		return err
+	}
+	w := &ResponseWriter{conn: conn}
+	defer func() {
+		if !w.upgraded {
+			conn.Close()
+		}
+	}()
+
+	rd := bufio.NewReaderSize(&pollReader{
+		conn:    conn,
+		timeout: s.config.Timeout,
+		idle:    s.config.Timeout,
+	}, 256)
+
+	req, err := readRequest(rd, s.config.MaxRequest)
+	if nil != err {
+		if err == ErrRequestSize {
+			w.Error(413)
+		} else {
+			w.Error(400)
+		}
+		return err
+	}
+
+	allowed := false
+	for _, r := range s.route {
+		if r.path != req.Path {
+			continue
+		}
+		if r.method == req.Method {
+			r.handle(w, req)
+			return nil
+		}
+		allowed = true // path exists, but not with this method
+	}
+	if allowed {
+		w.Error(405)
+	} else {
+		w.Error(404)
+	}
+	return nil
+}
+
+func readRequest(rd *bufio.Reader, maxBody int) (*Request, error) {
+
+	// request line, e.g.: "POST /api/v1/notify HTTP/1.1"
+	line, err := readLine(rd)
+	if nil != err {
+		return nil, err
+	}
+	fields := bytes.Fields(line)
+	if len(fields) != 3 || !bytes.HasPrefix(fields[2], []byte("HTTP/")) {
+		return nil, ErrMalformedRequest
+	}
+	req := &Request{Method: string(fields[0]), ContentLength: -1}
+	target := fields[1]
+	if i := bytes.IndexByte(target, '?'); i >= 0 {
+		target, req.Query = target[:i], string(target[i+1:])
+	}
+	req.Path = string(target)
+
+	// headers, terminated by an empty line
+	for {
+		if line, err = readLine(rd); nil != err {
+			return nil, err
+		}
+		if 0 == len(line) {
+			break
+		}
+		if val, ok := header(line, "Content-Length"); ok {
+			if req.ContentLength, ok = atoi(val); !ok {
+				return nil, ErrMalformedRequest
+			}
+		}
+		if val, ok := header(line, "Authorization"); ok {
+			req.Authorization = string(val)
+		}
+		if val, ok := header(line, "Sec-WebSocket-Key"); ok {
+			req.WebSocketKey = string(val)
+		}
+	}
+
+	size := req.ContentLength
+	if size > maxBody {
+		return nil, ErrRequestSize
+	}
+	if size < 0 {
+		size = 0 // bodies must have known length
+	}
+	req.Body = io.LimitReader(rd, int64(size))
+
+	return req, nil
+}
+
+// Param returns the value of the named parameter in the request query, which
+// is not unescaped. Returns the empty string if the parameter is not present.
+func (r *Request) Param(name string) string {
+	q := r.Query
+	for len(q) > 0 {
+		kv := q
+		if i := strings.IndexByte(q, '&'); i >= 0 {
+			kv, q = q[:i], q[i+1:]
+		} else {
+			q = ""
+		}
+		if len(kv) > len(name) && kv[:len(name)] == name && '=' == kv[len(name)] {
+			return kv[len(name)+1:]
+		}
+	}
+	return ""
+}
+
+// Header adds a header with the given name and value to the reply, in addition
+// to those sent by WriteHeader. It must be called before WriteHeader.
+func (w *ResponseWriter) Header(name, value string) {
+	w.header += name + ": " + value + "\r\n"
+}
+
+// Secret returns the secret given by the Authorization header, which is either
+// a bearer token or the password of HTTP basic authentication (the user name is
+// ignored). Returns the empty string if no credentials were given.
+func (r *Request) Secret() string {
+	auth := r.Authorization
+	if i := strings.IndexByte(auth, ' '); i >= 0 {
+		scheme, cred := auth[:i], strings.TrimSpace(auth[i+1:])
+		switch {
+		case strings.EqualFold(scheme, "Bearer"):
+			return cred
+		case strings.EqualFold(scheme, "Basic"):
+			b, err := base64.StdEncoding.DecodeString(cred)
+			if nil != err {
+				return ""
+			}
+			if i := bytes.IndexByte(b, ':'); i >= 0 {
+				return string(b[i+1:])
+			}
+		}
+	}
+	return ""
+}
+
+// WriteHeader sends the status line and headers of the reply. It must be called
+// before Write, and only once per Request.
+func (w *ResponseWriter) WriteHeader(status int, contentType string) error {
+	w.status = status
+	_, err := w.conn.Write([]byte("HTTP/1.1 " + strconv.Itoa(status) + " " +
+		statusText(status) + "\r\n" +
+		"Content-Type: " + contentType + "\r\n" + w.header +
+		"Connection: close\r\n\r\n"))
+	return err
+}
+
+// Write sends part of the body of the reply.
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if 0 == w.status {
+		if err := w.WriteHeader(200, "text/plain"); nil != err {
+			return 0, err
+		}
+	}
+	return w.conn.Write(b)
+}
+
+// Error sends a reply with the given status code and its description as body.
+func (w *ResponseWriter) Error(status int) {
+	if nil == w.WriteHeader(status, "text/plain") {
+		w.conn.Write([]byte(statusText(status) + "\n"))
+	}
+}
+
+func statusText(status int) string {
+	switch status {
+	case 200:
+		return "OK"
+	case 204:
+		return "No Content"
+	case 400:
+		return "Bad Request"
+	case 401:
+		return "Unauthorized"
+	case 404:
+		return "Not Found"
+	case 405:
+		return "Method Not Allowed"
+	case 413:
+		return "Payload Too Large"
+	case 500:
+		return "Internal Server Error"
+	case 503:
+		return "Service Unavailable"
+	}
+	return "Unknown"
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+
+	"github.com/ardnew/weatherhub/wifi"
+)
+
+// websocketGUID is appended to the key of a WebSocket handshake to compute the
+// accept value of the reply (RFC 6455, section 4.2.2).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes (RFC 6455, section 5.2).
+const (
+	opText   = 0x1
+	opBinary = 0x2
+	opClose  = 0x8
+	opPing   = 0x9
+	opPong   = 0xA
+)
+
+// maxControl defines the largest payload of a frame received from the client.
+// Only control frames, whose payloads are limited to this size, are expected.
+const maxControl = 125
+
+var (
+	ErrNotWebSocket = errors.New("HTTP request is not a WebSocket handshake")
+	ErrFrame        = errors.New("unexpected WebSocket frame from client")
+	ErrClosed       = errors.New("WebSocket closed by client")
+	ErrMessageSize  = errors.New("WebSocket message exceeds maximum size")
+)
+
+// WebSocket is a connection upgraded from an HTTP request, over which messages
+// are pushed to the client. Messages from the client are not delivered; only
+// the control frames ping and close are answered (see Poll).
+type WebSocket struct {
+	conn *wifi.Conn
+	in   [2 + 4 + maxControl]byte // one masked control frame
+	n    int                      // number of bytes buffered in in
+}
+
+// Upgrade replies to a WebSocket handshake and returns the WebSocket, which
+// remains open after the Handler returns, until closed by the caller.
+// If the request is not a handshake, it replies 400 and returns ErrNotWebSocket.
+func (w *ResponseWriter) Upgrade(r *Request) (*WebSocket, error) {
+	if "" == r.WebSocketKey {
+		w.Error(400)
+		return nil, ErrNotWebSocket
+	}
+	sum := sha1.Sum([]byte(r.WebSocketKey + websocketGUID))
+	w.status = 101
+	_, err := w.conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) +
+		"\r\n" + w.header + "\r\n"))
+	if nil != err {
+		return nil, err
+	}
+	w.upgraded = true
+	return &WebSocket{conn: w.conn}, nil
+}
+
+// WriteText sends b to the client as a text message.
+func (ws *WebSocket) WriteText(b []byte) error {
+	return ws.write(opText, b)
+}
+
+// WriteBinary sends b to the client as a binary message.
+func (ws *WebSocket) WriteBinary(b []byte) error {
+	return ws.write(opBinary, b)
+}
+
+// Poll answers the control frames received from the client, if any. Poll never
+// blocks, and returns ErrClosed once the client has closed the WebSocket, or
+// ErrFrame if the client sent anything other than a control frame.
+func (ws *WebSocket) Poll() error {
+	n, err := ws.conn.Read(ws.in[ws.n:])
+	if nil != err {
+		return err
+	}
+	ws.n += n
+	for ws.n >= 2 {
+		size := int(ws.in[1] & 0x7F)
+		if 0 == ws.in[1]&0x80 || size > maxControl || 0 == ws.in[0]&0x08 {
+			return ErrFrame // unmasked, or not a control frame
+		}
+		end := 2 + 4 + size
+		if ws.n < end {
+			return nil // wait for the rest of the frame
+		}
+		payload := ws.in[6:end]
+		for i := range payload {
+			payload[i] ^= ws.in[2+i%4]
+		}
+		switch ws.in[0] & 0x0F {
+		case opClose:
+			ws.write(opClose, payload)
+			return ErrClosed
+		case opPing:
+			if err := ws.write(opPong, payload); nil != err {
+				return err
+			}
+		}
+		ws.n = copy(ws.in[:], ws.in[end:ws.n])
+	}
+	return nil
+}
+
+// Close sends the close frame and closes the connection.
+func (ws *WebSocket) Close() error {
+	ws.write(opClose, nil)
+	return ws.conn.Close()
+}
+
+// write sends a single unmasked frame with the given opcode and payload, which
+// may not exceed 64 KiB.
+func (ws *WebSocket) write(op byte, b []byte) error {
+	head := make([]byte, 2, 4+len(b))
+	head[0] = 0x80 | op // final fragment
+	switch n := len(b); {
+	case n < 126:
+		head[1] = byte(n)
+	case n <= 0xFFFF:
+		head[1] = 126
+		head = append(head, byte(n>>8), byte(n))
+	default:
+		return ErrMessageSize
+	}
+	_, err := ws.conn.Write(append(head, b...))
+	return err
+}
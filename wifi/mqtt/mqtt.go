@@ -0,0 +1,496 @@
+// Package mqtt implements a minimal MQTT 3.1.1 client using the TCP sockets of
+// the WiFi coprocessor.
+//
+// Only QoS 0 is supported for both publishing and subscribing, which requires
+// no session state or acknowledgements to be kept by the client.
+//
+// The Client is polled by calling Sync from the run loop, which (re)connects to
+// the broker as needed, maintains the keep-alive, and dispatches any received
+// messages to their subscription Handler.
+package mqtt
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+
+	"github.com/ardnew/weatherhub/wifi"
+)
+
+// Default constants for Client configuration.
+const (
+	DefaultPort      = 1883
+	DefaultKeepAlive = 60 * time.Second
+	DefaultRetry     = 30 * time.Second
+	DefaultTimeout   = 5 * time.Second
+)
+
+// MaxPacket defines the size of the largest packet that can be received.
+const MaxPacket = 512
+
+var (
+	ErrNotConnected = errors.New("not connected to MQTT broker")
+	ErrRefused      = errors.New("MQTT broker refused connection")
+	ErrTimeout      = errors.New("timeout waiting for MQTT broker")
+	ErrMalformed    = errors.New("malformed MQTT packet")
+	ErrPacketSize   = errors.New("MQTT packet exceeds maximum size")
+)
+
+// Control packet types.
+const (
+	typeConnect    = 1
+	typeConnAck    = 2
+	typePublish    = 3
+	typeSubscribe  = 8
+	typeSubAck     = 9
+	typePingReq    = 12
+	typePingResp   = 13
+	typeDisconnect = 14
+)
+
+// Config defines the MQTT broker and client identity.
+//
+// The Client is disabled if Broker is empty. ClientID and Prefix default to the
+// name of the WiFi device, so that several devices may share a broker. The name
+// is read each time the connection is established, so that a name changed at
+// runtime is used once the client reconnects.
+type Config struct {
+	Broker    string // host name or IP address
+	Port      int
+	ClientID  string
+	Prefix    string // replaces the leading "~" of topics and topic filters
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	Retry     time.Duration // how long to wait before reconnecting
+	Timeout   time.Duration // how long to wait for the broker to reply
+}
+
+// Handler is called with the topic and payload of each message received on a
+// subscribed topic. The payload is only valid until the Handler returns.
+type Handler func(topic string, payload []byte)
+
+type subscription struct {
+	topic  string // filter as given, which may begin with "~"
+	filter string // expanded when the connection was last established
+	handle Handler
+}
+
+// Client is an MQTT client connection.
+type Client struct {
+	device    *wifi.WiFi
+	config    Config
+	clientID  string // of the connection last established
+	prefix    string
+	conn      io.ReadWriteCloser
+	sub       []subscription
+	rx        []byte
+	lastSend  time.Time
+	lastRecv  time.Time
+	lastDial  time.Time
+	packetID  uint16
+	willTopic string // of the last will as given, or empty if none
+	willMsg   []byte
+}
+
+// New returns a new Client using the given WiFi device and configuration.
+// The connection is not established until the first call to Sync.
+func New(device *wifi.WiFi, config Config) *Client {
+
+	if config.Port == 0 {
+		config.Port = DefaultPort
+	}
+	if config.KeepAlive == 0 {
+		config.KeepAlive = DefaultKeepAlive
+	}
+	if config.Retry == 0 {
+		config.Retry = DefaultRetry
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	c := &Client{
+		device: device,
+		config: config,
+		rx:     make([]byte, 0, MaxPacket),
+	}
+	c.resolve()
+	return c
+}
+
+// resolve applies the current name of the WiFi device to the ClientID and
+// Prefix not configured, and expands the filter of each subscription with the
+// resulting Prefix.
+func (c *Client) resolve() {
+	c.clientID, c.prefix = c.config.ClientID, c.config.Prefix
+	if "" == c.clientID {
+		c.clientID = c.device.Name()
+	}
+	if "" == c.prefix {
+		c.prefix = c.device.Name()
+	}
+	for i := range c.sub {
+		c.sub[i].filter = c.expand(c.sub[i].topic)
+	}
+}
+
+// Subscribe registers a Handler for messages published to topics matching the
+// given filter, which may contain the wildcards "+" and "#", and may begin with
+// "~" (see Config Prefix). Subscriptions persist across reconnects.
+func (c *Client) Subscribe(filter string, handle Handler) error {
+	s := subscription{topic: filter, filter: c.expand(filter), handle: handle}
+	c.sub = append(c.sub, s)
+	if nil != c.conn {
+		if err := c.subscribe(s.filter); nil != err {
+			c.close()
+			return err
+		}
+	}
+	return nil
+}
+
+// expand returns topic with its leading "~", if any, replaced by Prefix, e.g.,
+// "~/summary" as "weatherhub/summary".
+func (c *Client) expand(topic string) string {
+	if strings.HasPrefix(topic, "~") {
+		return c.prefix + topic[1:]
+	}
+	return topic
+}
+
+// Publish sends a message with the given payload to topic, which may begin with
+// "~" (see Config Prefix).
+// Returns ErrNotConnected if the connection has not been established.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	if nil == c.conn {
+		return ErrNotConnected
+	}
+	topic = c.expand(topic)
+	flags := byte(0)
+	if retain {
+		flags |= 0x01
+	}
+	pkt := header(typePublish<<4|flags, 2+len(topic)+len(payload))
+	pkt = appendString(pkt, topic)
+	pkt = append(pkt, payload...)
+	if err := c.send(pkt); nil != err {
+		c.close()
+		return err
+	}
+	return nil
+}
+
+// Prefix returns the prefix replacing the leading "~" of topics.
+func (c *Client) Prefix() string {
+	return c.prefix
+}
+
+// SetWill sets the retained message the broker publishes to topic, which may
+// begin with "~", if the connection is lost without disconnecting, e.g., so
+// that other devices learn this one is offline. It takes effect when the
+// connection is next established.
+func (c *Client) SetWill(topic string, payload []byte) {
+	c.willTopic, c.willMsg = topic, payload
+}
+
+// Connected returns true if the connection to the broker is established.
+func (c *Client) Connected() bool {
+	return nil != c.conn
+}
+
+// Sync connects to the broker if not connected, maintains the keep-alive, and
+// dispatches any messages received since the previous call.
+func (c *Client) Sync() error {
+
+	if "" == c.config.Broker {
+		return nil // client disabled
+	}
+
+	now := time.Now()
+	if nil == c.conn {
+		if !c.lastDial.IsZero() && now.Sub(c.lastDial) < c.config.Retry {
+			return nil
+		}
+		c.lastDial = now
+		if err := c.connect(); nil != err {
+			c.close()
+			return err
+		}
+	}
+
+	// the broker disconnects clients silent for 1.5x the keep-alive period, so
+	// send a ping after half that time. if the broker has been silent for just as
+	// long, consider the connection lost.
+	if now.Sub(c.lastRecv) > c.config.KeepAlive*3/2 {
+		c.close()
+		return ErrTimeout
+	}
+	if now.Sub(c.lastSend) > c.config.KeepAlive/2 {
+		if err := c.send([]byte{typePingReq << 4, 0}); nil != err {
+			c.close()
+			return err
+		}
+	}
+
+	if err := c.receive(); nil != err {
+		c.close()
+		return err
+	}
+	for {
+		p, n, err := decode(c.rx)
+		if nil != err {
+			c.close()
+			return err
+		}
+		if 0 == n {
+			return nil // no complete packets remaining
+		}
+		c.dispatch(p)
+		c.rx = c.rx[:copy(c.rx, c.rx[n:])]
+	}
+}
+
+func (c *Client) connect() error {
+
+	host, err := c.device.GetHostByName(c.config.Broker)
+	if nil != err {
+		return err
+	}
+	var conn io.ReadWriteCloser
+	err = c.device.Do(func() error {
+		tc, err := net.DialTCP("tcp", nil, &net.TCPAddr{IP: host, Port: c.config.Port})
+		conn = tc
+		return err
+	})
+	if nil != err {
+		return err
+	}
+	c.conn, c.rx = c.device.Serialize(conn), c.rx[:0]
+	c.resolve()
+	will := c.expand(c.willTopic)
+
+	// CONNECT with clean session
+	flags := byte(0x02)
+	size := 10 + 2 + len(c.clientID)
+	if "" != will {
+		flags |= 0x24 // will, retained, QoS 0
+		size += 2 + len(will) + 2 + len(c.willMsg)
+	}
+	if "" != c.config.Username {
+		flags |= 0x80
+		size += 2 + len(c.config.Username)
+		if "" != c.config.Password {
+			flags |= 0x40
+			size += 2 + len(c.config.Password)
+		}
+	}
+	keep := uint16(c.config.KeepAlive / time.Second)
+	pkt := header(typeConnect<<4, size)
+	pkt = appendString(pkt, "MQTT")
+	pkt = append(pkt, 4, flags, byte(keep>>8), byte(keep))
+	pkt = appendString(pkt, c.clientID)
+	if flags&0x04 != 0 {
+		pkt = appendString(pkt, will)
+		pkt = appendString(pkt, string(c.willMsg))
+	}
+	if flags&0x80 != 0 {
+		pkt = appendString(pkt, c.config.Username)
+	}
+	if flags&0x40 != 0 {
+		pkt = appendString(pkt, c.config.Password)
+	}
+	if err := c.send(pkt); nil != err {
+		return err
+	}
+
+	// wait for CONNACK
+	start := time.Now()
+	for time.Since(start) <= c.config.Timeout {
+		if err := c.receive(); nil != err {
+			return err
+		}
+		p, n, err := decode(c.rx)
+		if nil != err {
+			return err
+		}
+		if 0 == n {
+			time.Sleep(5 * time.Millisecond)
+			continue
+		}
+		c.rx = c.rx[:copy(c.rx, c.rx[n:])]
+		if typeConnAck != p.kind || len(p.body) != 2 {
+			return ErrMalformed
+		}
+		if 0 != p.body[1] {
+			return ErrRefused
+		}
+		for _, s := range c.sub {
+			if err := c.subscribe(s.filter); nil != err {
+				return err
+			}
+		}
+		return nil
+	}
+	return ErrTimeout
+}
+
+func (c *Client) subscribe(filter string) error {
+	c.packetID++
+	if 0 == c.packetID {
+		c.packetID++ // packet identifiers must be non-zero
+	}
+	pkt := header(typeSubscribe<<4|0x02, 2+2+len(filter)+1)
+	pkt = append(pkt, byte(c.packetID>>8), byte(c.packetID))
+	pkt = appendString(pkt, filter)
+	pkt = append(pkt, 0) // QoS 0
+	return c.send(pkt)
+}
+
+func (c *Client) dispatch(p packet) {
+	if typePublish != p.kind {
+		return // CONNACK, SUBACK, PINGRESP require no action
+	}
+	topic, payload, ok := p.publish()
+	if !ok {
+		return
+	}
+	for _, s := range c.sub {
+		if Match(s.filter, topic) {
+			s.handle(topic, payload)
+		}
+	}
+}
+
+// receive appends any data available on the socket to the receive buffer.
+func (c *Client) receive() error {
+	if len(c.rx) == cap(c.rx) {
+		return ErrPacketSize
+	}
+	n, err := c.conn.Read(c.rx[len(c.rx):cap(c.rx)])
+	if n > 0 {
+		c.rx = c.rx[:len(c.rx)+n]
+		c.lastRecv = time.Now()
+	}
+	return err
+}
+
+func (c *Client) send(pkt []byte) error {
+	if _, err := c.conn.Write(pkt); nil != err {
+		return err
+	}
+	c.lastSend = time.Now()
+	return nil
+}
+
+func (c *Client) close() {
+	if nil != c.conn {
+		c.conn.Write([]byte{typeDisconnect << 4, 0})
+		c.conn.Close()
+		c.conn = nil
+	}
+	c.rx = c.rx[:0]
+}
+
+// Match returns true if the given topic matches filter, which may contain the
+// single-level wildcard "+" and the multi-level wildcard "#".
+func Match(filter, topic string) bool {
+	for {
+		f, t := filter, topic
+		if i := strings.IndexByte(filter, '/'); i >= 0 {
+			f = filter[:i]
+		}
+		if i := strings.IndexByte(topic, '/'); i >= 0 {
+			t = topic[:i]
+		}
+		if "#" == f {
+			return true
+		}
+		if "+" != f && f != t {
+			return false
+		}
+		filter, topic = filter[len(f):], topic[len(t):]
+		if 0 == len(filter) || 0 == len(topic) {
+			return len(filter) == len(topic) || "/#" == filter
+		}
+		filter, topic = filter[1:], topic[1:] // skip '/'
+	}
+}
+
+// packet is a decoded MQTT control packet.
+type packet struct {
+	kind  byte // control packet type
+	flags byte
+	body  []byte
+}
+
+// decode returns the first complete packet in buf and its encoded length.
+// If buf does not contain a complete packet, the returned length is 0.
+// The body of the returned packet refers to the content of buf.
+func decode(buf []byte) (p packet, n int, err error) {
+	size, shift := 0, uint(0)
+	for i := 1; ; i++ {
+		if i > 4 {
+			return p, 0, ErrMalformed
+		}
+		if i >= len(buf) {
+			return p, 0, nil // incomplete length
+		}
+		size |= int(buf[i]&0x7F) << shift
+		shift += 7
+		if 0 == buf[i]&0x80 {
+			n = i + 1 + size
+			if n > MaxPacket {
+				return p, 0, ErrPacketSize
+			}
+			if n > len(buf) {
+				return p, 0, nil // incomplete body
+			}
+			return packet{kind: buf[0] >> 4, flags: buf[0] & 0x0F, body: buf[i+1 : n]}, n, nil
+		}
+	}
+}
+
+// publish returns the topic and payload of a PUBLISH packet.
+func (p packet) publish() (topic string, payload []byte, ok bool) {
+	if len(p.body) < 2 {
+		return "", nil, false
+	}
+	size := int(p.body[0])<<8 | int(p.body[1])
+	if len(p.body) < 2+size {
+		return "", nil, false
+	}
+	topic, payload = string(p.body[2:2+size]), p.body[2+size:]
+	if qos := (p.flags >> 1) & 0x03; qos > 0 {
+		// skip the packet identifier
+		if len(payload) < 2 {
+			return "", nil, false
+		}
+		payload = payload[2:]
+	}
+	return topic, payload, true
+}
+
+// header returns a buffer containing the fixed header of a packet with the
+// given first byte and remaining length, with capacity for the remainder.
+func header(first byte, size int) []byte {
+	pkt := make([]byte, 0, 5+size)
+	pkt = append(pkt, first)
+	for {
+		b := byte(size & 0x7F)
+		if size >>= 7; size > 0 {
+			b |= 0x80
+		}
+		pkt = append(pkt, b)
+		if 0 == size {
+			return pkt
+		}
+	}
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(append(b, byte(len(s)>>8), byte(len(s))), s...)
+}
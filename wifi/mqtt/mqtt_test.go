@@ -0,0 +1,53 @@
+package mqtt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// FuzzDecode checks that decode never panics nor returns a packet extending
+// beyond its input, and that a decoded PUBLISH packet is parsed safely.
+func FuzzDecode(f *testing.F) {
+	pub := append(header(0x30, 2+len("a/b")+len("hi")), 0, 3)
+	pub = append(append(pub, "a/b"...), "hi"...)
+	f.Add(pub)
+	f.Add([]byte{0x32, 0x80, 0x80, 0x80, 0x80, 0x01})
+	f.Add([]byte{0xD0, 0x00})
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		p, n, err := decode(buf)
+		if nil != err || 0 == n {
+			return
+		}
+		if n > len(buf) || n > MaxPacket {
+			t.Fatalf("decoded length %d of %d bytes", n, len(buf))
+		}
+		if 3 == p.kind {
+			p.publish()
+		}
+		// the packet encoded again decodes the same
+		again := append(header(p.kind<<4|p.flags, len(p.body)), p.body...)
+		q, m, err := decode(again)
+		if nil != err || m != len(again) || q.kind != p.kind || q.flags != p.flags ||
+			!bytes.Equal(q.body, p.body) {
+			t.Fatalf("re-encoded packet decodes as %+v %d %v", q, m, err)
+		}
+	})
+}
+
+// FuzzMatch checks that Match never panics, that a topic without wildcards
+// matches itself, and that "#" matches every topic.
+func FuzzMatch(f *testing.F) {
+	f.Add("home/+/temp", "home/kitchen/temp")
+	f.Add("home/#", "home")
+	f.Add("+/+", "/")
+	f.Fuzz(func(t *testing.T, filter, topic string) {
+		Match(filter, topic)
+		if !Match("#", topic) {
+			t.Fatalf("# does not match %q", topic)
+		}
+		if !strings.ContainsAny(topic, "+#") && !Match(topic, topic) {
+			t.Fatalf("%q does not match itself", topic)
+		}
+	})
+}
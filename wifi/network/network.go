@@ -1,12 +1,58 @@
+// Package network defines the access points the device connects to.
+//
+// Access points are provisioned at runtime, e.g., with the serial console
+// command "set wifi", and saved with the Settings (see package config). None
+// are compiled in, but a developer may list their own in Network from a file
+// ignored by git, credentials.go, built only with the tag "credentials", e.g.:
+//
+//	//go:build credentials
+//
+//	package network
+//
+//	func init() { Network = append(Network, AP{SSID: "home", Pass: "secret"}) }
+//
+// Builds with the tag "release" fail if the tag "credentials" is also given.
 package network
 
+import "sort"
+
+// Network contains the access points tried, in order, after the access point
+// of the Settings, which is none unless built with the tag "credentials".
+var Network []AP
+
+// AP defines an access point and how it is chosen among the others.
+//
+// Several access points sharing the same SSID, e.g., a mesh or a router and its
+// extenders, may be told apart by BSSID, the hardware address of each radio.
+// The coprocessor cannot be directed to a BSSID, so a pinned access point is
+// only accepted if the radio joined by SSID has the pinned BSSID.
+//
+// The coprocessor radio is 2.4 GHz only, so there is no band to prefer.
+//
+// An access point without a Pass is an open network. If WEP is true, Pass is
+// the WEP key instead of a WPA passphrase, given as 10 or 26 hex digits.
 type AP struct {
 	SSID, Pass string
+	WEP        bool   // Pass is a WEP key
+	Priority   int    // access points with higher priority are tried first
+	Hidden     bool   // SSID is not broadcast, so it is tried even if not scanned
+	BSSID      string // hardware address "xx:xx:xx:xx:xx:xx" required, if any
+	Wired      bool   // Ethernet link, not an access point (see package wifi)
+	Cellular   bool   // cellular link, not an access point (see wifi.Fallback)
+}
+
+// Unsecured returns true if traffic with the access point is unencrypted or
+// encrypted with WEP, which is easily broken. Wired and cellular links are never
+// unsecured.
+func (ap AP) Unsecured() bool {
+	return !ap.Wired && !ap.Cellular && ("" == ap.Pass || ap.WEP)
 }
 
-var Network = []AP{
-	{
-		SSID: "Shultzabarger",
-		Pass: "Samus01!",
-	},
+// Order sorts the given access points by descending Priority. Access points of
+// equal priority are kept in their given order.
+func Order(ap []AP) []AP {
+	sort.SliceStable(ap, func(i, j int) bool {
+		return ap[i].Priority > ap[j].Priority
+	})
+	return ap
 }
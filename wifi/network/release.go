@@ -0,0 +1,8 @@
+//go:build release && credentials
+// +build release,credentials
+
+package network
+
+// Release builds must not contain hard-coded credentials. This reference to an
+// undefined identifier stops the build with the tag that must be removed.
+var _ = releaseBuildExcludesTag_credentials
@@ -0,0 +1,481 @@
+//go:build !ethernet && !fake
+// +build !ethernet,!fake
+
+package wifi
+
+import (
+	"machine"
+	"strings"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+	"tinygo.org/x/drivers/wifinina"
+
+	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/wifi/network"
+)
+
+// WiFi wraps the WiFiNINA device driver.
+//
+// All calls to the driver are serialized (see Do), so that the WiFi may be used
+// concurrently.
+type WiFi struct {
+	nina   *wifinina.Device
+	config Config
+	bus    bus
+	ip     wifinina.IPAddress
+	dns    dnsCache
+
+	driver   net.DeviceDriver // sockets of the coprocessor
+	fallback Fallback         // link used if no access point is joined
+	falling  bool             // fallback is in use
+	up       bool             // fallback was connected when last checked
+	checked  time.Time        // when the fallback was last checked
+	backoff  time.Duration    // interval between retries of the access points
+	retry    time.Time        // when the access points are next retried
+}
+
+// Fallback is a network link used while no access point can be joined, e.g., a
+// cellular modem (see package cellular). While in use, it drives the sockets of
+// package net in place of the coprocessor. It can only dial out, so the HTTP
+// server and ping are unavailable. Connected reports whether the link is still
+// up, e.g., the modem is registered and its data connection holds an address.
+type Fallback interface {
+	net.DeviceDriver
+	Connect() (net.IP, error)
+	Connected() bool
+	GetHostByName(name string) (net.IP, error)
+}
+
+// Constants defining how the access points are retried while the Fallback is
+// in use: first after FallbackRetry, then at doubling intervals up to
+// FallbackMaxRetry. The state of the Fallback is queried at most once every
+// fallbackCheck, since each query is a few commands to the modem.
+const (
+	FallbackRetry    = 2 * time.Minute
+	FallbackMaxRetry = 30 * time.Minute
+	fallbackCheck    = 10 * time.Second
+)
+
+// fallbackAP is the "access point" of the Fallback (see InRange).
+var fallbackAP = network.AP{SSID: "cellular", Cellular: true}
+
+// New returns a new WiFi using the default peripherals and GPIO pins and the
+// given configuration.
+// The SPI interface connected to the WiFi coprocessor is also initialized and
+// configured for use.
+// This method will always return a nil WiFi or a nil error. It will never
+// return nil or non-nil for both WiFi and error.
+func New(config Config) (*WiFi, error) {
+
+	if config.Name == "" {
+		config.Name = DefaultName
+	}
+
+	// configure the SPI interface connected to ESP32
+	spiConfig := machine.SPIConfig{
+		Frequency: 8 * 1.0e6,
+		SDO:       machine.NINA_SDO,
+		SDI:       machine.NINA_SDI,
+		SCK:       machine.NINA_SCK,
+	}
+	machine.NINA_SPI.Configure(spiConfig)
+
+	// configure the WiFiNINA driver
+	nina := &wifinina.Device{
+		SPI:   machine.NINA_SPI,
+		CS:    machine.NINA_CS,
+		ACK:   machine.NINA_ACK,
+		GPIO0: machine.NINA_GPIO0,
+		RESET: machine.NINA_RESETN,
+	}
+	nina.Configure()
+
+	return &WiFi{nina: nina, config: config, bus: newBus(), driver: net.ActiveDevice}, nil
+}
+
+// SetFallback sets the link tried once no access point can be joined.
+func (w *WiFi) SetFallback(f Fallback) {
+	w.fallback = f
+}
+
+// Connect establishes an AP connection using given SSID and passphrase, or WEP
+// key, if any.
+// An error is returned if the AP could not be reached or an IP not obtained.
+func (w *WiFi) Connect(ap network.AP) error {
+
+	if ap.Cellular {
+		return w.connectFallback(ap)
+	}
+
+	// attempt to connect to SSID with passphrase, WEP key, or neither if open,
+	// announcing our host name in the DHCP request.
+	time.Sleep(2 * time.Second)
+	if err := w.bus.do(func() error {
+		if err := w.nina.SetHostname(w.config.Name); nil != err {
+			return err
+		}
+		switch {
+		case "" == ap.Pass:
+			return w.nina.SetNetwork(ap.SSID)
+		case ap.WEP:
+			return w.nina.SetKey(ap.SSID, 0, ap.Pass)
+		}
+		return w.nina.SetPassphrase(ap.SSID, ap.Pass)
+	}); nil != err {
+		return err
+	}
+
+	// wait for connection established
+	if !w.waitWithTimeout(w.joined) {
+		return ErrConnectToAP
+	}
+	// reject any other radio sharing the SSID of a pinned access point
+	if "" != ap.BSSID {
+		var bssid wifinina.MACAddress
+		w.bus.do(func() (err error) {
+			bssid, err = w.nina.GetCurrentBSSID()
+			return err
+		})
+		if !strings.EqualFold(bssid.String(), ap.BSSID) {
+			w.bus.do(w.nina.Disconnect)
+			return ErrWrongBSSID
+		}
+	}
+	// wait for DHCP IP lease
+	if !w.waitWithTimeout(w.leased) {
+		return ErrNoIPAddress
+	}
+
+	// the sockets of the coprocessor are used again if the fallback was in use.
+	if w.falling {
+		w.falling = false
+		net.UseDriver(w.driver)
+	}
+	w.backoff = 0
+
+	// update model with our connection details
+	model.Set(func(m *model.Model) {
+		m.AP, m.IP = ap, w.ip
+	})
+
+	return nil
+}
+
+// connectFallback connects the Fallback, unless it is still connected, and
+// makes it the driver of the sockets of package net. The access points are
+// retried after an interval doubling each time the Fallback is connected.
+func (w *WiFi) connectFallback(ap network.AP) error {
+	if nil == w.fallback {
+		return ErrConnectToAP
+	}
+	if !w.falling || !w.fallbackUp() {
+		var ip net.IP
+		if err := w.bus.do(func() (err error) {
+			ip, err = w.fallback.Connect()
+			return err
+		}); nil != err {
+			w.up = false
+			return err
+		}
+		w.falling, w.up, w.checked = true, true, time.Now()
+		net.UseDriver(w.fallback)
+		addr, _ := wifinina.ParseIPv4(ip.String())
+		model.Set(func(m *model.Model) {
+			m.AP, m.IP = ap, addr
+		})
+	}
+
+	switch {
+	case 0 == w.backoff:
+		w.backoff = FallbackRetry
+	case w.backoff < FallbackMaxRetry:
+		if w.backoff *= 2; w.backoff > FallbackMaxRetry {
+			w.backoff = FallbackMaxRetry
+		}
+	}
+	w.retry = time.Now().Add(w.backoff)
+	return nil
+}
+
+// Reconnect returns true if the connection should be established again, since
+// the Fallback is in use and either its link was lost or the access points are
+// due to be retried (see FallbackRetry). The connection to an access point is
+// never reestablished.
+func (w *WiFi) Reconnect() bool {
+	if !w.falling {
+		return false
+	}
+	return !w.fallbackUp() || !time.Now().Before(w.retry)
+}
+
+// fallbackUp returns true if the Fallback is connected, querying it at most
+// once every fallbackCheck.
+func (w *WiFi) fallbackUp() bool {
+	if time.Since(w.checked) >= fallbackCheck {
+		w.checked = time.Now()
+		w.bus.do(func() error {
+			w.up = w.fallback.Connected()
+			return nil
+		})
+	}
+	return w.up
+}
+
+// MAC returns the hardware address of the network interface.
+func (w *WiFi) MAC() (uint64, error) {
+	var mac wifinina.MACAddress
+	err := w.bus.do(func() error {
+		var err error
+		mac, err = w.nina.GetMACAddress()
+		return err
+	})
+	return uint64(mac), err
+}
+
+// Scan calls visit with the SSID and signal strength (dBm) of each access point
+// in range.
+func (w *WiFi) Scan(visit func(ssid string, rssi int32)) error {
+	return w.bus.do(func() error {
+		n, err := w.nina.StartScanNetworks()
+		if nil != err {
+			return err
+		}
+		for i := 0; i < int(n); i++ {
+			visit(w.nina.GetNetworkSSID(i), w.nina.GetNetworkRSSI(i))
+		}
+		return nil
+	})
+}
+
+// InRange returns the given access points that are either in range or hidden,
+// in the same order. A pinned access point is in range only if its BSSID was
+// scanned. All of the given access points are returned if the scan fails or
+// finds nothing, since the coprocessor may not support scanning at all. The
+// Fallback, if any, is always in range, following all access points.
+func (w *WiFi) InRange(ap []network.AP) []network.AP {
+	var seen []network.AP
+	w.bus.do(func() error {
+		n, err := w.nina.StartScanNetworks()
+		if nil != err {
+			return err
+		}
+		for i := 0; i < int(n); i++ {
+			bssid, _ := w.nina.GetNetworkBSSID(i)
+			seen = append(seen, network.AP{
+				SSID:  w.nina.GetNetworkSSID(i),
+				BSSID: bssid.String(),
+			})
+		}
+		return nil
+	})
+	in := ap[:len(ap):len(ap)]
+	if 0 != len(seen) {
+		in = nil
+		for _, a := range ap {
+			if a.Hidden || scanned(seen, a) {
+				in = append(in, a)
+			}
+		}
+	}
+	if nil != w.fallback {
+		in = append(in, fallbackAP)
+	}
+	return in
+}
+
+// scanned returns true if the given access point is among those seen.
+func scanned(seen []network.AP, ap network.AP) bool {
+	for _, s := range seen {
+		if s.SSID == ap.SSID &&
+			("" == ap.BSSID || strings.EqualFold(s.BSSID, ap.BSSID)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WiFi) getHostByName(name string) (net.IP, error) {
+	if w.falling {
+		var ip net.IP
+		err := w.bus.do(func() (err error) {
+			ip, err = w.fallback.GetHostByName(name)
+			return err
+		})
+		return ip, err
+	}
+	var addr wifinina.IPAddress
+	err := w.bus.do(func() (err error) {
+		addr, err = w.nina.GetHostByName(name)
+		return err
+	})
+	if nil != err {
+		return nil, err
+	}
+	return net.ParseIP(addr.String()), nil
+}
+
+// ping sends an ICMP echo request to the given IP address with the given time
+// to live, and returns the round-trip time.
+func (w *WiFi) ping(ip net.IP, ttl uint8) (time.Duration, error) {
+	if w.falling {
+		return 0, ErrPingFailed
+	}
+	addr, err := wifinina.ParseIPv4(ip.String())
+	if nil != err {
+		return 0, err
+	}
+	var rtt int16
+	w.bus.do(func() error {
+		rtt = w.nina.Ping(addr, ttl)
+		return nil
+	})
+	if rtt < 0 {
+		return 0, ErrPingFailed
+	}
+	return time.Duration(rtt) * time.Millisecond, nil
+}
+
+// Gateway returns the IP address of the default gateway, i.e., the router of
+// the access point.
+func (w *WiFi) Gateway() (net.IP, error) {
+	if w.falling {
+		return nil, ErrNotConnected
+	}
+	var gateway wifinina.IPAddress
+	err := w.bus.do(func() (err error) {
+		_, _, gateway, err = w.nina.GetIP()
+		return err
+	})
+	if nil != err {
+		return nil, err
+	}
+	return net.ParseIP(gateway.String()), nil
+}
+
+// Listener accepts TCP connections on a local port.
+type Listener struct {
+	nina *wifinina.Device
+	bus  bus
+	sock uint8
+}
+
+// Conn is a TCP connection accepted by a Listener.
+type Conn struct {
+	nina *wifinina.Device
+	bus  bus
+	sock uint8
+}
+
+// noSocket is returned by the coprocessor when no socket is available.
+const noSocket = 0xFF
+
+// Listen starts a TCP server on the given local port.
+func (w *WiFi) Listen(port uint16) (*Listener, error) {
+	if !w.isConnected() || w.falling {
+		return nil, ErrNotConnected
+	}
+	var sock uint8
+	err := w.bus.do(func() (err error) {
+		if sock, err = w.nina.GetSocket(); nil != err {
+			return err
+		}
+		return w.nina.StartServer(port, sock, wifinina.ProtoModeTCP)
+	})
+	if nil != err {
+		return nil, err
+	}
+	return &Listener{nina: w.nina, bus: w.bus, sock: sock}, nil
+}
+
+// Accept returns a connection from the next waiting client, or nil if no client
+// is waiting. Accept never blocks.
+func (l *Listener) Accept() (*Conn, error) {
+	var sock uint16
+	err := l.bus.do(func() (err error) {
+		sock, err = l.nina.AvailServer(l.sock)
+		return err
+	})
+	if nil != err {
+		return nil, err
+	}
+	if noSocket == sock {
+		return nil, nil
+	}
+	return &Conn{nina: l.nina, bus: l.bus, sock: uint8(sock)}, nil
+}
+
+// Close stops the TCP server.
+func (l *Listener) Close() error {
+	return l.bus.do(func() error {
+		return l.nina.StopClient(l.sock)
+	})
+}
+
+// Read reads any data received from the client into b. Read never blocks, and
+// returns 0 with a nil error if no data has been received.
+func (c *Conn) Read(b []byte) (n int, err error) {
+	err = c.bus.do(func() error {
+		avail, err := c.nina.AvailData(c.sock)
+		if nil != err || 0 == avail {
+			return err
+		}
+		n, err = c.nina.GetDataBuf(c.sock, b)
+		return err
+	})
+	return n, err
+}
+
+// Write sends b to the client.
+func (c *Conn) Write(b []byte) (n int, err error) {
+	err = c.bus.do(func() error {
+		sent, err := c.nina.SendData(b, c.sock)
+		if n = int(sent); nil != err {
+			return err
+		}
+		_, err = c.nina.CheckDataSent(c.sock)
+		return err
+	})
+	return n, err
+}
+
+// Close closes the connection to the client.
+func (c *Conn) Close() error {
+	return c.bus.do(func() error {
+		return c.nina.StopClient(c.sock)
+	})
+}
+
+func (w *WiFi) isConnected() bool {
+	if w.falling {
+		return w.fallbackUp()
+	}
+	return w.joined()
+}
+
+func (w *WiFi) hasIP() bool {
+	if w.falling {
+		return w.fallbackUp() // the address is part of the data connection
+	}
+	return w.leased()
+}
+
+// joined returns true if the coprocessor is connected to an access point, even
+// while the Fallback is in use.
+func (w *WiFi) joined() bool {
+	var stat wifinina.ConnectionStatus
+	w.bus.do(func() error {
+		stat, _ = w.nina.GetConnectionStatus()
+		return nil
+	})
+	return wifinina.StatusConnected == stat
+}
+
+// leased returns true if the coprocessor has an IP address, even while the
+// Fallback is in use.
+func (w *WiFi) leased() bool {
+	return nil == w.bus.do(func() (err error) {
+		w.ip, _, _, err = w.nina.GetIP()
+		return err
+	})
+}
@@ -8,7 +8,9 @@ import (
 
 	"tinygo.org/x/drivers/net"
 
+	"github.com/ardnew/weatherhub/feed"
 	"github.com/ardnew/weatherhub/model"
+	"github.com/ardnew/weatherhub/tz"
 	"github.com/ardnew/weatherhub/wifi"
 )
 
@@ -26,6 +28,8 @@ const (
 var (
 	ErrReadDatagramSize = errors.New("received unexpected NTP datagram size")
 	ErrReadNoResponse   = errors.New("timeout waiting for NTP datagram reply")
+	ErrKissOfDeath      = errors.New("NTP server refused request (kiss-o'-death)")
+	ErrMalformedReply   = errors.New("received malformed NTP reply")
 )
 
 type Config struct {
@@ -42,6 +46,9 @@ type NTP struct {
 	device   *wifi.WiFi
 	config   Config
 	locale   *time.Location
+	autoZone bool   // use time zone of Model Location if known
+	zone     string // name of time zone in locale
+	offset   int    // offset of time zone in locale
 	lastSync time.Time
 	lastPost time.Time
 	datagram datagram
@@ -63,6 +70,7 @@ func New(device *wifi.WiFi, config Config) *NTP {
 	if config.LocalPort == 0 {
 		config.LocalPort = DefaultLocalPort
 	}
+	autoZone := config.TZOffset == 0
 	if config.TZOffset == 0 {
 		config.TZOffset = DefaultTZOffset
 	}
@@ -77,6 +85,7 @@ func New(device *wifi.WiFi, config Config) *NTP {
 		device:   device,
 		config:   config,
 		locale:   time.FixedZone("localtime", config.TZOffset),
+		autoZone: autoZone,
 		datagram: make(datagram, datagramSize),
 	}
 }
@@ -99,28 +108,35 @@ func (n *NTP) Sync() error {
 		}
 		radd := &net.UDPAddr{IP: host, Port: n.config.RemotePort}
 		ladd := &net.UDPAddr{Port: n.config.LocalPort}
-		// create UDP socket
-		conn, err := net.DialUDP("udp", ladd, radd)
-		if nil != err {
+		// create UDP socket, send NTP request, and close the socket, all in a
+		// single transaction with the coprocessor.
+		var sync model.TimeSync
+		err = n.device.Do(func() error {
+			conn, err := net.DialUDP("udp", ladd, radd)
+			if nil != err {
+				return err
+			}
+			defer conn.Close()
+			sync, err = n.request(conn)
 			return err
-		}
-		// send NTP request
-		curr, err := n.request(conn)
-		// curr, err := getCurrentTime(conn)
+		})
 		if nil != err {
 			return err
 		}
-		// close the socket
-		conn.Close()
 		// update system time
-		runtime.AdjustTimeOffset(-1 * int64(time.Since(curr)))
+		runtime.AdjustTimeOffset(int64(sync.Offset))
 		n.lastSync = time.Now()
+		sync.Server, sync.Synced = n.config.Server[idx], n.lastSync
+		model.Mod(func(m *model.Model) {
+			m.Diagnostics.TimeSync = sync
+		})
 	}
 
 	// all other packages in the program rely on the Model data as time keeper.
 	// update it as often as requested by Config field Precision.
 	if modelExpired {
 		n.lastPost = time.Now()
+		n.updateZone(n.lastPost)
 		model.Set(func(m *model.Model) {
 			m.Time = n.lastPost.In(n.locale)
 		})
@@ -129,23 +145,71 @@ func (n *NTP) Sync() error {
 	return nil
 }
 
+// updateZone adopts the time zone of the Model Location, if known, when no time
+// zone offset was configured. If the zone is defined in package tz, its daylight
+// saving time rule determines the offset in effect at time now. Otherwise, the
+// fixed offset of the Location is used.
+func (n *NTP) updateZone(now time.Time) {
+	if !n.autoZone {
+		return
+	}
+	loc := model.Peek().Location
+	if "" == loc.Zone {
+		return
+	}
+	name, offset := loc.Zone, loc.Offset
+	if z, ok := tz.Lookup(loc.Zone); ok {
+		name, offset = z.At(now)
+	}
+	if name != n.zone || offset != n.offset {
+		n.zone, n.offset = name, offset
+		n.locale = time.FixedZone(name, offset)
+	}
+}
+
+// Expire causes the next call to Sync to synchronize with the NTP server,
+// regardless of the configured Interval.
+func (n *NTP) Expire() {
+	n.lastSync = time.Time{}
+}
+
 func isExpired(at, since time.Time, span time.Duration) bool {
 	return at.IsZero() || at.Sub(since) >= span
 }
 
+// isExpired returns whether the system time must be synchronized with the NTP
+// server, which is aligned to wall-clock boundaries of the Interval (see
+// feed.Due) once first synchronized, and whether the Model time must be updated.
 func (n *NTP) isExpired(at time.Time) (system, model bool) {
-	return isExpired(at, n.lastSync, n.config.Interval),
+	return n.lastSync.IsZero() || feed.Due(at, n.lastSync, n.config.Interval),
 		isExpired(at, n.lastPost, n.config.Precision)
 }
 
-func (n *NTP) request(conn *net.UDPSerialConn) (time.Time, error) {
+// request sends a request and returns the offset of the system time from that
+// of the server, the round-trip delay, and the stratum of the server. The
+// offset assumes the delay is symmetric.
+func (n *NTP) request(conn *net.UDPSerialConn) (model.TimeSync, error) {
+	sent := time.Now()
 	if err := n.write(conn); nil != err {
-		return time.Time{}, err
+		return model.TimeSync{}, err
 	}
 	if err := n.read(conn); nil != err {
-		return time.Time{}, err
+		return model.TimeSync{}, err
+	}
+	recv := time.Now()
+	if 0 == n.datagram[1] {
+		return model.TimeSync{}, ErrKissOfDeath
 	}
-	return n.datagram.parse(), nil
+	if !n.datagram.valid() {
+		return model.TimeSync{}, ErrMalformedReply
+	}
+	// the server received the request at t2 and replied at t3
+	t2, t3 := n.datagram.timestamp(32), n.datagram.timestamp(40)
+	return model.TimeSync{
+		Stratum: n.datagram[1],
+		Offset:  (t2.Sub(sent) + t3.Sub(recv)) / 2,
+		Delay:   recv.Sub(sent) - t3.Sub(t2),
+	}, nil
 }
 
 func (n *NTP) write(conn *net.UDPSerialConn) error {
@@ -198,9 +262,31 @@ func (d *datagram) reset() {
 	}
 }
 
-func (d *datagram) parse() time.Time {
+// valid returns true if the datagram is a reply from a synchronized server in
+// server mode, with a transmit timestamp. Any other reply, e.g., corrupted or
+// spoofed, would otherwise set the system time wildly wrong.
+func (d *datagram) valid() bool {
+	const (
+		modeServer = 4
+		leapAlarm  = 3 // leap indicator of an unsynchronized server
+	)
+	if leapAlarm == (*d)[0]>>6 || modeServer != (*d)[0]&0x07 || (*d)[1] > 15 {
+		return false
+	}
+	for _, b := range (*d)[40:48] {
+		if 0 != b {
+			return true
+		}
+	}
+	return false
+}
+
+// timestamp returns the time of the NTP timestamp at the given offset, i.e.,
+// seconds since 1900 followed by the fraction of a second, both 32-bit.
+func (d *datagram) timestamp(offset int) time.Time {
 	const seventyYears = 2208988800
-	t := uint32((*d)[40])<<24 | uint32((*d)[41])<<16 |
-		uint32((*d)[42])<<8 | uint32((*d)[43])
-	return time.Unix(int64(t-seventyYears), 0)
+	b := (*d)[offset : offset+8]
+	t := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	f := uint64(b[4])<<24 | uint64(b[5])<<16 | uint64(b[6])<<8 | uint64(b[7])
+	return time.Unix(int64(t-seventyYears), int64(f*uint64(time.Second)>>32))
 }
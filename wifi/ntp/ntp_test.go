@@ -0,0 +1,37 @@
+package ntp
+
+import (
+	"testing"
+	"time"
+)
+
+// FuzzDatagram checks that any reply of the size of an NTP datagram is either
+// rejected or a reply from a synchronized server in server mode, and that its
+// timestamps decode without panicking.
+func FuzzDatagram(f *testing.F) {
+	reply := make([]byte, datagramSize)
+	reply[0], reply[1] = 0b00100100, 1 // LI 0, version 4, server mode, stratum 1
+	reply[40] = 0xE0                   // transmit timestamp
+	f.Add(reply)
+	f.Add(make([]byte, datagramSize))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		d := make(datagram, datagramSize)
+		copy(d, b)
+		if d.valid() {
+			if 3 == d[0]>>6 {
+				t.Fatalf("accepted unsynchronized server: % x", d[:2])
+			}
+			if 4 != d[0]&0x07 {
+				t.Fatalf("accepted mode %d", d[0]&0x07)
+			}
+			if d[1] > 15 {
+				t.Fatalf("accepted stratum %d", d[1])
+			}
+			if d.timestamp(40).Equal(time.Unix(-2208988800, 0)) {
+				t.Fatal("accepted zero transmit timestamp")
+			}
+		}
+		d.timestamp(32)
+		d.timestamp(40)
+	})
+}
@@ -0,0 +1,169 @@
+package w5500
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers/net"
+)
+
+// DHCPTimeout defines how long to wait for each reply of the DHCP server.
+const DHCPTimeout = 4 * time.Second
+
+// Lease is the IP configuration leased from a DHCP server.
+type Lease struct {
+	IP, Subnet, Gateway, DNS net.IP
+	Server                   net.IP // identifier of the DHCP server
+	Start                    time.Time
+	Duration                 time.Duration
+}
+
+// Valid returns true if an IP address has been leased.
+func (l Lease) Valid() bool {
+	return 4 == len(l.IP)
+}
+
+// Renew returns the time at which the lease should be renewed, halfway through
+// its duration.
+func (l Lease) Renew() time.Time {
+	return l.Start.Add(l.Duration / 2)
+}
+
+// Message types and options of DHCP (RFC 2131, 2132).
+const (
+	dhcpDiscover = 1
+	dhcpOffer    = 2
+	dhcpRequest  = 3
+	dhcpAck      = 5
+
+	optSubnet    = 1
+	optRouter    = 3
+	optDNS       = 6
+	optHostname  = 12
+	optRequested = 50
+	optLease     = 51
+	optType      = 53
+	optServer    = 54
+	optParams    = 55
+	optEnd       = 255
+
+	dhcpHeader = 240 // fixed fields and magic cookie
+)
+
+// DHCP leases an IP configuration from the DHCP server of the network, and
+// configures the controller to use it. The given host name is announced to the
+// server, which many routers register in their local DNS. Returns ErrNoLease if
+// no server offers an address.
+func (d *Device) DHCP(hostname string) (Lease, error) {
+	if err := d.open(sockUtil, modeUDP, 68); nil != err {
+		return Lease{}, err
+	}
+	defer d.close(sockUtil)
+	broadcast := net.IP{255, 255, 255, 255}
+	if err := d.setDestination(sockUtil, broadcast, 67); nil != err {
+		return Lease{}, err
+	}
+
+	xid := uint32(time.Now().UnixNano())
+	offer, err := d.exchange(xid, dhcpDiscover, hostname, Lease{}, dhcpOffer)
+	if nil != err {
+		return Lease{}, err
+	}
+	lease, err := d.exchange(xid, dhcpRequest, hostname, offer, dhcpAck)
+	if nil != err {
+		return Lease{}, err
+	}
+	lease.Start = time.Now()
+
+	d.write(regSIPR, 0, lease.IP)
+	if 4 == len(lease.Subnet) {
+		d.write(regSUBR, 0, lease.Subnet)
+	}
+	if 4 == len(lease.Gateway) {
+		d.write(regGAR, 0, lease.Gateway)
+	}
+	d.lease = lease
+	return lease, nil
+}
+
+// exchange sends a DHCP message of the given type, requesting the address of
+// the given offer if any, and waits for a reply of the given type.
+func (d *Device) exchange(xid uint32, typ uint8, hostname string, offer Lease, reply uint8) (Lease, error) {
+	m := d.query[:]
+	for i := range m[:dhcpHeader] {
+		m[i] = 0
+	}
+	m[0], m[1], m[2] = 1, 1, 6 // BOOTREQUEST, Ethernet, hardware address length
+	m[4], m[5], m[6], m[7] = byte(xid>>24), byte(xid>>16), byte(xid>>8), byte(xid)
+	m[10] = 0x80 // ask for broadcast replies, since we have no address yet
+	d.read(regSHAR, 0, m[28:34])
+	m[236], m[237], m[238], m[239] = 99, 130, 83, 99 // magic cookie
+
+	n := dhcpHeader
+	n += copy(m[n:], []byte{optType, 1, typ})
+	if 4 == len(offer.IP) {
+		n += copy(m[n:], []byte{optRequested, 4})
+		n += copy(m[n:], offer.IP)
+		n += copy(m[n:], []byte{optServer, 4})
+		n += copy(m[n:], offer.Server)
+	}
+	if len(hostname) > 0 && len(hostname) < 64 {
+		n += copy(m[n:], []byte{optHostname, byte(len(hostname))})
+		n += copy(m[n:], hostname)
+	}
+	n += copy(m[n:], []byte{optParams, 4, optSubnet, optRouter, optDNS, optLease, optEnd})
+	if _, err := d.send(sockUtil, m[:n]); nil != err {
+		return Lease{}, err
+	}
+
+	start := time.Now()
+	for time.Since(start) < DHCPTimeout {
+		_, n := d.recvFrom(sockUtil, m, 8)
+		if n < dhcpHeader || 2 != m[0] ||
+			uint32(m[4])<<24|uint32(m[5])<<16|uint32(m[6])<<8|uint32(m[7]) != xid {
+			time.Sleep(10 * time.Millisecond)
+			continue // nothing received, or not a reply to us
+		}
+		lease, typ := parseLease(m[:n])
+		if reply == typ {
+			return lease, nil
+		}
+		if dhcpAck < typ {
+			break // declined (NAK)
+		}
+	}
+	return Lease{}, ErrNoLease
+}
+
+// parseLease returns the IP configuration and message type of the given reply.
+func parseLease(m []byte) (lease Lease, typ uint8) {
+	lease.IP = net.IP{m[16], m[17], m[18], m[19]}
+	for i := dhcpHeader; i+1 < len(m) && optEnd != m[i]; {
+		if 0 == m[i] {
+			i++ // padding
+			continue
+		}
+		opt, size := m[i], int(m[i+1])
+		val := m[i+2:]
+		if size > len(val) {
+			break
+		}
+		val = val[:size]
+		switch {
+		case optType == opt && size >= 1:
+			typ = val[0]
+		case optSubnet == opt && size >= 4:
+			lease.Subnet = net.IP{val[0], val[1], val[2], val[3]}
+		case optRouter == opt && size >= 4:
+			lease.Gateway = net.IP{val[0], val[1], val[2], val[3]}
+		case optDNS == opt && size >= 4:
+			lease.DNS = net.IP{val[0], val[1], val[2], val[3]}
+		case optServer == opt && size >= 4:
+			lease.Server = net.IP{val[0], val[1], val[2], val[3]}
+		case optLease == opt && size >= 4:
+			secs := uint32(val[0])<<24 | uint32(val[1])<<16 | uint32(val[2])<<8 | uint32(val[3])
+			lease.Duration = time.Duration(secs) * time.Second
+		}
+		i += 2 + size
+	}
+	return lease, typ
+}
@@ -0,0 +1,106 @@
+package w5500
+
+import (
+	"strings"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+)
+
+// DNSTimeout defines how long to wait for the reply of the DNS server.
+const DNSTimeout = 2 * time.Second
+
+// GetHostByName returns the IPv4 address of the given host name, resolved by
+// the DNS server of the lease.
+func (d *Device) GetHostByName(name string) (net.IP, error) {
+	if ip := net.ParseIP(name); nil != ip {
+		return ip, nil
+	}
+	if 4 != len(d.lease.DNS) {
+		return nil, ErrNoLease
+	}
+	if err := d.open(sockUtil, modeUDP, d.ephemeral()); nil != err {
+		return nil, err
+	}
+	defer d.close(sockUtil)
+	if err := d.setDestination(sockUtil, d.lease.DNS, 53); nil != err {
+		return nil, err
+	}
+
+	// query of a single question, recursion desired
+	id := uint16(time.Now().UnixNano())
+	m := d.query[:]
+	n := copy(m, []byte{byte(id >> 8), byte(id), 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0})
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if 0 == len(label) || len(label) > 63 || n+len(label)+6 > len(m) {
+			return nil, ErrBadAddress
+		}
+		m[n] = byte(len(label))
+		n += 1 + copy(m[n+1:], label)
+	}
+	n += copy(m[n:], []byte{0, 0, 1, 0, 1}) // root, type A, class IN
+	if _, err := d.send(sockUtil, m[:n]); nil != err {
+		return nil, err
+	}
+
+	start := time.Now()
+	for time.Since(start) < DNSTimeout {
+		_, n := d.recvFrom(sockUtil, m, 8)
+		if n < 12 || uint16(m[0])<<8|uint16(m[1]) != id {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		if ip := parseAnswer(m[:n]); nil != ip {
+			return ip, nil
+		}
+		break
+	}
+	return nil, ErrBadAddress
+}
+
+// parseAnswer returns the first IPv4 address answered by the given reply, or nil
+// if none.
+func parseAnswer(m []byte) net.IP {
+	if 0 != m[3]&0x0F {
+		return nil // error code
+	}
+	questions := int(m[4])<<8 | int(m[5])
+	answers := int(m[6])<<8 | int(m[7])
+	i := 12
+	for ; questions > 0; questions-- {
+		i = skipName(m, i) + 4 // type, class
+	}
+	for ; answers > 0 && i < len(m); answers-- {
+		i = skipName(m, i)
+		if i+10 > len(m) {
+			return nil
+		}
+		typ := int(m[i])<<8 | int(m[i+1])
+		size := int(m[i+8])<<8 | int(m[i+9])
+		i += 10
+		if i+size > len(m) {
+			return nil
+		}
+		if 1 == typ && 4 == size {
+			return net.IP{m[i], m[i+1], m[i+2], m[i+3]}
+		}
+		i += size // e.g., CNAME
+	}
+	return nil
+}
+
+// skipName returns the index following the name beginning at index i, which is
+// either a sequence of labels or a pointer to one.
+func skipName(m []byte, i int) int {
+	for i < len(m) {
+		switch n := int(m[i]); {
+		case 0 == n:
+			return i + 1
+		case 0xC0 == n&0xC0:
+			return i + 2
+		default:
+			i += 1 + n
+		}
+	}
+	return i
+}
@@ -0,0 +1,140 @@
+package w5500
+
+import (
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers/net"
+)
+
+// The methods in this file implement the DeviceDriver interface of package net,
+// whose sockets are used one at a time (e.g., net.DialTCP disconnects the socket
+// previously dialed), all using sockClient.
+
+// GetDNS returns the IP address of the given domain name.
+func (d *Device) GetDNS(domain string) (string, error) {
+	ip, err := d.GetHostByName(domain)
+	if nil != err {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// ConnectTCPSocket connects to the given IP address and port.
+func (d *Device) ConnectTCPSocket(addr, port string) error {
+	ip, dport, err := d.resolve(addr, port)
+	if nil != err {
+		return err
+	}
+	if err := d.open(sockClient, modeTCP, d.ephemeral()); nil != err {
+		return err
+	}
+	d.mode = modeTCP
+	if err := d.setDestination(sockClient, ip, dport); nil != err {
+		return err
+	}
+	if err := d.command(sockClient, cmdConnect); nil != err {
+		return err
+	}
+	start := time.Now()
+	for time.Since(start) < ConnectTimeout {
+		switch d.status(sockClient) {
+		case statEstablished:
+			return nil
+		case statClosed:
+			return ErrConnect // refused, or the controller timed out
+		}
+		time.Sleep(time.Millisecond)
+	}
+	d.close(sockClient)
+	return ErrConnect
+}
+
+// ConnectSSLSocket returns ErrNoTLS, since the controller has no TLS.
+func (d *Device) ConnectSSLSocket(addr, port string) error {
+	return ErrNoTLS
+}
+
+// ConnectUDPSocket opens a UDP socket on local port listenport sending
+// datagrams to the given IP address and port sendport.
+func (d *Device) ConnectUDPSocket(addr, sendport, listenport string) error {
+	ip, dport, err := d.resolve(addr, sendport)
+	if nil != err {
+		return err
+	}
+	lport, err := strconv.ParseUint(listenport, 10, 16)
+	if nil != err {
+		return ErrBadAddress
+	}
+	if err := d.open(sockClient, modeUDP, uint16(lport)); nil != err {
+		return err
+	}
+	d.mode = modeUDP
+	return d.setDestination(sockClient, ip, dport)
+}
+
+// DisconnectSocket closes the socket, disconnecting gracefully if connected.
+func (d *Device) DisconnectSocket() error {
+	if statEstablished == d.status(sockClient) {
+		d.command(sockClient, cmdDiscon)
+	}
+	d.close(sockClient)
+	d.mode = 0
+	return nil
+}
+
+// StartSocketSend does nothing, since data written is sent as is.
+func (d *Device) StartSocketSend(size int) error {
+	return nil
+}
+
+// Write sends b to the peer of the socket, as a single datagram if UDP.
+func (d *Device) Write(b []byte) (int, error) {
+	return d.send(sockClient, b)
+}
+
+// ReadSocket reads any data received by the socket into b, without waiting. If
+// UDP, a single datagram is read, and any part of it beyond len(b) discarded.
+func (d *Device) ReadSocket(b []byte) (int, error) {
+	if modeUDP == d.mode {
+		_, n := d.recvFrom(sockClient, b, 8)
+		return n, nil
+	}
+	return d.Receive(sockClient, b)
+}
+
+// IsSocketDataAvailable returns true if data has been received by the socket.
+func (d *Device) IsSocketDataAvailable() bool {
+	return d.available(sockClient) > 0
+}
+
+// Response is used only by AT command drivers, and returns nothing.
+func (d *Device) Response(timeout int) ([]byte, error) {
+	return nil, nil
+}
+
+// resolve returns the IP address and port of the given address, which may be
+// an IP address or a host name, and port.
+func (d *Device) resolve(addr, port string) (net.IP, uint16, error) {
+	p, err := strconv.ParseUint(port, 10, 16)
+	if nil != err {
+		return nil, 0, ErrBadAddress
+	}
+	ip := net.ParseIP(addr)
+	if nil == ip {
+		if ip, err = d.GetHostByName(addr); nil != err {
+			return nil, 0, err
+		}
+	}
+	return ip, uint16(p), nil
+}
+
+// ephemeral returns the next local port of the dynamic range to use for an
+// outgoing connection, so that a new connection is not mistaken by the peer for
+// one just closed.
+func (d *Device) ephemeral() uint16 {
+	if d.port++; d.port < 49152 {
+		d.port = 49152
+	}
+	return d.port
+}
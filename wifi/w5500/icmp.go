@@ -0,0 +1,62 @@
+package w5500
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers/net"
+)
+
+// PingTimeout defines how long to wait for an echo reply.
+const PingTimeout = time.Second
+
+// Ping sends an ICMP echo request to the given IP address with the given time
+// to live, and returns the round-trip time. Returns ErrTimeout if no reply is
+// received.
+func (d *Device) Ping(ip net.IP, ttl uint8) (time.Duration, error) {
+	if err := d.open(sockUtil, modeIPRAW, 0); nil != err {
+		return 0, err
+	}
+	defer d.close(sockUtil)
+	d.write8(snPROTO, block(sockUtil, 0), 1) // ICMP
+	d.write8(snTTL, block(sockUtil, 0), ttl)
+	if err := d.setDestination(sockUtil, ip, 0); nil != err {
+		return 0, err
+	}
+
+	// echo request, identified by our ephemeral port, with a short payload
+	id := d.ephemeral()
+	m := d.query[:16]
+	copy(m, []byte{8, 0, 0, 0, byte(id >> 8), byte(id), 0, 1})
+	copy(m[8:], "weather!")
+	sum := checksum(m)
+	m[2], m[3] = byte(sum>>8), byte(sum)
+
+	start := time.Now()
+	if _, err := d.send(sockUtil, m); nil != err {
+		return 0, err
+	}
+	for time.Since(start) < PingTimeout {
+		from, n := d.recvFrom(sockUtil, d.query[:], 6)
+		if n >= 8 && 0 == d.query[0] && string(from) == string(ip.To4()) &&
+			uint16(d.query[4])<<8|uint16(d.query[5]) == id {
+			return time.Since(start), nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return 0, ErrTimeout
+}
+
+// checksum returns the Internet checksum of b (RFC 1071).
+func checksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if 0 != len(b)%2 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xFFFF {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return ^uint16(sum)
+}
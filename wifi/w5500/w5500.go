@@ -0,0 +1,381 @@
+// Package w5500 implements a driver for the WIZnet W5500 Ethernet controller,
+// which provides a hardwired TCP/IP stack of eight sockets over SPI.
+//
+// The Device implements the DeviceDriver interface of package net, so that the
+// sockets of package net (e.g., net.DialTCP) may be used over Ethernet just as
+// over WiFi. The controller has no TLS, so secure sockets are not supported.
+//
+// The controller has no IP configuration of its own. It is leased from a DHCP
+// server (see DHCP), and host names are resolved by querying the DNS server of
+// the lease (see GetHostByName).
+package w5500
+
+import (
+	"errors"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/net"
+)
+
+// Constants defining the timing of socket operations.
+const (
+	CommandTimeout = 100 * time.Millisecond // for the controller to accept a command
+	ConnectTimeout = 5 * time.Second        // for a TCP connection to be established
+	SendTimeout    = 5 * time.Second        // for data sent to be acknowledged
+)
+
+var (
+	ErrNoDevice   = errors.New("W5500 not found")
+	ErrNoSocket   = errors.New("no socket available")
+	ErrNoTLS      = errors.New("secure sockets not supported by W5500")
+	ErrNoLease    = errors.New("no IP address leased from DHCP server")
+	ErrTimeout    = errors.New("timeout waiting for W5500")
+	ErrConnect    = errors.New("connection refused or timed out")
+	ErrClosed     = errors.New("socket closed")
+	ErrBadAddress = errors.New("invalid address")
+)
+
+// Socket is one of the eight hardware sockets of the controller.
+type Socket uint8
+
+// Sockets reserved for particular uses. The sockets of package net are used one
+// at a time, so a single socket serves them all.
+const (
+	sockClient Socket = 0 // used by package net
+	sockUtil   Socket = 1 // used by DHCP, DNS, and ping
+	sockServer Socket = 2 // first of those used by servers (see Listen)
+	numSockets        = 8
+)
+
+// Common registers.
+const (
+	regMR      = 0x0000 // mode
+	regGAR     = 0x0001 // gateway address
+	regSUBR    = 0x0005 // subnet mask
+	regSHAR    = 0x0009 // source hardware address
+	regSIPR    = 0x000F // source IP address
+	regPHYCFGR = 0x002E // PHY configuration
+	regVERSION = 0x0039 // chip version
+
+	mrReset     = 0x80
+	phyLink     = 0x01
+	chipVersion = 0x04
+)
+
+// Socket registers.
+const (
+	snMR    = 0x0000 // mode
+	snCR    = 0x0001 // command
+	snIR    = 0x0002 // interrupt
+	snSR    = 0x0003 // status
+	snPORT  = 0x0004 // source port
+	snDIPR  = 0x000C // destination IP address
+	snDPORT = 0x0010 // destination port
+	snPROTO = 0x0014 // IP protocol of IPRAW mode
+	snTTL   = 0x0016 // time to live
+	snTXFSR = 0x0020 // free size of TX buffer
+	snTXWR  = 0x0024 // TX write pointer
+	snRXRSR = 0x0026 // size of data received
+	snRXRD  = 0x0028 // RX read pointer
+
+	modeTCP   = 0x01
+	modeUDP   = 0x02
+	modeIPRAW = 0x03
+
+	cmdOpen    = 0x01
+	cmdListen  = 0x02
+	cmdConnect = 0x04
+	cmdDiscon  = 0x08
+	cmdClose   = 0x10
+	cmdSend    = 0x20
+	cmdRecv    = 0x40
+
+	irTimeout = 0x08
+	irSendOK  = 0x10
+
+	statClosed      = 0x00
+	statListen      = 0x14
+	statEstablished = 0x17
+	statCloseWait   = 0x1C
+)
+
+// Config defines the hardware address of the controller, which has none of its
+// own. It must be unique on the network.
+type Config struct {
+	MAC [6]byte
+}
+
+// Device wraps the SPI interface of the W5500.
+type Device struct {
+	SPI drivers.SPI
+	CS  machine.Pin
+
+	mode  uint8     // mode of sockClient, while open
+	used  uint8     // bit set of sockets in use
+	port  uint16    // last ephemeral port used by sockClient
+	lease Lease     // IP configuration leased from the DHCP server
+	hdr   [3]byte   // address and control phase of SPI frames
+	reg   [6]byte   // value of registers read or written
+	query [512]byte // DHCP, DNS, and ICMP messages
+}
+
+// Configure resets the controller and sets its hardware address. Returns
+// ErrNoDevice if no W5500 replies.
+func (d *Device) Configure(config Config) error {
+	d.CS.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	d.CS.High()
+	d.write8(regMR, 0, mrReset)
+	time.Sleep(10 * time.Millisecond)
+	if chipVersion != d.read8(regVERSION, 0) {
+		return ErrNoDevice
+	}
+	d.write(regSHAR, 0, config.MAC[:])
+	d.used = 1<<sockClient | 1<<sockUtil
+	d.port = 49152
+	return nil
+}
+
+// LinkUp returns true if a cable is connected to a powered link partner.
+func (d *Device) LinkUp() bool {
+	return 0 != d.read8(regPHYCFGR, 0)&phyLink
+}
+
+// Lease returns the IP configuration last leased from the DHCP server.
+func (d *Device) Lease() Lease {
+	return d.lease
+}
+
+// block returns the block select bits addressing the registers (0), TX buffer
+// (1), or RX buffer (2) of socket s.
+func block(s Socket, buf uint8) uint8 {
+	return uint8(s)<<2 + 1 + buf
+}
+
+func (d *Device) transfer(addr uint16, bsb uint8, write bool, b []byte) {
+	d.hdr[0], d.hdr[1], d.hdr[2] = byte(addr>>8), byte(addr), bsb<<3
+	d.CS.Low()
+	if write {
+		d.hdr[2] |= 0x04
+		d.SPI.Tx(d.hdr[:], nil)
+		d.SPI.Tx(b, nil)
+	} else {
+		d.SPI.Tx(d.hdr[:], nil)
+		d.SPI.Tx(nil, b)
+	}
+	d.CS.High()
+}
+
+func (d *Device) read(addr uint16, bsb uint8, b []byte)  { d.transfer(addr, bsb, false, b) }
+func (d *Device) write(addr uint16, bsb uint8, b []byte) { d.transfer(addr, bsb, true, b) }
+
+func (d *Device) read8(addr uint16, bsb uint8) uint8 {
+	d.read(addr, bsb, d.reg[:1])
+	return d.reg[0]
+}
+
+func (d *Device) write8(addr uint16, bsb uint8, v uint8) {
+	d.reg[0] = v
+	d.write(addr, bsb, d.reg[:1])
+}
+
+// read16 returns the value of a 16-bit counter, which must be read until two
+// reads agree, since it may change between reading its two bytes.
+func (d *Device) read16(addr uint16, bsb uint8) uint16 {
+	var v uint16
+	for {
+		d.read(addr, bsb, d.reg[:2])
+		u := uint16(d.reg[0])<<8 | uint16(d.reg[1])
+		if u == v {
+			return v
+		}
+		v = u
+	}
+}
+
+func (d *Device) write16(addr uint16, bsb uint8, v uint16) {
+	d.reg[0], d.reg[1] = byte(v>>8), byte(v)
+	d.write(addr, bsb, d.reg[:2])
+}
+
+// command issues command cmd to socket s, and waits for it to be accepted.
+func (d *Device) command(s Socket, cmd uint8) error {
+	d.write8(snCR, block(s, 0), cmd)
+	start := time.Now()
+	for 0 != d.read8(snCR, block(s, 0)) {
+		if time.Since(start) > CommandTimeout {
+			return ErrTimeout
+		}
+	}
+	return nil
+}
+
+func (d *Device) status(s Socket) uint8 {
+	return d.read8(snSR, block(s, 0))
+}
+
+// open closes socket s, if open, and opens it again in the given mode bound to
+// the given local port.
+func (d *Device) open(s Socket, mode uint8, port uint16) error {
+	d.close(s)
+	d.write8(snMR, block(s, 0), mode)
+	d.write16(snPORT, block(s, 0), port)
+	return d.command(s, cmdOpen)
+}
+
+// close closes socket s immediately, without disconnecting gracefully.
+func (d *Device) close(s Socket) {
+	d.command(s, cmdClose)
+	d.write8(snIR, block(s, 0), 0xFF)
+}
+
+// setDestination sets the IP address and port to which socket s connects or
+// sends datagrams.
+func (d *Device) setDestination(s Socket, ip net.IP, port uint16) error {
+	if ip = ip.To4(); 4 != len(ip) {
+		return ErrBadAddress
+	}
+	d.write(snDIPR, block(s, 0), ip)
+	d.write16(snDPORT, block(s, 0), port)
+	return nil
+}
+
+// send writes b to the TX buffer of socket s and sends it, waiting for it to be
+// acknowledged (TCP) or transmitted (UDP, IPRAW).
+func (d *Device) send(s Socket, b []byte) (int, error) {
+	n := 0
+	for n < len(b) {
+		free := int(d.read16(snTXFSR, block(s, 0)))
+		if 0 == free {
+			if statClosed == d.status(s) {
+				return n, ErrClosed
+			}
+			continue
+		}
+		chunk := b[n:]
+		if len(chunk) > free {
+			chunk = chunk[:free]
+		}
+		ptr := d.read16(snTXWR, block(s, 0))
+		d.write(ptr, block(s, 1), chunk)
+		d.write16(snTXWR, block(s, 0), ptr+uint16(len(chunk)))
+		if err := d.command(s, cmdSend); nil != err {
+			return n, err
+		}
+		start := time.Now()
+		for {
+			ir := d.read8(snIR, block(s, 0))
+			if 0 != ir&irSendOK {
+				d.write8(snIR, block(s, 0), irSendOK)
+				break
+			}
+			if 0 != ir&irTimeout || time.Since(start) > SendTimeout {
+				d.write8(snIR, block(s, 0), irTimeout)
+				return n, ErrTimeout
+			}
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// available returns the number of bytes received by socket s and not yet read.
+func (d *Device) available(s Socket) int {
+	return int(d.read16(snRXRSR, block(s, 0)))
+}
+
+// recv reads up to len(b) bytes received by socket s, without waiting.
+func (d *Device) recv(s Socket, b []byte) int {
+	n := d.available(s)
+	if n > len(b) {
+		n = len(b)
+	}
+	if 0 == n {
+		return 0
+	}
+	ptr := d.read16(snRXRD, block(s, 0))
+	d.read(ptr, block(s, 2), b[:n])
+	d.write16(snRXRD, block(s, 0), ptr+uint16(n))
+	d.command(s, cmdRecv)
+	return n
+}
+
+// recvFrom reads the next datagram received by socket s into b, without
+// waiting, and returns the address of its sender. Any part of the datagram
+// beyond len(b) is discarded. The header preceding each datagram is 8 bytes in
+// UDP mode (address, port, length) and 6 bytes in IPRAW mode (address, length).
+func (d *Device) recvFrom(s Socket, b []byte, hlen uint16) (net.IP, int) {
+	if d.available(s) < int(hlen) {
+		return nil, 0
+	}
+	var hdr [8]byte
+	ptr := d.read16(snRXRD, block(s, 0))
+	d.read(ptr, block(s, 2), hdr[:hlen])
+	size := uint16(hdr[hlen-2])<<8 | uint16(hdr[hlen-1])
+	n := int(size)
+	if n > len(b) {
+		n = len(b)
+	}
+	d.read(ptr+hlen, block(s, 2), b[:n])
+	d.write16(snRXRD, block(s, 0), ptr+hlen+size)
+	d.command(s, cmdRecv)
+	return net.IP{hdr[0], hdr[1], hdr[2], hdr[3]}, n
+}
+
+// Listen opens a free socket listening for TCP connections on the given local
+// port. Returns ErrNoSocket if all sockets reserved for servers are in use.
+func (d *Device) Listen(port uint16) (Socket, error) {
+	for s := sockServer; s < numSockets; s++ {
+		if 0 != d.used&(1<<s) {
+			continue
+		}
+		if err := d.open(s, modeTCP, port); nil != err {
+			return 0, err
+		}
+		if err := d.command(s, cmdListen); nil != err {
+			return 0, err
+		}
+		d.used |= 1 << s
+		return s, nil
+	}
+	return 0, ErrNoSocket
+}
+
+// Accepted returns true if a client has connected to listening socket s.
+func (d *Device) Accepted(s Socket) bool {
+	switch d.status(s) {
+	case statEstablished, statCloseWait:
+		return true
+	}
+	return false
+}
+
+// Receive reads any data received by connected socket s into b, without
+// waiting. Returns 0 with a nil error if no data has been received.
+func (d *Device) Receive(s Socket, b []byte) (int, error) {
+	if n := d.recv(s, b); n > 0 {
+		return n, nil
+	}
+	if statClosed == d.status(s) {
+		return 0, ErrClosed
+	}
+	return 0, nil
+}
+
+// Send sends b to the client of connected socket s.
+func (d *Device) Send(s Socket, b []byte) (int, error) {
+	return d.send(s, b)
+}
+
+// Close disconnects socket s, if connected, and frees it for reuse.
+func (d *Device) Close(s Socket) error {
+	if statEstablished == d.status(s) {
+		d.command(s, cmdDiscon)
+	}
+	d.close(s)
+	if s >= sockServer {
+		d.used &^= 1 << s
+	}
+	return nil
+}
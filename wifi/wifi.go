@@ -1,93 +1,122 @@
-// Package wifi implements an interface to the WiFi coprocessor.
+// Package wifi implements an interface to the network, which is the WiFi
+// coprocessor unless built with the tag "ethernet" (see ethernet.go), selecting
+// a W5500 Ethernet module instead, or the tag "fake" (see fake.go), selecting an
+// emulated network with no hardware at all. The interface is the same either
+// way, so that the clients of the network (e.g., packages http and ntp) are
+// unchanged.
 package wifi
 
 import (
 	"errors"
-	"machine"
 	"time"
 
 	"tinygo.org/x/drivers/net"
-	"tinygo.org/x/drivers/wifinina"
-
-	"github.com/ardnew/weatherhub/model"
-	"github.com/ardnew/weatherhub/wifi/network"
 )
 
+// DefaultName defines the name of the device on the network.
+const DefaultName = "weatherhub"
+
 var (
 	ErrConnectToAP  = errors.New("failed to connect to access point")
 	ErrNoIPAddress  = errors.New("could not obtain IP address from access point")
 	ErrNotConnected = errors.New("not connected to access point")
+	ErrPingFailed   = errors.New("no reply to ping")
+	ErrWrongBSSID   = errors.New("joined access point with unexpected BSSID")
 )
 
-// WiFi wraps the WiFiNINA device driver.
-type WiFi struct {
-	nina *wifinina.Device
-	ip   wifinina.IPAddress
+// Config defines the identity of the device on the network.
+//
+// Name is the host name announced to the DHCP server, and it is the default
+// identity used by other network clients, e.g., the MQTT client ID and topic
+// prefix. It must be unique among devices on the network, so that several
+// devices may be used together.
+//
+// The WiFiNINA firmware provides no mDNS responder, so the name is only
+// announced via DHCP, which many routers register in their local DNS.
+type Config struct {
+	Name string
 }
 
-// New returns a new WiFi using the default peripherals and GPIO pins.
-// The SPI interface connected to the WiFi coprocessor is also initialized and
-// configured for use.
-// This method will always return a nil WiFi or a nil error. It will never
-// return nil or non-nil for both WiFi and error.
-func New() (*WiFi, error) {
+// Name returns the name of the device on the network.
+func (w *WiFi) Name() string {
+	return w.config.Name
+}
 
-	// configure the SPI interface connected to ESP32
-	spiConfig := machine.SPIConfig{
-		Frequency: 8 * 1.0E6,
-		SDO:       machine.NINA_SDO,
-		SDI:       machine.NINA_SDI,
-		SCK:       machine.NINA_SCK,
+// SetName changes the name of the device on the network, or restores
+// DefaultName if empty, which is announced when the network is next joined.
+func (w *WiFi) SetName(name string) {
+	if "" == name {
+		name = DefaultName
 	}
-	machine.NINA_SPI.Configure(spiConfig)
+	w.config.Name = name
+}
 
-	// configure the WiFiNINA driver
-	nina := &wifinina.Device{
-		SPI:   machine.NINA_SPI,
-		CS:    machine.NINA_CS,
-		ACK:   machine.NINA_ACK,
-		GPIO0: machine.NINA_GPIO0,
-		RESET: machine.NINA_RESETN,
+// GetHostByName returns the IP address of the given host name.
+// Recently resolved names are cached (see DNSTTL and DNSStale).
+func (w *WiFi) GetHostByName(name string) (net.IP, error) {
+	if !w.isConnected() || !w.hasIP() {
+		return nil, ErrNotConnected
 	}
-	nina.Configure()
-
-	return &WiFi{nina: nina}, nil
+	return w.dns.resolve(name, w.getHostByName)
 }
 
-// Connect establishes an AP connection using given SSID and passphrase.
-// An error is returned if the AP could not be reached or an IP not obtained.
-func (w *WiFi) Connect(ap network.AP) error {
-
-	// attempt to connect to SSID with passphrase
-	time.Sleep(2 * time.Second)
-	w.nina.SetPassphrase(ap.SSID, ap.Pass)
-
-	// wait for connection established
-	if !w.waitWithTimeout(w.isConnected) {
-		return ErrConnectToAP
+// DefaultTTL defines the time to live of ping requests, which is the number of
+// hops allowed to reach the host.
+const DefaultTTL = 64
+
+// Ping sends an ICMP echo request to the given host name or IP address with the
+// given time to live, or DefaultTTL if zero, and returns the round-trip time.
+// Returns ErrPingFailed if no reply is received, e.g., if the host is more than
+// ttl hops away.
+func (w *WiFi) Ping(host string, ttl uint8) (time.Duration, error) {
+	if 0 == ttl {
+		ttl = DefaultTTL
 	}
-	// wait for DHCP IP lease
-	if !w.waitWithTimeout(w.hasIP) {
-		return ErrNoIPAddress
+	ip := net.ParseIP(host)
+	if nil == ip {
+		var err error
+		if ip, err = w.GetHostByName(host); nil != err {
+			return 0, err
+		}
 	}
-
-	// update model with our connection details
-	model.Set(func(m *model.Model) {
-		m.AP, m.IP = ap, w.ip
-	})
-
-	return nil
+	return w.ping(ip, ttl)
 }
 
-func (w *WiFi) GetHostByName(name string) (net.IP, error) {
-	if !w.isConnected() || !w.hasIP() {
-		return nil, ErrNotConnected
+// Hops returns the number of hops to the given host name or IP address, i.e.,
+// the least time to live with which a ping reaches it, up to max. The
+// coprocessor does not report the routers along the way, so unlike traceroute,
+// only the distance is known. Returns ErrPingFailed if the host is not reached.
+//
+// Each ping blocks until its reply or timeout, so rather than trying each time
+// to live in turn, the distance is found by bisection, with at most 1+log2(max)
+// pings, e.g., 6 of 30 hops, and only 1 if the host is not reached at all.
+func (w *WiFi) Hops(host string, max uint8) (uint8, error) {
+	ip := net.ParseIP(host)
+	if nil == ip {
+		var err error
+		if ip, err = w.GetHostByName(host); nil != err {
+			return 0, err
+		}
+	}
+	// the host is reached within max hops, or not at all.
+	if 0 == max {
+		return 0, ErrPingFailed
 	}
-	addr, err := w.nina.GetHostByName(name)
-	if nil != err {
-		return nil, err
+	if _, err := w.ping(ip, max); nil != err {
+		return 0, err
 	}
-	return net.ParseIP(addr.String()), nil
+	lo, hi := uint8(0), max // not reached with lo, reached with hi
+	for hi-lo > 1 {
+		ttl := lo + (hi-lo)/2
+		if _, err := w.ping(ip, ttl); nil == err {
+			hi = ttl
+		} else if ErrPingFailed == err {
+			lo = ttl
+		} else {
+			return 0, err
+		}
+	}
+	return hi, nil
 }
 
 func (w *WiFi) waitWithTimeout(ready func() bool) (ok bool) {
@@ -104,14 +133,3 @@ func (w *WiFi) waitWithTimeout(ready func() bool) (ok bool) {
 	}
 	return
 }
-
-func (w *WiFi) isConnected() bool {
-	stat, _ := w.nina.GetConnectionStatus()
-	return wifinina.StatusConnected == stat
-}
-
-func (w *WiFi) hasIP() bool {
-	var err error
-	w.ip, _, _, err = w.nina.GetIP()
-	return nil == err
-}